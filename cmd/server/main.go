@@ -10,10 +10,12 @@ import (
 	"syscall"
 	"time"
 
+	"geoanomaly/internal/analytics"
 	"geoanomaly/internal/auth"
 	"geoanomaly/internal/game"
 	"geoanomaly/internal/gameplay"
 	"geoanomaly/internal/media"
+	"geoanomaly/internal/realtime"
 	"geoanomaly/pkg/middleware"
 
 	"github.com/joho/godotenv"
@@ -23,11 +25,14 @@ import (
 )
 
 var (
-	db          *gorm.DB
-	redisClient *redis.Client
-	StartTime   time.Time
-	scheduler   *game.Scheduler
-	r2Client    *media.R2Client // Pridané pre R2
+	db                 *gorm.DB
+	redisClient        *redis.Client
+	StartTime          time.Time
+	scheduler          *game.Scheduler
+	rollupWorker       *analytics.RollupWorker
+	spawnConfigWatcher *game.SpawnConfigWatcher
+	realtimeHub        *realtime.Hub
+	r2Client           *media.R2Client // Pridané pre R2
 )
 
 func min(a, b int) int {
@@ -67,6 +72,7 @@ func main() {
 	if redisClient != nil {
 		log.Println("✅ Redis connected successfully")
 		middleware.LoadBlacklistFromRedis(redisClient)
+		middleware.SetRevocationStore(redisClient)
 	} else {
 		log.Println("⚠️  Redis disabled - security middleware will work without persistence")
 	}
@@ -124,12 +130,34 @@ func main() {
 		}
 	}
 
+	// Realtime push (WebSocket/SSE) - fan-out cez Redis pub/sub medzi inštanciami
+	realtimeHub = realtime.NewHub(redisClient)
+	realtimeHub.Start()
+	realtime.SetDefault(realtimeHub)
+	log.Println("✅ Realtime hub started")
+
+	// Zone lifecycle udalosti → inbox notifikácie (last chance, zmiznutá zóna)
+	game.OnZoneLifecycle(game.NewZoneLifecycleNotifier(db).Handle)
+
+	// Spawn tabuľky z DB (prázdna tabuľka sa naseeduje hodnotami z kódu)
+	if err := game.LoadSpawnConfig(db); err != nil {
+		log.Printf("⚠️  Failed to load spawn config, using built-in defaults: %v", err)
+	}
+	spawnConfigWatcher = game.NewSpawnConfigWatcher(db)
+	go spawnConfigWatcher.Start()
+	log.Println("✅ Spawn config loaded (hot reload every 30s)")
+
 	// Start zone cleanup scheduler
 	log.Println("🕐 Starting Zone TTL Cleanup Scheduler...")
 	scheduler = game.NewScheduler(db)
 	scheduler.Start()
 	log.Println("✅ Zone cleanup scheduler started (5min interval)")
 
+	// Start analytics rollup worker (event log → denné tabuľky)
+	rollupWorker = analytics.NewRollupWorker(db)
+	go rollupWorker.Start()
+	log.Println("✅ Analytics rollup worker started (15min interval)")
+
 	// Setup graceful shutdown
 	setupGracefulShutdown()
 
@@ -199,6 +227,24 @@ func setupGracefulShutdown() {
 			log.Println("✅ Zone cleanup scheduler stopped")
 		}
 
+		// Stop analytics rollup worker
+		if rollupWorker != nil {
+			rollupWorker.Stop()
+			log.Println("✅ Analytics rollup worker stopped")
+		}
+
+		// Stop spawn config watcher
+		if spawnConfigWatcher != nil {
+			spawnConfigWatcher.Stop()
+			log.Println("✅ Spawn config watcher stopped")
+		}
+
+		// Stop realtime hub
+		if realtimeHub != nil {
+			realtimeHub.Stop()
+			log.Println("✅ Realtime hub stopped")
+		}
+
 		// Close Redis connection
 		if redisClient != nil {
 			redisClient.Close()
@@ -271,6 +317,14 @@ func testEnvConfig() error {
 
 	log.Printf("🔑 JWT Secret: %s... (length: %d)", jwtSecret[:8], len(jwtSecret))
 
+	// Test world seed - s prázdnym secretom by sa layout sveta dal vypočítať dopredu
+	worldSeed := GetEnvVar("WORLD_SEED", "")
+	if len(worldSeed) < 16 {
+		return fmt.Errorf("WORLD_SEED must be at least 16 characters long and identical on all instances")
+	}
+
+	log.Printf("🌍 World seed configured (length: %d)", len(worldSeed))
+
 	return nil
 }
 
@@ -5,23 +5,30 @@ import (
 	"time"
 
 	"geoanomaly/internal/admin"
+	"geoanomaly/internal/analytics"
 	"geoanomaly/internal/auth"
 	"geoanomaly/internal/battery"
 	"geoanomaly/internal/deployable"
+	"geoanomaly/internal/friends"
 	"geoanomaly/internal/game"
 	"geoanomaly/internal/gameplay"
 	"geoanomaly/internal/inventory"
 	"geoanomaly/internal/laboratory"
+	"geoanomaly/internal/leaderboard"
 	"geoanomaly/internal/loadout"
 	"geoanomaly/internal/location"
 	"geoanomaly/internal/media"
 	"geoanomaly/internal/menu"
+	"geoanomaly/internal/movement"
+	"geoanomaly/internal/notifications"
+	"geoanomaly/internal/realtime"
 	"geoanomaly/internal/scanner"
 	"geoanomaly/internal/user"
 	"geoanomaly/internal/xp"
 	"geoanomaly/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -46,8 +53,9 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 	// Initialize handlers
 	authHandler := auth.NewHandler(db, nil)
 	userHandler := user.NewHandler(db, nil)
-	gameHandler := game.NewHandler(db, nil)
+	gameHandler := game.NewHandler(db, redisClient).WithWorldSeed(game.NewWorldSeed(GetEnvVar("WORLD_SEED", "")))
 	locationHandler := location.NewHandler(db, nil)
+	friendsHandler := friends.NewHandler(db, locationHandler.Friends())
 	inventoryHandler := inventory.NewHandler(db)
 	menuHandler := menu.NewHandler(db)
 	loadoutHandler := loadout.NewHandler(db)
@@ -57,15 +65,43 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 	scannerHandler := scanner.NewHandler(scannerService, db)
 
 	// Initialize deployable scanner service and handler
-	deployableService := deployable.NewService(db)
+	deployableService := deployable.NewService(db).WithMinigameSecret(GetEnvVar("MINIGAME_SECRET", GetEnvVar("JWT_SECRET", "")))
 	deployableHandler := deployable.NewHandler(deployableService)
 
+	// Po expirácii tieru aj po zmene tieru adminom sa zariadenia nad limit nového tieru zmrazia (najnovšie prvé)
+	enforceDeviceLimits := func(userID uuid.UUID, tier int) {
+		if _, err := deployableService.EnforceTierLimits(userID, tier); err != nil {
+			log.Printf("⚠️ Device limit enforcement failed for user %s: %v", userID, err)
+		}
+	}
+	menu.OnTierReset(enforceDeviceLimits)
+	user.SetTierChangeHook(enforceDeviceLimits)
+
+	// Zrušené priateľstvo / blokovanie ukončí aj zdieľanie zariadení a siete
+	friends.SetRelationEndHook(deployableService.RevokeFriendAccess)
+
 	// Initialize XP system and laboratory system
-	xpHandler := xp.NewHandler(db)
+	leaderboardService := leaderboard.NewService(db, redisClient)
+	xpHandler := xp.NewHandler(db).WithLeaderboard(leaderboardService)
 	laboratoryService := laboratory.NewService(db, xpHandler)
 	laboratoryHandler := laboratory.NewHandler(laboratoryService)
 
 	adminHandler := admin.NewHandler(db, nil)
+	analyticsHandler := analytics.NewHandler(db)
+	notificationsHandler := notifications.NewHandler(db)
+
+	// Shadow/soft ban za podozrivý pohyb okamžite vyradí hráča z leaderboardov
+	movement.SetRestrictionHook(leaderboardService.RemoveUser)
+
+	// Ban vyradí hráča z leaderboardov, unban a zmena tieru ho prepočítajú
+	user.SetModerationHooks(leaderboardService.RemoveUser, leaderboardService.SyncUser)
+
+	// Realtime hub vytvára main.go; bez neho (napr. testy) použijeme lokálny hub
+	realtimeHub := realtime.Default()
+	if realtimeHub == nil {
+		realtimeHub = realtime.NewHub(nil)
+	}
+	realtimeHandler := realtime.NewHandler(db, realtimeHub)
 
 	// Scanner rate limiter – zapne sa len ak máme Redis
 	var scannerRateLimiter *middleware.ScannerRateLimiter
@@ -338,12 +374,15 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 	{
 		authRoutes.POST("/register", authHandler.Register)
 		authRoutes.POST("/login", authHandler.Login)
+		authRoutes.POST("/refresh", authHandler.RefreshToken)
 
 		authProtected := authRoutes.Group("/")
 		authProtected.Use(middleware.JWTAuth())
 		{
-			authProtected.POST("/refresh", authHandler.RefreshToken)
 			authProtected.POST("/logout", authHandler.Logout)
+			authProtected.POST("/change-password", authHandler.ChangePassword)
+			authProtected.GET("/sessions", authHandler.ListSessions)
+			authProtected.DELETE("/sessions/:id", authHandler.RevokeSession)
 		}
 	}
 
@@ -360,6 +399,7 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 		userRoutes.GET("/inventory/:type", userHandler.GetInventoryByType)
 		userRoutes.POST("/location", userHandler.UpdateLocation)
 		userRoutes.GET("/location/history", userHandler.GetLocationHistory)
+		userRoutes.DELETE("/location/history", userHandler.DeleteLocationHistory)
 		userRoutes.GET("/stats", userHandler.GetUserStats)
 	}
 
@@ -421,6 +461,8 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 			deployableRoutes.GET("/my", deployableHandler.GetMyDevices)
 			deployableRoutes.GET("/:device_id", deployableHandler.GetDeviceDetails)
 			deployableRoutes.DELETE("/:device_id", deployableHandler.DeleteDevice)
+			deployableRoutes.POST("/:device_id/freeze", deployableHandler.FreezeDevice)
+			deployableRoutes.POST("/:device_id/unfreeze", deployableHandler.UnfreezeDevice)
 
 			// Device scanning
 			deployableRoutes.POST("/:device_id/scan", deployableHandler.ScanDevice)
@@ -431,9 +473,31 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 			deployableRoutes.POST("/:device_id/attach-battery", deployableHandler.AttachBattery)
 
 			// Device hacking
+			deployableRoutes.POST("/:device_id/minigame", deployableHandler.IssueMinigame)
 			deployableRoutes.POST("/:device_id/hack", deployableHandler.HackDevice)
 			deployableRoutes.POST("/:device_id/claim", deployableHandler.ClaimDevice)
 
+			// Device defense
+			deployableRoutes.GET("/:device_id/defense", deployableHandler.GetDeviceDefense)
+			deployableRoutes.POST("/:device_id/defense", deployableHandler.InstallDefenseModule)
+			deployableRoutes.DELETE("/:device_id/defense/:module_id", deployableHandler.UninstallDefenseModule)
+			deployableRoutes.POST("/:device_id/counter-hack", deployableHandler.CounterHack)
+			deployableRoutes.GET("/:device_id/hacks", deployableHandler.GetDeviceHackHistory)
+
+			// Device sharing (priatelia) a siete zariadení
+			deployableRoutes.GET("/shared", deployableHandler.GetSharedDevices)
+			deployableRoutes.GET("/:device_id/shares", deployableHandler.GetDeviceShares)
+			deployableRoutes.POST("/:device_id/shares", deployableHandler.ShareDevice)
+			deployableRoutes.DELETE("/:device_id/shares/:user_id", deployableHandler.RevokeDeviceShare)
+			deployableRoutes.GET("/networks", deployableHandler.GetMyNetworks)
+			deployableRoutes.POST("/networks", deployableHandler.CreateNetwork)
+			deployableRoutes.GET("/networks/:network_id", deployableHandler.GetNetwork)
+			deployableRoutes.DELETE("/networks/:network_id", deployableHandler.DeleteNetwork)
+			deployableRoutes.POST("/networks/:network_id/members", deployableHandler.AddNetworkMember)
+			deployableRoutes.DELETE("/networks/:network_id/members/:user_id", deployableHandler.RemoveNetworkMember)
+			deployableRoutes.POST("/networks/:network_id/devices", deployableHandler.AddNetworkDevice)
+			deployableRoutes.DELETE("/networks/:network_id/devices/:device_id", deployableHandler.RemoveNetworkDevice)
+
 			// Device discovery
 			deployableRoutes.GET("/nearby", deployableHandler.GetNearbyDevices)
 			deployableRoutes.GET("/abandoned", deployableHandler.GetAbandonedDevices)
@@ -443,6 +507,7 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 
 			// Hack tools
 			deployableRoutes.GET("/hack-tools", deployableHandler.GetHackTools)
+			deployableRoutes.GET("/hack-tools/:tool_id", deployableHandler.GetHackToolDetails)
 			deployableRoutes.POST("/hack-tools/:tool_id/use", deployableHandler.UseHackTool)
 		}
 
@@ -500,9 +565,14 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 		zoneRoutes := gameRoutes.Group("/zones")
 		{
 			zoneRoutes.GET("/nearby", gameHandler.GetNearbyZones)
+			zoneRoutes.GET("/actions", gameHandler.GetZoneActions)
+			zoneRoutes.POST("/reroll", gameHandler.RerollZones)
 			zoneRoutes.GET("/:id", gameHandler.GetZoneDetails)
 			zoneRoutes.POST("/:id/enter", gameHandler.EnterZone)
 			zoneRoutes.POST("/:id/exit", gameHandler.ExitZone)
+			zoneRoutes.POST("/:id/anchor", gameHandler.AnchorZone)
+			zoneRoutes.GET("/:id/waitlist", gameHandler.GetZoneWaitlist)
+			zoneRoutes.DELETE("/:id/waitlist", gameHandler.LeaveZoneWaitlist)
 			zoneRoutes.GET("/:id/scan", gameHandler.ScanZone)
 			zoneRoutes.POST("/:id/collect", gameHandler.CollectItem)
 			zoneRoutes.GET("/:id/stats", gameHandler.GetZoneStats)
@@ -531,11 +601,54 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 		locationRoutes.GET("/zones/:id/activity", locationHandler.GetZoneActivity)
 		locationRoutes.GET("/zones/:id/players", locationHandler.GetPlayersInZone)
 		locationRoutes.GET("/history", locationHandler.GetLocationHistory)
+		locationRoutes.DELETE("/history", locationHandler.DeleteLocationHistory)
 		locationRoutes.GET("/heatmap", locationHandler.GetLocationHeatmap)
+		locationRoutes.GET("/share", locationHandler.GetLocationShares)
 		locationRoutes.POST("/share", locationHandler.ShareLocation)
+		locationRoutes.DELETE("/share/:friend_id", locationHandler.StopSharingLocation)
 		locationRoutes.GET("/friends/nearby", locationHandler.GetNearbyFriends)
 	}
 
+	// ==========================================
+	// 📡 REALTIME ROUTES (WebSocket + SSE fallback)
+	// ==========================================
+	realtimeRoutes := v1.Group("/realtime")
+	realtimeRoutes.Use(middleware.JWTAuthStream())
+	{
+		realtimeRoutes.GET("/ws", realtimeHandler.ServeWebSocket)
+		realtimeRoutes.GET("/events", realtimeHandler.ServeSSE)
+	}
+
+	// ==========================================
+	// 🤝 FRIENDS ROUTES (Protected - JWT required)
+	// ==========================================
+	friendsRoutes := v1.Group("/friends")
+	friendsRoutes.Use(middleware.JWTAuth())
+	friendsRoutes.Use(middleware.TierExpirationMiddleware(menuHandler.GetService()))
+	{
+		friendsRoutes.GET("", friendsHandler.GetFriends)
+		friendsRoutes.POST("/requests", friendsHandler.SendFriendRequest)
+		friendsRoutes.POST("/requests/:id/accept", friendsHandler.AcceptFriendRequest)
+		friendsRoutes.POST("/requests/:id/decline", friendsHandler.DeclineFriendRequest)
+		friendsRoutes.DELETE("/requests/:id", friendsHandler.CancelFriendRequest)
+		friendsRoutes.DELETE("/:id", friendsHandler.Unfriend)
+		friendsRoutes.GET("/blocked", friendsHandler.GetBlocked)
+		friendsRoutes.POST("/blocked/:id", friendsHandler.BlockUser)
+		friendsRoutes.DELETE("/blocked/:id", friendsHandler.UnblockUser)
+	}
+
+	// ==========================================
+	// 🔔 NOTIFICATIONS ROUTES (Protected - JWT required)
+	// ==========================================
+	notificationRoutes := v1.Group("/notifications")
+	notificationRoutes.Use(middleware.JWTAuth())
+	notificationRoutes.Use(middleware.TierExpirationMiddleware(menuHandler.GetService()))
+	{
+		notificationRoutes.GET("", notificationsHandler.GetNotifications)
+		notificationRoutes.POST("/read", notificationsHandler.MarkNotificationsRead)
+		notificationRoutes.POST("/:id/read", notificationsHandler.MarkNotificationRead)
+	}
+
 	// ==========================================
 	// 💰 MENU ROUTES (Protected - JWT required)
 	// ==========================================
@@ -581,6 +694,16 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 		menuRoutes.POST("/orders/:id/expedite", menuHandler.ExpediteOrder)
 	}
 
+	// ==========================================
+	// 🛡️ MODERATOR ROUTES (Protected - Moderator+, len dočasné bany)
+	// ==========================================
+	moderatorRoutes := v1.Group("/moderator")
+	moderatorRoutes.Use(middleware.JWTAuth())
+	moderatorRoutes.Use(middleware.ModeratorOnly())
+	{
+		moderatorRoutes.POST("/users/:id/ban", userHandler.BanUser)
+	}
+
 	// ==========================================
 	// 🔧 ADMIN ROUTES (Protected - Admin only)
 	// ==========================================
@@ -593,15 +716,34 @@ func setupRoutes(db *gorm.DB, redisClient *redis.Client, r2Client *media.R2Clien
 		adminRoutes.DELETE("/zones/:id", gameHandler.DeleteZone)
 		adminRoutes.POST("/zones/:id/spawn/artifact", gameHandler.SpawnArtifact)
 		adminRoutes.POST("/zones/:id/spawn/gear", gameHandler.SpawnGear)
+
+		// Spawn tabuľky (verzie, validácia, hot reload, dry run)
+		adminRoutes.GET("/spawn-config", gameHandler.GetSpawnConfig)
+		adminRoutes.POST("/spawn-config/validate", gameHandler.ValidateSpawnConfig)
+		adminRoutes.POST("/spawn-config/simulate", gameHandler.SimulateSpawnConfig)
+		adminRoutes.POST("/spawn-config/reload", gameHandler.ReloadSpawnConfig)
+		adminRoutes.GET("/spawn-config/versions", gameHandler.ListSpawnConfigVersions)
+		adminRoutes.POST("/spawn-config/versions", gameHandler.CreateSpawnConfigVersion)
+		adminRoutes.GET("/spawn-config/versions/:version", gameHandler.GetSpawnConfigVersion)
+		adminRoutes.POST("/spawn-config/versions/:version/activate", gameHandler.ActivateSpawnConfigVersion)
+		adminRoutes.DELETE("/spawn-config/versions/:version", gameHandler.DeleteSpawnConfigVersion)
+
 		adminRoutes.POST("/zones/cleanup", gameHandler.CleanupExpiredZones)
 		adminRoutes.GET("/zones/expired", gameHandler.GetExpiredZones)
 		adminRoutes.GET("/users", userHandler.GetAllUsers)
 		adminRoutes.PUT("/users/:id/tier", userHandler.UpdateUserTier)
 		adminRoutes.POST("/users/:id/ban", userHandler.BanUser)
 		adminRoutes.POST("/users/:id/unban", userHandler.UnbanUser)
+		adminRoutes.GET("/users/:id/movement", userHandler.GetUserMovement)
+		adminRoutes.POST("/users/:id/movement/clear", userHandler.ClearUserMovement)
+		adminRoutes.GET("/movement/flagged", userHandler.GetFlaggedMovement)
 		adminRoutes.GET("/analytics/zones", gameHandler.GetZoneAnalytics)
-		adminRoutes.GET("/analytics/players", userHandler.GetPlayerAnalytics)
-		adminRoutes.GET("/analytics/items", gameHandler.GetItemAnalytics)
+		adminRoutes.GET("/analytics/zones/stats", gameHandler.GetZoneStatsAggregate)
+		adminRoutes.GET("/analytics/players", analyticsHandler.GetPlayerAnalytics)
+		adminRoutes.GET("/analytics/items", analyticsHandler.GetItemAnalytics)
+		adminRoutes.GET("/analytics/economy", analyticsHandler.GetEconomyAnalytics)
+		adminRoutes.GET("/analytics/funnel", analyticsHandler.GetFunnel)
+		adminRoutes.GET("/analytics/heatmap", adminHandler.GetHeatmap)
 
 		// Inventory management
 		adminRoutes.POST("/inventory/add", adminHandler.AddInventoryItem)
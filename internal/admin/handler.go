@@ -2,11 +2,10 @@ package admin
 
 import (
 	"geoanomaly/internal/auth"
-	"geoanomaly/internal/common"
-	"geoanomaly/internal/gameplay"
 	"net/http"
 
 	"geoanomaly/internal/game"
+	"geoanomaly/internal/heatmap"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,97 +14,17 @@ import (
 )
 
 type Handler struct {
-	db    *gorm.DB
-	redis *redis.Client
-}
-
-type CreateEventZoneRequest struct {
-	Name           string                  `json:"name" binding:"required"`
-	Description    string                  `json:"description"`
-	Location       common.Location         `json:"location" binding:"required"`
-	RadiusMeters   int                     `json:"radius_meters" binding:"required"`
-	TierRequired   int                     `json:"tier_required" binding:"required"`
-	EventType      string                  `json:"event_type"`
-	Permanent      bool                    `json:"permanent"`
-	EventArtifacts []CreateArtifactRequest `json:"event_artifacts"`
-}
-
-type CreateArtifactRequest struct {
-	Name   string `json:"name"`
-	Type   string `json:"type"`
-	Rarity string `json:"rarity"`
+	db      *gorm.DB
+	redis   *redis.Client
+	heatmap *heatmap.Service
 }
 
 func NewHandler(db *gorm.DB, redisClient *redis.Client) *Handler {
 	return &Handler{
-		db:    db,
-		redis: redisClient,
-	}
-}
-
-func (h *Handler) CreateEventZone(c *gin.Context) {
-	var req CreateEventZoneRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	zone := gameplay.Zone{
-		BaseModel:   gameplay.BaseModel{ID: uuid.New()},
-		Name:        req.Name,
-		Description: req.Description,
-		Location: gameplay.Location{
-			Latitude:  req.Location.Latitude,
-			Longitude: req.Location.Longitude,
-			Timestamp: req.Location.Timestamp,
-		},
-		RadiusMeters: req.RadiusMeters,
-		TierRequired: req.TierRequired,
-		ZoneType:     "event",
-		Properties: gameplay.JSONB{
-			"event_type": req.EventType,
-			"created_by": "admin",
-			"permanent":  req.Permanent,
-		},
-		IsActive: true,
-	}
-
-	if err := h.db.Create(&zone).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create zone"})
-		return
-	}
-
-	// Spawn event artifacts
-	for _, artifact := range req.EventArtifacts {
-		h.spawnEventArtifact(zone.ID, artifact)
+		db:      db,
+		redis:   redisClient,
+		heatmap: heatmap.NewService(db),
 	}
-
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Event zone created successfully",
-		"zone":    zone,
-	})
-}
-
-func (h *Handler) spawnEventArtifact(zoneID uuid.UUID, artifact CreateArtifactRequest) {
-	// Implementation for spawning artifacts
-	// TODO: Add artifact creation logic
-}
-
-// Add other admin methods as stubs
-func (h *Handler) UpdateZone(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Update zone not implemented yet"})
-}
-
-func (h *Handler) DeleteZone(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Delete zone not implemented yet"})
-}
-
-func (h *Handler) SpawnArtifact(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Spawn artifact not implemented yet"})
-}
-
-func (h *Handler) SpawnGear(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "Spawn gear not implemented yet"})
 }
 
 // AddInventoryItem - pridá predmet do inventára používateľa
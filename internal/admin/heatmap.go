@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"geoanomaly/internal/game"
+	"geoanomaly/internal/heatmap"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetHeatmap - admin heatmap: hráči (bez k-anonymity), spawny, zbery a zóny + rozostupy zón
+// Query: layers=players,spawns,collections,zones + filtre z heatmap.ParseFilter
+func (h *Handler) GetHeatmap(c *gin.Context) {
+	filter, err := heatmap.ParseFilter(c, heatmap.MaxAdminPrecision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	layers := heatmap.AllLayers
+	if value := c.Query("layers"); value != "" {
+		layers = nil
+		for _, name := range strings.Split(value, ",") {
+			layer := heatmap.Layer(strings.TrimSpace(name))
+			if !isKnownLayer(layer) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":        fmt.Sprintf("Unknown layer '%s'", layer),
+					"valid_layers": heatmap.AllLayers,
+				})
+				return
+			}
+			layers = append(layers, layer)
+		}
+	}
+
+	maps := gin.H{}
+	for _, layer := range layers {
+		var result *heatmap.Map
+		switch layer {
+		case heatmap.LayerPlayers:
+			result, err = h.heatmap.Players(filter, 1)
+		case heatmap.LayerSpawns:
+			result, err = h.heatmap.Spawns(filter)
+		case heatmap.LayerCollections:
+			result, err = h.heatmap.Collections(filter)
+		case heatmap.LayerZones:
+			result, err = h.heatmap.Zones(filter)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   fmt.Sprintf("Failed to build %s heatmap", layer),
+				"details": err.Error(),
+			})
+			return
+		}
+		maps[string(layer)] = result
+	}
+
+	spacing, err := h.heatmap.ZoneSpacing(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to compute zone spacing",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"layers":       maps,
+		"zone_spacing": spacing,
+		"tuning": gin.H{
+			"max_spawn_radius_m":  game.MaxSpawnRadius,
+			"min_zone_distance_m": game.MinZoneDistance,
+		},
+		"filter": gin.H{
+			"from":      filter.From.Format(time.RFC3339),
+			"to":        filter.To.Format(time.RFC3339),
+			"biome":     filter.Biome,
+			"tier":      filter.Tier,
+			"precision": filter.Precision,
+			"bbox":      filter.BBox,
+		},
+		"requested_by": c.GetString("username"),
+		"timestamp":    time.Now().Format(time.RFC3339),
+	})
+}
+
+func isKnownLayer(layer heatmap.Layer) bool {
+	for _, known := range heatmap.AllLayers {
+		if layer == known {
+			return true
+		}
+	}
+	return false
+}
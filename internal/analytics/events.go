@@ -0,0 +1,70 @@
+package analytics
+
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Typy udalostí v event logu
+const (
+	EventUserRegistered    = "user_registered"
+	EventUserLogin         = "user_login"
+	EventAreaScanned       = "area_scanned"
+	EventZoneSpawned       = "zone_spawned"
+	EventZoneEntered       = "zone_entered"
+	EventItemSpawned       = "item_spawned"
+	EventItemCollected     = "item_collected"
+	EventItemSold          = "item_sold"
+	EventItemCrafted       = "item_crafted"
+	EventResearchCompleted = "research_completed"
+)
+
+// Event - jeden záznam v append-only event logu (analytics.events)
+type Event struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	OccurredAt time.Time  `json:"occurred_at" gorm:"not null;index;index:idx_analytics_events_type_time,priority:2"`
+	Type       string     `json:"type" gorm:"column:event_type;size:30;not null;index:idx_analytics_events_type_time,priority:1"`
+	UserID     *uuid.UUID `json:"user_id,omitempty" gorm:"type:uuid;index"`
+	Tier       *int       `json:"tier,omitempty"` // tier hráča v čase udalosti
+
+	ZoneID   *uuid.UUID `json:"zone_id,omitempty" gorm:"type:uuid"`
+	Biome    string     `json:"biome,omitempty" gorm:"size:50;not null;default:''"`
+	ZoneTier *int       `json:"zone_tier,omitempty"`
+
+	ItemType string `json:"item_type,omitempty" gorm:"size:20;not null;default:''"` // artifact, gear, recipe, research
+	ItemKey  string `json:"item_key,omitempty" gorm:"size:50;not null;default:''"`  // typ artefaktu, gear kategória, recept...
+	Rarity   string `json:"rarity,omitempty" gorm:"size:20;not null;default:''"`
+
+	Currency string `json:"currency,omitempty" gorm:"size:20;not null;default:''"`
+	Amount   int    `json:"amount,omitempty" gorm:"not null;default:0"`
+
+	Properties JSONB `json:"properties,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
+}
+
+func (Event) TableName() string {
+	return "analytics.events"
+}
+
+// Track - zapíše udalosť; chyba sa len zaloguje (analytika nesmie zhodiť hru).
+// Funguje aj vnútri transakcie - zápis ide cez savepoint, takže zlyhanie ju neabortuje.
+func Track(db *gorm.DB, event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.Tier == nil && event.UserID != nil {
+		var tier int
+		if err := db.Table("auth.users").Select("tier").Where("id = ?", *event.UserID).Scan(&tier).Error; err == nil {
+			event.Tier = &tier
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		log.Printf("⚠️ Analytics: failed to track %s: %v", event.Type, err)
+	}
+}
@@ -0,0 +1,144 @@
+package analytics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxRangeDays - najdlhšie obdobie jedného dopytu
+const maxRangeDays = 366
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{service: NewService(db)}
+}
+
+// ParseRange - from/to (YYYY-MM-DD alebo RFC3339), default posledných 30 dní
+func ParseRange(c *gin.Context) (Range, bool) {
+	r := DefaultRange()
+
+	parse := func(key string, target *time.Time) bool {
+		value := c.Query(key)
+		if value == "" {
+			return true
+		}
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			parsed, err = time.Parse(time.RFC3339, value)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid '" + key + "' date, expected YYYY-MM-DD or RFC3339"})
+			return false
+		}
+		*target = truncateDay(parsed)
+		return true
+	}
+
+	if !parse("from", &r.From) || !parse("to", &r.To) {
+		return r, false
+	}
+	if r.To.Before(r.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'from' must not be after 'to'"})
+		return r, false
+	}
+	if r.To.Sub(r.From) > maxRangeDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Range too long", "max_days": maxRangeDays})
+		return r, false
+	}
+	return r, true
+}
+
+// GetItemAnalytics - spawn-vs-collect podľa typu artefaktu / gearu (GET /admin/analytics/items)
+func (h *Handler) GetItemAnalytics(c *gin.Context) {
+	r, ok := ParseRange(c)
+	if !ok {
+		return
+	}
+
+	rates, err := h.service.ItemRates(r, c.Query("item_type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load item analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"range":     r,
+		"items":     rates,
+		"count":     len(rates),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetEconomyAnalytics - prítok / odtok podľa meny (GET /admin/analytics/economy)
+func (h *Handler) GetEconomyAnalytics(c *gin.Context) {
+	r, ok := ParseRange(c)
+	if !ok {
+		return
+	}
+
+	flows, err := h.service.Economy(r)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load economy analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"range":      r,
+		"currencies": flows,
+		"timestamp":  time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetPlayerAnalytics - DAU a retencia podľa tieru + funnel (GET /admin/analytics/players)
+func (h *Handler) GetPlayerAnalytics(c *gin.Context) {
+	r, ok := ParseRange(c)
+	if !ok {
+		return
+	}
+
+	daily, retention, err := h.service.Activity(r)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load player analytics"})
+		return
+	}
+	funnel, err := h.service.Funnel(r)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load funnel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"range":     r,
+		"daily":     daily,
+		"by_tier":   retention,
+		"funnel":    funnel,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetFunnel - drop-off od registrácie po prvý zber (GET /admin/analytics/funnel)
+func (h *Handler) GetFunnel(c *gin.Context) {
+	r, ok := ParseRange(c)
+	if !ok {
+		return
+	}
+
+	funnel, err := h.service.Funnel(r)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load funnel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"range":              r,
+		"steps":              funnel,
+		"cohort_window_days": CohortWindowDays,
+		"timestamp":          time.Now().Format(time.RFC3339),
+	})
+}
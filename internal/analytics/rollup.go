@@ -0,0 +1,309 @@
+package analytics
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// RollupInterval - ako často worker prepočítava denné tabuľky
+	RollupInterval = 15 * time.Minute
+	// CohortWindowDays - retencia (D1/D7) a funnel sa dopočítavajú ešte tento počet dní po registrácii
+	CohortWindowDays = 7
+
+	rollupLockID = int64(24014)
+)
+
+// DailyItemStats - spawn vs. zber (a ďalší osud) itemov podľa typu a rarity za deň
+type DailyItemStats struct {
+	Day        time.Time `json:"day" gorm:"type:date;primaryKey"`
+	ItemType   string    `json:"item_type" gorm:"size:20;primaryKey"`
+	ItemKey    string    `json:"item_key" gorm:"size:50;primaryKey"`
+	Rarity     string    `json:"rarity" gorm:"size:20;primaryKey"`
+	Spawned    int64     `json:"spawned" gorm:"not null;default:0"`
+	Collected  int64     `json:"collected" gorm:"not null;default:0"`
+	Sold       int64     `json:"sold" gorm:"not null;default:0"`
+	Crafted    int64     `json:"crafted" gorm:"not null;default:0"`
+	Researched int64     `json:"researched" gorm:"not null;default:0"`
+}
+
+func (DailyItemStats) TableName() string {
+	return "analytics.daily_item_stats"
+}
+
+// DailyZoneStats - spawnuté zóny a vstupy podľa biome a tieru zóny za deň
+type DailyZoneStats struct {
+	Day            time.Time `json:"day" gorm:"type:date;primaryKey"`
+	Biome          string    `json:"biome" gorm:"size:50;primaryKey"`
+	ZoneTier       int       `json:"zone_tier" gorm:"primaryKey"`
+	ZonesSpawned   int64     `json:"zones_spawned" gorm:"not null;default:0"`
+	Entries        int64     `json:"entries" gorm:"not null;default:0"`
+	UniqueEntrants int64     `json:"unique_entrants" gorm:"not null;default:0"`
+}
+
+func (DailyZoneStats) TableName() string {
+	return "analytics.daily_zone_stats"
+}
+
+// DailyEconomy - prítok / odtok meny podľa zdroja (typ transakcie) za deň
+type DailyEconomy struct {
+	Day          time.Time `json:"day" gorm:"type:date;primaryKey"`
+	Currency     string    `json:"currency" gorm:"size:20;primaryKey"`
+	Source       string    `json:"source" gorm:"size:30;primaryKey"`
+	Inflow       int64     `json:"inflow" gorm:"not null;default:0"`
+	Outflow      int64     `json:"outflow" gorm:"not null;default:0"`
+	Transactions int64     `json:"transactions" gorm:"not null;default:0"`
+}
+
+func (DailyEconomy) TableName() string {
+	return "analytics.daily_economy"
+}
+
+// DailyActivity - DAU a retencia podľa tieru; new_users/retained_* patria kohorte registrovanej v daný deň
+type DailyActivity struct {
+	Day         time.Time `json:"day" gorm:"type:date;primaryKey"`
+	Tier        int       `json:"tier" gorm:"primaryKey"`
+	ActiveUsers int64     `json:"active_users" gorm:"not null;default:0"`
+	NewUsers    int64     `json:"new_users" gorm:"not null;default:0"`
+	RetainedD1  int64     `json:"retained_d1" gorm:"not null;default:0"`
+	RetainedD7  int64     `json:"retained_d7" gorm:"not null;default:0"`
+}
+
+func (DailyActivity) TableName() string {
+	return "analytics.daily_activity"
+}
+
+// DailyFunnel - kohorta registrovaná v daný deň a koľko z nej prešlo krokmi do CohortWindowDays
+type DailyFunnel struct {
+	CohortDay   time.Time `json:"cohort_day" gorm:"type:date;primaryKey"`
+	Registered  int64     `json:"registered" gorm:"not null;default:0"`
+	Scanned     int64     `json:"scanned" gorm:"not null;default:0"`
+	EnteredZone int64     `json:"entered_zone" gorm:"not null;default:0"`
+	Collected   int64     `json:"collected" gorm:"not null;default:0"`
+}
+
+func (DailyFunnel) TableName() string {
+	return "analytics.daily_funnel"
+}
+
+// RollupWorker - periodicky prepočítava denné tabuľky z event logu a market transakcií
+type RollupWorker struct {
+	db     *gorm.DB
+	stopCh chan bool
+}
+
+func NewRollupWorker(db *gorm.DB) *RollupWorker {
+	return &RollupWorker{
+		db:     db,
+		stopCh: make(chan bool),
+	}
+}
+
+// Start - blokujúci loop (spúšťať cez go)
+func (w *RollupWorker) Start() {
+	log.Println("📊 Analytics rollup worker: Starting...")
+	w.run()
+
+	ticker := time.NewTicker(RollupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.run()
+		case <-w.stopCh:
+			log.Println("📊 Analytics rollup worker: Stopping...")
+			return
+		}
+	}
+}
+
+func (w *RollupWorker) Stop() {
+	close(w.stopCh)
+}
+
+// run - dnešok a okno kohort (retencia D7 a funnel sa dopĺňajú spätne)
+func (w *RollupWorker) run() {
+	today := truncateDay(time.Now())
+	for offset := CohortWindowDays + 1; offset >= 0; offset-- {
+		day := today.AddDate(0, 0, -offset)
+		if err := RollupDay(w.db, day); err != nil {
+			log.Printf("⚠️ Analytics rollup for %s failed: %v", day.Format("2006-01-02"), err)
+		}
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// RollupDay - idempotentný prepočet všetkých denných tabuliek pre jeden deň (UTC)
+func RollupDay(db *gorm.DB, day time.Time) error {
+	from := truncateDay(day)
+	to := from.AddDate(0, 0, 1)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Viac inštancií servera - prepočet robí len jedna
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", rollupLockID).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		steps := []struct {
+			name string
+			fn   func(*gorm.DB, time.Time, time.Time) error
+		}{
+			{"items", rollupItems},
+			{"zones", rollupZones},
+			{"economy", rollupEconomy},
+			{"activity", rollupActivity},
+			{"funnel", rollupFunnel},
+		}
+		for _, step := range steps {
+			if err := step.fn(tx, from, to); err != nil {
+				return fmt.Errorf("%s: %w", step.name, err)
+			}
+		}
+		return nil
+	})
+}
+
+func rollupItems(tx *gorm.DB, from, to time.Time) error {
+	if err := tx.Where("day = ?", from).Delete(&DailyItemStats{}).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`
+		INSERT INTO analytics.daily_item_stats (day, item_type, item_key, rarity, spawned, collected, sold, crafted, researched)
+		SELECT ?::date, item_type, item_key, rarity,
+		       COUNT(*) FILTER (WHERE event_type = ?),
+		       COUNT(*) FILTER (WHERE event_type = ?),
+		       COUNT(*) FILTER (WHERE event_type = ?),
+		       COUNT(*) FILTER (WHERE event_type = ?),
+		       COUNT(*) FILTER (WHERE event_type = ?)
+		FROM analytics.events
+		WHERE occurred_at >= ? AND occurred_at < ?
+		  AND event_type IN (?, ?, ?, ?, ?)
+		GROUP BY item_type, item_key, rarity`,
+		from,
+		EventItemSpawned, EventItemCollected, EventItemSold, EventItemCrafted, EventResearchCompleted,
+		from, to,
+		EventItemSpawned, EventItemCollected, EventItemSold, EventItemCrafted, EventResearchCompleted,
+	).Error
+}
+
+func rollupZones(tx *gorm.DB, from, to time.Time) error {
+	if err := tx.Where("day = ?", from).Delete(&DailyZoneStats{}).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`
+		INSERT INTO analytics.daily_zone_stats (day, biome, zone_tier, zones_spawned, entries, unique_entrants)
+		SELECT ?::date, biome, COALESCE(zone_tier, 0),
+		       COUNT(*) FILTER (WHERE event_type = ?),
+		       COUNT(*) FILTER (WHERE event_type = ?),
+		       COUNT(DISTINCT user_id) FILTER (WHERE event_type = ?)
+		FROM analytics.events
+		WHERE occurred_at >= ? AND occurred_at < ?
+		  AND event_type IN (?, ?)
+		GROUP BY biome, COALESCE(zone_tier, 0)`,
+		from,
+		EventZoneSpawned, EventZoneEntered, EventZoneEntered,
+		from, to,
+		EventZoneSpawned, EventZoneEntered,
+	).Error
+}
+
+// rollupEconomy - zdrojom sú market transakcie (kladná suma = prítok, záporná = odtok)
+func rollupEconomy(tx *gorm.DB, from, to time.Time) error {
+	if err := tx.Where("day = ?", from).Delete(&DailyEconomy{}).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`
+		INSERT INTO analytics.daily_economy (day, currency, source, inflow, outflow, transactions)
+		SELECT ?::date, currency_type, type,
+		       COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0),
+		       COALESCE(-SUM(amount) FILTER (WHERE amount < 0), 0),
+		       COUNT(*)
+		FROM market.transactions
+		WHERE created_at >= ? AND created_at < ? AND deleted_at IS NULL
+		GROUP BY currency_type, type`,
+		from, from, to,
+	).Error
+}
+
+// rollupActivity - aktívny = aspoň jedna udalosť v daný deň; tier = najvyšší tier toho dňa
+func rollupActivity(tx *gorm.DB, from, to time.Time) error {
+	if err := tx.Where("day = ?", from).Delete(&DailyActivity{}).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`
+		WITH active AS (
+			SELECT user_id, COALESCE(MAX(tier), 0) AS tier
+			FROM analytics.events
+			WHERE occurred_at >= ? AND occurred_at < ? AND user_id IS NOT NULL
+			GROUP BY user_id
+		), cohort AS (
+			SELECT DISTINCT user_id
+			FROM analytics.events
+			WHERE event_type = ? AND occurred_at >= ? AND occurred_at < ?
+		)
+		INSERT INTO analytics.daily_activity (day, tier, active_users, new_users, retained_d1, retained_d7)
+		SELECT ?::date, a.tier, COUNT(*), COUNT(c.user_id),
+		       COUNT(c.user_id) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM analytics.events e
+		           WHERE e.user_id = a.user_id AND e.occurred_at >= ? AND e.occurred_at < ?)),
+		       COUNT(c.user_id) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM analytics.events e
+		           WHERE e.user_id = a.user_id AND e.occurred_at >= ? AND e.occurred_at < ?))
+		FROM active a
+		LEFT JOIN cohort c ON c.user_id = a.user_id
+		GROUP BY a.tier`,
+		from, to,
+		EventUserRegistered, from, to,
+		from,
+		from.AddDate(0, 0, 1), from.AddDate(0, 0, 2),
+		from.AddDate(0, 0, 7), from.AddDate(0, 0, 8),
+	).Error
+}
+
+// rollupFunnel - registrácia → scan → vstup do zóny → prvý zber (v rámci CohortWindowDays)
+func rollupFunnel(tx *gorm.DB, from, to time.Time) error {
+	if err := tx.Where("cohort_day = ?", from).Delete(&DailyFunnel{}).Error; err != nil {
+		return err
+	}
+	windowEnd := to.AddDate(0, 0, CohortWindowDays)
+	return tx.Exec(`
+		WITH cohort AS (
+			SELECT DISTINCT user_id
+			FROM analytics.events
+			WHERE event_type = ? AND occurred_at >= ? AND occurred_at < ?
+		), reached AS (
+			SELECT e.user_id,
+			       BOOL_OR(e.event_type = ?) AS scanned,
+			       BOOL_OR(e.event_type = ?) AS entered,
+			       BOOL_OR(e.event_type = ?) AS collected
+			FROM analytics.events e
+			JOIN cohort c ON c.user_id = e.user_id
+			WHERE e.occurred_at >= ? AND e.occurred_at < ? AND e.event_type IN (?, ?, ?)
+			GROUP BY e.user_id
+		)
+		INSERT INTO analytics.daily_funnel (cohort_day, registered, scanned, entered_zone, collected)
+		SELECT ?::date, COUNT(*),
+		       COUNT(*) FILTER (WHERE r.scanned),
+		       COUNT(*) FILTER (WHERE r.entered),
+		       COUNT(*) FILTER (WHERE r.collected)
+		FROM cohort c
+		LEFT JOIN reached r ON r.user_id = c.user_id
+		HAVING COUNT(*) > 0`,
+		EventUserRegistered, from, to,
+		EventAreaScanned, EventZoneEntered, EventItemCollected,
+		from, windowEnd, EventAreaScanned, EventZoneEntered, EventItemCollected,
+		from,
+	).Error
+}
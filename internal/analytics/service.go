@@ -0,0 +1,322 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Service - čítanie denných rollupov pre admin endpointy
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Range - uzavretý interval dní [From, To] (UTC)
+type Range struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// DefaultRange - posledných 30 dní vrátane dneška
+func DefaultRange() Range {
+	today := truncateDay(time.Now())
+	return Range{From: today.AddDate(0, 0, -29), To: today}
+}
+
+// ItemRate - spawn vs. zber pre jeden typ itemu
+type ItemRate struct {
+	ItemType    string           `json:"item_type"`
+	ItemKey     string           `json:"item_key"`
+	Spawned     int64            `json:"spawned"`
+	Collected   int64            `json:"collected"`
+	Sold        int64            `json:"sold"`
+	Crafted     int64            `json:"crafted"`
+	Researched  int64            `json:"researched"`
+	CollectRate float64          `json:"collect_rate"`
+	ByRarity    []ItemRarityRate `json:"by_rarity,omitempty" gorm:"-"`
+}
+
+// ItemRarityRate - rozpad ItemRate podľa rarity
+type ItemRarityRate struct {
+	ItemType    string  `json:"-"`
+	ItemKey     string  `json:"-"`
+	Rarity      string  `json:"rarity"`
+	Spawned     int64   `json:"spawned"`
+	Collected   int64   `json:"collected"`
+	Sold        int64   `json:"sold"`
+	Crafted     int64   `json:"crafted"`
+	Researched  int64   `json:"researched"`
+	CollectRate float64 `json:"collect_rate"`
+}
+
+// ItemRates - spawn-vs-collect podľa typu artefaktu / gear kategórie
+func (s *Service) ItemRates(r Range, itemType string) ([]ItemRate, error) {
+	query := s.db.Model(&DailyItemStats{}).
+		Select(`item_type, item_key,
+			SUM(spawned) AS spawned, SUM(collected) AS collected, SUM(sold) AS sold,
+			SUM(crafted) AS crafted, SUM(researched) AS researched`).
+		Where("day BETWEEN ? AND ?", r.From, r.To).
+		Group("item_type, item_key").
+		Order("item_type, SUM(spawned) DESC")
+	if itemType != "" {
+		query = query.Where("item_type = ?", itemType)
+	}
+
+	rates := []ItemRate{}
+	if err := query.Scan(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	var rarities []ItemRarityRate
+	rarityQuery := s.db.Model(&DailyItemStats{}).
+		Select(`item_type, item_key, rarity,
+			SUM(spawned) AS spawned, SUM(collected) AS collected, SUM(sold) AS sold,
+			SUM(crafted) AS crafted, SUM(researched) AS researched`).
+		Where("day BETWEEN ? AND ?", r.From, r.To).
+		Group("item_type, item_key, rarity").
+		Order("rarity")
+	if itemType != "" {
+		rarityQuery = rarityQuery.Where("item_type = ?", itemType)
+	}
+	if err := rarityQuery.Scan(&rarities).Error; err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]*ItemRate, len(rates))
+	for i := range rates {
+		rates[i].CollectRate = ratio(rates[i].Collected, rates[i].Spawned)
+		index[rates[i].ItemType+"/"+rates[i].ItemKey] = &rates[i]
+	}
+	for _, row := range rarities {
+		if rate, ok := index[row.ItemType+"/"+row.ItemKey]; ok {
+			row.CollectRate = ratio(row.Collected, row.Spawned)
+			rate.ByRarity = append(rate.ByRarity, row)
+		}
+	}
+
+	return rates, nil
+}
+
+// CurrencyFlow - prítok / odtok jednej meny
+type CurrencyFlow struct {
+	Currency     string       `json:"currency"`
+	Inflow       int64        `json:"inflow"`
+	Outflow      int64        `json:"outflow"`
+	Net          int64        `json:"net"`
+	Transactions int64        `json:"transactions"`
+	Sources      []EconomyRow `json:"sources" gorm:"-"`
+	Daily        []EconomyRow `json:"daily" gorm:"-"`
+}
+
+// EconomyRow - súčet za zdroj (Source) alebo za deň (Day)
+type EconomyRow struct {
+	Day          *time.Time `json:"day,omitempty"`
+	Currency     string     `json:"-"`
+	Source       string     `json:"source,omitempty"`
+	Inflow       int64      `json:"inflow"`
+	Outflow      int64      `json:"outflow"`
+	Transactions int64      `json:"transactions"`
+}
+
+// Economy - prítok / odtok podľa meny, rozpad podľa zdroja a denný priebeh
+func (s *Service) Economy(r Range) ([]CurrencyFlow, error) {
+	var sources []EconomyRow
+	if err := s.db.Model(&DailyEconomy{}).
+		Select(`currency, source, SUM(inflow) AS inflow, SUM(outflow) AS outflow, SUM(transactions) AS transactions`).
+		Where("day BETWEEN ? AND ?", r.From, r.To).
+		Group("currency, source").
+		Order("currency, source").
+		Scan(&sources).Error; err != nil {
+		return nil, err
+	}
+
+	var daily []EconomyRow
+	if err := s.db.Model(&DailyEconomy{}).
+		Select(`day, currency, SUM(inflow) AS inflow, SUM(outflow) AS outflow, SUM(transactions) AS transactions`).
+		Where("day BETWEEN ? AND ?", r.From, r.To).
+		Group("day, currency").
+		Order("day").
+		Scan(&daily).Error; err != nil {
+		return nil, err
+	}
+
+	flows := []CurrencyFlow{}
+	index := map[string]int{}
+	flowFor := func(currency string) *CurrencyFlow {
+		if i, ok := index[currency]; ok {
+			return &flows[i]
+		}
+		flows = append(flows, CurrencyFlow{Currency: currency, Sources: []EconomyRow{}, Daily: []EconomyRow{}})
+		index[currency] = len(flows) - 1
+		return &flows[len(flows)-1]
+	}
+
+	for _, row := range sources {
+		flow := flowFor(row.Currency)
+		flow.Inflow += row.Inflow
+		flow.Outflow += row.Outflow
+		flow.Transactions += row.Transactions
+		flow.Sources = append(flow.Sources, row)
+	}
+	for _, row := range daily {
+		flow := flowFor(row.Currency)
+		flow.Daily = append(flow.Daily, row)
+	}
+	for i := range flows {
+		flows[i].Net = flows[i].Inflow - flows[i].Outflow
+	}
+
+	return flows, nil
+}
+
+// TierRetention - súčty za obdobie pre jeden tier
+type TierRetention struct {
+	Tier        int     `json:"tier"`
+	AvgDAU      float64 `json:"avg_dau"`
+	PeakDAU     int64   `json:"peak_dau"`
+	NewUsers    int64   `json:"new_users"`
+	RetainedD1  int64   `json:"retained_d1"`
+	RetainedD7  int64   `json:"retained_d7"`
+	RetentionD1 float64 `json:"retention_d1"`
+	RetentionD7 float64 `json:"retention_d7"`
+}
+
+// Activity - denné DAU podľa tieru + retencia kohort za obdobie
+func (s *Service) Activity(r Range) ([]DailyActivity, []TierRetention, error) {
+	daily := []DailyActivity{}
+	if err := s.db.Where("day BETWEEN ? AND ?", r.From, r.To).
+		Order("day, tier").Find(&daily).Error; err != nil {
+		return nil, nil, err
+	}
+
+	days := int(r.To.Sub(r.From).Hours()/24) + 1
+	today := truncateDay(time.Now())
+
+	byTier := map[int]*TierRetention{}
+	tiers := []int{}
+	// Kohorty, ktorým D1 / D7 ešte neuplynul, sa do menovateľa nepočítajú
+	d1Base := map[int]int64{}
+	d7Base := map[int]int64{}
+	for _, row := range daily {
+		t, ok := byTier[row.Tier]
+		if !ok {
+			t = &TierRetention{Tier: row.Tier}
+			byTier[row.Tier] = t
+			tiers = append(tiers, row.Tier)
+		}
+		t.AvgDAU += float64(row.ActiveUsers)
+		if row.ActiveUsers > t.PeakDAU {
+			t.PeakDAU = row.ActiveUsers
+		}
+		t.NewUsers += row.NewUsers
+		if row.Day.AddDate(0, 0, 1).Before(today) {
+			t.RetainedD1 += row.RetainedD1
+			d1Base[row.Tier] += row.NewUsers
+		}
+		if row.Day.AddDate(0, 0, 7).Before(today) {
+			t.RetainedD7 += row.RetainedD7
+			d7Base[row.Tier] += row.NewUsers
+		}
+	}
+
+	sort.Ints(tiers)
+	retention := make([]TierRetention, 0, len(tiers))
+	for _, tier := range tiers {
+		t := byTier[tier]
+		if days > 0 {
+			t.AvgDAU /= float64(days)
+		}
+		t.RetentionD1 = ratio(t.RetainedD1, d1Base[tier])
+		t.RetentionD7 = ratio(t.RetainedD7, d7Base[tier])
+		retention = append(retention, *t)
+	}
+
+	return daily, retention, nil
+}
+
+// FunnelStep - krok funnelu s konverziou voči predchádzajúcemu kroku
+type FunnelStep struct {
+	Step           string  `json:"step"`
+	Users          int64   `json:"users"`
+	FromPrevious   float64 `json:"conversion_from_previous"`
+	FromRegistered float64 `json:"conversion_from_registered"`
+	DropOff        int64   `json:"drop_off"`
+}
+
+// Funnel - registrácia → prvý scan → prvý vstup do zóny → prvý zber (kohorty v Range)
+func (s *Service) Funnel(r Range) ([]FunnelStep, error) {
+	var totals struct {
+		Registered  int64
+		Scanned     int64
+		EnteredZone int64
+		Collected   int64
+	}
+	if err := s.db.Model(&DailyFunnel{}).
+		Select(`COALESCE(SUM(registered), 0) AS registered, COALESCE(SUM(scanned), 0) AS scanned,
+			COALESCE(SUM(entered_zone), 0) AS entered_zone, COALESCE(SUM(collected), 0) AS collected`).
+		Where("cohort_day BETWEEN ? AND ?", r.From, r.To).
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+
+	counts := []struct {
+		step  string
+		users int64
+	}{
+		{"registered", totals.Registered},
+		{"first_scan", totals.Scanned},
+		{"first_zone_entry", totals.EnteredZone},
+		{"first_collect", totals.Collected},
+	}
+
+	steps := make([]FunnelStep, 0, len(counts))
+	for i, c := range counts {
+		step := FunnelStep{
+			Step:           c.step,
+			Users:          c.users,
+			FromRegistered: ratio(c.users, totals.Registered),
+			FromPrevious:   1,
+		}
+		if i > 0 {
+			previous := counts[i-1].users
+			step.FromPrevious = ratio(c.users, previous)
+			step.DropOff = previous - c.users
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// ZoneActivityRow - súčty za biome a tier zóny (unique_entrants = súčet denných unikátov)
+type ZoneActivityRow struct {
+	Biome          string `json:"biome"`
+	ZoneTier       int    `json:"zone_tier"`
+	ZonesSpawned   int64  `json:"zones_spawned"`
+	Entries        int64  `json:"entries"`
+	UniqueEntrants int64  `json:"unique_entrants"`
+}
+
+// ZoneActivity - spawnuté zóny a vstupy podľa biome a tieru za obdobie
+func (s *Service) ZoneActivity(r Range) ([]ZoneActivityRow, error) {
+	rows := []ZoneActivityRow{}
+	err := s.db.Model(&DailyZoneStats{}).
+		Select(`biome, zone_tier, SUM(zones_spawned) AS zones_spawned, SUM(entries) AS entries,
+			SUM(unique_entrants) AS unique_entrants`).
+		Where("day BETWEEN ? AND ?", r.From, r.To).
+		Group("biome, zone_tier").
+		Order("biome, zone_tier").
+		Scan(&rows).Error
+	return rows, err
+}
+
+func ratio(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"time"
+
+	"geoanomaly/internal/auth"
+
+	"github.com/google/uuid"
+)
+
+// Admin action types
+const (
+	ActionSpawnArtifact = "spawn_artifact"
+	ActionSpawnGear     = "spawn_gear"
+	ActionBanUser       = "ban_user"
+	ActionUnbanUser     = "unban_user"
+	ActionAutoUnban     = "auto_unban"
+	ActionUpdateTier    = "update_tier"
+	ActionClearMovement = "clear_movement_restriction"
+
+	ActionCreateSpawnConfig   = "create_spawn_config"
+	ActionActivateSpawnConfig = "activate_spawn_config"
+	ActionDeleteSpawnConfig   = "delete_spawn_config"
+)
+
+// SystemActor - meno pre automatické akcie (scheduler)
+const SystemActor = "system"
+
+// Target types
+const (
+	TargetZone = "zone"
+	TargetUser = "user"
+
+	TargetSpawnConfig = "spawn_config"
+)
+
+// AdminAction - audit záznam o zásahu admina/moderátora
+type AdminAction struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+
+	ActorID       uuid.UUID  `json:"actor_id" gorm:"type:uuid;not null;index"`
+	ActorUsername string     `json:"actor_username" gorm:"size:50"`
+	Action        string     `json:"action" gorm:"size:50;not null;index"`
+	TargetType    string     `json:"target_type" gorm:"size:30;not null"`
+	TargetID      *uuid.UUID `json:"target_id,omitempty" gorm:"type:uuid;index"`
+
+	Before   auth.JSONB `json:"before,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
+	After    auth.JSONB `json:"after,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
+	Reason   string     `json:"reason,omitempty" gorm:"type:text"`
+	Metadata auth.JSONB `json:"metadata,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
+}
+
+func (AdminAction) TableName() string {
+	return "auth.admin_actions"
+}
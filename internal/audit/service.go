@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Service zapisuje a číta admin audit trail
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Record uloží audit záznam; db môže byť aj transakcia
+func (s *Service) Record(db *gorm.DB, action *AdminAction) error {
+	if db == nil {
+		db = s.db
+	}
+	if action.ID == uuid.Nil {
+		action.ID = uuid.New()
+	}
+
+	if err := db.Create(action).Error; err != nil {
+		log.Printf("❌ Failed to record admin action %s by %s: %v", action.Action, action.ActorUsername, err)
+		return err
+	}
+
+	log.Printf("📝 AUDIT: %s (%s) → %s %s", action.ActorUsername, action.ActorID, action.Action, action.TargetType)
+	return nil
+}
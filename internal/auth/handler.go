@@ -1,9 +1,9 @@
 package auth
 
 import (
-	"context"
-	"fmt"
+	"geoanomaly/internal/analytics"
 	"geoanomaly/pkg/middleware"
+	"log"
 	"net/http"
 	"time"
 
@@ -20,14 +20,18 @@ type Handler struct {
 }
 
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required,min=3,max=50"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Username   string `json:"username" binding:"required,min=3,max=50"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required,min=8"`
+	DeviceID   string `json:"device_id,omitempty" binding:"omitempty,max=100"`
+	DeviceName string `json:"device_name,omitempty" binding:"omitempty,max=100"`
 }
 
 type LoginRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username   string `json:"username" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	DeviceID   string `json:"device_id,omitempty" binding:"omitempty,max=100"`
+	DeviceName string `json:"device_name,omitempty" binding:"omitempty,max=100"`
 }
 
 type AuthResponse struct {
@@ -92,8 +96,10 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := middleware.GenerateJWT(user.ID, user.Username, user.Tier)
+	analytics.Track(h.db, analytics.Event{Type: analytics.EventUserRegistered, UserID: &user.ID, Tier: &user.Tier})
+
+	// Generate access + refresh token for this device
+	tokens, err := h.startSession(c, &user, req.DeviceID, req.DeviceName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
@@ -102,12 +108,11 @@ func (h *Handler) Register(c *gin.Context) {
 	// Remove password hash from response
 	user.PasswordHash = ""
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message":   "User registered successfully",
-		"token":     token,
-		"user":      user,
-		"timestamp": time.Now().Format(time.RFC3339),
-	})
+	response := tokens.toJSON()
+	response["message"] = "User registered successfully"
+	response["user"] = user
+	response["timestamp"] = time.Now().Format(time.RFC3339)
+	c.JSON(http.StatusCreated, response)
 }
 
 func (h *Handler) Login(c *gin.Context) {
@@ -148,6 +153,17 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
+	// Check if user is banned (expired temporary ban no longer blocks login)
+	if user.IsBanActive(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":        "Account is banned",
+			"message":      "Your account has been banned. Please contact support.",
+			"reason":       user.BanReason,
+			"banned_until": user.BannedUntil,
+		})
+		return
+	}
+
 	// Update last login timestamp
 	now := time.Now()
 	h.db.Model(&user).Updates(map[string]interface{}{
@@ -155,60 +171,23 @@ func (h *Handler) Login(c *gin.Context) {
 		// "last_login": now, // Uncomment if you add this field to User model
 	})
 
-	// Generate JWT token
-	token, err := middleware.GenerateJWT(user.ID, user.Username, user.Tier)
+	// Generate access + refresh token for this device
+	tokens, err := h.startSession(c, &user, req.DeviceID, req.DeviceName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
+	analytics.Track(h.db, analytics.Event{Type: analytics.EventUserLogin, UserID: &user.ID, Tier: &user.Tier})
+
 	// Remove password hash from response
 	user.PasswordHash = ""
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Login successful",
-		"token":     token,
-		"user":      user,
-		"expires":   time.Now().Add(24 * time.Hour).Unix(),
-		"timestamp": time.Now().Format(time.RFC3339),
-	})
-}
-
-func (h *Handler) RefreshToken(c *gin.Context) {
-	// Get user from context (set by JWT middleware)
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
-		return
-	}
-
-	// ✅ OPRAVENÉ: Removed unused variables username and tier
-	// We get fresh data from database instead
-
-	// Verify user still exists and is active
-	var user User
-	if err := h.db.Where("id = ? AND is_active = true", userID).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "User not found or inactive",
-			"message": "Your account may have been deactivated",
-		})
-		return
-	}
-
-	// Use current user data from DB (in case tier changed)
-	token, err := middleware.GenerateJWT(user.ID, user.Username, user.Tier)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message":   "Token refreshed successfully",
-		"token":     token,
-		"expires":   time.Now().Add(24 * time.Hour).Unix(),
-		"user_tier": user.Tier,
-		"timestamp": time.Now().Format(time.RFC3339),
-	})
+	response := tokens.toJSON()
+	response["message"] = "Login successful"
+	response["user"] = user
+	response["timestamp"] = time.Now().Format(time.RFC3339)
+	c.JSON(http.StatusOK, response)
 }
 
 // Logout endpoint - improved implementation
@@ -219,16 +198,27 @@ func (h *Handler) Logout(c *gin.Context) {
 		return
 	}
 
-	// If using Redis for session management, invalidate token here
-	if h.redis != nil {
-		tokenKey := fmt.Sprintf("blacklist:token:%s", userID)
-		h.redis.Set(context.Background(), tokenKey, "blacklisted", 24*time.Hour)
+	// Odvolaj aktuálny token (jti) - JWTAuth ho od teraz odmietne
+	tokenRevoked := false
+	tokenID := c.GetString("token_id")
+	if expires, ok := c.Get("token_expires"); ok {
+		if err := middleware.RevokeToken(tokenID, expires.(time.Time)); err != nil {
+			log.Printf("⚠️ Failed to revoke token for user %v: %v", userID, err)
+		} else {
+			tokenRevoked = true
+		}
+	}
+
+	// Odhlás aj zariadenie - jeho refresh token už nepôjde použiť
+	if sessionID, err := uuid.Parse(c.GetString("session_id")); err == nil {
+		h.revokeSession(&RefreshSession{ID: sessionID}, SessionRevokedLogout)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":   "Logged out successfully",
-		"timestamp": time.Now().Format(time.RFC3339),
-		"note":      "Please delete the token from your client",
+		"message":       "Logged out successfully",
+		"token_revoked": tokenRevoked,
+		"timestamp":     time.Now().Format(time.RFC3339),
+		"note":          "Please delete the token from your client",
 	})
 }
 
@@ -319,8 +309,17 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	// Odhlás všetky zariadenia - staré tokeny prestanú platiť
+	sessionsRevoked := true
+	if err := RevokeUserSessions(h.db, user.ID, SessionRevokedPasswordChanged); err != nil {
+		log.Printf("⚠️ Failed to revoke sessions for user %s after password change: %v", user.ID, err)
+		sessionsRevoked = false
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message":   "Password changed successfully",
-		"timestamp": time.Now().Format(time.RFC3339),
+		"message":          "Password changed successfully",
+		"sessions_revoked": sessionsRevoked,
+		"note":             "Please log in again on all devices",
+		"timestamp":        time.Now().Format(time.RFC3339),
 	})
 }
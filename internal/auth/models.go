@@ -64,6 +64,10 @@ type User struct {
 	ZonesDiscovered int        `json:"zones_discovered" gorm:"default:0"`
 	IsActive        bool       `json:"is_active" gorm:"default:true"`
 	IsBanned        bool       `json:"is_banned" gorm:"default:false"`
+	BanReason       string     `json:"ban_reason,omitempty" gorm:"type:text"`
+	BannedAt        *time.Time `json:"banned_at,omitempty"`
+	BannedUntil     *time.Time `json:"banned_until,omitempty" gorm:"index"` // nil = permanentný ban
+	BannedBy        *uuid.UUID `json:"banned_by,omitempty" gorm:"type:uuid"`
 	LastLogin       *time.Time `json:"last_login,omitempty"`
 	ProfileData     JSONB      `json:"profile_data,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
 }
@@ -86,6 +90,28 @@ type PlayerSession struct {
 	LastLocationTimestamp time.Time `json:"last_location_timestamp"`
 }
 
+// RefreshSession model - migrovaný do auth.refresh_sessions
+// Jedna session = jedno prihlásené zariadenie; refresh token sa pri každom použití rotuje
+type RefreshSession struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	DeviceID   string    `json:"device_id" gorm:"size:100;index"`
+	DeviceName string    `json:"device_name" gorm:"size:100"`
+	UserAgent  string    `json:"user_agent" gorm:"size:255"`
+	IPAddress  string    `json:"ip_address" gorm:"size:45"`
+
+	TokenHash     string    `json:"-" gorm:"not null;size:64"` // sha256 aktuálneho refresh tokenu
+	RotationCount int       `json:"rotation_count" gorm:"default:0"`
+	LastUsedAt    time.Time `json:"last_used_at"`
+	ExpiresAt     time.Time `json:"expires_at" gorm:"not null;index"`
+
+	RevokedAt     *time.Time `json:"revoked_at,omitempty" gorm:"index"`
+	RevokedReason string     `json:"revoked_reason,omitempty" gorm:"size:50"`
+}
+
 // TableName methods for GORM schema qualification
 func (User) TableName() string {
 	return "auth.users"
@@ -95,6 +121,18 @@ func (PlayerSession) TableName() string {
 	return "auth.player_sessions"
 }
 
+func (RefreshSession) TableName() string {
+	return "auth.refresh_sessions"
+}
+
+// IsBanActive - ban platí, kým nevyprší (dočasný) alebo nie je zrušený
+func (u *User) IsBanActive(now time.Time) bool {
+	if !u.IsBanned {
+		return false
+	}
+	return u.BannedUntil == nil || now.Before(*u.BannedUntil)
+}
+
 // Helper methods for PlayerSession
 func (ps *PlayerSession) GetLastLocation() LocationWithAccuracy {
 	return LocationWithAccuracy{
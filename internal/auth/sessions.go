@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"geoanomaly/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefreshTokenTTL - refresh token platí 30 dní od posledného použitia (mobilné klienty ostanú prihlásené)
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// Dôvody odvolania refresh session
+const (
+	SessionRevokedLogout          = "logout"
+	SessionRevokedByUser          = "revoked_by_user"
+	SessionRevokedReplaced        = "replaced"
+	SessionRevokedReuse           = "token_reuse"
+	SessionRevokedPasswordChanged = "password_changed"
+	SessionRevokedBanned          = "banned"
+	SessionRevokedAccountInactive = "account_inactive"
+)
+
+var errInvalidRefreshToken = errors.New("invalid refresh token")
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// tokenPair - odpoveď pri login/register/refresh
+type tokenPair struct {
+	AccessToken    string
+	AccessExpires  time.Time
+	RefreshToken   string
+	RefreshExpires time.Time
+	SessionID      uuid.UUID
+}
+
+func (tp tokenPair) toJSON() gin.H {
+	return gin.H{
+		"token":           tp.AccessToken,
+		"expires":         tp.AccessExpires.Unix(),
+		"refresh_token":   tp.RefreshToken,
+		"refresh_expires": tp.RefreshExpires.Unix(),
+		"session_id":      tp.SessionID,
+	}
+}
+
+// newRefreshToken - vygeneruje "<session_id>.<secret>" a hash secretu pre DB
+func newRefreshToken(sessionID uuid.UUID) (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	return sessionID.String() + "." + encoded, hashRefreshSecret(encoded), nil
+}
+
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseRefreshToken(token string) (uuid.UUID, string, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return uuid.Nil, "", errInvalidRefreshToken
+	}
+	sessionID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, "", errInvalidRefreshToken
+	}
+	return sessionID, parts[1], nil
+}
+
+// startSession - vytvorí novú refresh session pre zariadenie a vydá pár tokenov
+func (h *Handler) startSession(c *gin.Context, user *User, deviceID, deviceName string) (*tokenPair, error) {
+	now := time.Now()
+
+	// Jedno zariadenie = jedna aktívna session; staré prihlásenie z toho istého zariadenia zrušíme
+	if deviceID != "" {
+		var previous []RefreshSession
+		h.db.Where("user_id = ? AND device_id = ? AND revoked_at IS NULL", user.ID, deviceID).Find(&previous)
+		for i := range previous {
+			h.revokeSession(&previous[i], SessionRevokedReplaced)
+		}
+	}
+
+	session := RefreshSession{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		DeviceID:   deviceID,
+		DeviceName: deviceName,
+		UserAgent:  truncate(c.Request.UserAgent(), 255),
+		IPAddress:  c.ClientIP(),
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(RefreshTokenTTL),
+	}
+
+	refreshToken, tokenHash, err := newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	session.TokenHash = tokenHash
+
+	if err := h.db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	accessToken, err := middleware.GenerateJWT(user.ID, user.Username, user.Tier, session.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenPair{
+		AccessToken:    accessToken,
+		AccessExpires:  now.Add(middleware.AccessTokenTTL),
+		RefreshToken:   refreshToken,
+		RefreshExpires: session.ExpiresAt,
+		SessionID:      session.ID,
+	}, nil
+}
+
+// revokeSession - zneplatní refresh session aj jej access tokeny
+func (h *Handler) revokeSession(session *RefreshSession, reason string) {
+	now := time.Now()
+	h.db.Model(&RefreshSession{}).
+		Where("id = ? AND revoked_at IS NULL", session.ID).
+		Updates(map[string]interface{}{
+			"revoked_at":     now,
+			"revoked_reason": reason,
+		})
+	session.RevokedAt = &now
+	session.RevokedReason = reason
+
+	if err := middleware.RevokeSession(session.ID.String()); err != nil {
+		log.Printf("⚠️ Failed to revoke access tokens for session %s: %v", session.ID, err)
+	}
+}
+
+// RevokeUserSessions - odhlási používateľa zo všetkých zariadení (ban, zmena hesla)
+func RevokeUserSessions(db *gorm.DB, userID uuid.UUID, reason string) error {
+	err := db.Model(&RefreshSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"revoked_at":     time.Now(),
+			"revoked_reason": reason,
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return middleware.RevokeAllUserTokens(userID)
+}
+
+// RefreshToken - vymení refresh token za nový pár tokenov (rotácia s detekciou opakovaného použitia)
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	sessionID, secret, err := parseRefreshToken(req.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token", "code": "REFRESH_TOKEN_INVALID"})
+		return
+	}
+
+	var session RefreshSession
+	if err := h.db.First(&session, "id = ?", sessionID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token", "code": "REFRESH_TOKEN_INVALID"})
+		return
+	}
+
+	if session.RevokedAt != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked", "code": "SESSION_REVOKED"})
+		return
+	}
+
+	// Platná session, ale starý token => niekto použil už rotovaný token (možná krádež)
+	if hashRefreshSecret(secret) != session.TokenHash {
+		log.Printf("🚨 Refresh token reuse detected for user %s (session %s) - revoking session", session.UserID, session.ID)
+		h.revokeSession(&session, SessionRevokedReuse)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected", "code": "REFRESH_TOKEN_REUSED"})
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token expired", "code": "REFRESH_TOKEN_EXPIRED"})
+		return
+	}
+
+	// Verify user still exists and is active
+	var user User
+	if err := h.db.Where("id = ? AND is_active = true", session.UserID).First(&user).Error; err != nil {
+		h.revokeSession(&session, SessionRevokedAccountInactive)
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "User not found or inactive",
+			"message": "Your account may have been deactivated",
+		})
+		return
+	}
+	if user.IsBanActive(time.Now()) {
+		h.revokeSession(&session, SessionRevokedBanned)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is banned"})
+		return
+	}
+
+	newToken, newHash, err := newRefreshToken(session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	// Rotácia je podmienená starým hashom - pri súbežnom refreshi vyhrá len jeden request
+	now := time.Now()
+	result := h.db.Model(&RefreshSession{}).
+		Where("id = ? AND token_hash = ? AND revoked_at IS NULL", session.ID, session.TokenHash).
+		Updates(map[string]interface{}{
+			"token_hash":     newHash,
+			"rotation_count": gorm.Expr("rotation_count + 1"),
+			"last_used_at":   now,
+			"expires_at":     now.Add(RefreshTokenTTL),
+			"ip_address":     c.ClientIP(),
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate refresh token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("🚨 Concurrent refresh token use for user %s (session %s) - revoking session", session.UserID, session.ID)
+		h.revokeSession(&session, SessionRevokedReuse)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected", "code": "REFRESH_TOKEN_REUSED"})
+		return
+	}
+
+	// Use current user data from DB (in case tier changed)
+	accessToken, err := middleware.GenerateJWT(user.ID, user.Username, user.Tier, session.ID.String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	pair := tokenPair{
+		AccessToken:    accessToken,
+		AccessExpires:  now.Add(middleware.AccessTokenTTL),
+		RefreshToken:   newToken,
+		RefreshExpires: now.Add(RefreshTokenTTL),
+		SessionID:      session.ID,
+	}
+
+	response := pair.toJSON()
+	response["message"] = "Token refreshed successfully"
+	response["user_tier"] = user.Tier
+	response["timestamp"] = now.Format(time.RFC3339)
+	c.JSON(http.StatusOK, response)
+}
+
+// ListSessions - zoznam aktívnych zariadení používateľa
+func (h *Handler) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var sessions []RefreshSession
+	if err := h.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	currentSession := c.GetString("session_id")
+	result := make([]gin.H, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, gin.H{
+			"id":             s.ID,
+			"device_id":      s.DeviceID,
+			"device_name":    s.DeviceName,
+			"user_agent":     s.UserAgent,
+			"ip_address":     s.IPAddress,
+			"created_at":     s.CreatedAt,
+			"last_used_at":   s.LastUsedAt,
+			"expires_at":     s.ExpiresAt,
+			"rotation_count": s.RotationCount,
+			"current":        s.ID.String() == currentSession,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions":  result,
+		"total":     len(result),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// RevokeSession - odhlási konkrétne zariadenie
+func (h *Handler) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var session RefreshSession
+	if err := h.db.Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	h.revokeSession(&session, SessionRevokedByUser)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Session revoked",
+		"session_id": session.ID,
+		"current":    session.ID.String() == c.GetString("session_id"),
+		"timestamp":  time.Now().Format(time.RFC3339),
+	})
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
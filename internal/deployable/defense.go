@@ -0,0 +1,537 @@
+package deployable
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/notifications"
+	"geoanomaly/internal/realtime"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Obranné moduly zariadenia
+const (
+	DefenseFirewall     = "firewall"      // zvyšuje hack_resistance
+	DefenseToolShredder = "tool_shredder" // pasca: zničí hackerovi použitia nástroja
+	DefenseTracer       = "tracer"        // pasca: prezradí vlastníkovi meno hackera
+
+	MaxDefenseModules = 3 // sloty na zariadení, každý typ najviac raz
+	MaxDefenseLevel   = 3
+	MaxHackResistance = 10
+
+	CounterHackWindow = 5 * time.Minute // vlastník môže po úspešnom hacku zrušiť prístup
+
+	defaultHackHistoryLimit = 20
+	maxHackHistoryLimit     = 100
+)
+
+var (
+	ErrDefenseModuleNotFound   = errors.New("obranný modul nebol nájdený")
+	ErrInvalidDefenseModule    = errors.New("predmet nie je obranný modul")
+	ErrDefenseSlotsFull        = errors.New("zariadenie nemá voľný slot pre obranný modul")
+	ErrDefenseModuleInstalled  = errors.New("modul tohto typu je už na zariadení nainštalovaný")
+	ErrHackAttemptNotFound     = errors.New("pokus o hack nebol nájdený")
+	ErrNoAccessToRevoke        = errors.New("hack neudelil žiadny prístup, ktorý by sa dal zrušiť")
+	ErrCounterHackWindowClosed = errors.New("okno na counter-hack už uplynulo")
+)
+
+// DefenseModuleSpec - efekty typu modulu podľa levelu
+type DefenseModuleSpec struct {
+	ResistancePerLevel int     `json:"resistance_per_level,omitempty"`
+	TriggerChance      float64 `json:"trigger_chance,omitempty"`    // pasca na leveli 1
+	TriggerPerLevel    float64 `json:"trigger_per_level,omitempty"` // + za každý ďalší level
+	ToolDamagePerLevel int     `json:"tool_damage_per_level,omitempty"`
+	Charges            int     `json:"charges,omitempty"` // 0 = permanentný modul
+}
+
+var defenseModuleSpecs = map[string]DefenseModuleSpec{
+	DefenseFirewall:     {ResistancePerLevel: 2},
+	DefenseToolShredder: {TriggerChance: 0.3, TriggerPerLevel: 0.15, ToolDamagePerLevel: 1, Charges: 5},
+	DefenseTracer:       {TriggerChance: 0.4, TriggerPerLevel: 0.2, Charges: 5},
+}
+
+// DefenseModule - modul nainštalovaný na zariadení (predmet z inventára je zatiaľ soft-deleted)
+type DefenseModule struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	DeviceID        uuid.UUID `json:"device_id" gorm:"type:uuid;not null;index"`
+	OwnerID         uuid.UUID `json:"owner_id" gorm:"type:uuid;not null"`
+	ModuleType      string    `json:"module_type" gorm:"size:30;not null"`
+	Level           int       `json:"level" gorm:"not null;default:1"`
+	ChargesLeft     *int      `json:"charges_left,omitempty"` // nil = permanentný
+	InventoryItemID uuid.UUID `json:"inventory_item_id" gorm:"type:uuid;not null"`
+	InstalledAt     time.Time `json:"installed_at" gorm:"not null"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (DefenseModule) TableName() string {
+	return "gameplay.device_defense_modules"
+}
+
+func (m *DefenseModule) spec() DefenseModuleSpec {
+	return defenseModuleSpecs[m.ModuleType]
+}
+
+// ResistanceBonus - príspevok modulu k hack_resistance
+func (m *DefenseModule) ResistanceBonus() int {
+	return m.spec().ResistancePerLevel * m.Level
+}
+
+// TriggerChance - šanca, že pasca zareaguje na pokus o hack
+func (m *DefenseModule) TriggerChance() float64 {
+	spec := m.spec()
+	if spec.TriggerChance == 0 || (m.ChargesLeft != nil && *m.ChargesLeft <= 0) {
+		return 0
+	}
+	return spec.TriggerChance + spec.TriggerPerLevel*float64(m.Level-1)
+}
+
+// DeviceDefense - obrana zariadenia pre vlastníka
+type DeviceDefense struct {
+	DeviceID            uuid.UUID       `json:"device_id"`
+	BaseResistance      int             `json:"base_resistance"`
+	EffectiveResistance int             `json:"effective_resistance"`
+	Modules             []DefenseModule `json:"modules"`
+	FreeSlots           int             `json:"free_slots"`
+}
+
+// InstallDefenseModuleRequest - inštalácia modulu z inventára
+type InstallDefenseModuleRequest struct {
+	InventoryItemID uuid.UUID `json:"inventory_item_id" binding:"required"`
+}
+
+// CounterHackRequest - zrušenie prístupu získaného hackom
+type CounterHackRequest struct {
+	HackID uuid.UUID `json:"hack_id" binding:"required"`
+}
+
+// CounterHackResponse - výsledok counter-hacku
+type CounterHackResponse struct {
+	HackID          uuid.UUID `json:"hack_id"`
+	AccessRevoked   bool      `json:"access_revoked"`
+	DeviceReenabled bool      `json:"device_reenabled"`
+}
+
+// HackAttempt - záznam v histórii pokusov pre vlastníka (hacker je anonymný, kým ho nevystopuje tracer)
+type HackAttempt struct {
+	ID               uuid.UUID  `json:"id"`
+	HackTime         time.Time  `json:"hack_time"`
+	Success          bool       `json:"success"`
+	Claim            bool       `json:"claim"`
+	ToolType         string     `json:"tool_type"`
+	MinigameType     string     `json:"minigame_type"`
+	MinigameScore    int        `json:"minigame_score"`
+	MinigameVerified bool       `json:"minigame_verified"`
+	DistanceM        float64    `json:"distance_m"`
+	HackerUsername   string     `json:"hacker_username,omitempty"`
+	DefenseTriggered []string   `json:"defense_triggered"`
+	ToolDamage       int        `json:"tool_damage,omitempty"`
+	Countered        bool       `json:"countered"`
+	CounterHackUntil *time.Time `json:"counter_hack_until,omitempty"`
+}
+
+// defenseResult - čo obrana spravila pri jednom pokuse
+type defenseResult struct {
+	Resistance     int
+	Triggered      []string
+	ToolDamage     int
+	HackerUsername string
+}
+
+// DefenseModuleProperties - properties nového modulu v inventári (market)
+func DefenseModuleProperties(source map[string]interface{}) map[string]interface{} {
+	moduleType, _ := source["module_type"].(string)
+	if _, ok := defenseModuleSpecs[moduleType]; !ok {
+		moduleType = DefenseFirewall
+	}
+	level := int(propFloatOr(source, "level", 1))
+	level = max(1, min(level, MaxDefenseLevel))
+
+	props := map[string]interface{}{
+		"module_type": moduleType,
+		"level":       level,
+	}
+	if charges := defenseModuleSpecs[moduleType].Charges; charges > 0 {
+		// zostávajúce náboje (reinštalácia) majú prednosť pred market hodnotou
+		charges = int(propFloatOr(source, "charges", float64(charges)))
+		props["charges_left"] = int(propFloatOr(source, "charges_left", float64(charges)))
+	}
+	return props
+}
+
+// effectiveResistance - základný odpor zariadenia + firewally (max MaxHackResistance)
+func effectiveResistance(base int, modules []DefenseModule) int {
+	resistance := base
+	for i := range modules {
+		resistance += modules[i].ResistanceBonus()
+	}
+	return min(resistance, MaxHackResistance)
+}
+
+// rollDefenseTraps - ktoré pasce zareagovali na pokus
+func rollDefenseTraps(rng randSource, modules []DefenseModule) []*DefenseModule {
+	var triggered []*DefenseModule
+	for i := range modules {
+		chance := modules[i].TriggerChance()
+		if chance > 0 && rng.Float64() < chance {
+			triggered = append(triggered, &modules[i])
+		}
+	}
+	return triggered
+}
+
+func (s *Service) defenseModules(deviceID uuid.UUID) []DefenseModule {
+	var modules []DefenseModule
+	s.db.Where("device_id = ?", deviceID).Order("installed_at ASC").Find(&modules)
+	return modules
+}
+
+// deviceResistance - efektívny odpor zariadenia vrátane firewallov (opustené sa nebráni)
+func (s *Service) deviceResistance(device *DeployedDevice) int {
+	if device.Status == DeviceStatusAbandoned {
+		return device.HackResistance
+	}
+	return effectiveResistance(device.HackResistance, s.defenseModules(device.ID))
+}
+
+// applyDefense - obrana pri pokuse o hack: efektívny odpor + pasce (poškodenie nástroja, tracer).
+// Opustené zariadenie nemá vlastníka, ktorý by ho bránil.
+func (s *Service) applyDefense(device *DeployedDevice, hackerID uuid.UUID, hackTool *HackTool) *defenseResult {
+	result := &defenseResult{Resistance: device.HackResistance, Triggered: []string{}}
+	if device.Status == DeviceStatusAbandoned || device.OwnerID == hackerID {
+		return result
+	}
+
+	modules := s.defenseModules(device.ID)
+	result.Resistance = effectiveResistance(device.HackResistance, modules)
+
+	for _, module := range rollDefenseTraps(s.rng, modules) {
+		switch module.ModuleType {
+		case DefenseToolShredder:
+			damage := module.spec().ToolDamagePerLevel * module.Level
+			if err := s.damageHackTool(hackTool, damage); err != nil {
+				log.Printf("❌ Failed to damage hack tool %s: %v", hackTool.ID, err)
+				continue
+			}
+			result.ToolDamage += damage
+		case DefenseTracer:
+			var hacker auth.User
+			if err := s.db.Select("id", "username").First(&hacker, "id = ?", hackerID).Error; err != nil {
+				continue
+			}
+			result.HackerUsername = hacker.Username
+		}
+		result.Triggered = append(result.Triggered, module.ModuleType)
+		s.useDefenseCharge(module)
+	}
+
+	if len(result.Triggered) > 0 {
+		log.Printf("🛡️ Defense on device %s triggered %v against %s", device.ID, result.Triggered, hackerID)
+	}
+	return result
+}
+
+// useDefenseCharge - pasca minie náboj; vybitý modul zmizne zo zariadenia
+func (s *Service) useDefenseCharge(module *DefenseModule) {
+	if module.ChargesLeft == nil {
+		return
+	}
+	s.db.Model(&DefenseModule{}).Where("id = ? AND charges_left > 0", module.ID).Updates(map[string]interface{}{
+		"charges_left": gorm.Expr("charges_left - 1"),
+		"updated_at":   time.Now().UTC(),
+	})
+	s.db.Where("id = ? AND charges_left <= 0", module.ID).Delete(&DefenseModule{})
+}
+
+// damageHackTool - pasca zničí hackerovi ďalšie použitia nástroja (pri 0 sa nástroj zmaže)
+func (s *Service) damageHackTool(tool *HackTool, uses int) error {
+	return s.db.Exec(`
+		UPDATE gameplay.inventory_items
+		SET properties = jsonb_set(properties, '{uses_left}', to_jsonb(GREATEST((properties->>'uses_left')::int - ?, 0)), true),
+		    deleted_at = CASE WHEN (properties->>'uses_left')::int <= ? THEN NOW() ELSE NULL END,
+		    updated_at = NOW()
+		WHERE id = ?
+		  AND deleted_at IS NULL
+	`, uses, uses, tool.ID).Error
+}
+
+// ownedDevice - zariadenie patriace hráčovi
+func (s *Service) ownedDevice(db *gorm.DB, userID, deviceID uuid.UUID) (*DeployedDevice, error) {
+	var device DeployedDevice
+	if err := db.Where("id = ? AND owner_id = ?", deviceID, userID).First(&device).Error; err != nil {
+		return nil, fmt.Errorf("device not found or not owned by user")
+	}
+	return &device, nil
+}
+
+// GetDeviceDefense - moduly a efektívny odpor zariadenia
+func (s *Service) GetDeviceDefense(userID, deviceID uuid.UUID) (*DeviceDefense, error) {
+	device, err := s.ownedDevice(s.db, userID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	modules := s.defenseModules(deviceID)
+	if modules == nil {
+		modules = []DefenseModule{}
+	}
+	return &DeviceDefense{
+		DeviceID:            deviceID,
+		BaseResistance:      device.HackResistance,
+		EffectiveResistance: effectiveResistance(device.HackResistance, modules),
+		Modules:             modules,
+		FreeSlots:           max(MaxDefenseModules-len(modules), 0),
+	}, nil
+}
+
+// InstallDefenseModule - presunie modul z inventára na zariadenie
+func (s *Service) InstallDefenseModule(userID, deviceID uuid.UUID, req *InstallDefenseModuleRequest) (*DefenseModule, error) {
+	var module DefenseModule
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND owner_id = ? AND status <> ?", deviceID, userID, DeviceStatusAbandoned).
+			First(&DeployedDevice{}).Error; err != nil {
+			return fmt.Errorf("device not found or not owned by user")
+		}
+
+		var item gameplay.InventoryItem
+		if err := tx.Where("id = ? AND user_id = ? AND deleted_at IS NULL", req.InventoryItemID, userID).First(&item).Error; err != nil {
+			return ErrDefenseModuleNotFound
+		}
+		moduleType, _ := item.Properties["module_type"].(string)
+		if _, known := defenseModuleSpecs[moduleType]; item.ItemType != "defense_module" || !known {
+			return ErrInvalidDefenseModule
+		}
+
+		var installed []DefenseModule
+		if err := tx.Where("device_id = ?", deviceID).Find(&installed).Error; err != nil {
+			return err
+		}
+		if len(installed) >= MaxDefenseModules {
+			return ErrDefenseSlotsFull
+		}
+		for _, m := range installed {
+			if m.ModuleType == moduleType {
+				return ErrDefenseModuleInstalled
+			}
+		}
+
+		props := DefenseModuleProperties(item.Properties)
+		module = DefenseModule{
+			ID:              uuid.New(),
+			DeviceID:        deviceID,
+			OwnerID:         userID,
+			ModuleType:      moduleType,
+			Level:           props["level"].(int),
+			InventoryItemID: item.ID,
+			InstalledAt:     time.Now().UTC(),
+		}
+		if charges, ok := props["charges_left"].(int); ok {
+			module.ChargesLeft = &charges
+		}
+
+		if err := tx.Model(&gameplay.InventoryItem{}).Where("id = ?", item.ID).Update("deleted_at", time.Now()).Error; err != nil {
+			return fmt.Errorf("failed to remove module from inventory: %w", err)
+		}
+		return tx.Create(&module).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🛡️ Defense module %s (level %d) installed on device %s", module.ModuleType, module.Level, deviceID)
+	return &module, nil
+}
+
+// UninstallDefenseModule - vráti modul (so zostávajúcimi nábojmi) do inventára
+func (s *Service) UninstallDefenseModule(userID, deviceID, moduleID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var module DefenseModule
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND device_id = ? AND owner_id = ?", moduleID, deviceID, userID).
+			First(&module).Error; err != nil {
+			return ErrDefenseModuleNotFound
+		}
+
+		restore := map[string]interface{}{"deleted_at": nil}
+		if module.ChargesLeft != nil {
+			restore["properties"] = gorm.Expr("jsonb_set(properties, '{charges_left}', to_jsonb(?::int), true)", *module.ChargesLeft)
+		}
+		if err := tx.Model(&gameplay.InventoryItem{}).Unscoped().Where("id = ?", module.InventoryItemID).Updates(restore).Error; err != nil {
+			return fmt.Errorf("failed to restore module to inventory: %w", err)
+		}
+		return tx.Delete(&module).Error
+	})
+}
+
+// alarmDeviceOwner - alarm vlastníkovi pri každom pokuse o hack (realtime + inbox).
+// Identitu hackera prezradí len tracer.
+func (s *Service) alarmDeviceOwner(device *DeployedDevice, hack *DeviceHack, result *HackResponse, defense *defenseResult) {
+	if device.OwnerID == hack.HackerID || device.OwnerID == uuid.Nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"device_id":             device.ID,
+		"device_name":           device.Name,
+		"hack_id":               hack.ID,
+		"hack_successful":       result.Success,
+		"device_disabled":       result.DeviceDisabled,
+		"device_disabled_until": result.DeviceDisabledUntil,
+		"ownership_transferred": result.OwnershipTransferred,
+		"defense_triggered":     defense.Triggered,
+		"tool_damage":           defense.ToolDamage,
+	}
+	if defense.HackerUsername != "" {
+		data["hacker_username"] = defense.HackerUsername
+	}
+	if result.AccessGrantedUntil != nil {
+		data["counter_hack_until"] = hack.HackTime.Add(CounterHackWindow)
+	}
+	realtime.NotifyUser(device.OwnerID, realtime.EventDeviceHacked, data)
+
+	title := fmt.Sprintf("Pokus o hack: %s", device.Name)
+	body := "Niekto sa neúspešne pokúsil hacknúť tvoje zariadenie."
+	if result.Success {
+		body = "Tvoje zariadenie bolo hacknuté."
+		if result.AccessGrantedUntil != nil {
+			body += fmt.Sprintf(" Prístup môžeš zrušiť counter-hackom do %d minút.", int(CounterHackWindow.Minutes()))
+		}
+	}
+	if defense.HackerUsername != "" {
+		body += fmt.Sprintf(" Tracer vystopoval hráča %s.", defense.HackerUsername)
+	}
+	s.notifications.Send([]uuid.UUID{device.OwnerID}, notifications.Notification{
+		Type:  notifications.TypeDeviceAlarm,
+		Title: title,
+		Body:  body,
+		Data:  gameplay.JSONB(data),
+	}, "device_hack:"+hack.ID.String())
+}
+
+// CounterHack - vlastník v okne po úspešnom hacku zruší udelený prístup
+func (s *Service) CounterHack(userID, deviceID uuid.UUID, req *CounterHackRequest) (*CounterHackResponse, error) {
+	var hack DeviceHack
+	response := &CounterHackResponse{HackID: req.HackID}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.ownedDevice(tx, userID, deviceID); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ? AND device_id = ?", req.HackID, deviceID).First(&hack).Error; err != nil {
+			return ErrHackAttemptNotFound
+		}
+
+		var access DeviceAccess
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("device_id = ? AND granted_by_hack_id = ?", deviceID, hack.ID).
+			First(&access).Error; err != nil {
+			return ErrNoAccessToRevoke
+		}
+
+		now := time.Now().UTC()
+		if now.Sub(access.GrantedAt) > CounterHackWindow {
+			return ErrCounterHackWindowClosed
+		}
+		if !access.ExpiresAt.After(now) {
+			return ErrNoAccessToRevoke
+		}
+
+		if err := tx.Model(&access).Updates(map[string]interface{}{
+			"expires_at":         now,
+			"is_device_disabled": false,
+			"disabled_until":     nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to revoke access: %w", err)
+		}
+		response.AccessRevoked = true
+		response.DeviceReenabled = access.IsDeviceDisabled
+
+		return tx.Model(&DeviceHack{}).Where("id = ?", hack.ID).
+			Update("properties", gorm.Expr("COALESCE(properties, '{}'::jsonb) || jsonb_build_object('countered', true, 'countered_at', ?::text)", now.Format(time.RFC3339))).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifications.Send([]uuid.UUID{hack.HackerID}, notifications.Notification{
+		Type:  notifications.TypeDeviceCounterHack,
+		Title: "Prístup zrušený",
+		Body:  "Vlastník zariadenia odhalil tvoj hack a zrušil ti prístup.",
+		Data:  gameplay.JSONB{"device_id": deviceID, "hack_id": hack.ID},
+	}, "counter_hack:"+hack.ID.String())
+
+	log.Printf("⚔️ Counter-hack on device %s revoked access from hack %s", deviceID, hack.ID)
+	return response, nil
+}
+
+// GetDeviceHackHistory - pokusy o hack zariadenia od najnovších (len pre vlastníka)
+func (s *Service) GetDeviceHackHistory(userID, deviceID uuid.UUID, limit, offset int) ([]HackAttempt, int64, error) {
+	if _, err := s.ownedDevice(s.db, userID, deviceID); err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = defaultHackHistoryLimit
+	}
+	limit = min(limit, maxHackHistoryLimit)
+	offset = max(offset, 0)
+
+	var total int64
+	s.db.Model(&DeviceHack{}).Where("device_id = ?", deviceID).Count(&total)
+
+	var hacks []DeviceHack
+	if err := s.db.Where("device_id = ?", deviceID).Order("hack_time DESC").
+		Limit(limit).Offset(offset).Find(&hacks).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load hack history: %w", err)
+	}
+
+	now := time.Now()
+	attempts := make([]HackAttempt, 0, len(hacks))
+	for _, hack := range hacks {
+		attempts = append(attempts, hackAttemptFromRecord(hack, now))
+	}
+	return attempts, total, nil
+}
+
+func hackAttemptFromRecord(hack DeviceHack, now time.Time) HackAttempt {
+	props := hack.Properties
+	attempt := HackAttempt{
+		ID:               hack.ID,
+		HackTime:         hack.HackTime,
+		Success:          hack.Success,
+		ToolType:         hack.HackToolUsed,
+		MinigameType:     hack.MinigameType,
+		MinigameScore:    hack.MinigameScore,
+		MinigameVerified: hack.MinigameVerified,
+		DistanceM:        hack.DistanceM,
+		DefenseTriggered: []string{},
+		ToolDamage:       int(propFloatOr(props, "tool_damage", 0)),
+	}
+	attempt.Claim, _ = props["claim"].(bool)
+	attempt.Countered, _ = props["countered"].(bool)
+	attempt.HackerUsername, _ = props["hacker_username"].(string)
+
+	switch triggered := props["defense_triggered"].(type) {
+	case []string:
+		attempt.DefenseTriggered = triggered
+	case []interface{}:
+		for _, t := range triggered {
+			if name, ok := t.(string); ok {
+				attempt.DefenseTriggered = append(attempt.DefenseTriggered, name)
+			}
+		}
+	}
+
+	if hack.Success && !attempt.Claim && !attempt.Countered {
+		if until := hack.HackTime.Add(CounterHackWindow); now.Before(until) {
+			attempt.CounterHackUntil = &until
+		}
+	}
+	return attempt
+}
@@ -0,0 +1,129 @@
+package deployable
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestEffectiveResistance(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    int
+		modules []DefenseModule
+		want    int
+	}{
+		{"no modules", 2, nil, 2},
+		{"firewall level 2", 1, []DefenseModule{{ModuleType: DefenseFirewall, Level: 2}}, 5},
+		{"traps add nothing", 1, []DefenseModule{{ModuleType: DefenseTracer, Level: 3}}, 1},
+		{"capped", 5, []DefenseModule{{ModuleType: DefenseFirewall, Level: 3}}, MaxHackResistance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := effectiveResistance(tt.base, tt.modules); got != tt.want {
+				t.Errorf("effectiveResistance() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefenseModule_TriggerChance(t *testing.T) {
+	tests := []struct {
+		name   string
+		module DefenseModule
+		want   float64
+	}{
+		{"firewall is not a trap", DefenseModule{ModuleType: DefenseFirewall, Level: 3}, 0},
+		{"shredder level 1", DefenseModule{ModuleType: DefenseToolShredder, Level: 1, ChargesLeft: intPtr(5)}, 0.3},
+		{"tracer level 3", DefenseModule{ModuleType: DefenseTracer, Level: 3, ChargesLeft: intPtr(1)}, 0.8},
+		{"out of charges", DefenseModule{ModuleType: DefenseTracer, Level: 3, ChargesLeft: intPtr(0)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.module.TriggerChance(); got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("TriggerChance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollDefenseTraps_Frequency(t *testing.T) {
+	const attempts = 20000
+	rng := rand.New(rand.NewSource(7))
+	modules := []DefenseModule{
+		{ModuleType: DefenseFirewall, Level: 1},
+		{ModuleType: DefenseToolShredder, Level: 2, ChargesLeft: intPtr(5)},
+	}
+
+	hits := 0
+	for i := 0; i < attempts; i++ {
+		for _, m := range rollDefenseTraps(rng, modules) {
+			if m.ModuleType != DefenseToolShredder {
+				t.Fatalf("unexpected trap %s", m.ModuleType)
+			}
+			hits++
+		}
+	}
+	if got := float64(hits) / attempts; got < 0.435 || got > 0.465 {
+		t.Errorf("shredder trigger rate %.3f, want 0.45 ± 0.015", got)
+	}
+}
+
+func TestDefenseModuleProperties(t *testing.T) {
+	tests := []struct {
+		name   string
+		source map[string]interface{}
+		want   map[string]interface{}
+	}{
+		{"unknown type falls back to firewall", map[string]interface{}{"module_type": "moat"}, map[string]interface{}{"module_type": DefenseFirewall, "level": 1}},
+		{"level capped", map[string]interface{}{"module_type": DefenseFirewall, "level": float64(9)}, map[string]interface{}{"module_type": DefenseFirewall, "level": MaxDefenseLevel}},
+		{"market charges", map[string]interface{}{"module_type": DefenseTracer, "charges": float64(8)}, map[string]interface{}{"module_type": DefenseTracer, "level": 1, "charges_left": 8}},
+		{"remaining charges kept", map[string]interface{}{"module_type": DefenseToolShredder, "level": float64(2), "charges_left": float64(2)}, map[string]interface{}{"module_type": DefenseToolShredder, "level": 2, "charges_left": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefenseModuleProperties(tt.source); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DefenseModuleProperties() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHackAttemptFromRecord_CounterHackWindow(t *testing.T) {
+	hackTime := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	hack := DeviceHack{
+		ID:       uuid.New(),
+		HackTime: hackTime,
+		Success:  true,
+		Properties: datatypes.JSONMap{
+			"defense_triggered": []interface{}{DefenseTracer},
+			"hacker_username":   "neo",
+			"tool_damage":       float64(1),
+		},
+	}
+
+	attempt := hackAttemptFromRecord(hack, hackTime.Add(time.Minute))
+	if attempt.CounterHackUntil == nil || !attempt.CounterHackUntil.Equal(hackTime.Add(CounterHackWindow)) {
+		t.Errorf("counter-hack until = %v, want %v", attempt.CounterHackUntil, hackTime.Add(CounterHackWindow))
+	}
+	if !reflect.DeepEqual(attempt.DefenseTriggered, []string{DefenseTracer}) || attempt.HackerUsername != "neo" || attempt.ToolDamage != 1 {
+		t.Errorf("unexpected attempt %+v", attempt)
+	}
+
+	if late := hackAttemptFromRecord(hack, hackTime.Add(CounterHackWindow)); late.CounterHackUntil != nil {
+		t.Error("window must be closed after CounterHackWindow")
+	}
+	hack.Properties["countered"] = true
+	if countered := hackAttemptFromRecord(hack, hackTime.Add(time.Minute)); countered.CounterHackUntil != nil {
+		t.Error("countered hack cannot be countered again")
+	}
+}
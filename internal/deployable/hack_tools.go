@@ -0,0 +1,342 @@
+package deployable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+
+	"geoanomaly/internal/gameplay"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Hack / claim pravidlá
+const (
+	HackRangeMeters      = 50               // základný dosah hacku aj claimu
+	HackRetryCooldown    = 10 * time.Minute // po neúspešnom pokuse na to isté zariadenie
+	MaxCooldownReduction = 0.9
+
+	hackBaseSuccessRate     = 0.5
+	hackResistancePenalty   = 0.05 // za každý level hack_resistance
+	hackMinSuccessRate      = 0.05
+	hackMaxSuccessRate      = 0.95
+	hackToolRecentUsesLimit = 20
+)
+
+var (
+	ErrHackToolNotFound = errors.New("hackovací nástroj nebol nájdený v inventári")
+	ErrHackToolNoUses   = errors.New("hackovací nástroj nemá žiadne zostávajúce použitia")
+	ErrHackToolExpired  = errors.New("hackovací nástroj vypršal")
+	ErrHackToolConsumed = errors.New("nástroj už neexistuje v inventári")
+	ErrHackCooldown     = errors.New("zariadenie sa dá znova hackovať až po cooldowne")
+)
+
+// HackToolSpec - predvolené vlastnosti typu nástroja (market/recept ich môže prepísať)
+type HackToolSpec struct {
+	Uses              int           `json:"uses"`
+	SuccessRate       float64       `json:"success_rate"`
+	SuccessBonus      float64       `json:"success_bonus"`
+	RangeBonusM       int           `json:"range_bonus_m"`
+	CooldownReduction float64       `json:"cooldown_reduction"`
+	Lifetime          time.Duration `json:"-"` // 0 = nevyprší
+}
+
+var hackToolSpecs = map[string]HackToolSpec{
+	"circuit_breaker":      {Uses: 3, SuccessRate: 0.5, Lifetime: 7 * 24 * time.Hour},
+	"code_cracker":         {Uses: 5, SuccessRate: 0.6, SuccessBonus: 0.05, RangeBonusM: 25, CooldownReduction: 0.25, Lifetime: 14 * 24 * time.Hour},
+	"stealth_infiltration": {Uses: 2, SuccessRate: 0.55, SuccessBonus: 0.1, RangeBonusM: 50, CooldownReduction: 0.5, Lifetime: 3 * 24 * time.Hour},
+}
+
+// normalizeToolType - market typy na povolené typy pre DB constraint (basic_hack → circuit_breaker, ...)
+func normalizeToolType(toolType string) string {
+	switch toolType {
+	case "basic_hack":
+		return "circuit_breaker"
+	case "advanced_hack":
+		return "code_cracker"
+	case "device_claimer", "stealth_hack":
+		return "stealth_infiltration"
+	case "":
+		return "circuit_breaker"
+	default:
+		return toolType
+	}
+}
+
+// HackToolProperties - properties nového nástroja v inventári pri získaní (market, crafting).
+// Hodnoty zo zdroja (market item / výsledok receptu) majú prednosť pred defaultmi typu.
+func HackToolProperties(source map[string]interface{}, acquiredVia string, now time.Time) map[string]interface{} {
+	rawType, _ := source["tool_type"].(string)
+	if rawType == "" {
+		rawType = "basic_hack"
+	}
+	spec := hackToolSpecs[normalizeToolType(rawType)]
+
+	uses := spec.Uses
+	if v, ok := propFloat(source, "uses"); ok {
+		uses = int(v)
+	} else if v, ok := propFloat(source, "uses_left"); ok {
+		uses = int(v)
+	}
+	if uses <= 0 {
+		uses = 1
+	}
+
+	props := map[string]interface{}{
+		"tool_type":          rawType,
+		"uses_left":          uses,
+		"max_uses":           uses,
+		"success_rate":       propFloatOr(source, "success_rate", spec.SuccessRate),
+		"success_bonus":      propFloatOr(source, "success_bonus", spec.SuccessBonus),
+		"range_bonus_m":      propFloatOr(source, "range_bonus_m", float64(spec.RangeBonusM)),
+		"cooldown_reduction": math.Min(propFloatOr(source, "cooldown_reduction", spec.CooldownReduction), MaxCooldownReduction),
+		"acquired_via":       acquiredVia,
+		"acquired_at":        now.Unix(),
+	}
+	if hackTime, ok := source["hack_time_seconds"]; ok {
+		props["hack_time_seconds"] = hackTime
+	}
+
+	lifetime := spec.Lifetime
+	if hours, ok := propFloat(source, "lifetime_hours"); ok {
+		lifetime = time.Duration(hours * float64(time.Hour))
+	}
+	if lifetime > 0 {
+		props["expires_at"] = now.Add(lifetime).Unix()
+	}
+	return props
+}
+
+// hackToolFromItem - HackTool z inventory_items (tabuľka hack_tools sa nepoužíva)
+func hackToolFromItem(item gameplay.InventoryItem) HackTool {
+	props := item.Properties
+	rawType, _ := props["tool_type"].(string)
+
+	name := "Unknown Hack Tool"
+	if n, ok := props["name"].(string); ok {
+		name = n
+	} else if dn, ok := props["display_name"].(string); ok {
+		name = dn
+	}
+
+	var expiresAt *time.Time
+	if unix, ok := propFloat(props, "expires_at"); ok {
+		t := time.Unix(int64(unix), 0).UTC()
+		expiresAt = &t
+	}
+
+	usesLeft, _ := propFloat(props, "uses_left")
+	return HackTool{
+		ID:         item.ID,
+		UserID:     item.UserID,
+		ToolType:   normalizeToolType(rawType),
+		Name:       name,
+		UsesLeft:   int(usesLeft),
+		ExpiresAt:  expiresAt, // nástroje bez expires_at (staršie nákupy) nevypršia
+		Properties: datatypes.JSONMap(props),
+		CreatedAt:  item.CreatedAt,
+		UpdatedAt:  item.UpdatedAt,
+	}
+}
+
+// Expired - nástroj s uplynutým expires_at sa už nedá použiť
+func (t *HackTool) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// SuccessRate - základná šanca nástroja (bez odporu zariadenia)
+func (t *HackTool) SuccessRate() float64 {
+	return propFloatOr(t.Properties, "success_rate", hackBaseSuccessRate)
+}
+
+func (t *HackTool) SuccessBonus() float64 {
+	return propFloatOr(t.Properties, "success_bonus", 0)
+}
+
+// RangeM - dosah hacku/claimu s bonusom nástroja
+func (t *HackTool) RangeM() int {
+	return HackRangeMeters + int(propFloatOr(t.Properties, "range_bonus_m", 0))
+}
+
+// RetryCooldown - cooldown po neúspechu skrátený nástrojom
+func (t *HackTool) RetryCooldown() time.Duration {
+	reduction := math.Max(0, math.Min(propFloatOr(t.Properties, "cooldown_reduction", 0), MaxCooldownReduction))
+	return time.Duration(float64(HackRetryCooldown) * (1 - reduction))
+}
+
+// hackSuccessChance - šanca, že hack po úspešnej minihre prejde: success_rate nástroja
+// (default 50 %) - 5 % za level odporu zariadenia + success_bonus, orezané na 5-95 %
+func hackSuccessChance(device *DeployedDevice, hackTool *HackTool) float64 {
+	chance := hackTool.SuccessRate() - float64(device.HackResistance)*hackResistancePenalty + hackTool.SuccessBonus()
+	return math.Max(hackMinSuccessRate, math.Min(hackMaxSuccessRate, chance))
+}
+
+// loadHackTool - nástroj hráča pripravený na použitie (existuje, má použitia, nevypršal)
+func (s *Service) loadHackTool(userID, toolID uuid.UUID) (*HackTool, error) {
+	var item gameplay.InventoryItem
+	if err := s.db.Where("id = ? AND user_id = ? AND item_type = ? AND deleted_at IS NULL",
+		toolID, userID, "hack_tool").First(&item).Error; err != nil {
+		return nil, ErrHackToolNotFound
+	}
+
+	tool := hackToolFromItem(item)
+	if tool.Expired(time.Now()) {
+		return nil, ErrHackToolExpired
+	}
+	if tool.UsesLeft <= 0 {
+		return nil, ErrHackToolNoUses
+	}
+	return &tool, nil
+}
+
+// consumeHackTool - atomicky zníži uses_left; posledné použitie nástroj zmaže (soft delete).
+// Súbežné použitie toho istého nástroja prejde len raz.
+func (s *Service) consumeHackTool(tool *HackTool) (int, error) {
+	var remaining []int
+	result := s.db.Raw(`
+		UPDATE gameplay.inventory_items
+		SET properties = jsonb_set(properties, '{uses_left}', to_jsonb((properties->>'uses_left')::int - 1), true),
+		    deleted_at = CASE WHEN (properties->>'uses_left')::int <= 1 THEN NOW() ELSE NULL END,
+		    updated_at = NOW()
+		WHERE id = ?
+		  AND user_id = ?
+		  AND deleted_at IS NULL
+		  AND (properties->>'uses_left')::int > 0
+		RETURNING (properties->>'uses_left')::int
+	`, tool.ID, tool.UserID).Scan(&remaining)
+	if result.Error != nil {
+		return 0, fmt.Errorf("chyba pri spotrebovaní nástroja: %w", result.Error)
+	}
+	if len(remaining) == 0 {
+		return 0, ErrHackToolConsumed
+	}
+
+	if remaining[0] == 0 {
+		log.Printf("🗑️ Hack tool deleted (uses depleted): %s, uses: %d → 0 (deleted)", tool.ID, tool.UsesLeft)
+	} else {
+		log.Printf("🔧 Hack tool consumed: %s, uses: %d → %d", tool.ID, tool.UsesLeft, remaining[0])
+	}
+	tool.UsesLeft = remaining[0]
+	return remaining[0], nil
+}
+
+// validateHackCooldown - po neúspešnom pokuse musí hacker počkať (nástroj cooldown skracuje)
+func (s *Service) validateHackCooldown(hackerID, deviceID uuid.UUID, tool *HackTool) error {
+	var last DeviceHack
+	err := s.db.Where("device_id = ? AND hacker_id = ?", deviceID, hackerID).Order("hack_time DESC").First(&last).Error
+	if err != nil || last.Success {
+		return nil
+	}
+
+	availableAt := last.HackTime.Add(tool.RetryCooldown())
+	if time.Now().Before(availableAt) {
+		return fmt.Errorf("%w (%ds)", ErrHackCooldown, int(time.Until(availableAt).Seconds())+1)
+	}
+	return nil
+}
+
+// HackToolEffects - efektívne hodnoty nástroja pre detail
+type HackToolEffects struct {
+	SuccessRate       float64 `json:"success_rate"`
+	SuccessBonus      float64 `json:"success_bonus"`
+	RangeM            int     `json:"range_m"`
+	RangeBonusM       int     `json:"range_bonus_m"`
+	CooldownReduction float64 `json:"cooldown_reduction"`
+	RetryCooldownSec  int     `json:"retry_cooldown_seconds"`
+}
+
+// HackToolDetails - detail nástroja s efektmi a históriou použitia
+type HackToolDetails struct {
+	HackTool
+	Effects          HackToolEffects `json:"effects"`
+	Expired          bool            `json:"expired"`
+	ExpiresInSeconds *int64          `json:"expires_in_seconds,omitempty"`
+	RecentUses       []DeviceHack    `json:"recent_uses"`
+}
+
+// GetHackToolDetails - detail jedného nástroja (GET /devices/hack-tools/:tool_id)
+func (s *Service) GetHackToolDetails(userID, toolID uuid.UUID) (*HackToolDetails, error) {
+	var item gameplay.InventoryItem
+	if err := s.db.Where("id = ? AND user_id = ? AND item_type = ? AND deleted_at IS NULL",
+		toolID, userID, "hack_tool").First(&item).Error; err != nil {
+		return nil, ErrHackToolNotFound
+	}
+
+	tool := hackToolFromItem(item)
+	now := time.Now()
+	details := &HackToolDetails{
+		HackTool: tool,
+		Effects: HackToolEffects{
+			SuccessRate:       tool.SuccessRate(),
+			SuccessBonus:      tool.SuccessBonus(),
+			RangeM:            tool.RangeM(),
+			RangeBonusM:       tool.RangeM() - HackRangeMeters,
+			CooldownReduction: propFloatOr(tool.Properties, "cooldown_reduction", 0),
+			RetryCooldownSec:  int(tool.RetryCooldown().Seconds()),
+		},
+		Expired:    tool.Expired(now),
+		RecentUses: []DeviceHack{},
+	}
+	if tool.ExpiresAt != nil {
+		left := int64(math.Max(0, tool.ExpiresAt.Sub(now).Seconds()))
+		details.ExpiresInSeconds = &left
+	}
+
+	s.db.Where("hacker_id = ? AND properties->>'hack_tool_id' = ?", userID, toolID.String()).
+		Order("hack_time DESC").Limit(hackToolRecentUsesLimit).Find(&details.RecentUses)
+
+	return details, nil
+}
+
+// SweepExpiredHackTools - soft delete vypršaných nástrojov (volá scheduler)
+func SweepExpiredHackTools(db *gorm.DB) (int64, error) {
+	result := db.Exec(`
+		UPDATE gameplay.inventory_items
+		SET deleted_at = NOW(),
+		    updated_at = NOW()
+		WHERE item_type = 'hack_tool'
+		  AND deleted_at IS NULL
+		  AND properties->>'expires_at' IS NOT NULL
+		  AND (properties->>'expires_at')::bigint <= ?
+	`, time.Now().Unix())
+	return result.RowsAffected, result.Error
+}
+
+// randSource - zdroj náhody pre hack roll (v testoch seedovaný)
+type randSource interface {
+	Float64() float64
+}
+
+type globalRand struct{}
+
+func (globalRand) Float64() float64 { return rand.Float64() }
+
+func propFloat(props map[string]interface{}, key string) (float64, bool) {
+	switch v := props[key].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func propFloatOr(props map[string]interface{}, key string, fallback float64) float64 {
+	if v, ok := propFloat(props, key); ok {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,105 @@
+package deployable
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"geoanomaly/internal/gameplay"
+
+	"gorm.io/datatypes"
+)
+
+func testHackTool(props map[string]interface{}) *HackTool {
+	return &HackTool{Properties: datatypes.JSONMap(props)}
+}
+
+func TestHackSuccessChance(t *testing.T) {
+	tests := []struct {
+		name       string
+		resistance int
+		props      map[string]interface{}
+		want       float64
+	}{
+		{"default base", 0, nil, 0.5},
+		{"resistance penalty", 3, nil, 0.35},
+		{"success_rate override", 0, map[string]interface{}{"success_rate": 0.7}, 0.7},
+		{"success_bonus", 2, map[string]interface{}{"success_rate": 0.6, "success_bonus": 0.1}, 0.6},
+		{"int properties", 0, map[string]interface{}{"success_rate": 1, "success_bonus": 0}, 0.95},
+		{"clamped to max", 0, map[string]interface{}{"success_rate": 0.9, "success_bonus": 0.2}, 0.95},
+		{"clamped to min", 20, nil, 0.05},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := &DeployedDevice{HackResistance: tt.resistance}
+			got := hackSuccessChance(device, testHackTool(tt.props))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("hackSuccessChance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPerformHack_Frequency(t *testing.T) {
+	const attempts = 20000
+
+	tests := []struct {
+		name       string
+		resistance int
+		props      map[string]interface{}
+	}{
+		{"circuit breaker", 0, HackToolProperties(map[string]interface{}{"tool_type": "basic_hack"}, "market", time.Now())},
+		{"code cracker vs resistance", 4, HackToolProperties(map[string]interface{}{"tool_type": "advanced_hack"}, "market", time.Now())},
+		{"floor", 30, nil},
+		{"ceiling", 0, map[string]interface{}{"success_rate": 0.99, "success_bonus": 0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{rng: rand.New(rand.NewSource(42))}
+			device := &DeployedDevice{HackResistance: tt.resistance}
+			tool := testHackTool(tt.props)
+
+			successes := 0
+			for i := 0; i < attempts; i++ {
+				if s.performHack(device, tool) {
+					successes++
+				}
+			}
+
+			want := hackSuccessChance(device, tool)
+			got := float64(successes) / attempts
+			if math.Abs(got-want) > 0.015 {
+				t.Errorf("success rate %.3f, want %.3f ± 0.015", got, want)
+			}
+		})
+	}
+}
+
+func TestHackToolProperties(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	props := HackToolProperties(map[string]interface{}{"tool_type": "stealth_hack", "uses": float64(4)}, "market", now)
+	tool := hackToolFromItem(gameplay.InventoryItem{Properties: gameplay.JSONB(props)})
+
+	if tool.ToolType != "stealth_infiltration" {
+		t.Errorf("tool type = %s, want stealth_infiltration", tool.ToolType)
+	}
+	if tool.UsesLeft != 4 {
+		t.Errorf("uses left = %d, want 4 (market uses override)", tool.UsesLeft)
+	}
+	if tool.RangeM() != HackRangeMeters+50 {
+		t.Errorf("range = %d, want %d", tool.RangeM(), HackRangeMeters+50)
+	}
+	if got, want := tool.RetryCooldown(), HackRetryCooldown/2; got != want {
+		t.Errorf("retry cooldown = %v, want %v", got, want)
+	}
+	if tool.ExpiresAt == nil || !tool.ExpiresAt.Equal(now.Add(3*24*time.Hour)) {
+		t.Errorf("expires at = %v, want %v", tool.ExpiresAt, now.Add(3*24*time.Hour))
+	}
+	if tool.Expired(now) || !tool.Expired(now.Add(3*24*time.Hour)) {
+		t.Error("expected tool to expire exactly at expires_at")
+	}
+}
@@ -1,19 +1,27 @@
 package deployable
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
+	"geoanomaly/internal/movement"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type Handler struct {
-	service *Service
+	service  *Service
+	movement *movement.Service
 }
 
 func NewHandler(service *Service) *Handler {
-	return &Handler{service: service}
+	return &Handler{
+		service:  service,
+		movement: movement.NewService(service.db),
+	}
 }
 
 // DeployDevice - umiestni zariadenie na mapu
@@ -36,8 +44,19 @@ func (h *Handler) DeployDevice(c *gin.Context) {
 		return
 	}
 
+	// Kontrola hodnovernosti pohybu (teleport → 422, soft ban → 403)
+	if _, ok := h.movement.Enforce(c, userUUID, movement.SourceDeployDevice, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}); !ok {
+		return
+	}
+
 	response, err := h.service.DeployDevice(userUUID, &req)
 	if err != nil {
+		if respondDeviceLimitError(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -65,9 +84,16 @@ func (h *Handler) GetMyDevices(c *gin.Context) {
 		return
 	}
 
+	quota, err := h.service.GetDeviceQuota(userUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"devices": devices,
+		"quota":   quota,
 	})
 }
 
@@ -131,6 +157,14 @@ func (h *Handler) ScanDevice(c *gin.Context) {
 		return
 	}
 
+	// Kontrola hodnovernosti pohybu (teleport → 422, soft ban → 403)
+	if _, ok := h.movement.Enforce(c, userUUID, movement.SourceScanDevice, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}); !ok {
+		return
+	}
+
 	response, err := h.service.ScanDeployableDevice(userUUID, deviceID, &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -197,8 +231,19 @@ func (h *Handler) HackDevice(c *gin.Context) {
 		return
 	}
 
+	// Kontrola hodnovernosti pohybu (teleport → 422, soft ban → 403)
+	if _, ok := h.movement.Enforce(c, userUUID, movement.SourceHackDevice, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}); !ok {
+		return
+	}
+
 	response, err := h.service.HackDevice(userUUID, deviceID, &req)
 	if err != nil {
+		if respondHackToolError(c, err) || respondMinigameError(c, err) || respondDeviceLimitError(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -206,6 +251,47 @@ func (h *Handler) HackDevice(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// IssueMinigame - vydá podpísanú výzvu minihry pre hack/claim zariadenia
+func (h *Handler) IssueMinigame(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	var req MinigameChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	challenge, err := h.service.IssueMinigameChallenge(userUUID, deviceID, &req)
+	if err != nil {
+		if respondHackToolError(c, err) || respondMinigameError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"challenge": challenge,
+	})
+}
+
 // ClaimDevice - claimne opustené zariadenie
 func (h *Handler) ClaimDevice(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -233,8 +319,19 @@ func (h *Handler) ClaimDevice(c *gin.Context) {
 		return
 	}
 
+	// Kontrola hodnovernosti pohybu (teleport → 422, soft ban → 403)
+	if _, ok := h.movement.Enforce(c, userUUID, movement.SourceClaimDevice, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}); !ok {
+		return
+	}
+
 	response, err := h.service.ClaimAbandonedDevice(userUUID, deviceID, &req)
 	if err != nil {
+		if respondHackToolError(c, err) || respondMinigameError(c, err) || respondDeviceLimitError(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -401,8 +498,8 @@ func (h *Handler) DeleteDevice(c *gin.Context) {
 	})
 }
 
-// GetHackTools - získa hackovacie nástroje hráča
-func (h *Handler) GetHackTools(c *gin.Context) {
+// FreezeDevice - vlastník vypne zariadenie a uvoľní miesto v limite tieru
+func (h *Handler) FreezeDevice(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -415,21 +512,29 @@ func (h *Handler) GetHackTools(c *gin.Context) {
 		return
 	}
 
-	// Získať hack tools z databázy
-	hackTools, err := h.service.GetHackTools(userUUID)
+	deviceID, err := uuid.Parse(c.Param("device_id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve hack tools"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	device, err := h.service.FreezeDevice(userUUID, deviceID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	quota, _ := h.service.GetDeviceQuota(userUUID)
 	c.JSON(http.StatusOK, gin.H{
-		"success":    true,
-		"hack_tools": hackTools,
+		"success": true,
+		"message": "Device frozen",
+		"device":  device,
+		"quota":   quota,
 	})
 }
 
-// UseHackTool - použije hackovací nástroj
-func (h *Handler) UseHackTool(c *gin.Context) {
+// UnfreezeDevice - znova zapne zmrazené zariadenie, ak je v limite tieru miesto
+func (h *Handler) UnfreezeDevice(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
@@ -442,30 +547,871 @@ func (h *Handler) UseHackTool(c *gin.Context) {
 		return
 	}
 
-	toolIDStr := c.Param("tool_id")
-	_, err := uuid.Parse(toolIDStr)
+	deviceID, err := uuid.Parse(c.Param("device_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tool ID"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
 		return
 	}
 
-	var req struct {
-		TargetDeviceID uuid.UUID `json:"target_device_id" binding:"required"`
-		Latitude       float64   `json:"latitude" binding:"required"`
-		Longitude      float64   `json:"longitude" binding:"required"`
+	device, err := h.service.UnfreezeDevice(userUUID, deviceID)
+	if err != nil {
+		if respondDeviceLimitError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota, _ := h.service.GetDeviceQuota(userUUID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Device unfrozen",
+		"device":  device,
+		"quota":   quota,
+	})
+}
+
+// GetDeviceDefense - obranné moduly a efektívny odpor zariadenia
+func (h *Handler) GetDeviceDefense(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	defense, err := h.service.GetDeviceDefense(userUUID, deviceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"defense": defense,
+	})
+}
+
+// InstallDefenseModule - nainštaluje obranný modul z inventára na zariadenie
+func (h *Handler) InstallDefenseModule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
 	}
 
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	var req InstallDefenseModuleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
 		return
 	}
 
-	// TODO: Implement hack tool usage
-	// For now, just return success
-	_ = userUUID // Suppress unused variable warning
+	module, err := h.service.InstallDefenseModule(userUUID, deviceID, &req)
+	if err != nil {
+		if respondDefenseError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	defense, _ := h.service.GetDeviceDefense(userUUID, deviceID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"module":  module,
+		"defense": defense,
+	})
+}
+
+// UninstallDefenseModule - vráti obranný modul do inventára
+func (h *Handler) UninstallDefenseModule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+	moduleID, err := uuid.Parse(c.Param("module_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID"})
+		return
+	}
+
+	if err := h.service.UninstallDefenseModule(userUUID, deviceID, moduleID); err != nil {
+		if respondDefenseError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Hack tool used successfully",
+		"message": "Defense module returned to inventory",
+	})
+}
+
+// CounterHack - vlastník zruší prístup získaný hackom (len krátko po hacku)
+func (h *Handler) CounterHack(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	var req CounterHackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	response, err := h.service.CounterHack(userUUID, deviceID, &req)
+	if err != nil {
+		if respondDefenseError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"counter_hack": response,
+	})
+}
+
+// GetDeviceHackHistory - história pokusov o hack zariadenia (len vlastník)
+func (h *Handler) GetDeviceHackHistory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	attempts, total, err := h.service.GetDeviceHackHistory(userUUID, deviceID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"attempts": attempts,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// respondDefenseError - chyby obranných modulov a counter-hacku s kódom pre klienta
+func respondDefenseError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, ErrDefenseModuleNotFound), errors.Is(err, ErrHackAttemptNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": "not_found"})
+	case errors.Is(err, ErrInvalidDefenseModule):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "invalid_module"})
+	case errors.Is(err, ErrDefenseSlotsFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "slots_full"})
+	case errors.Is(err, ErrDefenseModuleInstalled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "module_installed"})
+	case errors.Is(err, ErrNoAccessToRevoke):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "no_access"})
+	case errors.Is(err, ErrCounterHackWindowClosed):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error(), "code": "window_closed"})
+	default:
+		return false
+	}
+	return true
+}
+
+// GetDeviceShares - s kým vlastník zdieľa zariadenie
+func (h *Handler) GetDeviceShares(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	shares, err := h.service.GetDeviceShares(userUUID, deviceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"shares":  shares,
+	})
+}
+
+// ShareDevice - vlastník zdieľa zariadenie s priateľom (view, scan, maintain)
+func (h *Handler) ShareDevice(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	var req ShareDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	share, err := h.service.ShareDevice(userUUID, deviceID, &req)
+	if err != nil {
+		if respondSharingError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"share":   share,
+	})
+}
+
+// RevokeDeviceShare - vlastník zruší zdieľanie
+func (h *Handler) RevokeDeviceShare(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+	targetID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.service.RevokeDeviceShare(userUUID, deviceID, targetID); err != nil {
+		if respondSharingError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Device share revoked",
+	})
+}
+
+// GetSharedDevices - cudzie zariadenia dostupné cez zdieľanie alebo sieť
+func (h *Handler) GetSharedDevices(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	devices, err := h.service.GetSharedDevices(userUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"devices": devices,
+		"count":   len(devices),
+	})
+}
+
+// GetMyNetworks - siete zariadení, v ktorých je hráč členom
+func (h *Handler) GetMyNetworks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	networks, err := h.service.GetMyNetworks(userUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"networks": networks,
+	})
+}
+
+// CreateNetwork - založí sieť zariadení
+func (h *Handler) CreateNetwork(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	var req CreateNetworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	network, err := h.service.CreateNetwork(userUUID, &req)
+	if err != nil {
+		if respondSharingError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"network": network,
+	})
+}
+
+// GetNetwork - detail siete s členmi a zariadeniami
+func (h *Handler) GetNetwork(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	networkID, err := uuid.Parse(c.Param("network_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid network ID"})
+		return
+	}
+
+	network, err := h.service.GetNetwork(userUUID, networkID)
+	if err != nil {
+		if respondSharingError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"network": network,
+	})
+}
+
+// DeleteNetwork - vlastník zruší sieť
+func (h *Handler) DeleteNetwork(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	networkID, err := uuid.Parse(c.Param("network_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid network ID"})
+		return
+	}
+
+	if err := h.service.DeleteNetwork(userUUID, networkID); err != nil {
+		if respondSharingError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Network disbanded",
+	})
+}
+
+// AddNetworkMember - vlastník pridá priateľa do siete
+func (h *Handler) AddNetworkMember(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	networkID, err := uuid.Parse(c.Param("network_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid network ID"})
+		return
+	}
+
+	var req AddNetworkMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	member, err := h.service.AddNetworkMember(userUUID, networkID, &req)
+	if err != nil {
+		if respondSharingError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"member":  member,
+	})
+}
+
+// RemoveNetworkMember - odobratie člena (vlastník) alebo odchod zo siete (člen sám seba)
+func (h *Handler) RemoveNetworkMember(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	networkID, err := uuid.Parse(c.Param("network_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid network ID"})
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.service.RemoveNetworkMember(userUUID, networkID, memberID); err != nil {
+		if respondSharingError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Member removed from network",
+	})
+}
+
+// AddNetworkDevice - člen vloží vlastné zariadenie do siete
+func (h *Handler) AddNetworkDevice(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	networkID, err := uuid.Parse(c.Param("network_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid network ID"})
+		return
+	}
+
+	var req AddNetworkDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	device, err := h.service.AddNetworkDevice(userUUID, networkID, &req)
+	if err != nil {
+		if respondSharingError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"device":  device,
+	})
+}
+
+// RemoveNetworkDevice - vyberie zariadenie zo siete
+func (h *Handler) RemoveNetworkDevice(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	networkID, err := uuid.Parse(c.Param("network_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid network ID"})
+		return
+	}
+	deviceID, err := uuid.Parse(c.Param("device_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid device ID"})
+		return
+	}
+
+	if err := h.service.RemoveNetworkDevice(userUUID, networkID, deviceID); err != nil {
+		if respondSharingError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Device removed from network",
+	})
+}
+
+// respondSharingError - chyby zdieľania a sietí s kódom pre klienta
+func respondSharingError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, ErrNetworkNotFound), errors.Is(err, ErrNetworkMemberNotFound),
+		errors.Is(err, ErrNetworkDeviceNotFound), errors.Is(err, ErrDeviceShareNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": "not_found"})
+	case errors.Is(err, ErrNetworkNotOwner), errors.Is(err, ErrShareNotFriends):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "forbidden"})
+	case errors.Is(err, ErrNetworkLimit), errors.Is(err, ErrNetworkFull), errors.Is(err, ErrDeviceShareLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "limit_reached"})
+	case errors.Is(err, ErrNetworkAlreadyMember), errors.Is(err, ErrDeviceInNetwork), errors.Is(err, ErrNetworkOwnerLeave):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "conflict"})
+	case errors.Is(err, ErrInvalidAccessLevel), errors.Is(err, ErrInvalidShareDuration),
+		errors.Is(err, ErrShareWithSelf), errors.Is(err, ErrNetworkInvalidName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "invalid_request"})
+	default:
+		return false
+	}
+	return true
+}
+
+// respondDeviceLimitError - 409 s kódom limitu, aby klient vedel ponúknuť zmrazenie/zmazanie iného zariadenia
+func respondDeviceLimitError(c *gin.Context, err error) bool {
+	var code string
+	switch {
+	case errors.Is(err, ErrDeviceLimitReached):
+		code = "device_limit"
+	case errors.Is(err, ErrDeviceDensityLimit):
+		code = "density_limit"
+	case errors.Is(err, ErrDeviceTooClose):
+		code = "min_spacing"
+	default:
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": code})
+	return true
+}
+
+// respondHackToolError - chyby nástroja a cooldownu s kódom pre klienta
+func respondHackToolError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, ErrHackToolNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": "tool_not_found"})
+	case errors.Is(err, ErrHackToolExpired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "tool_expired"})
+	case errors.Is(err, ErrHackToolNoUses), errors.Is(err, ErrHackToolConsumed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "tool_depleted"})
+	case errors.Is(err, ErrHackCooldown):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "code": "hack_cooldown"})
+	default:
+		return false
+	}
+	return true
+}
+
+// respondMinigameError - neplatná, použitá alebo vypršaná výzva minihry
+func respondMinigameError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, ErrChallengeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "code": "challenge_not_found"})
+	case errors.Is(err, ErrChallengeSignature), errors.Is(err, ErrChallengeMismatch):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "challenge_invalid"})
+	case errors.Is(err, ErrChallengeUsed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "challenge_used"})
+	case errors.Is(err, ErrChallengeExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error(), "code": "challenge_expired"})
+	case errors.Is(err, ErrChallengeNoTool):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "hack_tool_required"})
+	case errors.Is(err, ErrMinigameUnknownType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "unknown_minigame"})
+	default:
+		return false
+	}
+	return true
+}
+
+// GetHackTools - získa hackovacie nástroje hráča
+func (h *Handler) GetHackTools(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	// Získať hack tools z databázy
+	hackTools, err := h.service.GetHackTools(userUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve hack tools"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"hack_tools": hackTools,
+	})
+}
+
+// UseHackTool - použije hackovací nástroj
+func (h *Handler) UseHackTool(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	toolIDStr := c.Param("tool_id")
+	toolID, err := uuid.Parse(toolIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tool ID"})
+		return
+	}
+
+	var req struct {
+		TargetDeviceID uuid.UUID          `json:"target_device_id" binding:"required"`
+		Latitude       float64            `json:"latitude" binding:"required"`
+		Longitude      float64            `json:"longitude" binding:"required"`
+		Minigame       MinigameSubmission `json:"minigame" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	// Kontrola hodnovernosti pohybu (teleport → 422, soft ban → 403)
+	if _, ok := h.movement.Enforce(c, userUUID, movement.SourceHackDevice, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}); !ok {
+		return
+	}
+
+	// Použitie nástroja = hack cieľového zariadenia týmto nástrojom
+	response, err := h.service.HackDevice(userUUID, req.TargetDeviceID, &HackRequest{
+		HackToolID: toolID,
+		Latitude:   req.Latitude,
+		Longitude:  req.Longitude,
+		Minigame:   req.Minigame,
+	})
+	if err != nil {
+		if respondHackToolError(c, err) || respondMinigameError(c, err) || respondDeviceLimitError(c, err) {
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"tool_id": toolID,
+		"result":  response,
+	})
+}
+
+// GetHackToolDetails - detail nástroja s efektmi, expiráciou a históriou použitia
+func (h *Handler) GetHackToolDetails(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	toolID, err := uuid.Parse(c.Param("tool_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tool ID"})
+		return
+	}
+
+	details, err := h.service.GetHackToolDetails(userUUID, toolID)
+	if err != nil {
+		if respondHackToolError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve hack tool"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"hack_tool": details,
 	})
 }
 
@@ -0,0 +1,264 @@
+package deployable
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"geoanomaly/internal/auth"
+	"geoanomaly/pkg/geoquery"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrDeviceLimitReached = errors.New("device limit for your tier reached")
+	ErrDeviceDensityLimit = errors.New("too many of your devices in this area")
+	ErrDeviceTooClose     = errors.New("too close to another of your devices")
+	ErrDeviceNotFrozen    = errors.New("device is not frozen")
+)
+
+// Plocha, na ktorú sa počíta hustota (1 km² = kruh s polomerom ~564 m)
+const densityAreaKm2 = 1.0
+
+var densityRadiusM = math.Sqrt(densityAreaKm2 * 1e6 / math.Pi)
+
+// TierDeviceLimits - limity nasadených zariadení pre jeden tier
+type TierDeviceLimits struct {
+	MaxActive   int     `json:"max_active"`
+	MaxPerKm2   int     `json:"max_per_km2"`
+	MinSpacingM float64 `json:"min_spacing_m"` // minimálna vzdialenosť medzi vlastnými zariadeniami
+}
+
+// DefaultTierDeviceLimits - predvolené limity; prepíšu sa cez Service.WithTierLimits
+func DefaultTierDeviceLimits() map[int]TierDeviceLimits {
+	return map[int]TierDeviceLimits{
+		0: {MaxActive: 2, MaxPerKm2: 1, MinSpacingM: 500},
+		1: {MaxActive: 4, MaxPerKm2: 2, MinSpacingM: 300},
+		2: {MaxActive: 6, MaxPerKm2: 3, MinSpacingM: 200},
+		3: {MaxActive: 10, MaxPerKm2: 4, MinSpacingM: 150},
+		4: {MaxActive: 15, MaxPerKm2: 6, MinSpacingM: 100},
+	}
+}
+
+// DeviceQuota - stav kvóty hráča pre GetMyDevices
+type DeviceQuota struct {
+	Tier      int              `json:"tier"`
+	Limits    TierDeviceLimits `json:"limits"`
+	Active    int              `json:"active"`
+	Frozen    int              `json:"frozen"`
+	Remaining int              `json:"remaining"`
+}
+
+// WithTierLimits - vlastné limity pre tiery (chýbajúci tier = limity najbližšieho nižšieho)
+func (s *Service) WithTierLimits(limits map[int]TierDeviceLimits) *Service {
+	s.limits = limits
+	return s
+}
+
+// limitsForTier - limity tieru; neznámy tier dostane najbližší nižší definovaný
+func (s *Service) limitsForTier(tier int) TierDeviceLimits {
+	for t := tier; t >= 0; t-- {
+		if limits, ok := s.limits[t]; ok {
+			return limits
+		}
+	}
+	return s.limits[0]
+}
+
+// countedDevices - zariadenia, ktoré sa rátajú do limitu (opustené už hráčovi nepatria, zmrazené nebežia)
+func countedDevices(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Model(&DeployedDevice{}).
+		Where("owner_id = ? AND is_active = true AND status IN ?", userID, []DeviceStatus{DeviceStatusActive, DeviceStatusDepleted})
+}
+
+// GetDeviceQuota - koľko zariadení ešte hráč môže nasadiť
+func (s *Service) GetDeviceQuota(userID uuid.UUID) (*DeviceQuota, error) {
+	var user auth.User
+	if err := s.db.Select("id", "tier").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	limits := s.limitsForTier(user.Tier)
+	var active, frozen int64
+	countedDevices(s.db, userID).Count(&active)
+	s.db.Model(&DeployedDevice{}).Where("owner_id = ? AND status = ?", userID, DeviceStatusFrozen).Count(&frozen)
+
+	return &DeviceQuota{
+		Tier:      user.Tier,
+		Limits:    limits,
+		Active:    int(active),
+		Frozen:    int(frozen),
+		Remaining: max(limits.MaxActive-int(active), 0),
+	}, nil
+}
+
+// validateTierLimits - počet, hustota a rozostup vlastných zariadení pre nové miesto.
+// Beží pod zámkom hráča, takže dva súbežné deploye limit neprekročia.
+func (s *Service) validateTierLimits(tx *gorm.DB, userID uuid.UUID, lat, lng float64) error {
+	limits, err := s.validateDeviceCount(tx, userID)
+	if err != nil {
+		return err
+	}
+	return s.validateDevicePlacement(tx, userID, limits, lat, lng)
+}
+
+// validateDeviceCount - zamkne hráča a overí max počet bežiacich zariadení
+func (s *Service) validateDeviceCount(tx *gorm.DB, userID uuid.UUID) (TierDeviceLimits, error) {
+	var user auth.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "tier").First(&user, "id = ?", userID).Error; err != nil {
+		return TierDeviceLimits{}, fmt.Errorf("failed to load user: %w", err)
+	}
+	limits := s.limitsForTier(user.Tier)
+
+	var active int64
+	if err := countedDevices(tx, userID).Count(&active).Error; err != nil {
+		return limits, fmt.Errorf("failed to count devices: %w", err)
+	}
+	if int(active) >= limits.MaxActive {
+		return limits, fmt.Errorf("%w (%d/%d)", ErrDeviceLimitReached, active, limits.MaxActive)
+	}
+	return limits, nil
+}
+
+// validateDevicePlacement - rozostup od vlastných zariadení a ich počet na km² okolo miesta
+func (s *Service) validateDevicePlacement(tx *gorm.DB, userID uuid.UUID, limits TierDeviceLimits, lat, lng float64) error {
+	distance := s.geo.Distance(geoquery.DeviceColumns, lat, lng)
+	within := s.geo.Within(geoquery.DeviceColumns, lat, lng, math.Max(densityRadiusM, limits.MinSpacingM))
+	var nearby []struct {
+		DistanceM float64
+	}
+	query := `
+		SELECT ` + distance.SQL + ` AS distance_m
+		FROM gameplay.deployed_devices
+		WHERE owner_id = ?
+		  AND is_active = true
+		  AND status IN ?
+		  AND ` + within.SQL
+	if err := tx.Raw(query, geoquery.Args(distance, userID, []DeviceStatus{DeviceStatusActive, DeviceStatusDepleted}, within)...).Scan(&nearby).Error; err != nil {
+		return fmt.Errorf("failed to check nearby devices: %w", err)
+	}
+
+	inArea := 0
+	for _, d := range nearby {
+		if d.DistanceM < limits.MinSpacingM {
+			return fmt.Errorf("%w (%.0fm, minimum %.0fm)", ErrDeviceTooClose, d.DistanceM, limits.MinSpacingM)
+		}
+		if d.DistanceM <= densityRadiusM {
+			inArea++
+		}
+	}
+	if inArea >= limits.MaxPerKm2 {
+		return fmt.Errorf("%w (%d per km²)", ErrDeviceDensityLimit, limits.MaxPerKm2)
+	}
+	return nil
+}
+
+// EnforceTierLimits - po zmene tieru (expirácia, zmena adminom) zmrazí najnovšie zariadenia nad limit.
+// Najstaršie ostávajú bežať; hráč si môže vybrať iné - zmrazené zariadenie rozmrazí cez
+// UnfreezeDevice, keď uvoľní miesto (zmazaním alebo zmrazením iného).
+func (s *Service) EnforceTierLimits(userID uuid.UUID, tier int) (int, error) {
+	limits := s.limitsForTier(tier)
+
+	var frozen int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var devices []DeployedDevice
+		if err := countedDevices(tx, userID).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Order("deployed_at ASC").Find(&devices).Error; err != nil {
+			return err
+		}
+		if len(devices) <= limits.MaxActive {
+			return nil
+		}
+
+		var ids []uuid.UUID
+		for _, device := range devices[limits.MaxActive:] {
+			ids = append(ids, device.ID)
+		}
+		result := tx.Model(&DeployedDevice{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":     DeviceStatusFrozen,
+			"is_active":  false,
+			"properties": gorm.Expr("COALESCE(properties, '{}'::jsonb) || jsonb_build_object('frozen_reason', 'tier_limit', 'frozen_at', ?::text, 'frozen_from_status', status)", time.Now().UTC().Format(time.RFC3339)),
+			"updated_at": time.Now().UTC(),
+		})
+		frozen = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to enforce tier limits: %w", err)
+	}
+
+	if frozen > 0 {
+		log.Printf("🧊 Froze %d devices of user %s over tier %d limit (%d)", frozen, userID, tier, limits.MaxActive)
+	}
+	return int(frozen), nil
+}
+
+// FreezeDevice - hráč sám vypne zariadenie, aby uvoľnil miesto pre iné
+func (s *Service) FreezeDevice(userID, deviceID uuid.UUID) (*DeployedDevice, error) {
+	result := s.db.Model(&DeployedDevice{}).
+		Where("id = ? AND owner_id = ? AND is_active = true AND status IN ?", deviceID, userID, []DeviceStatus{DeviceStatusActive, DeviceStatusDepleted}).
+		Updates(map[string]interface{}{
+			"status":     DeviceStatusFrozen,
+			"is_active":  false,
+			"properties": gorm.Expr("COALESCE(properties, '{}'::jsonb) || jsonb_build_object('frozen_reason', 'owner', 'frozen_at', ?::text, 'frozen_from_status', status)", time.Now().UTC().Format(time.RFC3339)),
+			"updated_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to freeze device: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("device not found or not owned by user")
+	}
+
+	var device DeployedDevice
+	s.db.First(&device, "id = ?", deviceID)
+	return &device, nil
+}
+
+// UnfreezeDevice - znova zapne zmrazené zariadenie, ak to kvóta tieru dovolí
+func (s *Service) UnfreezeDevice(userID, deviceID uuid.UUID) (*DeployedDevice, error) {
+	var device DeployedDevice
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND owner_id = ?", deviceID, userID).First(&device).Error; err != nil {
+			return fmt.Errorf("device not found or not owned by user")
+		}
+		if device.Status != DeviceStatusFrozen {
+			return ErrDeviceNotFrozen
+		}
+
+		// Rozostup a hustota sa kontrolovali pri deployi, pri rozmrazení len počet
+		if _, err := s.validateDeviceCount(tx, userID); err != nil {
+			return err
+		}
+
+		// Vybitá batéria počas zmrazenia nezmizla - zariadenie sa vráti do stavu pred zmrazením
+		status := DeviceStatusActive
+		if previous, ok := device.Properties["frozen_from_status"].(string); ok && previous == string(DeviceStatusDepleted) {
+			status = DeviceStatusDepleted
+		}
+		delete(device.Properties, "frozen_reason")
+		delete(device.Properties, "frozen_at")
+		delete(device.Properties, "frozen_from_status")
+
+		device.Status = status
+		device.IsActive = true
+		return tx.Model(&device).Updates(map[string]interface{}{
+			"status":     status,
+			"is_active":  true,
+			"properties": device.Properties,
+			"updated_at": time.Now().UTC(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔥 Device %s unfrozen by user %s", deviceID, userID)
+	return &device, nil
+}
@@ -0,0 +1,568 @@
+package deployable
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"net/netip"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Minihry overované serverom: server vydá podpísaný seed s deadlinom, klient pošle
+// riešenie a server z rovnakého seedu deterministicky zrekonštruuje puzzle a overí ho.
+const (
+	MinigameCircuitBreaker = "circuit_breaker"
+	MinigameCodeCracker    = "code_cracker"
+	MinigameIPHacker       = "ip_hacker"
+
+	MinigameActionHack  = "hack"
+	MinigameActionClaim = "claim"
+
+	MinMinigameDuration  = 3 * time.Second // rýchlejšie riešenie = bot
+	MaxMinigameScore     = 1000
+	MaxMinigameLevel     = 4
+	minigameChallengeTTL = 24 * time.Hour // použité/vypršané výzvy sa potom mažú
+)
+
+var minigameTimeLimits = map[string]time.Duration{
+	MinigameCircuitBreaker: 60 * time.Second,
+	MinigameCodeCracker:    90 * time.Second,
+	MinigameIPHacker:       30 * time.Second, // claim minihra mala vždy max 30s
+}
+
+var (
+	ErrMinigameUnknownType = errors.New("neznámy typ minihry")
+	ErrChallengeNotFound   = errors.New("výzva minihry nebola nájdená")
+	ErrChallengeSignature  = errors.New("neplatný podpis výzvy minihry")
+	ErrChallengeMismatch   = errors.New("výzva minihry patrí inému zariadeniu, akcii alebo nástroju")
+	ErrChallengeNoTool     = errors.New("výzva na hack vyžaduje hackovací nástroj")
+	ErrChallengeUsed       = errors.New("výzva minihry už bola použitá")
+	ErrChallengeExpired    = errors.New("čas na minihru vypršal")
+)
+
+// HackChallenge - vydaná výzva minihry (jednorazová)
+type HackChallenge struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID       uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	DeviceID     uuid.UUID  `json:"device_id" gorm:"type:uuid;not null"`
+	Action       string     `json:"action" gorm:"size:10;not null"`
+	HackToolID   uuid.UUID  `json:"hack_tool_id" gorm:"type:uuid"` // nástroj, pre ktorý bola minihra zvolená
+	MinigameType string     `json:"minigame_type" gorm:"size:30;not null"`
+	Seed         int64      `json:"seed" gorm:"not null"`
+	Difficulty   int        `json:"difficulty" gorm:"not null;default:0"`
+	IssuedAt     time.Time  `json:"issued_at" gorm:"not null"`
+	Deadline     time.Time  `json:"deadline" gorm:"not null;index"`
+	UsedAt       *time.Time `json:"used_at,omitempty"`
+	Solved       bool       `json:"solved" gorm:"not null;default:false"`
+	Score        int        `json:"score" gorm:"not null;default:0"`
+	DurationMs   int64      `json:"duration_ms" gorm:"not null;default:0"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (HackChallenge) TableName() string {
+	return "gameplay.hack_challenges"
+}
+
+// MinigameChallengeRequest - request na vydanie výzvy
+// Typ minihry určuje server podľa akcie a nástroja - klient si nevyberie najľahšiu.
+type MinigameChallengeRequest struct {
+	Action     string    `json:"action"`       // hack (default) | claim
+	HackToolID uuid.UUID `json:"hack_tool_id"` // povinné pre hack, určí typ minihry
+}
+
+// MinigameChallenge - výzva pre klienta; puzzle sa dá zrekonštruovať aj zo seedu
+type MinigameChallenge struct {
+	ChallengeID uuid.UUID      `json:"challenge_id"`
+	Type        string         `json:"minigame_type"`
+	Action      string         `json:"action"`
+	Seed        int64          `json:"seed"`
+	Difficulty  int            `json:"difficulty"`
+	Puzzle      MinigamePuzzle `json:"puzzle"`
+	IssuedAt    time.Time      `json:"issued_at"`
+	Deadline    time.Time      `json:"deadline"`
+	Signature   string         `json:"signature"`
+}
+
+// MinigameSubmission - riešenie výzvy poslané s hack/claim requestom
+type MinigameSubmission struct {
+	ChallengeID uuid.UUID        `json:"challenge_id" binding:"required"`
+	Signature   string           `json:"signature" binding:"required"`
+	Solution    MinigameSolution `json:"solution"`
+}
+
+// MinigameSolution - riešenie; vyplní sa len pole pre daný typ minihry
+type MinigameSolution struct {
+	Rotations []int `json:"rotations,omitempty"` // circuit_breaker: otočenia každej dlaždice (0-3)
+	Code      []int `json:"code,omitempty"`      // code_cracker: uhádnutý kód
+	Indices   []int `json:"indices,omitempty"`   // ip_hacker: indexy IP adries v podsieti
+}
+
+// MinigamePuzzle - zadanie minihry; vyplnené sú len polia pre daný typ
+type MinigamePuzzle struct {
+	// circuit_breaker: otočenie dlaždice i posunie aj dlaždicu i+1, cieľ = všetky na 0
+	Tiles []int `json:"tiles,omitempty"`
+
+	// code_cracker: mastermind - kód dĺžky CodeLength z číslic 0..Digits-1
+	CodeLength int        `json:"code_length,omitempty"`
+	Digits     int        `json:"digits,omitempty"`
+	Clues      []CodeClue `json:"clues,omitempty"`
+
+	// ip_hacker: vyber všetky adresy, ktoré patria do podsiete
+	Subnet     string   `json:"subnet,omitempty"`
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// CodeClue - jeden pokus s odpoveďou (exact = správna číslica na správnom mieste)
+type CodeClue struct {
+	Guess   []int `json:"guess"`
+	Exact   int   `json:"exact"`
+	Partial int   `json:"partial"`
+}
+
+// MinigameOutcome - serverom overený výsledok minihry
+type MinigameOutcome struct {
+	ChallengeID uuid.UUID
+	Type        string
+	Success     bool
+	Score       int
+	Duration    time.Duration
+}
+
+// WithMinigameSecret - spoločný secret pre podpis výziev (rovnaký na všetkých inštanciách)
+func (s *Service) WithMinigameSecret(secret string) *Service {
+	if secret != "" {
+		s.minigameSecret = []byte(secret)
+	}
+	return s
+}
+
+// randomMinigameSecret - default pre jednu inštanciu (výzvy neprežijú reštart)
+func randomMinigameSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := crand.Read(secret); err != nil {
+		panic(fmt.Sprintf("failed to generate minigame secret: %v", err))
+	}
+	return secret
+}
+
+func randomMinigameSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return time.Now().UnixNano()
+	}
+	return int64(binary.BigEndian.Uint64(b[:]) >> 1)
+}
+
+// defaultMinigameType - minihra podľa nástroja (claim má vždy ip_hacker)
+func defaultMinigameType(action, toolType string) string {
+	if action == MinigameActionClaim {
+		return MinigameIPHacker
+	}
+	switch normalizeToolType(toolType) {
+	case "code_cracker":
+		return MinigameCodeCracker
+	case "stealth_infiltration":
+		return MinigameIPHacker
+	default:
+		return MinigameCircuitBreaker
+	}
+}
+
+// minigameDifficulty - obtiažnosť podľa efektívneho odporu zariadenia 1-10 (0..MaxMinigameLevel)
+func minigameDifficulty(resistance int) int {
+	return max(0, min((resistance+1)/2, MaxMinigameLevel))
+}
+
+// signChallenge - HMAC nad všetkými hodnotami, z ktorých sa odvodzuje puzzle a overenie
+func signChallenge(secret []byte, c *HackChallenge) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s|%s|%s|%s|%s|%s|%d|%d|%d|%d",
+		c.ID, c.UserID, c.DeviceID, c.Action, c.HackToolID, c.MinigameType, c.Seed, c.Difficulty, c.IssuedAt.Unix(), c.Deadline.Unix())
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IssueMinigameChallenge - vydá novú výzvu; predchádzajúce nepoužité výzvy hráča
+// pre to isté zariadenie prepadnú (žiadne zbieranie ľahkých puzzle)
+func (s *Service) IssueMinigameChallenge(userID, deviceID uuid.UUID, req *MinigameChallengeRequest) (*MinigameChallenge, error) {
+	action := req.Action
+	if action == "" {
+		action = MinigameActionHack
+	}
+	if action != MinigameActionHack && action != MinigameActionClaim {
+		return nil, fmt.Errorf("neplatná akcia minihry: %s", action)
+	}
+
+	var device DeployedDevice
+	query := s.db.Where("id = ?", deviceID)
+	if action == MinigameActionClaim {
+		query = query.Where("status = ?", DeviceStatusAbandoned)
+	}
+	if err := query.First(&device).Error; err != nil {
+		return nil, fmt.Errorf("zariadenie nebolo nájdené")
+	}
+
+	if action == MinigameActionHack && req.HackToolID == uuid.Nil {
+		return nil, ErrChallengeNoTool
+	}
+
+	toolType := ""
+	if req.HackToolID != uuid.Nil {
+		tool, err := s.loadHackTool(userID, req.HackToolID)
+		if err != nil {
+			return nil, err
+		}
+		toolType = tool.ToolType
+	}
+	minigameType := defaultMinigameType(action, toolType)
+	limit, ok := minigameTimeLimits[minigameType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMinigameUnknownType, minigameType)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	challenge := HackChallenge{
+		ID:           uuid.New(),
+		UserID:       userID,
+		DeviceID:     deviceID,
+		Action:       action,
+		HackToolID:   req.HackToolID,
+		MinigameType: minigameType,
+		Seed:         randomMinigameSeed(),
+		Difficulty:   minigameDifficulty(s.deviceResistance(&device)),
+		IssuedAt:     now,
+		Deadline:     now.Add(limit),
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&HackChallenge{}).
+			Where("user_id = ? AND device_id = ? AND used_at IS NULL", userID, deviceID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&challenge).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("chyba pri vytváraní výzvy minihry: %w", err)
+	}
+
+	puzzle, err := GenerateMinigamePuzzle(minigameType, challenge.Seed, challenge.Difficulty)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🧩 Minigame challenge issued: user=%s, device=%s, type=%s, difficulty=%d", userID, deviceID, minigameType, challenge.Difficulty)
+
+	return &MinigameChallenge{
+		ChallengeID: challenge.ID,
+		Type:        minigameType,
+		Action:      action,
+		Seed:        challenge.Seed,
+		Difficulty:  challenge.Difficulty,
+		Puzzle:      puzzle,
+		IssuedAt:    challenge.IssuedAt,
+		Deadline:    challenge.Deadline,
+		Signature:   signChallenge(s.minigameSecret, &challenge),
+	}, nil
+}
+
+// verifyMinigame - overí podpis, deadline a riešenie; výzvu spotrebuje (aj pri zlom riešení)
+func (s *Service) verifyMinigame(userID, deviceID uuid.UUID, action string, toolID uuid.UUID, sub *MinigameSubmission) (*MinigameOutcome, error) {
+	var challenge HackChallenge
+	if err := s.db.Where("id = ? AND user_id = ?", sub.ChallengeID, userID).First(&challenge).Error; err != nil {
+		return nil, ErrChallengeNotFound
+	}
+	if !hmac.Equal([]byte(sub.Signature), []byte(signChallenge(s.minigameSecret, &challenge))) {
+		return nil, ErrChallengeSignature
+	}
+	if err := checkChallengeTarget(&challenge, deviceID, action, toolID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	result := s.db.Model(&HackChallenge{}).
+		Where("id = ? AND used_at IS NULL", challenge.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, fmt.Errorf("chyba pri overení minihry: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrChallengeUsed
+	}
+	if now.After(challenge.Deadline) {
+		return nil, ErrChallengeExpired
+	}
+
+	elapsed := now.Sub(challenge.IssuedAt)
+	outcome, err := evaluateMinigame(&challenge, &sub.Solution, elapsed)
+	if err != nil {
+		return nil, err
+	}
+
+	s.db.Model(&HackChallenge{}).Where("id = ?", challenge.ID).Updates(map[string]interface{}{
+		"solved":      outcome.Success,
+		"score":       outcome.Score,
+		"duration_ms": elapsed.Milliseconds(),
+	})
+
+	if elapsed < MinMinigameDuration {
+		log.Printf("⚠️ Suspicious minigame duration: %v for user %s (challenge %s)", elapsed, userID, challenge.ID)
+	}
+	return outcome, nil
+}
+
+// checkChallengeTarget - výzva sa dá použiť len na zariadenie, akciu a nástroj, pre ktoré bola vydaná
+// (ľahká minihra lacného nástroja nesmie odomknúť bonusy drahšieho)
+func checkChallengeTarget(challenge *HackChallenge, deviceID uuid.UUID, action string, toolID uuid.UUID) error {
+	if challenge.DeviceID != deviceID || challenge.Action != action {
+		return ErrChallengeMismatch
+	}
+	if (challenge.Action == MinigameActionHack || challenge.HackToolID != uuid.Nil) && challenge.HackToolID != toolID {
+		return ErrChallengeMismatch
+	}
+	return nil
+}
+
+// evaluateMinigame - deterministické overenie riešenia proti puzzle zo seedu výzvy
+func evaluateMinigame(challenge *HackChallenge, solution *MinigameSolution, elapsed time.Duration) (*MinigameOutcome, error) {
+	puzzle, err := GenerateMinigamePuzzle(challenge.MinigameType, challenge.Seed, challenge.Difficulty)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &MinigameOutcome{
+		ChallengeID: challenge.ID,
+		Type:        challenge.MinigameType,
+		Duration:    elapsed,
+	}
+	limit := challenge.Deadline.Sub(challenge.IssuedAt)
+	outcome.Success = elapsed >= MinMinigameDuration && elapsed <= limit && CheckMinigameSolution(challenge.MinigameType, puzzle, solution)
+	if outcome.Success {
+		// Polovica za vyriešenie, polovica za zostávajúci čas
+		remaining := 1 - elapsed.Seconds()/limit.Seconds()
+		outcome.Score = MaxMinigameScore/2 + int(math.Round(float64(MaxMinigameScore/2)*math.Max(0, remaining)))
+	}
+	return outcome, nil
+}
+
+// GenerateMinigamePuzzle - zadanie minihry zo seedu (rovnaký seed = rovnaké zadanie)
+func GenerateMinigamePuzzle(minigameType string, seed int64, difficulty int) (MinigamePuzzle, error) {
+	rng := rand.New(rand.NewSource(seed))
+	difficulty = max(0, min(difficulty, MaxMinigameLevel))
+
+	switch minigameType {
+	case MinigameCircuitBreaker:
+		tiles := make([]int, 6+2*difficulty)
+		for i := range tiles {
+			tiles[i] = 1 + rng.Intn(3)
+		}
+		return MinigamePuzzle{Tiles: tiles}, nil
+
+	case MinigameCodeCracker:
+		length, digits := 4, 6
+		if difficulty >= 3 {
+			length = 5
+		}
+		return MinigamePuzzle{CodeLength: length, Digits: digits, Clues: codeCrackerClues(rng, length, digits)}, nil
+
+	case MinigameIPHacker:
+		return ipHackerPuzzle(rng, difficulty), nil
+	}
+	return MinigamePuzzle{}, fmt.Errorf("%w: %s", ErrMinigameUnknownType, minigameType)
+}
+
+// CheckMinigameSolution - je riešenie platné pre zadanie?
+func CheckMinigameSolution(minigameType string, puzzle MinigamePuzzle, solution *MinigameSolution) bool {
+	switch minigameType {
+	case MinigameCircuitBreaker:
+		if len(solution.Rotations) != len(puzzle.Tiles) {
+			return false
+		}
+		for i, tile := range puzzle.Tiles {
+			r := solution.Rotations[i]
+			if r < 0 || r > 3 {
+				return false
+			}
+			prev := 0
+			if i > 0 {
+				prev = solution.Rotations[i-1]
+			}
+			if (tile+r+prev)%4 != 0 {
+				return false
+			}
+		}
+		return true
+
+	case MinigameCodeCracker:
+		// Platný je každý kód konzistentný so všetkými nápovedami
+		if len(solution.Code) != puzzle.CodeLength {
+			return false
+		}
+		for _, d := range solution.Code {
+			if d < 0 || d >= puzzle.Digits {
+				return false
+			}
+		}
+		for _, clue := range puzzle.Clues {
+			exact, partial := codeFeedback(solution.Code, clue.Guess, puzzle.Digits)
+			if exact != clue.Exact || partial != clue.Partial {
+				return false
+			}
+		}
+		return true
+
+	case MinigameIPHacker:
+		subnet, err := netip.ParsePrefix(puzzle.Subnet)
+		if err != nil {
+			return false
+		}
+		var want []int
+		for i, candidate := range puzzle.Candidates {
+			if addr, err := netip.ParseAddr(candidate); err == nil && subnet.Contains(addr) {
+				want = append(want, i)
+			}
+		}
+		got := append([]int(nil), solution.Indices...)
+		sort.Ints(got)
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// codeCrackerClues - pridáva náhodné pokusy, kým nezostane jediný konzistentný kód (max 12 nápovied)
+func codeCrackerClues(rng *rand.Rand, length, digits int) []CodeClue {
+	randomCode := func() []int {
+		code := make([]int, length)
+		for i := range code {
+			code[i] = rng.Intn(digits)
+		}
+		return code
+	}
+
+	secret := randomCode()
+	candidates := allCodes(length, digits)
+	var clues []CodeClue
+	for len(candidates) > 1 && len(clues) < 12 {
+		guess := randomCode()
+		exact, partial := codeFeedback(secret, guess, digits)
+		if exact == length {
+			continue
+		}
+		clues = append(clues, CodeClue{Guess: guess, Exact: exact, Partial: partial})
+
+		remaining := candidates[:0]
+		for _, code := range candidates {
+			if e, p := codeFeedback(code, guess, digits); e == exact && p == partial {
+				remaining = append(remaining, code)
+			}
+		}
+		candidates = remaining
+	}
+	return clues
+}
+
+func allCodes(length, digits int) [][]int {
+	total := int(math.Pow(float64(digits), float64(length)))
+	codes := make([][]int, 0, total)
+	for n := 0; n < total; n++ {
+		code := make([]int, length)
+		for i, v := length-1, n; i >= 0; i-- {
+			code[i] = v % digits
+			v /= digits
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// codeFeedback - mastermind odpoveď (exact, partial)
+func codeFeedback(secret, guess []int, digits int) (int, int) {
+	exact := 0
+	secretCounts := make([]int, digits)
+	guessCounts := make([]int, digits)
+	for i := range secret {
+		if secret[i] == guess[i] {
+			exact++
+			continue
+		}
+		secretCounts[secret[i]]++
+		guessCounts[guess[i]]++
+	}
+	partial := 0
+	for d := 0; d < digits; d++ {
+		partial += min(secretCounts[d], guessCounts[d])
+	}
+	return exact, partial
+}
+
+// ipHackerPuzzle - podsieť a zoznam adries z rovnakého /16, 2-3 z nich patria do podsiete
+func ipHackerPuzzle(rng *rand.Rand, difficulty int) MinigamePuzzle {
+	bits := 20 + rng.Intn(5) // /20 - /24
+	base := netip.AddrFrom4([4]byte{10, byte(rng.Intn(256)), byte(rng.Intn(256)), byte(rng.Intn(256))})
+	subnet := netip.PrefixFrom(base, bits).Masked()
+
+	total := 6 + difficulty
+	inside := 2 + rng.Intn(2)
+	hostBits := 32 - bits
+
+	seen := map[netip.Addr]bool{}
+	var candidates []string
+	add := func(addr netip.Addr) bool {
+		if seen[addr] {
+			return false
+		}
+		seen[addr] = true
+		candidates = append(candidates, addr.String())
+		return true
+	}
+
+	network := subnet.Addr().As4()
+	netValue := binary.BigEndian.Uint32(network[:])
+	for len(candidates) < inside {
+		add(u32Addr(netValue | uint32(rng.Int63n(int64(1)<<hostBits))))
+	}
+	prefix16 := netValue &^ 0xFFFF
+	for len(candidates) < total {
+		// Zavádzajúce adresy z rovnakého /16, ale mimo podsiete
+		addr := u32Addr(prefix16 | uint32(rng.Intn(1<<16)))
+		if subnet.Contains(addr) {
+			continue
+		}
+		add(addr)
+	}
+
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	return MinigamePuzzle{Subnet: subnet.String(), Candidates: candidates}
+}
+
+func u32Addr(v uint32) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return netip.AddrFrom4(b)
+}
+
+// PruneHackChallenges - zmaže staré výzvy (volá scheduler)
+func PruneHackChallenges(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Where("deadline < ?", now.Add(-minigameChallengeTTL)).Delete(&HackChallenge{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,226 @@
+package deployable
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// solveMinigame - referenčný solver, aký by mal implementovať klient
+func solveMinigame(t *testing.T, minigameType string, puzzle MinigamePuzzle) MinigameSolution {
+	t.Helper()
+	switch minigameType {
+	case MinigameCircuitBreaker:
+		rotations := make([]int, len(puzzle.Tiles))
+		prev := 0
+		for i, tile := range puzzle.Tiles {
+			rotations[i] = (8 - tile - prev) % 4
+			prev = rotations[i]
+		}
+		return MinigameSolution{Rotations: rotations}
+
+	case MinigameCodeCracker:
+		for _, code := range allCodes(puzzle.CodeLength, puzzle.Digits) {
+			if CheckMinigameSolution(minigameType, puzzle, &MinigameSolution{Code: code}) {
+				return MinigameSolution{Code: code}
+			}
+		}
+
+	case MinigameIPHacker:
+		subnet := netip.MustParsePrefix(puzzle.Subnet)
+		var indices []int
+		for i, candidate := range puzzle.Candidates {
+			if subnet.Contains(netip.MustParseAddr(candidate)) {
+				indices = append(indices, i)
+			}
+		}
+		return MinigameSolution{Indices: indices}
+	}
+	t.Fatalf("no solution for %s", minigameType)
+	return MinigameSolution{}
+}
+
+func TestMinigamePuzzles_DeterministicAndSolvable(t *testing.T) {
+	types := []string{MinigameCircuitBreaker, MinigameCodeCracker, MinigameIPHacker}
+
+	for _, minigameType := range types {
+		for difficulty := 0; difficulty <= MaxMinigameLevel; difficulty++ {
+			for seed := int64(1); seed <= 20; seed++ {
+				a, err := GenerateMinigamePuzzle(minigameType, seed, difficulty)
+				if err != nil {
+					t.Fatalf("%s: %v", minigameType, err)
+				}
+				b, _ := GenerateMinigamePuzzle(minigameType, seed, difficulty)
+				if !reflect.DeepEqual(a, b) {
+					t.Fatalf("%s seed %d: puzzle not deterministic", minigameType, seed)
+				}
+
+				solution := solveMinigame(t, minigameType, a)
+				if !CheckMinigameSolution(minigameType, a, &solution) {
+					t.Errorf("%s seed %d difficulty %d: valid solution rejected", minigameType, seed, difficulty)
+				}
+			}
+		}
+	}
+}
+
+func TestCheckMinigameSolution_RejectsWrongSolutions(t *testing.T) {
+	circuit, _ := GenerateMinigamePuzzle(MinigameCircuitBreaker, 7, 2)
+	code, _ := GenerateMinigamePuzzle(MinigameCodeCracker, 7, 2)
+	ip, _ := GenerateMinigamePuzzle(MinigameIPHacker, 7, 2)
+
+	wrongRotations := solveMinigame(t, MinigameCircuitBreaker, circuit).Rotations
+	wrongRotations[0] = (wrongRotations[0] + 1) % 4
+	wrongIndices := solveMinigame(t, MinigameIPHacker, ip).Indices[1:]
+
+	tests := []struct {
+		name     string
+		typ      string
+		puzzle   MinigamePuzzle
+		solution MinigameSolution
+	}{
+		{"circuit wrong rotation", MinigameCircuitBreaker, circuit, MinigameSolution{Rotations: wrongRotations}},
+		{"circuit missing tiles", MinigameCircuitBreaker, circuit, MinigameSolution{Rotations: wrongRotations[:2]}},
+		{"code repeats first clue", MinigameCodeCracker, code, MinigameSolution{Code: code.Clues[0].Guess}},
+		{"code out of range digit", MinigameCodeCracker, code, MinigameSolution{Code: []int{9, 9, 9, 9}}},
+		{"ip missing address", MinigameIPHacker, ip, MinigameSolution{Indices: wrongIndices}},
+		{"ip all addresses", MinigameIPHacker, ip, MinigameSolution{Indices: []int{0, 1, 2, 3, 4, 5, 6, 7}}},
+		{"empty solution", MinigameCircuitBreaker, circuit, MinigameSolution{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if CheckMinigameSolution(tt.typ, tt.puzzle, &tt.solution) {
+				t.Error("wrong solution accepted")
+			}
+		})
+	}
+}
+
+func TestEvaluateMinigame(t *testing.T) {
+	issued := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	challenge := &HackChallenge{
+		ID:           uuid.New(),
+		MinigameType: MinigameIPHacker,
+		Seed:         99,
+		Difficulty:   1,
+		IssuedAt:     issued,
+		Deadline:     issued.Add(minigameTimeLimits[MinigameIPHacker]),
+	}
+	puzzle, _ := GenerateMinigamePuzzle(challenge.MinigameType, challenge.Seed, challenge.Difficulty)
+	solution := solveMinigame(t, challenge.MinigameType, puzzle)
+
+	tests := []struct {
+		name        string
+		solution    MinigameSolution
+		elapsed     time.Duration
+		wantSuccess bool
+		wantScore   int
+	}{
+		{"solved instantly is a bot", solution, time.Second, false, 0},
+		{"solved at minimum time", solution, MinMinigameDuration, true, 950},
+		{"solved at deadline", solution, 30 * time.Second, true, 500},
+		{"after deadline", solution, 31 * time.Second, false, 0},
+		{"wrong solution", MinigameSolution{Indices: []int{0}}, 10 * time.Second, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, err := evaluateMinigame(challenge, &tt.solution, tt.elapsed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if outcome.Success != tt.wantSuccess || outcome.Score != tt.wantScore {
+				t.Errorf("got success=%v score=%d, want success=%v score=%d", outcome.Success, outcome.Score, tt.wantSuccess, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestSignChallenge_DetectsTampering(t *testing.T) {
+	secret := []byte("test-secret")
+	issued := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	challenge := HackChallenge{
+		ID:           uuid.New(),
+		UserID:       uuid.New(),
+		DeviceID:     uuid.New(),
+		Action:       MinigameActionHack,
+		MinigameType: MinigameCircuitBreaker,
+		Seed:         42,
+		Difficulty:   2,
+		IssuedAt:     issued,
+		Deadline:     issued.Add(time.Minute),
+	}
+	signature := signChallenge(secret, &challenge)
+
+	tampered := challenge
+	tampered.Seed = 43
+	if signChallenge(secret, &tampered) == signature {
+		t.Error("changing the seed must change the signature")
+	}
+	tampered = challenge
+	tampered.Deadline = tampered.Deadline.Add(time.Hour)
+	if signChallenge(secret, &tampered) == signature {
+		t.Error("extending the deadline must change the signature")
+	}
+	if signChallenge([]byte("other-secret"), &challenge) == signature {
+		t.Error("signature must depend on the secret")
+	}
+}
+
+func TestGenerateMinigamePuzzle_UnknownType(t *testing.T) {
+	if _, err := GenerateMinigamePuzzle("pinball", 1, 0); !errors.Is(err, ErrMinigameUnknownType) {
+		t.Errorf("expected ErrMinigameUnknownType, got %v", err)
+	}
+}
+
+func TestDefaultMinigameType(t *testing.T) {
+	cases := []struct {
+		action, toolType, want string
+	}{
+		{MinigameActionHack, "", MinigameCircuitBreaker},
+		{MinigameActionHack, "basic_hack", MinigameCircuitBreaker},
+		{MinigameActionHack, "advanced_hack", MinigameCodeCracker},
+		{MinigameActionHack, "stealth_hack", MinigameIPHacker},
+		{MinigameActionClaim, "advanced_hack", MinigameIPHacker},
+	}
+	for _, tc := range cases {
+		if got := defaultMinigameType(tc.action, tc.toolType); got != tc.want {
+			t.Errorf("defaultMinigameType(%q, %q) = %q, want %q", tc.action, tc.toolType, got, tc.want)
+		}
+	}
+}
+
+func TestCheckChallengeTarget_BindsHackTool(t *testing.T) {
+	deviceID := uuid.New()
+	issuedFor := uuid.New()
+	challenge := &HackChallenge{
+		ID:           uuid.New(),
+		DeviceID:     deviceID,
+		Action:       MinigameActionHack,
+		HackToolID:   issuedFor,
+		MinigameType: MinigameCircuitBreaker,
+	}
+
+	if err := checkChallengeTarget(challenge, deviceID, MinigameActionHack, issuedFor); err != nil {
+		t.Fatalf("challenge should verify with the tool it was issued for, got %v", err)
+	}
+	if err := checkChallengeTarget(challenge, deviceID, MinigameActionHack, uuid.New()); !errors.Is(err, ErrChallengeMismatch) {
+		t.Errorf("challenge issued for one tool must not verify with another, got %v", err)
+	}
+	if err := checkChallengeTarget(challenge, uuid.New(), MinigameActionHack, issuedFor); !errors.Is(err, ErrChallengeMismatch) {
+		t.Errorf("challenge must not verify for another device, got %v", err)
+	}
+
+	// HackToolID je súčasťou podpisu - výmena nástroja v DB podpis rozbije
+	secret := []byte("test-secret")
+	tampered := *challenge
+	tampered.HackToolID = uuid.New()
+	if signChallenge(secret, &tampered) == signChallenge(secret, challenge) {
+		t.Error("changing the hack tool must change the signature")
+	}
+}
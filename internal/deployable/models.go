@@ -47,6 +47,7 @@ const (
 	DeviceStatusDepleted  DeviceStatus = "depleted"
 	DeviceStatusAbandoned DeviceStatus = "abandoned"
 	DeviceStatusDestroyed DeviceStatus = "destroyed"
+	DeviceStatusFrozen    DeviceStatus = "frozen" // nad limitom tieru (po expirácii) alebo vypnuté vlastníkom
 )
 
 // DeviceHack reprezentuje pokus o hack
@@ -59,10 +60,12 @@ type DeviceHack struct {
 	HackToolUsed     string            `json:"hack_tool_used" db:"hack_tool_used"`
 	DistanceM        float64           `json:"distance_m" db:"distance_m"`
 	HackDurationSec  int               `json:"hack_duration_seconds" db:"hack_duration_seconds" gorm:"column:hack_duration_seconds"`
-	MinigameType     string            `json:"minigame_type" db:"minigame_type"`             // Typ minihry (napr. "circuit_breaker")
-	MinigameScore    int               `json:"minigame_score" db:"minigame_score"`           // Skóre z minihry
-	MinigameDuration int               `json:"minigame_duration" db:"minigame_duration"`     // Trvanie minihry v sekundách
-	Properties       datatypes.JSONMap `json:"properties" db:"properties" gorm:"type:jsonb"` // Doplnkové info o minihre
+	MinigameType     string            `json:"minigame_type" db:"minigame_type"`         // Typ minihry (napr. "circuit_breaker")
+	MinigameScore    int               `json:"minigame_score" db:"minigame_score"`       // Skóre z minihry
+	MinigameDuration int               `json:"minigame_duration" db:"minigame_duration"` // Trvanie minihry v sekundách
+	ChallengeID      *uuid.UUID        `json:"challenge_id,omitempty" db:"challenge_id" gorm:"type:uuid"`
+	MinigameVerified bool              `json:"minigame_verified" db:"minigame_verified" gorm:"not null;default:false"` // Výsledok overil server
+	Properties       datatypes.JSONMap `json:"properties" db:"properties" gorm:"type:jsonb"`                           // Doplnkové info o minihre
 	CreatedAt        time.Time         `json:"created_at" db:"created_at"`
 }
 
@@ -89,7 +92,7 @@ func (HackTool) TableName() string {
 	return "gameplay.hack_tools"
 }
 
-// DeviceAccess reprezentuje prístupové práva k zariadeniam (po hackovaní)
+// DeviceAccess reprezentuje prístupové práva k zariadeniam (po hackovaní alebo zdieľaní vlastníkom)
 type DeviceAccess struct {
 	ID               uuid.UUID  `json:"id" db:"id" gorm:"primaryKey"`
 	DeviceID         uuid.UUID  `json:"device_id" db:"device_id"`
@@ -98,6 +101,7 @@ type DeviceAccess struct {
 	ExpiresAt        time.Time  `json:"expires_at" db:"expires_at"`
 	AccessLevel      string     `json:"access_level" db:"access_level"`
 	GrantedByHackID  *uuid.UUID `json:"granted_by_hack_id" db:"granted_by_hack_id"`
+	GrantedByUserID  *uuid.UUID `json:"granted_by_user_id,omitempty" db:"granted_by_user_id" gorm:"type:uuid"` // vlastník pri zdieľaní
 	IsDeviceDisabled bool       `json:"is_device_disabled" db:"is_device_disabled"`
 	DisabledUntil    *time.Time `json:"disabled_until" db:"disabled_until"`
 	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
@@ -177,6 +181,8 @@ type HackResponse struct {
 	NewOwnerID           *uuid.UUID `json:"new_owner_id,omitempty"`
 	BatteryReplaced      bool       `json:"battery_replaced,omitempty"`
 	DeviceStatus         string     `json:"device_status,omitempty"`
+	DefenseTriggered     []string   `json:"defense_triggered,omitempty"` // pasce obrany, ktoré zareagovali
+	ToolDamage           int        `json:"tool_damage,omitempty"`       // použitia nástroja zničené pascou
 }
 
 // DeployRequest - request na deploy zariadenia
@@ -198,24 +204,18 @@ type DeployResponse struct {
 
 // HackRequest - request na hack zariadenia
 type HackRequest struct {
-	HackToolID       uuid.UUID `json:"hack_tool_id" binding:"required"`
-	Latitude         float64   `json:"latitude" binding:"required"`
-	Longitude        float64   `json:"longitude" binding:"required"`
-	MinigameSuccess  bool      `json:"minigame_success"`         // Výsledok minihry (true = úspech, false = neúspech)
-	MinigameType     string    `json:"minigame_type"`            // Typ minihry (napr. "circuit_breaker", "code_cracker")
-	MinigameScore    int       `json:"minigame_score,omitempty"` // Skóre z minihry (voliteľné)
-	MinigameDuration int       `json:"minigame_duration"`        // Trvanie minihry v sekundách (anti-cheat)
+	HackToolID uuid.UUID          `json:"hack_tool_id" binding:"required"`
+	Latitude   float64            `json:"latitude" binding:"required"`
+	Longitude  float64            `json:"longitude" binding:"required"`
+	Minigame   MinigameSubmission `json:"minigame" binding:"required"` // Riešenie výzvy z POST /devices/:device_id/minigame
 }
 
 // ClaimRequest - request na claim opusteného zariadenia
 type ClaimRequest struct {
-	HackToolID       uuid.UUID `json:"hack_tool_id" binding:"required"`
-	Latitude         float64   `json:"latitude" binding:"required"`
-	Longitude        float64   `json:"longitude" binding:"required"`
-	MinigameSuccess  bool      `json:"minigame_success"`         // Výsledok minihry (true = úspech, false = neúspech)
-	MinigameType     string    `json:"minigame_type"`            // Typ minihry (napr. "ip_hacker")
-	MinigameScore    int       `json:"minigame_score,omitempty"` // Skóre z minihry (voliteľné)
-	MinigameDuration int       `json:"minigame_duration"`        // Trvanie minihry v sekundách (anti-cheat)
+	HackToolID uuid.UUID          `json:"hack_tool_id" binding:"required"`
+	Latitude   float64            `json:"latitude" binding:"required"`
+	Longitude  float64            `json:"longitude" binding:"required"`
+	Minigame   MinigameSubmission `json:"minigame" binding:"required"` // Riešenie výzvy z POST /devices/:device_id/minigame
 }
 
 // ClaimResponse - response z claim zariadenia
@@ -259,12 +259,15 @@ type MapMarker struct {
 	OwnerID        *uuid.UUID `json:"owner_id,omitempty"`
 	HackedBy       *uuid.UUID `json:"hacked_by,omitempty"`
 	DistanceKm     float64    `json:"distance_km"`
-	VisibilityType string     `json:"visibility_type"` // "owner", "hacker", "public", "scan_data"
+	VisibilityType string     `json:"visibility_type"`        // "owner", "hacker", "public", "scan_data", "shared", "network"
+	AccessLevel    string     `json:"access_level,omitempty"` // zdieľaný prístup: view, scan, maintain
+	NetworkID      *uuid.UUID `json:"network_id,omitempty"`
 }
 
 // MapMarkersResponse - response pre mapové markery
 type MapMarkersResponse struct {
-	Markers []MapMarker `json:"markers"`
+	Markers  []MapMarker       `json:"markers"`
+	Networks []NetworkCoverage `json:"networks,omitempty"` // spoločné pokrytie sietí, v ktorých je hráč
 }
 
 // Value a Scan pre JSONB - removed as they are not needed for map[string]any
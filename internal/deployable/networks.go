@@ -0,0 +1,519 @@
+package deployable
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/notifications"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Siete zariadení - tím (vlastník siete + priatelia) zdieľa svoje scannery.
+// Člen siete má k zariadeniam ostatných členov prístup podľa úrovne, s ktorou ich vlastník pridal.
+const (
+	NetworkRoleOwner  = "owner"
+	NetworkRoleMember = "member"
+
+	MaxNetworkMembers   = 10
+	MaxNetworksPerUser  = 3
+	MaxNetworkNameLen   = 50
+	coverageGridSamples = 200 // rozlíšenie mriežky pri výpočte plochy pokrytia
+)
+
+var (
+	ErrNetworkNotFound       = errors.New("sieť nebola nájdená")
+	ErrNetworkNotOwner       = errors.New("túto akciu môže vykonať len vlastník siete")
+	ErrNetworkInvalidName    = errors.New("neplatný názov siete")
+	ErrNetworkLimit          = errors.New("dosiahol si maximálny počet sietí")
+	ErrNetworkFull           = errors.New("sieť má maximálny počet členov")
+	ErrNetworkAlreadyMember  = errors.New("hráč už je členom siete")
+	ErrNetworkMemberNotFound = errors.New("hráč nie je členom siete")
+	ErrNetworkOwnerLeave     = errors.New("vlastník nemôže opustiť sieť, môže ju zrušiť")
+	ErrDeviceInNetwork       = errors.New("zariadenie už je v inej sieti")
+	ErrNetworkDeviceNotFound = errors.New("zariadenie nie je v sieti")
+)
+
+// DeviceNetwork - sieť zdieľaných zariadení
+type DeviceNetwork struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Name      string    `json:"name" gorm:"size:50;not null"`
+	OwnerID   uuid.UUID `json:"owner_id" gorm:"type:uuid;not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (DeviceNetwork) TableName() string {
+	return "gameplay.device_networks"
+}
+
+// DeviceNetworkMember - člen siete
+type DeviceNetworkMember struct {
+	NetworkID uuid.UUID `json:"network_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	Role      string    `json:"role" gorm:"size:20;not null"`
+	JoinedAt  time.Time `json:"joined_at" gorm:"not null"`
+}
+
+func (DeviceNetworkMember) TableName() string {
+	return "gameplay.device_network_members"
+}
+
+// DeviceNetworkDevice - zariadenie vložené do siete (každé zariadenie najviac v jednej sieti)
+type DeviceNetworkDevice struct {
+	DeviceID    uuid.UUID `json:"device_id" gorm:"type:uuid;primaryKey"`
+	NetworkID   uuid.UUID `json:"network_id" gorm:"type:uuid;not null;index"`
+	OwnerID     uuid.UUID `json:"owner_id" gorm:"type:uuid;not null;index"`
+	AccessLevel string    `json:"access_level" gorm:"size:20;not null"`
+	AddedAt     time.Time `json:"added_at" gorm:"not null"`
+}
+
+func (DeviceNetworkDevice) TableName() string {
+	return "gameplay.device_network_devices"
+}
+
+// CreateNetworkRequest - nová sieť
+type CreateNetworkRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// AddNetworkMemberRequest - vlastník pridá priateľa do siete
+type AddNetworkMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// AddNetworkDeviceRequest - člen vloží svoje zariadenie do siete
+type AddNetworkDeviceRequest struct {
+	DeviceID    uuid.UUID `json:"device_id" binding:"required"`
+	AccessLevel string    `json:"access_level"` // default scan
+}
+
+// NetworkMemberInfo - člen siete pre UI
+type NetworkMemberInfo struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+	DeviceCount int       `json:"device_count"`
+}
+
+// NetworkDeviceInfo - zariadenie v sieti pre UI
+type NetworkDeviceInfo struct {
+	DeviceID     uuid.UUID    `json:"device_id"`
+	Name         string       `json:"name"`
+	OwnerID      uuid.UUID    `json:"owner_id"`
+	AccessLevel  string       `json:"access_level"`
+	Status       DeviceStatus `json:"status"`
+	Latitude     float64      `json:"latitude"`
+	Longitude    float64      `json:"longitude"`
+	ScanRadiusKm float64      `json:"scan_radius_km"`
+	BatteryLevel *int         `json:"battery_level"`
+	AddedAt      time.Time    `json:"added_at"`
+}
+
+// NetworkDetails - sieť s členmi a zariadeniami
+type NetworkDetails struct {
+	DeviceNetwork
+	MyRole  string              `json:"my_role"`
+	Members []NetworkMemberInfo `json:"members"`
+	Devices []NetworkDeviceInfo `json:"devices"`
+}
+
+// CoverageCircle - dosah jedného scannera
+type CoverageCircle struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	RadiusKm  float64 `json:"radius_km"`
+}
+
+// NetworkCoverage - spoločné pokrytie siete v okolí hráča (pre mapu)
+type NetworkCoverage struct {
+	NetworkID     uuid.UUID        `json:"network_id"`
+	Name          string           `json:"name"`
+	DeviceCount   int              `json:"device_count"`
+	ActiveDevices int              `json:"active_devices"` // schopné skenovať, tvoria pokrytie
+	CoverageKm2   float64          `json:"coverage_km2"`   // plocha zjednotenia dosahov (prekryvy sa nerátajú dvakrát)
+	Areas         []CoverageCircle `json:"areas"`
+}
+
+// coverageAreaKm2 - približná plocha zjednotenia kruhov (mriežka v lokálnej rovinnej projekcii)
+func coverageAreaKm2(circles []CoverageCircle) float64 {
+	if len(circles) == 0 {
+		return 0
+	}
+
+	const kmPerDegLat = 111.32
+	refLat := circles[0].Latitude
+	kmPerDegLng := kmPerDegLat * math.Cos(refLat*math.Pi/180)
+
+	type point struct{ x, y, r float64 }
+	points := make([]point, len(circles))
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i, c := range circles {
+		p := point{x: c.Longitude * kmPerDegLng, y: c.Latitude * kmPerDegLat, r: c.RadiusKm}
+		points[i] = p
+		minX, maxX = math.Min(minX, p.x-p.r), math.Max(maxX, p.x+p.r)
+		minY, maxY = math.Min(minY, p.y-p.r), math.Max(maxY, p.y+p.r)
+	}
+
+	stepX := (maxX - minX) / coverageGridSamples
+	stepY := (maxY - minY) / coverageGridSamples
+	if stepX <= 0 || stepY <= 0 {
+		return 0
+	}
+
+	covered := 0
+	for i := 0; i < coverageGridSamples; i++ {
+		x := minX + (float64(i)+0.5)*stepX
+		for j := 0; j < coverageGridSamples; j++ {
+			y := minY + (float64(j)+0.5)*stepY
+			for _, p := range points {
+				if dx, dy := x-p.x, y-p.y; dx*dx+dy*dy <= p.r*p.r {
+					covered++
+					break
+				}
+			}
+		}
+	}
+	return float64(covered) * stepX * stepY
+}
+
+// networkCoverage - súhrn pokrytia podľa sietí z network markerov (pred dedupom, vrátane vlastných zariadení)
+func (s *Service) networkCoverage(markers []MapMarker) []NetworkCoverage {
+	byNetwork := make(map[uuid.UUID]*NetworkCoverage)
+	var order []uuid.UUID
+	for _, m := range markers {
+		if m.NetworkID == nil {
+			continue
+		}
+		c, ok := byNetwork[*m.NetworkID]
+		if !ok {
+			c = &NetworkCoverage{NetworkID: *m.NetworkID, Areas: []CoverageCircle{}}
+			byNetwork[*m.NetworkID] = c
+			order = append(order, *m.NetworkID)
+		}
+		c.DeviceCount++
+		if m.Status == "active" && m.BatteryLevel > 0 {
+			c.ActiveDevices++
+			c.Areas = append(c.Areas, CoverageCircle{Latitude: m.Latitude, Longitude: m.Longitude, RadiusKm: m.ScanRadiusKm})
+		}
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	var networks []DeviceNetwork
+	s.db.Where("id IN ?", order).Find(&networks)
+	for _, n := range networks {
+		byNetwork[n.ID].Name = n.Name
+	}
+
+	coverage := make([]NetworkCoverage, 0, len(order))
+	for _, id := range order {
+		c := byNetwork[id]
+		c.CoverageKm2 = math.Round(coverageAreaKm2(c.Areas)*100) / 100
+		coverage = append(coverage, *c)
+	}
+	return coverage
+}
+
+func (s *Service) networkMembership(db *gorm.DB, networkID, userID uuid.UUID) (*DeviceNetworkMember, error) {
+	var member DeviceNetworkMember
+	if err := db.Where("network_id = ? AND user_id = ?", networkID, userID).First(&member).Error; err != nil {
+		return nil, ErrNetworkNotFound
+	}
+	return &member, nil
+}
+
+// ownedNetwork - sieť, ktorú hráč vlastní (zamknutá v transakcii)
+func (s *Service) ownedNetwork(tx *gorm.DB, networkID, userID uuid.UUID) (*DeviceNetwork, error) {
+	var network DeviceNetwork
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", networkID).First(&network).Error; err != nil {
+		return nil, ErrNetworkNotFound
+	}
+	if network.OwnerID != userID {
+		if _, err := s.networkMembership(tx, networkID, userID); err != nil {
+			return nil, err
+		}
+		return nil, ErrNetworkNotOwner
+	}
+	return &network, nil
+}
+
+// CreateNetwork - založí sieť, zakladateľ je jej vlastník
+func (s *Service) CreateNetwork(userID uuid.UUID, req *CreateNetworkRequest) (*DeviceNetwork, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > MaxNetworkNameLen {
+		return nil, ErrNetworkInvalidName
+	}
+
+	now := time.Now().UTC()
+	network := DeviceNetwork{ID: uuid.New(), Name: name, OwnerID: userID, CreatedAt: now, UpdatedAt: now}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		tx.Model(&DeviceNetworkMember{}).Where("user_id = ?", userID).Count(&count)
+		if count >= MaxNetworksPerUser {
+			return ErrNetworkLimit
+		}
+		if err := tx.Create(&network).Error; err != nil {
+			return err
+		}
+		return tx.Create(&DeviceNetworkMember{NetworkID: network.ID, UserID: userID, Role: NetworkRoleOwner, JoinedAt: now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🕸️ Device network %s (%s) created by %s", network.ID, network.Name, userID)
+	return &network, nil
+}
+
+// GetMyNetworks - siete, v ktorých je hráč členom
+func (s *Service) GetMyNetworks(userID uuid.UUID) ([]NetworkDetails, error) {
+	var memberships []DeviceNetworkMember
+	if err := s.db.Where("user_id = ?", userID).Order("joined_at ASC").Find(&memberships).Error; err != nil {
+		return nil, fmt.Errorf("failed to get networks: %w", err)
+	}
+
+	networks := []NetworkDetails{}
+	for _, m := range memberships {
+		details, err := s.GetNetwork(userID, m.NetworkID)
+		if err != nil {
+			continue
+		}
+		networks = append(networks, *details)
+	}
+	return networks, nil
+}
+
+// GetNetwork - detail siete (len pre členov)
+func (s *Service) GetNetwork(userID, networkID uuid.UUID) (*NetworkDetails, error) {
+	member, err := s.networkMembership(s.db, networkID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var network DeviceNetwork
+	if err := s.db.Where("id = ?", networkID).First(&network).Error; err != nil {
+		return nil, ErrNetworkNotFound
+	}
+
+	details := &NetworkDetails{DeviceNetwork: network, MyRole: member.Role, Members: []NetworkMemberInfo{}, Devices: []NetworkDeviceInfo{}}
+
+	if err := s.db.Table("gameplay.device_network_devices nd").
+		Select("nd.device_id, dd.name, nd.owner_id, nd.access_level, dd.status, dd.latitude, dd.longitude, dd.scan_radius_km, dd.battery_level, nd.added_at").
+		Joins("JOIN gameplay.deployed_devices dd ON dd.id = nd.device_id").
+		Where("nd.network_id = ?", networkID).
+		Order("nd.added_at ASC").
+		Scan(&details.Devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to get network devices: %w", err)
+	}
+	perOwner := make(map[uuid.UUID]int)
+	for _, d := range details.Devices {
+		perOwner[d.OwnerID]++
+	}
+
+	if err := s.db.Table("gameplay.device_network_members nm").
+		Select("nm.user_id, u.username, nm.role, nm.joined_at").
+		Joins("JOIN auth.users u ON u.id = nm.user_id").
+		Where("nm.network_id = ?", networkID).
+		Order("nm.joined_at ASC").
+		Scan(&details.Members).Error; err != nil {
+		return nil, fmt.Errorf("failed to get network members: %w", err)
+	}
+	for i := range details.Members {
+		details.Members[i].DeviceCount = perOwner[details.Members[i].UserID]
+	}
+
+	return details, nil
+}
+
+// DeleteNetwork - vlastník zruší sieť; zariadenia ostávajú vlastníkom, zanikne len zdieľanie
+func (s *Service) DeleteNetwork(userID, networkID uuid.UUID) error {
+	var members []uuid.UUID
+	var name string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		network, err := s.ownedNetwork(tx, networkID, userID)
+		if err != nil {
+			return err
+		}
+		name = network.Name
+		tx.Model(&DeviceNetworkMember{}).Where("network_id = ? AND user_id <> ?", networkID, userID).Pluck("user_id", &members)
+
+		if err := tx.Where("network_id = ?", networkID).Delete(&DeviceNetworkDevice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("network_id = ?", networkID).Delete(&DeviceNetworkMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(network).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🕸️ Device network %s disbanded by %s", networkID, userID)
+	s.notifications.Send(members, notifications.Notification{
+		Type:  notifications.TypeDeviceNetwork,
+		Title: fmt.Sprintf("Sieť %s bola zrušená", name),
+		Body:  "Vlastník zrušil sieť, zdieľané zariadenia už nevidíš.",
+		Data:  gameplay.JSONB{"network_id": networkID, "event": "disbanded"},
+	}, "device_network_disbanded:"+networkID.String())
+	return nil
+}
+
+// AddNetworkMember - vlastník pridá priateľa do siete
+func (s *Service) AddNetworkMember(userID, networkID uuid.UUID, req *AddNetworkMemberRequest) (*NetworkMemberInfo, error) {
+	if req.UserID == userID {
+		return nil, ErrNetworkAlreadyMember
+	}
+	if friends, err := s.friends.AreFriends(userID, req.UserID); err != nil {
+		return nil, err
+	} else if !friends {
+		return nil, ErrShareNotFriends
+	}
+
+	var user auth.User
+	if err := s.db.Select("id", "username").Where("id = ?", req.UserID).First(&user).Error; err != nil {
+		return nil, ErrShareNotFriends
+	}
+
+	var network *DeviceNetwork
+	member := DeviceNetworkMember{NetworkID: networkID, UserID: req.UserID, Role: NetworkRoleMember, JoinedAt: time.Now().UTC()}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if network, err = s.ownedNetwork(tx, networkID, userID); err != nil {
+			return err
+		}
+		if _, err := s.networkMembership(tx, networkID, req.UserID); err == nil {
+			return ErrNetworkAlreadyMember
+		}
+
+		var members, joined int64
+		tx.Model(&DeviceNetworkMember{}).Where("network_id = ?", networkID).Count(&members)
+		if members >= MaxNetworkMembers {
+			return ErrNetworkFull
+		}
+		tx.Model(&DeviceNetworkMember{}).Where("user_id = ?", req.UserID).Count(&joined)
+		if joined >= MaxNetworksPerUser {
+			return ErrNetworkLimit
+		}
+		return tx.Create(&member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🕸️ User %s added to device network %s", req.UserID, networkID)
+	s.notifications.Send([]uuid.UUID{req.UserID}, notifications.Notification{
+		Type:  notifications.TypeDeviceNetwork,
+		Title: fmt.Sprintf("Pridaný do siete %s", network.Name),
+		Body:  "Vidíš zariadenia členov siete a môžeš do nej pridať svoje.",
+		Data:  gameplay.JSONB{"network_id": networkID, "event": "joined"},
+	}, "")
+
+	return &NetworkMemberInfo{UserID: user.ID, Username: user.Username, Role: member.Role, JoinedAt: member.JoinedAt}, nil
+}
+
+// RemoveNetworkMember - vlastník odoberie člena alebo člen sám odíde; jeho zariadenia opustia sieť
+func (s *Service) RemoveNetworkMember(userID, networkID, memberID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if memberID != userID {
+			if _, err := s.ownedNetwork(tx, networkID, userID); err != nil {
+				return err
+			}
+		}
+
+		member, err := s.networkMembership(tx, networkID, memberID)
+		if err != nil {
+			return ErrNetworkMemberNotFound
+		}
+		if member.Role == NetworkRoleOwner {
+			return ErrNetworkOwnerLeave
+		}
+
+		if err := tx.Where("network_id = ? AND owner_id = ?", networkID, memberID).Delete(&DeviceNetworkDevice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("network_id = ? AND user_id = ?", networkID, memberID).Delete(&DeviceNetworkMember{}).Error; err != nil {
+			return err
+		}
+
+		log.Printf("🕸️ User %s left device network %s", memberID, networkID)
+		return nil
+	})
+}
+
+// leaveNetworks - člen opustí siete z networkIDs aj so svojimi zariadeniami (vlastník siete ostáva)
+func leaveNetworks(tx *gorm.DB, memberID uuid.UUID, networkIDs *gorm.DB) error {
+	left := tx.Model(&DeviceNetworkMember{}).Select("network_id").
+		Where("user_id = ? AND role = ? AND network_id IN (?)", memberID, NetworkRoleMember, networkIDs)
+	if err := tx.Where("owner_id = ? AND network_id IN (?)", memberID, left).Delete(&DeviceNetworkDevice{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ? AND role = ? AND network_id IN (?)", memberID, NetworkRoleMember, networkIDs).
+		Delete(&DeviceNetworkMember{}).Error
+}
+
+// AddNetworkDevice - člen vloží vlastné zariadenie do siete
+func (s *Service) AddNetworkDevice(userID, networkID uuid.UUID, req *AddNetworkDeviceRequest) (*DeviceNetworkDevice, error) {
+	level := req.AccessLevel
+	if level == "" {
+		level = AccessLevelScan
+	}
+	if _, ok := shareLevelRank[level]; !ok {
+		return nil, ErrInvalidAccessLevel
+	}
+
+	pooled := DeviceNetworkDevice{DeviceID: req.DeviceID, NetworkID: networkID, OwnerID: userID, AccessLevel: level, AddedAt: time.Now().UTC()}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := s.networkMembership(tx, networkID, userID); err != nil {
+			return err
+		}
+		if _, err := s.ownedDevice(tx, userID, req.DeviceID); err != nil {
+			return err
+		}
+
+		var existing DeviceNetworkDevice
+		if err := tx.Where("device_id = ?", req.DeviceID).First(&existing).Error; err == nil {
+			if existing.NetworkID != networkID {
+				return ErrDeviceInNetwork
+			}
+			// už v tejto sieti - len zmena úrovne prístupu
+			pooled.AddedAt = existing.AddedAt
+			return tx.Model(&existing).Update("access_level", level).Error
+		}
+		return tx.Create(&pooled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🕸️ Device %s pooled into network %s (%s)", req.DeviceID, networkID, level)
+	return &pooled, nil
+}
+
+// RemoveNetworkDevice - vlastník zariadenia alebo vlastník siete ho zo siete vyberie
+func (s *Service) RemoveNetworkDevice(userID, networkID, deviceID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var pooled DeviceNetworkDevice
+		if err := tx.Where("network_id = ? AND device_id = ?", networkID, deviceID).First(&pooled).Error; err != nil {
+			return ErrNetworkDeviceNotFound
+		}
+		if pooled.OwnerID != userID {
+			if _, err := s.ownedNetwork(tx, networkID, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&pooled).Error
+	})
+}
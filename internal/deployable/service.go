@@ -8,10 +8,12 @@ import (
 	"time"
 
 	"geoanomaly/internal/auth"
+	"geoanomaly/internal/friends"
 	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/notifications"
+	"geoanomaly/pkg/geoquery"
 
 	"github.com/google/uuid"
-	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -27,13 +29,27 @@ const (
 )
 
 type Service struct {
-	db *gorm.DB
+	db     *gorm.DB
+	geo    *geoquery.Querier
+	limits map[int]TierDeviceLimits
+	rng    randSource
+
+	minigameSecret []byte
+	notifications  *notifications.Service
+	friends        *friends.Service
 }
 
 func NewService(db *gorm.DB) *Service {
 	rand.Seed(time.Now().UnixNano())
 	return &Service{
-		db: db,
+		db:     db,
+		geo:    geoquery.New(db),
+		limits: DefaultTierDeviceLimits(),
+		rng:    globalRand{},
+
+		minigameSecret: randomMinigameSecret(),
+		notifications:  notifications.NewService(db),
+		friends:        friends.NewService(db),
 	}
 }
 
@@ -42,26 +58,20 @@ func (s *Service) DeployDevice(userID uuid.UUID, req *DeployRequest) (*DeployRes
 	log.Printf("🔧 DeployDevice: userID=%s, deviceID=%s, batteryID=%s, lat=%.6f, lng=%.6f",
 		userID, req.DeviceInventoryID, req.BatteryInventoryID, req.Latitude, req.Longitude)
 
-	// 1. Validovať tier obmedzenia
-	if err := s.validateTierLimits(userID); err != nil {
-		log.Printf("❌ DeployDevice: tier validation failed: %v", err)
-		return nil, err
-	}
-
-	// 2. Validovať vzdialenosť od hráča
+	// 1. Validovať vzdialenosť od hráča
 	if err := s.validateDeploymentDistance(userID, req.Latitude, req.Longitude); err != nil {
 		log.Printf("❌ DeployDevice: distance validation failed: %v", err)
 		return nil, err
 	}
 
-	// 3. Validácia inventára – hráč to musí vlastniť a kusy nesmú byť v použití
+	// 2. Validácia inventára – hráč to musí vlastniť a kusy nesmú byť v použití
 	iq := NewInventoryQueries(s.db)
 	if err := iq.ValidateDeploymentInventory(userID, req.DeviceInventoryID, req.BatteryInventoryID); err != nil {
 		log.Printf("❌ DeployDevice: inventory validation failed: %v", err)
 		return nil, err
 	}
 
-	// 4. Vytvoriť zariadenie
+	// 3. Vytvoriť zariadenie
 	device := DeployedDevice{
 		ID:                 uuid.New(),
 		OwnerID:            userID,
@@ -83,13 +93,23 @@ func (s *Service) DeployDevice(userID uuid.UUID, req *DeployRequest) (*DeployRes
 		UpdatedAt:          time.Now().UTC(),
 	}
 
-	// 5. Uložiť do databázy
-	if err := s.db.Create(&device).Error; err != nil {
+	// 4. Tier limity (počet, hustota, rozostup) a uloženie pod zámkom hráča
+	var limitErr error
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if limitErr = s.validateTierLimits(tx, userID, req.Latitude, req.Longitude); limitErr != nil {
+			return limitErr
+		}
+		return tx.Create(&device).Error
+	}); err != nil {
+		if limitErr != nil {
+			log.Printf("❌ DeployDevice: tier validation failed: %v", err)
+			return nil, err
+		}
 		log.Printf("❌ DeployDevice: failed to create device: %v", err)
 		return nil, fmt.Errorf("failed to create deployed device: %w", err)
 	}
 
-	// 6. Odstrániť scanner a batériu z inventára (soft-delete)
+	// 5. Odstrániť scanner a batériu z inventára (soft-delete)
 	if err := s.removeScannerFromInventory(req.DeviceInventoryID); err != nil {
 		log.Printf("❌ DeployDevice: failed to remove scanner: %v", err)
 		// Ak sa nepodarí odstrániť scanner z inventára, odstráň aj nasadené zariadenie
@@ -172,16 +192,13 @@ func (s *Service) GetMyActiveDevices(userID uuid.UUID) ([]DeployedDevice, error)
 	return devices, nil
 }
 
-// GetDeviceDetails - získa detaily zariadenia
+// GetDeviceDetails - získa detaily zariadenia (vlastník alebo zdieľaný prístup aspoň view)
 func (s *Service) GetDeviceDetails(deviceID uuid.UUID, userID uuid.UUID) (*DeployedDevice, error) {
-	var device DeployedDevice
-	if err := s.db.Where("id = ? AND owner_id = ?", deviceID, userID).First(&device).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("device not found or not owned by user")
-		}
-		return nil, fmt.Errorf("failed to get device details: %w", err)
+	device, err := s.accessibleDevice(userID, deviceID, AccessLevelView)
+	if err != nil {
+		return nil, fmt.Errorf("device not found or not owned by user")
 	}
-	return &device, nil
+	return device, nil
 }
 
 // ScanDeployableDevice - skenuje deployable zariadenie
@@ -198,8 +215,9 @@ func (s *Service) ScanDeployableDevice(userID uuid.UUID, deviceID uuid.UUID, req
 		return nil, fmt.Errorf("zariadenie nebolo nájdené alebo nie je aktívne")
 	}
 
-	// 2b. Overiť oprávnenie na sken: owner alebo platný záznam v gameplay.device_access
-	if device.OwnerID != userID {
+	// 2b. Overiť oprávnenie na sken: owner, platný záznam v gameplay.device_access z hacku
+	// alebo zdieľanie / sieť s úrovňou aspoň scan
+	if device.OwnerID != userID && !s.hasSharedAccess(userID, deviceID, AccessLevelScan) {
 		var count int64
 		err := s.db.
 			Table("gameplay.device_access").
@@ -329,7 +347,13 @@ func (s *Service) HackDevice(hackerID uuid.UUID, deviceID uuid.UUID, req *HackRe
 		return nil, fmt.Errorf("zariadenie nebolo nájdené")
 	}
 
-	// 3. Vypočítať skutočnú vzdialenosť
+	// 3. Získať a validovať hackovací nástroj (uses_left, expires_at)
+	hackTool, err := s.loadHackTool(hackerID, req.HackToolID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Vypočítať skutočnú vzdialenosť
 	distance := s.calculateDistance(
 		session.LastLocationLatitude,
 		session.LastLocationLongitude,
@@ -337,72 +361,82 @@ func (s *Service) HackDevice(hackerID uuid.UUID, deviceID uuid.UUID, req *HackRe
 		device.Longitude,
 	)
 
-	// 4. Validovať vzdialenosť (50m pre hack)
-	if distance > 50 {
-		return nil, fmt.Errorf("príliš ďaleko od zariadenia (%dm)", distance)
+	// 5. Validovať vzdialenosť (50m + range bonus nástroja)
+	if distance > hackTool.RangeM() {
+		return nil, fmt.Errorf("príliš ďaleko od zariadenia (%dm, max %dm)", distance, hackTool.RangeM())
 	}
 
-	// 5. Získať a validovať hackovací nástroj z inventory_items
-	var inventoryItem gameplay.InventoryItem
-	if err := s.db.Where("id = ? AND user_id = ? AND item_type = ? AND deleted_at IS NULL",
-		req.HackToolID, hackerID, "hack_tool").First(&inventoryItem).Error; err != nil {
-		return nil, fmt.Errorf("hackovací nástroj nebol nájdený v inventári")
+	// 6. Cooldown po neúspešnom pokuse
+	if err := s.validateHackCooldown(hackerID, deviceID, hackTool); err != nil {
+		return nil, err
 	}
 
-	// Extrahuj uses_left z properties
-	usesLeft := 0
-	if ul, ok := inventoryItem.Properties["uses_left"].(float64); ok {
-		usesLeft = int(ul)
+	// 7. Overiť riešenie minihry (podpísaná výzva, deadline, deterministická kontrola)
+	minigame, err := s.verifyMinigame(hackerID, deviceID, MinigameActionHack, req.HackToolID, &req.Minigame)
+	if err != nil {
+		return nil, err
 	}
 
-	// Validovať uses_left
-	if usesLeft <= 0 {
-		return nil, fmt.Errorf("hackovací nástroj nemá žiadne zostávajúce použitia")
+	// 8. Spotrebovať hack tool - každý pokus stojí jedno použitie
+	if _, err := s.consumeHackTool(hackTool); err != nil {
+		return nil, err
 	}
 
-	// Extrahuj tool_type z properties
-	toolType := "circuit_breaker" // default
-	if tt, ok := inventoryItem.Properties["tool_type"].(string); ok {
-		switch tt {
-		case "basic_hack":
-			toolType = "circuit_breaker"
-		case "advanced_hack":
-			toolType = "code_cracker"
-		case "device_claimer":
-			toolType = "stealth_infiltration"
-		case "stealth_hack":
-			toolType = "stealth_infiltration"
-		default:
-			toolType = tt
-		}
-	}
+	// 9. Obrana vlastníka: firewally zvýšia odpor, pasce môžu poškodiť nástroj / vystopovať hackera
+	defense := s.applyDefense(&device, hackerID, hackTool)
+	defended := device
+	defended.HackResistance = defense.Resistance
 
-	// Vytvor HackTool štruktúru pre kompatibilitu
-	hackTool := HackTool{
-		ID:         inventoryItem.ID,
-		UserID:     inventoryItem.UserID,
-		ToolType:   toolType,
-		UsesLeft:   usesLeft,
-		Properties: datatypes.JSONMap(inventoryItem.Properties),
-	}
+	// 10. Výsledok: overená minihra + roll podľa nástroja a odporu zariadenia
+	chance := hackSuccessChance(&defended, hackTool)
+	success := minigame.Success && s.performHack(&defended, hackTool)
 
-	// 6. Výsledok minihry z frontendu
-	// Frontend spustil minihru a poslal výsledok (success/fail)
-	success := req.MinigameSuccess
-	minigameType := req.MinigameType
-	if minigameType == "" {
-		minigameType = hackTool.ToolType // Fallback na tool type
+	// 11. Zaznamenať hack s overeným výsledkom minihry a reakciou obrany
+	hack := newDeviceHack(deviceID, hackerID, hackTool, distance, minigame, success)
+	hack.Properties["success_chance"] = chance
+	hack.Properties["effective_resistance"] = defense.Resistance
+	hack.Properties["defense_triggered"] = defense.Triggered
+	if defense.ToolDamage > 0 {
+		hack.Properties["tool_damage"] = defense.ToolDamage
 	}
+	if defense.HackerUsername != "" {
+		hack.Properties["hacker_username"] = defense.HackerUsername
+	}
+	s.db.Create(&hack)
+
+	log.Printf("🎮 Hack attempt: user=%s, device=%s, minigame=%s, minigame_success=%v, chance=%.2f, success=%v, score=%d, duration=%v",
+		hackerID, deviceID, minigame.Type, minigame.Success, chance, success, minigame.Score, minigame.Duration.Round(time.Millisecond))
 
-	// 7. Validácia minigame duration (anti-cheat - minihra musí trvať aspoň 3 sekundy)
-	if req.MinigameDuration < 3 {
-		log.Printf("⚠️ Suspicious minigame duration: %d seconds for user %s", req.MinigameDuration, hackerID)
-		// Môžeš to odmietnuť alebo len logovaťpre monitoring
-		// return nil, fmt.Errorf("minihra musí trvať minimálne 3 sekundy")
+	// 12. Spracovať výsledok hacku
+	hackResponse := &HackResponse{Success: false}
+	if success && device.Status == DeviceStatusAbandoned {
+		// Opustené zariadenie - claim s minigame
+		hackResponse, err = s.claimAbandonedDevice(hackerID, deviceID, hackTool)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("🎯 Abandoned device claimed via hack minigame: device=%s, new_owner=%s", deviceID, hackerID)
+	} else if success {
+		// Funkčné zariadenie - prístup na 24h (vlastník ho môže counter-hackom zrušiť)
+		hackResponse, err = s.grantDeviceAccess(hackerID, deviceID, hack.ID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// 8. Zaznamenať hack s minigame dátami
-	hack := DeviceHack{
+	// Hacker vidí, že narazil na pascu, nie čo presne vlastník zistil
+	hackResponse.DefenseTriggered = defense.Triggered
+	hackResponse.ToolDamage = defense.ToolDamage
+	s.alarmDeviceOwner(&device, &hack, hackResponse, defense)
+
+	return hackResponse, nil
+}
+
+// newDeviceHack - záznam pokusu s výsledkom minihry overeným serverom
+func newDeviceHack(deviceID, hackerID uuid.UUID, hackTool *HackTool, distance int, minigame *MinigameOutcome, success bool) DeviceHack {
+	challengeID := minigame.ChallengeID
+	durationSec := int(minigame.Duration.Round(time.Second) / time.Second)
+	return DeviceHack{
 		ID:               uuid.New(),
 		DeviceID:         deviceID,
 		HackerID:         hackerID,
@@ -410,71 +444,18 @@ func (s *Service) HackDevice(hackerID uuid.UUID, deviceID uuid.UUID, req *HackRe
 		Success:          success,
 		HackToolUsed:     hackTool.ToolType,
 		DistanceM:        float64(distance),
-		HackDurationSec:  req.MinigameDuration, // Trvanie minihry
-		MinigameType:     minigameType,
-		MinigameScore:    req.MinigameScore,
-		MinigameDuration: req.MinigameDuration,
-		Properties:       make(map[string]any),
-		CreatedAt:        time.Now().UTC(),
-	}
-
-	s.db.Create(&hack)
-
-	log.Printf("🎮 Hack attempt: user=%s, device=%s, minigame=%s, success=%v, score=%d, duration=%ds",
-		hackerID, deviceID, minigameType, success, req.MinigameScore, req.MinigameDuration)
-
-	// Spotrebovať hack tool (znížiť uses_left alebo vymazať ak 0)
-	newUsesLeft := usesLeft - 1
-	if newUsesLeft < 0 {
-		newUsesLeft = 0
-	}
-
-	var result *gorm.DB
-	if newUsesLeft == 0 {
-		// Ak uses_left dosiahne 0, vymaž item z inventára (soft delete)
-		result = s.db.Exec(`
-			UPDATE gameplay.inventory_items
-			SET deleted_at = NOW(),
-			    updated_at = NOW()
-			WHERE id = ?
-		`, hackTool.ID)
-		log.Printf("🗑️ Hack tool deleted (uses depleted): %s, uses: %d → 0 (deleted)", hackTool.ID, usesLeft)
-	} else {
-		// Inak len zníž uses_left
-		result = s.db.Exec(`
-			UPDATE gameplay.inventory_items
-			SET properties = jsonb_set(properties, '{uses_left}', to_jsonb(?::int), true),
-			    updated_at = NOW()
-			WHERE id = ?
-		`, newUsesLeft, hackTool.ID)
-		log.Printf("🔧 Hack tool consumed: %s, uses: %d → %d", hackTool.ID, usesLeft, newUsesLeft)
-	}
-
-	if result.Error != nil {
-		return nil, fmt.Errorf("chyba pri spotrebovaní nástroja: %w", result.Error)
-	}
-	if result.RowsAffected == 0 {
-		return nil, fmt.Errorf("nástroj už neexistuje v inventári")
-	}
-
-	if !success {
-		return &HackResponse{Success: false}, nil
-	}
-
-	// 6. Spracovať výsledok hacku
-	if device.Status == DeviceStatusAbandoned {
-		// Opustené zariadenie - claim s minigame (už prebehla vo Flutteri)
-		hackResponse, err := s.claimAbandonedDevice(hackerID, deviceID, &hackTool)
-		if err != nil {
-			return nil, err
-		}
-
-		log.Printf("🎯 Abandoned device claimed via hack minigame: device=%s, new_owner=%s", deviceID, hackerID)
-
-		return hackResponse, nil
-	} else {
-		// Funkčné zariadenie - prístup na 24h
-		return s.grantDeviceAccess(hackerID, deviceID, &hackTool)
+		HackDurationSec:  durationSec,
+		MinigameType:     minigame.Type,
+		MinigameScore:    minigame.Score,
+		MinigameDuration: durationSec,
+		ChallengeID:      &challengeID,
+		MinigameVerified: true,
+		Properties: map[string]any{
+			"hack_tool_id":     hackTool.ID.String(),
+			"minigame_success": minigame.Success,
+			"minigame_ms":      minigame.Duration.Milliseconds(),
+		},
+		CreatedAt: time.Now().UTC(),
 	}
 }
 
@@ -492,7 +473,18 @@ func (s *Service) ClaimAbandonedDevice(hackerID uuid.UUID, deviceID uuid.UUID, r
 		return nil, fmt.Errorf("opustené zariadenie nebolo nájdené")
 	}
 
-	// 3. Vypočítať skutočnú vzdialenosť
+	// Claimnuté zariadenie sa ráta do limitu tieru - over skôr, než sa minie nástroj
+	if _, err := s.validateDeviceCount(s.db, hackerID); err != nil {
+		return nil, err
+	}
+
+	// 3. Získať a validovať hackovací nástroj (uses_left, expires_at)
+	hackTool, err := s.loadHackTool(hackerID, req.HackToolID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Vypočítať skutočnú vzdialenosť
 	distance := s.calculateDistance(
 		session.LastLocationLatitude,
 		session.LastLocationLongitude,
@@ -500,173 +492,54 @@ func (s *Service) ClaimAbandonedDevice(hackerID uuid.UUID, deviceID uuid.UUID, r
 		device.Longitude,
 	)
 
-	// 4. Validovať vzdialenosť (50m pre claim)
-	if distance > 50 {
-		return nil, fmt.Errorf("príliš ďaleko od zariadenia (%dm)", distance)
-	}
-
-	// 5. Získať a validovať hackovací nástroj z inventory_items
-	var inventoryItem gameplay.InventoryItem
-	if err := s.db.Where("id = ? AND user_id = ? AND item_type = ? AND deleted_at IS NULL",
-		req.HackToolID, hackerID, "hack_tool").First(&inventoryItem).Error; err != nil {
-		return nil, fmt.Errorf("hackovací nástroj nebol nájdený v inventári")
-	}
-
-	// Extrahuj uses_left z properties
-	usesLeft := 0
-	if ul, ok := inventoryItem.Properties["uses_left"].(float64); ok {
-		usesLeft = int(ul)
-	}
-
-	// Validovať uses_left
-	if usesLeft <= 0 {
-		return nil, fmt.Errorf("hackovací nástroj nemá žiadne zostávajúce použitia")
-	}
-
-	// Extrahuj tool_type z properties
-	toolType := "stealth_infiltration" // default pre claim
-	if tt, ok := inventoryItem.Properties["tool_type"].(string); ok {
-		switch tt {
-		case "basic_hack":
-			toolType = "circuit_breaker"
-		case "advanced_hack":
-			toolType = "code_cracker"
-		case "device_claimer":
-			toolType = "stealth_infiltration"
-		case "stealth_hack":
-			toolType = "stealth_infiltration"
-		default:
-			toolType = tt
-		}
-	}
-
-	// Vytvor HackTool štruktúru pre kompatibilitu
-	hackTool := HackTool{
-		ID:         inventoryItem.ID,
-		UserID:     inventoryItem.UserID,
-		ToolType:   toolType,
-		UsesLeft:   usesLeft,
-		Properties: datatypes.JSONMap(inventoryItem.Properties),
-	}
-
-	// 6. Výsledok minihry z frontendu (rovnako ako pri hacku)
-	success := req.MinigameSuccess
-	minigameType := req.MinigameType
-	if minigameType == "" {
-		minigameType = "ip_hacker" // Default pre claim
-	}
-
-	// 7. Validácia minigame duration (anti-cheat - claim minihra max 30 sekúnd)
-	if req.MinigameDuration > 30 {
-		log.Printf("⚠️ Suspicious claim minigame duration: %d seconds (max 30s) for user %s", req.MinigameDuration, hackerID)
-		return nil, fmt.Errorf("claim minihra trvala príliš dlho (max 30s)")
-	}
-	if req.MinigameDuration < 3 {
-		log.Printf("⚠️ Suspicious claim minigame duration: %d seconds (min 3s) for user %s", req.MinigameDuration, hackerID)
+	// 5. Validovať vzdialenosť (50m + range bonus nástroja)
+	if distance > hackTool.RangeM() {
+		return nil, fmt.Errorf("príliš ďaleko od zariadenia (%dm, max %dm)", distance, hackTool.RangeM())
 	}
 
-	// 8. Ak minihra nebola úspešná, neclaimuj
-	if !success {
-		log.Printf("❌ Claim failed - minihra neúspešná pre user %s, device %s", hackerID, deviceID)
-		
-		// ✨ Odpocítaj uses_left aj pri FAILED claim
-		usesLeft := 0
-		if ul, ok := hackTool.Properties["uses_left"].(float64); ok {
-			usesLeft = int(ul)
-		}
-		
-		newUsesLeft := usesLeft - 1
-		if newUsesLeft < 0 {
-			newUsesLeft = 0
-		}
-		
-		var result *gorm.DB
-		if newUsesLeft == 0 {
-			// Ak uses_left dosiahne 0, vymaž item z inventára (soft delete)
-			result = s.db.Exec(`
-				UPDATE gameplay.inventory_items
-				SET deleted_at = NOW(),
-					updated_at = NOW()
-				WHERE id = ?
-			`, hackTool.ID)
-			log.Printf("🗑️ Claim tool deleted (uses depleted, failed): %s, uses: %d → 0 (deleted)", hackTool.ID, usesLeft)
-		} else {
-			// Inak len zníž uses_left
-			result = s.db.Exec(`
-				UPDATE gameplay.inventory_items
-				SET properties = jsonb_set(properties, '{uses_left}', to_jsonb(?::int), true),
-					updated_at = NOW()
-				WHERE id = ?
-			`, newUsesLeft, hackTool.ID)
-			log.Printf("🔧 Claim tool consumed (failed): %s, uses: %d → %d", hackTool.ID, usesLeft, newUsesLeft)
-		}
-		
-		if result.Error != nil {
-			log.Printf("❌ Chyba pri spotrebovaní claim tool: %v", result.Error)
-		}
-		
-		return &ClaimResponse{Success: false}, nil
+	// 6. Cooldown po neúspešnom pokuse
+	if err := s.validateHackCooldown(hackerID, deviceID, hackTool); err != nil {
+		return nil, err
 	}
 
-	// 9. Claim zariadenie (minihra bola úspešná)
-	hackResponse, err := s.claimAbandonedDevice(hackerID, deviceID, &hackTool)
+	// 7. Overiť riešenie minihry (claim minihra má deadline 30s)
+	minigame, err := s.verifyMinigame(hackerID, deviceID, MinigameActionClaim, req.HackToolID, &req.Minigame)
 	if err != nil {
 		return nil, err
 	}
 
-	// 10. Zaznamenať claim pokus s minigame dátami
-	hack := DeviceHack{
-		ID:               uuid.New(),
-		DeviceID:         deviceID,
-		HackerID:         hackerID,
-		HackTime:         time.Now().UTC(),
-		Success:          hackResponse.Success,
-		HackToolUsed:     hackTool.ToolType,
-		DistanceM:        float64(distance),
-		HackDurationSec:  req.MinigameDuration,
-		MinigameType:     minigameType,
-		MinigameScore:    req.MinigameScore,
-		MinigameDuration: req.MinigameDuration,
-		Properties:       make(map[string]any),
-		CreatedAt:        time.Now().UTC(),
+	// 8. Spotrebovať hack tool - aj neúspešný claim stojí jedno použitie
+	if _, err := s.consumeHackTool(hackTool); err != nil {
+		return nil, err
 	}
-	s.db.Create(&hack)
 
-	log.Printf("🎮 Claim attempt: user=%s, device=%s, minigame=%s, success=%v, score=%d, duration=%ds",
-		hackerID, deviceID, minigameType, success, req.MinigameScore, req.MinigameDuration)
+	// 9. Výsledok: overená minihra + roll podľa nástroja a odporu zariadenia
+	chance := hackSuccessChance(&device, hackTool)
+	success := minigame.Success && s.performHack(&device, hackTool)
 
-	// Spotrebovať hack tool (znížiť uses_left alebo vymazať ak 0)
-	newUsesLeft := usesLeft - 1
-	if newUsesLeft < 0 {
-		newUsesLeft = 0
-	}
-
-	var result *gorm.DB
-	if newUsesLeft == 0 {
-		// Ak uses_left dosiahne 0, vymaž item z inventára (soft delete)
-		result = s.db.Exec(`
-			UPDATE gameplay.inventory_items
-			SET deleted_at = NOW(),
-			    updated_at = NOW()
-			WHERE id = ?
-		`, hackTool.ID)
-		log.Printf("🗑️ Hack tool deleted (uses depleted, claim): %s, uses: %d → 0 (deleted)", hackTool.ID, usesLeft)
+	// 10. Claim zariadenie
+	var hackResponse *HackResponse
+	var claimErr error
+	if success {
+		hackResponse, claimErr = s.claimAbandonedDevice(hackerID, deviceID, hackTool)
 	} else {
-		// Inak len zníž uses_left
-		result = s.db.Exec(`
-			UPDATE gameplay.inventory_items
-			SET properties = jsonb_set(properties, '{uses_left}', to_jsonb(?::int), true),
-			    updated_at = NOW()
-			WHERE id = ?
-		`, newUsesLeft, hackTool.ID)
-		log.Printf("🔧 Hack tool consumed (claim): %s, uses: %d → %d", hackTool.ID, usesLeft, newUsesLeft)
+		log.Printf("❌ Claim failed - minigame_success=%v, chance=%.2f pre user %s, device %s", minigame.Success, chance, hackerID, deviceID)
 	}
 
-	if result.Error != nil {
-		return nil, fmt.Errorf("chyba pri spotrebovaní nástroja: %w", result.Error)
+	// 11. Zaznamenať claim pokus s overeným výsledkom minihry
+	hack := newDeviceHack(deviceID, hackerID, hackTool, distance, minigame, hackResponse != nil && hackResponse.Success)
+	hack.Properties["success_chance"] = chance
+	hack.Properties["claim"] = true
+	s.db.Create(&hack)
+
+	log.Printf("🎮 Claim attempt: user=%s, device=%s, minigame=%s, success=%v, score=%d, duration=%v",
+		hackerID, deviceID, minigame.Type, hack.Success, minigame.Score, minigame.Duration.Round(time.Millisecond))
+
+	if claimErr != nil {
+		return nil, claimErr
 	}
-	if result.RowsAffected == 0 {
-		return nil, fmt.Errorf("nástroj už neexistuje v inventári")
+	if hackResponse == nil {
+		return &ClaimResponse{Success: false}, nil
 	}
 
 	// Convert HackResponse to ClaimResponse
@@ -737,29 +610,27 @@ func (s *Service) GetNearbyDevices(userID uuid.UUID, lat, lng float64, radiusM i
 		return nil, err
 	}
 
-	// PostGIS query pre aktívne zariadenia v okolí
+	// Geo query pre aktívne zariadenia v okolí
+	within := s.geo.Within(geoquery.DeviceColumns, lat, lng, float64(radiusM))
+	nearest := s.geo.Nearest(geoquery.DeviceColumns, lat, lng)
 	q := `
 		SELECT *
 		FROM gameplay.deployed_devices
 		WHERE is_active = true
 		  AND status = ?
-		  AND ST_DWithin(
-		        location,
-		        ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography,
-		        ?
-		  )
+		  AND ` + within.SQL + `
 		  AND owner_id != ?
-		ORDER BY location <-> ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography
+		ORDER BY ` + nearest.SQL + `
 		LIMIT 200
 	`
 	var nearbyDevices []DeployedDevice
-	if err := s.db.Raw(q, DeviceStatusActive, lng, lat, radiusM, userID, lng, lat).Scan(&nearbyDevices).Error; err != nil {
+	if err := s.db.Raw(q, geoquery.Args(DeviceStatusActive, within, userID, nearest)...).Scan(&nearbyDevices).Error; err != nil {
 		return nil, fmt.Errorf("failed to get nearby devices: %w", err)
 	}
 
-	// PostGIS query pre opustené zariadenia v okolí
+	// Geo query pre opustené zariadenia v okolí
 	var abandonedDevices []DeployedDevice
-	if err := s.db.Raw(q, DeviceStatusAbandoned, lng, lat, radiusM, userID, lng, lat).Scan(&abandonedDevices).Error; err != nil {
+	if err := s.db.Raw(q, geoquery.Args(DeviceStatusAbandoned, within, userID, nearest)...).Scan(&abandonedDevices).Error; err != nil {
 		return nil, fmt.Errorf("failed to get abandoned devices: %w", err)
 	}
 
@@ -772,23 +643,21 @@ func (s *Service) GetNearbyDevices(userID uuid.UUID, lat, lng float64, radiusM i
 
 // GetAbandonedDevicesInRadius - získa iba opustené zariadenia v okolí
 func (s *Service) GetAbandonedDevicesInRadius(userID uuid.UUID, lat, lng float64, radiusM int) ([]DeployedDevice, error) {
-	// PostGIS query pre opustené zariadenia v okolí
+	// Geo query pre opustené zariadenia v okolí
+	within := s.geo.Within(geoquery.DeviceColumns, lat, lng, float64(radiusM))
+	nearest := s.geo.Nearest(geoquery.DeviceColumns, lat, lng)
 	q := `
 		SELECT *
 		FROM gameplay.deployed_devices
 		WHERE is_active = true
 		  AND status = ?
-		  AND ST_DWithin(
-		        location,
-		        ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography,
-		        ?
-		  )
+		  AND ` + within.SQL + `
 		  AND owner_id != ?
-		ORDER BY location <-> ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography
+		ORDER BY ` + nearest.SQL + `
 		LIMIT 200
 	`
 	var abandonedDevices []DeployedDevice
-	if err := s.db.Raw(q, DeviceStatusAbandoned, lng, lat, radiusM, userID, lng, lat).Scan(&abandonedDevices).Error; err != nil {
+	if err := s.db.Raw(q, geoquery.Args(DeviceStatusAbandoned, within, userID, nearest)...).Scan(&abandonedDevices).Error; err != nil {
 		return nil, fmt.Errorf("failed to get abandoned devices: %w", err)
 	}
 
@@ -842,6 +711,11 @@ func (s *Service) DeleteDevice(userID uuid.UUID, deviceID uuid.UUID) error {
 			log.Printf("🔋 Battery %s restored to inventory for user %s with %d%% charge", *device.BatteryInventoryID, userID, level)
 		}
 
+		// Zariadenie opúšťa sieť
+		if err := tx.Where("device_id = ?", deviceID).Delete(&DeviceNetworkDevice{}).Error; err != nil {
+			return fmt.Errorf("failed to remove device from network: %w", err)
+		}
+
 		// Zmazať záznam zariadenia (kaskádne zmaže prístupy, cooldowny, históriu)
 		if err := tx.Delete(&DeployedDevice{}, "id = ?", deviceID).Error; err != nil {
 			return fmt.Errorf("failed to delete device: %w", err)
@@ -854,13 +728,6 @@ func (s *Service) DeleteDevice(userID uuid.UUID, deviceID uuid.UUID) error {
 
 // Helper functions
 
-func (s *Service) validateTierLimits(userID uuid.UUID) error {
-	// TODO: Implement tier validation
-	// Get user tier and check device limits
-	_ = userID // Suppress unused parameter warning
-	return nil
-}
-
 func (s *Service) validateDeploymentDistance(userID uuid.UUID, deviceLat, deviceLng float64) error {
 	// Získať aktuálnu polohu hráča z existujúcej player_sessions tabuľky
 	var session auth.PlayerSession
@@ -949,25 +816,12 @@ func (s *Service) updateScanCooldown(userID uuid.UUID, deviceID uuid.UUID, coold
 func (s *Service) findNearbyZones(deviceLat, deviceLng float64, scanRadiusKm float64) ([]gameplay.Zone, error) {
 	var zones []gameplay.Zone
 
-	// SQL query s geografickým výpočtom vzdialenosti
-	query := `
-		SELECT *, 
-		       ST_Distance(
-		           ST_SetSRID(ST_MakePoint(location_longitude, location_latitude), 4326)::geography,
-		           ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography
-		       ) as distance_m
-		FROM gameplay.zones
-		WHERE is_active = true 
-		  AND ST_DWithin(
-		      ST_SetSRID(ST_MakePoint(location_longitude, location_latitude), 4326)::geography,
-		      ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography,
-		      ?
-		  )
-		ORDER BY distance_m ASC
-	`
-
+	// Zóny v dosahu scanneru, najbližšie prvé
 	radiusMeters := scanRadiusKm * 1000
-	err := s.db.Raw(query, deviceLng, deviceLat, deviceLng, deviceLat, radiusMeters).Scan(&zones).Error
+	err := s.db.Where("is_active = true").
+		Where(s.geo.Within(geoquery.ZoneColumns, deviceLat, deviceLng, radiusMeters)).
+		Order(s.geo.OrderByDistance(geoquery.ZoneColumns, deviceLat, deviceLng)).
+		Find(&zones).Error
 
 	return zones, err
 }
@@ -1052,38 +906,13 @@ func (s *Service) canDetectRarity(artifactRarity, maxRarity string) bool {
 	return artifactLevel <= maxLevel
 }
 
-// performHack - DEPRECATED: Táto funkcia už nie je používaná
-// Hack úspešnosť je teraz určená minihrami vo Flutteri, nie RNG výpočtom
-// Zachované pre historické účely a možné budúce použitie (napr. AI hackery)
+// performHack - roll úspechu hacku podľa nástroja a odporu zariadenia (šanca viď hackSuccessChance).
+// Minihra vo Flutteri musí prejsť ako prvá, roll rozhodne až o úspešnom pokuse.
 func (s *Service) performHack(device *DeployedDevice, hackTool *HackTool) bool {
-	// Base success rate
-	base := 0.5 // 50% default
-
-	// Allow tools to override base via "success_rate"
-	if sr, ok := hackTool.Properties["success_rate"].(float64); ok {
-		base = sr
-	}
-
-	// Hack resistance penalty (-5% per resistance level)
-	resPenalty := float64(device.HackResistance) * 0.05
-
-	// Optional additive bonus from "success_bonus"
-	toolBonus := 0.0
-	if bonus, ok := hackTool.Properties["success_bonus"].(float64); ok {
-		toolBonus = bonus
-	}
-
-	// Calculate final success probability
-	successRate := base - resPenalty + toolBonus
-
-	// Ensure minimum 5% and maximum 95% success rate
-	successRate = math.Max(0.05, math.Min(0.95, successRate))
-
-	return rand.Float64() < successRate
+	return s.rng.Float64() < hackSuccessChance(device, hackTool)
 }
 
-func (s *Service) grantDeviceAccess(hackerID uuid.UUID, deviceID uuid.UUID, hackTool *HackTool) (*HackResponse, error) {
-	_ = hackTool // Suppress unused parameter warning
+func (s *Service) grantDeviceAccess(hackerID uuid.UUID, deviceID uuid.UUID, hackID uuid.UUID) (*HackResponse, error) {
 	// Získať zariadenie pre kontrolu cooldownu
 	var device DeployedDevice
 	if err := s.db.Where("id = ?", deviceID).First(&device).Error; err != nil {
@@ -1096,7 +925,7 @@ func (s *Service) grantDeviceAccess(hackerID uuid.UUID, deviceID uuid.UUID, hack
 
 	if device.LastDisabledAt == nil || time.Since(*device.LastDisabledAt) > 24*time.Hour {
 		// Môže byť disabled - 25% šanca
-		isDisabled = rand.Float64() < 0.25
+		isDisabled = s.rng.Float64() < 0.25
 		if isDisabled {
 			dt := time.Now().UTC().Add(24 * time.Hour)
 			disabledUntil = &dt
@@ -1115,6 +944,7 @@ func (s *Service) grantDeviceAccess(hackerID uuid.UUID, deviceID uuid.UUID, hack
 		GrantedAt:        time.Now().UTC(),
 		ExpiresAt:        accessUntil,
 		AccessLevel:      "temporary",
+		GrantedByHackID:  &hackID,
 		IsDeviceDisabled: isDisabled,
 		DisabledUntil:    disabledUntil,
 		CreatedAt:        time.Now().UTC(),
@@ -1158,6 +988,11 @@ func (s *Service) claimAbandonedDevice(hackerID uuid.UUID, deviceID uuid.UUID, h
 			return fmt.Errorf("chyba pri načítaní zariadenia: %w", err)
 		}
 
+		// Limit tieru pod zámkom hackera (súbežné claimy)
+		if _, err := s.validateDeviceCount(tx, hackerID); err != nil {
+			return err
+		}
+
 		// 3. TODO: Validovať a odpočítať claim kit/batériu z inventára hackera
 		// if err := s.validateAndConsumeClaimKit(tx, hackerID); err != nil {
 		//     return fmt.Errorf("chyba pri validácii claim kitu: %w", err)
@@ -1212,10 +1047,18 @@ func (s *Service) claimAbandonedDevice(hackerID uuid.UUID, deviceID uuid.UUID, h
 			return fmt.Errorf("chyba pri aktualizácii zariadenia: %w", err)
 		}
 
-		// 6. Zmazať staré prístupové práva pre toto zariadenie
+		// 6. Zmazať staré prístupové práva pre toto zariadenie (vrátane zdieľaní a siete pôvodného vlastníka)
 		if err := tx.Where("device_id = ?", deviceID).Delete(&DeviceAccess{}).Error; err != nil {
 			return fmt.Errorf("chyba pri mazaní starých prístupov: %w", err)
 		}
+		if err := tx.Where("device_id = ?", deviceID).Delete(&DeviceNetworkDevice{}).Error; err != nil {
+			return fmt.Errorf("chyba pri odstránení zariadenia zo siete: %w", err)
+		}
+
+		// Nainštalované obranné moduly prechádzajú so zariadením na nového vlastníka
+		if err := tx.Model(&DefenseModule{}).Where("device_id = ?", deviceID).Update("owner_id", hackerID).Error; err != nil {
+			return fmt.Errorf("chyba pri prevode obranných modulov: %w", err)
+		}
 
 		// 7. Vytvoriť response
 		result = &HackResponse{
@@ -1294,6 +1137,21 @@ func (s *Service) GetMapMarkers(userID uuid.UUID, lat, lng float64) (*MapMarkers
 	}
 	markers = append(markers, ownMarkers...)
 
+	// 1b. Siete (do 50km) - zariadenia všetkých členov, vlastné si ponechajú visibility "owner"
+	networkMarkers, err := s.getNetworkScanners(userID, lat, lng, 50.0)
+	if err != nil {
+		return nil, fmt.Errorf("chyba pri načítaní scannerov sietí: %w", err)
+	}
+	coverage := s.networkCoverage(networkMarkers)
+	markers = append(markers, networkMarkers...)
+
+	// 1c. Scannery, ktoré so mnou zdieľajú priatelia (do 50km)
+	sharedMarkers, err := s.getSharedScanners(userID, lat, lng, 50.0)
+	if err != nil {
+		return nil, fmt.Errorf("chyba pri načítaní zdieľaných scannerov: %w", err)
+	}
+	markers = append(markers, sharedMarkers...)
+
 	// 2. Opustené scannery (do 50m) - viditeľné pre všetkých
 	abandonedMarkers, err := s.getAbandonedScanners(lat, lng, 0.05)
 	if err != nil {
@@ -1330,6 +1188,17 @@ func (s *Service) GetMapMarkers(userID uuid.UUID, lat, lng float64) (*MapMarkers
 		}
 	}
 
+	// Vlastné zariadenia v sieti dostanú network_id aj v "owner" markeri
+	inNetwork := make(map[uuid.UUID]*uuid.UUID)
+	for _, m := range networkMarkers {
+		inNetwork[m.ID] = m.NetworkID
+	}
+	for i := range markers {
+		if networkID, ok := inNetwork[markers[i].ID]; ok && markers[i].NetworkID == nil {
+			markers[i].NetworkID = networkID
+		}
+	}
+
 	// Dedup podľa ID (ponechaj prvý výskyt, aby ostal „visibilityType" z primárneho zdroja)
 	seen := make(map[uuid.UUID]bool)
 	uniq := make([]MapMarker, 0, len(markers))
@@ -1340,7 +1209,7 @@ func (s *Service) GetMapMarkers(userID uuid.UUID, lat, lng float64) (*MapMarkers
 		seen[m.ID] = true
 		uniq = append(uniq, m)
 	}
-	return &MapMarkersResponse{Markers: uniq}, nil
+	return &MapMarkersResponse{Markers: uniq, Networks: coverage}, nil
 }
 
 // pomocné štruktúry na skenovanie s distance/hacked_by
@@ -1356,26 +1225,20 @@ type hackedDeviceRow struct {
 
 // getOwnScanners - vlastné scannery do 50km
 func (s *Service) getOwnScanners(userID uuid.UUID, lat, lng float64, radiusKm float64) ([]MapMarker, error) {
+	distance := s.geo.Distance(geoquery.DeviceColumns, lat, lng)
+	within := s.geo.Within(geoquery.DeviceColumns, lat, lng, radiusKm*1000)
 	query := `
 		SELECT *, 
-		       ST_Distance(
-		           location,
-		           ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography
-		       ) / 1000.0 as distance_km
+		       ` + distance.SQL + ` / 1000.0 as distance_km
 		FROM gameplay.deployed_devices
 		WHERE owner_id = ? 
 		  AND is_active = true
-		  AND ST_DWithin(
-		      location,
-		      ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography,
-		      ?
-		  )
+		  AND ` + within.SQL + `
 		ORDER BY distance_km ASC
 	`
 
-	radiusMeters := radiusKm * 1000
 	var rows []deviceWithDistance
-	err := s.db.Raw(query, lng, lat, userID, lng, lat, radiusMeters).Scan(&rows).Error
+	err := s.db.Raw(query, geoquery.Args(distance, userID, within)...).Scan(&rows).Error
 	if err != nil {
 		return nil, err
 	}
@@ -1392,25 +1255,19 @@ func (s *Service) getOwnScanners(userID uuid.UUID, lat, lng float64, radiusKm fl
 
 // getAbandonedScanners - opustené scannery do 10km
 func (s *Service) getAbandonedScanners(lat, lng float64, radiusKm float64) ([]MapMarker, error) {
+	distance := s.geo.Distance(geoquery.DeviceColumns, lat, lng)
+	within := s.geo.Within(geoquery.DeviceColumns, lat, lng, radiusKm*1000)
 	query := `
 		SELECT *, 
-		       ST_Distance(
-		           location,
-		           ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography
-		       ) / 1000.0 as distance_km
+		       ` + distance.SQL + ` / 1000.0 as distance_km
 		FROM gameplay.deployed_devices
 		WHERE status = ?
-		  AND ST_DWithin(
-		      location,
-		      ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography,
-		      ?
-		  )
+		  AND ` + within.SQL + `
 		ORDER BY distance_km ASC
 	`
 
-	radiusMeters := radiusKm * 1000
 	var rows []deviceWithDistance
-	err := s.db.Raw(query, lng, lat, DeviceStatusAbandoned, lng, lat, radiusMeters).Scan(&rows).Error
+	err := s.db.Raw(query, geoquery.Args(distance, DeviceStatusAbandoned, within)...).Scan(&rows).Error
 	if err != nil {
 		return nil, err
 	}
@@ -1427,30 +1284,25 @@ func (s *Service) getAbandonedScanners(lat, lng float64, radiusKm float64) ([]Ma
 
 // getHackedScanners - hacknuté scannery do 20km
 func (s *Service) getHackedScanners(userID uuid.UUID, lat, lng float64, radiusKm float64) ([]MapMarker, error) {
+	columns := geoquery.DeviceColumns.As("dd")
+	distance := s.geo.Distance(columns, lat, lng)
+	within := s.geo.Within(columns, lat, lng, radiusKm*1000)
 	query := `
 		SELECT dd.*,
-		       ST_Distance(
-		           dd.location,
-		           ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography
-		       ) / 1000.0 AS distance_km,
+		       ` + distance.SQL + ` / 1000.0 AS distance_km,
 		       da.user_id AS hacked_by
 		FROM gameplay.deployed_devices dd
 		LEFT JOIN gameplay.device_access da
-		       ON dd.id = da.device_id AND da.user_id = ?
+		       ON dd.id = da.device_id AND da.user_id = ? AND da.access_level = 'temporary'
 		WHERE dd.is_active = TRUE
 		  AND da.expires_at > NOW()
 		  AND (dd.owner_id = ? OR da.user_id IS NOT NULL)
-		  AND ST_DWithin(
-		        dd.location,
-		        ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography,
-		        ?
-		  )
+		  AND ` + within.SQL + `
 		ORDER BY distance_km ASC
 	`
 
-	radiusMeters := radiusKm * 1000
 	var rows []hackedDeviceRow
-	if err := s.db.Raw(query, lng, lat, userID, userID, lng, lat, radiusMeters).Scan(&rows).Error; err != nil {
+	if err := s.db.Raw(query, geoquery.Args(distance, userID, userID, within)...).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
 	var markers []MapMarker
@@ -1463,30 +1315,118 @@ func (s *Service) getHackedScanners(userID uuid.UUID, lat, lng float64, radiusKm
 	return markers, nil
 }
 
+type sharedDeviceRow struct {
+	DeployedDevice
+	DistanceKm  float64    `gorm:"column:distance_km"`
+	AccessLevel string     `gorm:"column:shared_access_level"`
+	NetworkID   *uuid.UUID `gorm:"column:shared_network_id"`
+}
+
+// sharedMarker - marker cudzieho zariadenia so zdieľaným prístupom (hackovať sa nedá, skenovať od úrovne scan)
+func (s *Service) sharedMarker(userID uuid.UUID, r sharedDeviceRow, visibilityType string) MapMarker {
+	if r.OwnerID == userID {
+		visibilityType = "owner"
+	}
+	marker := s.createMarkerFromDevice(r.DeployedDevice, visibilityType)
+	marker.DistanceKm = r.DistanceKm
+	marker.NetworkID = r.NetworkID
+	if r.OwnerID != userID {
+		marker.AccessLevel = r.AccessLevel
+		marker.CanHack = false
+		marker.CanScan = marker.CanScan && shareLevelRank[r.AccessLevel] >= shareLevelRank[AccessLevelScan]
+	}
+	return marker
+}
+
+// getSharedScanners - scannery, ktoré so mnou priamo zdieľajú priatelia
+func (s *Service) getSharedScanners(userID uuid.UUID, lat, lng float64, radiusKm float64) ([]MapMarker, error) {
+	columns := geoquery.DeviceColumns.As("dd")
+	distance := s.geo.Distance(columns, lat, lng)
+	within := s.geo.Within(columns, lat, lng, radiusKm*1000)
+	query := `
+		SELECT dd.*,
+		       ` + distance.SQL + ` / 1000.0 AS distance_km,
+		       da.access_level AS shared_access_level
+		FROM gameplay.device_access da
+		JOIN gameplay.deployed_devices dd ON dd.id = da.device_id
+		WHERE da.user_id = ?
+		  AND da.access_level IN ?
+		  AND da.expires_at > NOW()
+		  AND dd.is_active = TRUE
+		  AND ` + within.SQL + `
+		ORDER BY distance_km ASC
+	`
+
+	var rows []sharedDeviceRow
+	if err := s.db.Raw(query, geoquery.Args(distance, userID, shareLevels(AccessLevelView), within)...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	// pri viacerých zdieľaniach toho istého zariadenia ponechaj najvyššiu úroveň
+	best := make(map[uuid.UUID]int)
+	var markers []MapMarker
+	for _, r := range rows {
+		if i, ok := best[r.ID]; ok {
+			if shareLevelRank[r.AccessLevel] > shareLevelRank[markers[i].AccessLevel] {
+				markers[i] = s.sharedMarker(userID, r, "shared")
+			}
+			continue
+		}
+		best[r.ID] = len(markers)
+		markers = append(markers, s.sharedMarker(userID, r, "shared"))
+	}
+	return markers, nil
+}
+
+// getNetworkScanners - zariadenia v sieťach, v ktorých je hráč členom (vrátane vlastných)
+func (s *Service) getNetworkScanners(userID uuid.UUID, lat, lng float64, radiusKm float64) ([]MapMarker, error) {
+	columns := geoquery.DeviceColumns.As("dd")
+	distance := s.geo.Distance(columns, lat, lng)
+	within := s.geo.Within(columns, lat, lng, radiusKm*1000)
+	query := `
+		SELECT dd.*,
+		       ` + distance.SQL + ` / 1000.0 AS distance_km,
+		       nd.access_level AS shared_access_level,
+		       nd.network_id AS shared_network_id
+		FROM gameplay.device_network_members nm
+		JOIN gameplay.device_network_devices nd ON nd.network_id = nm.network_id
+		JOIN gameplay.deployed_devices dd ON dd.id = nd.device_id
+		WHERE nm.user_id = ?
+		  AND dd.is_active = TRUE
+		  AND ` + within.SQL + `
+		ORDER BY distance_km ASC
+	`
+
+	var rows []sharedDeviceRow
+	if err := s.db.Raw(query, geoquery.Args(distance, userID, within)...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var markers []MapMarker
+	for _, r := range rows {
+		markers = append(markers, s.sharedMarker(userID, r, "network"))
+	}
+	return markers, nil
+}
+
 // getScanDataScanners - cudzie scannery pre scan data (viditeľné len z veľmi blízka)
 // Hráč musí byť veľmi blízko cudzieho scanneru aby ho videl (typicky 100m)
 func (s *Service) getScanDataScanners(userID uuid.UUID, lat, lng float64, radiusKm float64) ([]MapMarker, error) {
+	distance := s.geo.Distance(geoquery.DeviceColumns, lat, lng)
+	within := s.geo.Within(geoquery.DeviceColumns, lat, lng, radiusKm*1000)
 	query := `
 		SELECT *, 
-		       ST_Distance(
-		           location,
-		           ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography
-		       ) / 1000.0 as distance_km
+		       ` + distance.SQL + ` / 1000.0 as distance_km
 		FROM gameplay.deployed_devices
 		WHERE owner_id != ? 
 		  AND status = ?
 		  AND is_active = true
-		  AND ST_DWithin(
-		      location,
-		      ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography,
-		      ?
-		  )
+		  AND ` + within.SQL + `
 		ORDER BY distance_km ASC
 	`
 
-	radiusMeters := radiusKm * 1000
 	var rows []deviceWithDistance
-	err := s.db.Raw(query, lng, lat, userID, DeviceStatusActive, lng, lat, radiusMeters).Scan(&rows).Error
+	err := s.db.Raw(query, geoquery.Args(distance, userID, DeviceStatusActive, within)...).Scan(&rows).Error
 	if err != nil {
 		return nil, err
 	}
@@ -1595,12 +1535,12 @@ type AttachBatteryResponse struct {
 
 // RemoveBattery - vyberie vybitú batériu z zariadenia
 func (s *Service) RemoveBattery(deviceID uuid.UUID, userID uuid.UUID) (*RemoveBatteryResponse, error) {
-	// 1. Načítať zariadenie
-	var device DeployedDevice
-	if err := s.db.Where("id = ? AND owner_id = ? AND is_active = true", deviceID, userID).First(&device).Error; err != nil {
+	// 1. Načítať zariadenie (vlastník alebo zdieľaný prístup maintain)
+	device, err := s.accessibleDevice(userID, deviceID, AccessLevelMaintain)
+	if err != nil || !device.IsActive {
 		return &RemoveBatteryResponse{
 			Success: false,
-			Message: "Zariadenie nebolo nájdené alebo nepatrí vám",
+			Message: "Zariadenie nebolo nájdené alebo k nemu nemáte prístup",
 		}, nil
 	}
 
@@ -1624,9 +1564,9 @@ func (s *Service) RemoveBattery(deviceID uuid.UUID, userID uuid.UUID) (*RemoveBa
 	batteryInventoryID := *device.BatteryInventoryID
 	var updatedDevice DeployedDevice
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Najprv odstrániť batériu zo zariadenia
-		if err := tx.Model(&device).Updates(map[string]interface{}{
+		if err := tx.Model(device).Updates(map[string]interface{}{
 			"battery_inventory_id": nil,
 			"battery_status":       "removed",
 			"battery_level":        0,
@@ -1674,12 +1614,12 @@ func (s *Service) RemoveBattery(deviceID uuid.UUID, userID uuid.UUID) (*RemoveBa
 
 // AttachBattery - pripojí batériu k zariadeniu
 func (s *Service) AttachBattery(deviceID uuid.UUID, userID uuid.UUID, req *AttachBatteryRequest) (*AttachBatteryResponse, error) {
-	// 1. Načítať zariadenie
-	var device DeployedDevice
-	if err := s.db.Where("id = ? AND owner_id = ? AND is_active = true", deviceID, userID).First(&device).Error; err != nil {
+	// 1. Načítať zariadenie (vlastník alebo zdieľaný prístup maintain)
+	device, err := s.accessibleDevice(userID, deviceID, AccessLevelMaintain)
+	if err != nil || !device.IsActive {
 		return &AttachBatteryResponse{
 			Success: false,
-			Message: "Zariadenie nebolo nájdené alebo nepatrí vám",
+			Message: "Zariadenie nebolo nájdené alebo k nemu nemáte prístup",
 		}, nil
 	}
 
@@ -1714,9 +1654,9 @@ func (s *Service) AttachBattery(deviceID uuid.UUID, userID uuid.UUID, req *Attac
 
 	// 5. Pripojiť batériu v transakcii
 	var updatedDevice DeployedDevice
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Pripojiť batériu k zariadeniu
-		if err := tx.Model(&device).Updates(map[string]interface{}{
+		if err := tx.Model(device).Updates(map[string]interface{}{
 			"battery_inventory_id": req.BatteryInventoryID,
 			"battery_status":       "installed",
 			"battery_level":        100,
@@ -1766,52 +1706,15 @@ func (s *Service) GetHackTools(userID uuid.UUID) ([]HackTool, error) {
 		return nil, fmt.Errorf("failed to retrieve hack tools from inventory: %w", err)
 	}
 
-	// Konvertuj inventory items na HackTool štruktúru
-	var hackTools []HackTool
+	// Konvertuj inventory items na HackTool štruktúru (vypršané čakajú na sweep, nezobrazujú sa)
+	now := time.Now()
+	hackTools := []HackTool{}
 	for _, item := range inventoryItems {
-		// Extrahuj uses_left z properties
-		usesLeft := 0
-		if ul, ok := item.Properties["uses_left"].(float64); ok {
-			usesLeft = int(ul)
-		}
-
-		// Extrahuj tool_type z properties (mapuj na povolené typy pre DB constraint)
-		toolType := "circuit_breaker" // default
-		if tt, ok := item.Properties["tool_type"].(string); ok {
-			// Mapovanie: basic_hack → circuit_breaker, advanced_hack → code_cracker, atď.
-			switch tt {
-			case "basic_hack":
-				toolType = "circuit_breaker"
-			case "advanced_hack":
-				toolType = "code_cracker"
-			case "device_claimer":
-				toolType = "stealth_infiltration"
-			case "stealth_hack":
-				toolType = "stealth_infiltration"
-			default:
-				toolType = tt // Použij ako je (ak je už mapované)
-			}
-		}
-
-		// Extrahuj name z properties
-		name := "Unknown Hack Tool"
-		if n, ok := item.Properties["name"].(string); ok {
-			name = n
-		} else if dn, ok := item.Properties["display_name"].(string); ok {
-			name = dn
+		tool := hackToolFromItem(item)
+		if tool.Expired(now) || tool.UsesLeft <= 0 {
+			continue
 		}
-
-		hackTools = append(hackTools, HackTool{
-			ID:         item.ID,
-			UserID:     item.UserID,
-			ToolType:   toolType,
-			Name:       name,
-			UsesLeft:   usesLeft,
-			ExpiresAt:  nil, // Hack tools z marketu nevypršavajú
-			Properties: datatypes.JSONMap(item.Properties),
-			CreatedAt:  item.CreatedAt,
-			UpdatedAt:  item.UpdatedAt,
-		})
+		hackTools = append(hackTools, tool)
 	}
 
 	log.Printf("🔧 Retrieved %d hack tools from inventory for user %s", len(hackTools), userID)
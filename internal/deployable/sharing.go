@@ -0,0 +1,355 @@
+package deployable
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/notifications"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Úrovne prístupu v gameplay.device_access. Hack dáva "temporary",
+// vlastník môže priateľom zdieľať view < scan < maintain (každá zahŕňa nižšie).
+const (
+	AccessLevelTemporary = "temporary" // prístup získaný hackom
+	AccessLevelView      = "view"      // vidí zariadenie na mape a jeho detail
+	AccessLevelScan      = "scan"      // + môže skenovať
+	AccessLevelMaintain  = "maintain"  // + môže meniť batériu
+
+	DefaultDeviceShareDuration = 7 * 24 * time.Hour
+	MaxDeviceShareDuration     = 30 * 24 * time.Hour
+	MaxSharesPerDevice         = 10
+)
+
+var shareLevelRank = map[string]int{
+	AccessLevelView:     1,
+	AccessLevelScan:     2,
+	AccessLevelMaintain: 3,
+}
+
+var (
+	ErrInvalidAccessLevel   = errors.New("neplatná úroveň prístupu (view, scan, maintain)")
+	ErrInvalidShareDuration = errors.New("neplatná doba zdieľania")
+	ErrShareWithSelf        = errors.New("zariadenie nemôžeš zdieľať sám so sebou")
+	ErrShareNotFriends      = errors.New("zdieľať môžeš len s priateľmi")
+	ErrDeviceShareLimit     = errors.New("zariadenie je už zdieľané s maximálnym počtom hráčov")
+	ErrDeviceShareNotFound  = errors.New("zdieľanie nebolo nájdené")
+	ErrDeviceAccessDenied   = errors.New("zariadenie nebolo nájdené alebo k nemu nemáš prístup")
+)
+
+// ShareDeviceRequest - vlastník zdieľa zariadenie s priateľom
+type ShareDeviceRequest struct {
+	UserID        uuid.UUID `json:"user_id" binding:"required"`
+	AccessLevel   string    `json:"access_level" binding:"required"`
+	DurationHours int       `json:"duration_hours"` // 0 = DefaultDeviceShareDuration
+}
+
+// DeviceShare - zdieľanie z pohľadu vlastníka
+type DeviceShare struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	AccessLevel string    `json:"access_level"`
+	GrantedAt   time.Time `json:"granted_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// SharedDevice - cudzie zariadenie, ku ktorému má hráč prístup (zdieľanie alebo sieť)
+type SharedDevice struct {
+	Device      DeployedDevice `json:"device"`
+	AccessLevel string         `json:"access_level"`
+	Via         string         `json:"via"` // "share" alebo "network"
+	NetworkID   *uuid.UUID     `json:"network_id,omitempty"`
+	ExpiresAt   *time.Time     `json:"expires_at,omitempty"`
+}
+
+// shareLevels - zdieľané úrovne, ktoré spĺňajú aspoň minLevel
+func shareLevels(minLevel string) []string {
+	var levels []string
+	for level, rank := range shareLevelRank {
+		if rank >= shareLevelRank[minLevel] {
+			levels = append(levels, level)
+		}
+	}
+	return levels
+}
+
+// higherAccessLevel - silnejšia z dvoch zdieľaných úrovní ("" = žiadna)
+func higherAccessLevel(a, b string) string {
+	if shareLevelRank[b] > shareLevelRank[a] {
+		return b
+	}
+	return a
+}
+
+// shareDuration - doba zdieľania z requestu
+func shareDuration(hours int) (time.Duration, error) {
+	if hours == 0 {
+		return DefaultDeviceShareDuration, nil
+	}
+	duration := time.Duration(hours) * time.Hour
+	if hours < 0 || duration > MaxDeviceShareDuration {
+		return 0, ErrInvalidShareDuration
+	}
+	return duration, nil
+}
+
+// sharedAccessLevel - najvyššia úroveň, ktorú hráč má k cudziemu zariadeniu cez
+// platné zdieľanie alebo spoločnú sieť ("" = žiadny prístup)
+func (s *Service) sharedAccessLevel(userID, deviceID uuid.UUID) string {
+	var direct []string
+	s.db.Model(&DeviceAccess{}).
+		Where("device_id = ? AND user_id = ? AND access_level IN ? AND expires_at > NOW()", deviceID, userID, shareLevels(AccessLevelView)).
+		Pluck("access_level", &direct)
+
+	var pooled []string
+	s.db.Table("gameplay.device_network_devices nd").
+		Joins("JOIN gameplay.device_network_members nm ON nm.network_id = nd.network_id").
+		Where("nd.device_id = ? AND nm.user_id = ?", deviceID, userID).
+		Pluck("nd.access_level", &pooled)
+
+	level := ""
+	for _, l := range append(direct, pooled...) {
+		level = higherAccessLevel(level, l)
+	}
+	return level
+}
+
+// hasSharedAccess - hráč má k zariadeniu zdieľaný prístup aspoň na úrovni minLevel
+func (s *Service) hasSharedAccess(userID, deviceID uuid.UUID, minLevel string) bool {
+	level := s.sharedAccessLevel(userID, deviceID)
+	return level != "" && shareLevelRank[level] >= shareLevelRank[minLevel]
+}
+
+// accessibleDevice - zariadenie, ktoré hráč vlastní alebo k nemu má zdieľaný prístup aspoň minLevel
+func (s *Service) accessibleDevice(userID, deviceID uuid.UUID, minLevel string) (*DeployedDevice, error) {
+	var device DeployedDevice
+	if err := s.db.Where("id = ?", deviceID).First(&device).Error; err != nil {
+		return nil, ErrDeviceAccessDenied
+	}
+	if device.OwnerID != userID && !s.hasSharedAccess(userID, deviceID, minLevel) {
+		return nil, ErrDeviceAccessDenied
+	}
+	return &device, nil
+}
+
+// ShareDevice - vlastník zdieľa zariadenie s priateľom (nahradí predchádzajúce zdieľanie)
+func (s *Service) ShareDevice(ownerID, deviceID uuid.UUID, req *ShareDeviceRequest) (*DeviceShare, error) {
+	if _, ok := shareLevelRank[req.AccessLevel]; !ok {
+		return nil, ErrInvalidAccessLevel
+	}
+	duration, err := shareDuration(req.DurationHours)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == ownerID {
+		return nil, ErrShareWithSelf
+	}
+
+	device, err := s.ownedDevice(s.db, ownerID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if friends, err := s.friends.AreFriends(ownerID, req.UserID); err != nil {
+		return nil, err
+	} else if !friends {
+		return nil, ErrShareNotFriends
+	}
+
+	var user auth.User
+	if err := s.db.Select("id", "username").Where("id = ?", req.UserID).First(&user).Error; err != nil {
+		return nil, ErrShareNotFriends
+	}
+
+	now := time.Now().UTC()
+	access := DeviceAccess{
+		ID:              uuid.New(),
+		DeviceID:        deviceID,
+		UserID:          req.UserID,
+		GrantedAt:       now,
+		ExpiresAt:       now.Add(duration),
+		AccessLevel:     req.AccessLevel,
+		GrantedByUserID: &ownerID,
+		CreatedAt:       now,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("device_id = ? AND user_id = ? AND access_level IN ?", deviceID, req.UserID, shareLevels(AccessLevelView)).
+			Delete(&DeviceAccess{}).Error; err != nil {
+			return err
+		}
+
+		var active int64
+		tx.Model(&DeviceAccess{}).
+			Where("device_id = ? AND access_level IN ? AND expires_at > NOW()", deviceID, shareLevels(AccessLevelView)).
+			Count(&active)
+		if active >= MaxSharesPerDevice {
+			return ErrDeviceShareLimit
+		}
+		return tx.Create(&access).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🤝 Device %s shared by %s with %s (%s until %s)", deviceID, ownerID, req.UserID, req.AccessLevel, access.ExpiresAt.Format(time.RFC3339))
+
+	s.notifications.Send([]uuid.UUID{req.UserID}, notifications.Notification{
+		Type:  notifications.TypeDeviceShared,
+		Title: fmt.Sprintf("Zdieľané zariadenie: %s", device.Name),
+		Body:  fmt.Sprintf("Priateľ s tebou zdieľa zariadenie (%s) do %s.", req.AccessLevel, access.ExpiresAt.Format("02.01. 15:04")),
+		Data: gameplay.JSONB{
+			"device_id":    deviceID,
+			"device_name":  device.Name,
+			"owner_id":     ownerID,
+			"access_level": req.AccessLevel,
+			"expires_at":   access.ExpiresAt,
+		},
+	}, "device_share:"+access.ID.String())
+
+	return &DeviceShare{
+		UserID:      user.ID,
+		Username:    user.Username,
+		AccessLevel: access.AccessLevel,
+		GrantedAt:   access.GrantedAt,
+		ExpiresAt:   access.ExpiresAt,
+	}, nil
+}
+
+// RevokeDeviceShare - vlastník zruší zdieľanie (prístupy z hacku ostávajú, na tie je counter-hack)
+func (s *Service) RevokeDeviceShare(ownerID, deviceID, userID uuid.UUID) error {
+	if _, err := s.ownedDevice(s.db, ownerID, deviceID); err != nil {
+		return err
+	}
+
+	result := s.db.Where("device_id = ? AND user_id = ? AND access_level IN ?", deviceID, userID, shareLevels(AccessLevelView)).
+		Delete(&DeviceAccess{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeviceShareNotFound
+	}
+
+	log.Printf("🚫 Device %s share with %s revoked by owner %s", deviceID, userID, ownerID)
+	return nil
+}
+
+// RevokeFriendAccess - po zrušení priateľstva alebo blokovaní zanikne zdieľanie zariadení
+// medzi dvojicou aj členstvo v sieťach toho druhého; po blokovaní nesmú ostať ani v spoločnej sieti.
+// Prístupy z hacku ostávajú (na tie je counter-hack).
+func (s *Service) RevokeFriendAccess(userID, otherID uuid.UUID, blocked bool) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, pair := range [][2]uuid.UUID{{userID, otherID}, {otherID, userID}} {
+			owner, viewer := pair[0], pair[1]
+			if err := tx.Where("user_id = ? AND access_level IN ? AND device_id IN (?)", viewer, shareLevels(AccessLevelView),
+				tx.Model(&DeployedDevice{}).Select("id").Where("owner_id = ?", owner)).
+				Delete(&DeviceAccess{}).Error; err != nil {
+				return err
+			}
+			if err := leaveNetworks(tx, viewer, tx.Model(&DeviceNetwork{}).Select("id").Where("owner_id = ?", owner)); err != nil {
+				return err
+			}
+		}
+		if !blocked {
+			return nil
+		}
+		// Sieť tretieho hráča - zablokovaný z nej odíde
+		return leaveNetworks(tx, otherID, tx.Model(&DeviceNetworkMember{}).Select("network_id").Where("user_id = ?", userID))
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to revoke device access between %s and %s: %v", userID, otherID, err)
+		return
+	}
+	log.Printf("🚫 Device shares and networks between %s and %s revoked (blocked=%v)", userID, otherID, blocked)
+}
+
+// GetDeviceShares - platné zdieľania zariadenia (len vlastník)
+func (s *Service) GetDeviceShares(ownerID, deviceID uuid.UUID) ([]DeviceShare, error) {
+	if _, err := s.ownedDevice(s.db, ownerID, deviceID); err != nil {
+		return nil, err
+	}
+
+	shares := []DeviceShare{}
+	err := s.db.Table("gameplay.device_access da").
+		Select("da.user_id, u.username, da.access_level, da.granted_at, da.expires_at").
+		Joins("JOIN auth.users u ON u.id = da.user_id").
+		Where("da.device_id = ? AND da.access_level IN ? AND da.expires_at > NOW()", deviceID, shareLevels(AccessLevelView)).
+		Order("da.granted_at ASC").
+		Scan(&shares).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device shares: %w", err)
+	}
+	return shares, nil
+}
+
+// GetSharedDevices - cudzie zariadenia, ku ktorým má hráč prístup cez zdieľanie alebo sieť
+func (s *Service) GetSharedDevices(userID uuid.UUID) ([]SharedDevice, error) {
+	var accesses []DeviceAccess
+	if err := s.db.Where("user_id = ? AND access_level IN ? AND expires_at > NOW()", userID, shareLevels(AccessLevelView)).
+		Find(&accesses).Error; err != nil {
+		return nil, fmt.Errorf("failed to get shared devices: %w", err)
+	}
+
+	var pooled []DeviceNetworkDevice
+	if err := s.db.Table("gameplay.device_network_devices nd").
+		Select("nd.*").
+		Joins("JOIN gameplay.device_network_members nm ON nm.network_id = nd.network_id").
+		Where("nm.user_id = ? AND nd.owner_id <> ?", userID, userID).
+		Scan(&pooled).Error; err != nil {
+		return nil, fmt.Errorf("failed to get network devices: %w", err)
+	}
+
+	byDevice := make(map[uuid.UUID]*SharedDevice)
+	var order []uuid.UUID
+	for _, a := range accesses {
+		expiresAt := a.ExpiresAt
+		if existing, ok := byDevice[a.DeviceID]; ok {
+			existing.AccessLevel = higherAccessLevel(existing.AccessLevel, a.AccessLevel)
+			continue
+		}
+		byDevice[a.DeviceID] = &SharedDevice{AccessLevel: a.AccessLevel, Via: "share", ExpiresAt: &expiresAt}
+		order = append(order, a.DeviceID)
+	}
+	for _, p := range pooled {
+		networkID := p.NetworkID
+		if existing, ok := byDevice[p.DeviceID]; ok {
+			// sieť dáva trvalý prístup, ponechaj silnejšiu úroveň
+			if shareLevelRank[p.AccessLevel] >= shareLevelRank[existing.AccessLevel] {
+				*existing = SharedDevice{AccessLevel: p.AccessLevel, Via: "network", NetworkID: &networkID}
+			}
+			continue
+		}
+		byDevice[p.DeviceID] = &SharedDevice{AccessLevel: p.AccessLevel, Via: "network", NetworkID: &networkID}
+		order = append(order, p.DeviceID)
+	}
+
+	shared := []SharedDevice{}
+	if len(order) == 0 {
+		return shared, nil
+	}
+
+	var devices []DeployedDevice
+	if err := s.db.Where("id IN ? AND owner_id <> ?", order, userID).Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to load shared devices: %w", err)
+	}
+	found := make(map[uuid.UUID]DeployedDevice, len(devices))
+	for _, d := range devices {
+		found[d.ID] = d
+	}
+	for _, id := range order {
+		device, ok := found[id]
+		if !ok {
+			continue
+		}
+		entry := *byDevice[id]
+		entry.Device = device
+		shared = append(shared, entry)
+	}
+	return shared, nil
+}
@@ -0,0 +1,106 @@
+package deployable
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestShareLevels(t *testing.T) {
+	tests := []struct {
+		minLevel string
+		want     []string
+	}{
+		{AccessLevelView, []string{AccessLevelMaintain, AccessLevelScan, AccessLevelView}},
+		{AccessLevelScan, []string{AccessLevelMaintain, AccessLevelScan}},
+		{AccessLevelMaintain, []string{AccessLevelMaintain}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.minLevel, func(t *testing.T) {
+			got := shareLevels(tt.minLevel)
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("shareLevels(%s) = %v, want %v", tt.minLevel, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("shareLevels(%s) = %v, want %v", tt.minLevel, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestHigherAccessLevel(t *testing.T) {
+	tests := []struct {
+		a, b, want string
+	}{
+		{"", AccessLevelView, AccessLevelView},
+		{AccessLevelScan, AccessLevelView, AccessLevelScan},
+		{AccessLevelScan, AccessLevelMaintain, AccessLevelMaintain},
+		{AccessLevelView, AccessLevelTemporary, AccessLevelView},
+	}
+
+	for _, tt := range tests {
+		if got := higherAccessLevel(tt.a, tt.b); got != tt.want {
+			t.Errorf("higherAccessLevel(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestShareDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		hours   int
+		want    time.Duration
+		wantErr error
+	}{
+		{"default", 0, DefaultDeviceShareDuration, nil},
+		{"one day", 24, 24 * time.Hour, nil},
+		{"maximum", 30 * 24, MaxDeviceShareDuration, nil},
+		{"too long", 30*24 + 1, 0, ErrInvalidShareDuration},
+		{"negative", -1, 0, ErrInvalidShareDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shareDuration(tt.hours)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("shareDuration(%d) = %v, %v; want %v, %v", tt.hours, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCoverageAreaKm2(t *testing.T) {
+	const lat, lng = 48.1486, 17.1077
+	// 1 km na západ/východ v stupňoch dĺžky na tejto šírke
+	kmLng := 1 / (111.32 * math.Cos(lat*math.Pi/180))
+	circle := math.Pi // r = 1 km
+
+	tests := []struct {
+		name    string
+		circles []CoverageCircle
+		want    float64
+	}{
+		{"no devices", nil, 0},
+		{"single scanner", []CoverageCircle{{lat, lng, 1}}, circle},
+		{"identical scanners count once", []CoverageCircle{{lat, lng, 1}, {lat, lng, 1}}, circle},
+		{"disjoint scanners add up", []CoverageCircle{{lat, lng, 1}, {lat, lng + 5*kmLng, 1}}, 2 * circle},
+		// dva kruhy r=1 so stredmi 1 km od seba: 2π - (2π/3 - √3/2)
+		{"overlap counted once", []CoverageCircle{{lat, lng, 1}, {lat, lng + kmLng, 1}}, 2*circle - (2*math.Pi/3 - math.Sqrt(3)/2)},
+		{"small scanner inside large", []CoverageCircle{{lat, lng, 2}, {lat, lng + 0.5*kmLng, 0.5}}, 4 * circle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := coverageAreaKm2(tt.circles)
+			if math.Abs(got-tt.want) > tt.want*0.02+1e-9 {
+				t.Errorf("coverageAreaKm2() = %.3f, want %.3f ± 2%%", got, tt.want)
+			}
+		})
+	}
+}
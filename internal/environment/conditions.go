@@ -0,0 +1,68 @@
+package environment
+
+import (
+	"hash/fnv"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Počasie
+const (
+	WeatherClear  = "clear"
+	WeatherCloudy = "cloudy"
+	WeatherRain   = "rain"
+	WeatherFog    = "fog"
+	WeatherStorm  = "storm"
+)
+
+// Conditions - aktuálne podmienky v mieste (počasie od providera)
+type Conditions struct {
+	Weather string `json:"weather"`
+	Source  string `json:"source"`
+}
+
+// ConditionsProvider - zdroj podmienok (lokálny stub, neskôr napr. weather API)
+type ConditionsProvider interface {
+	Conditions(lat, lng float64, at time.Time) (Conditions, error)
+}
+
+// LocalProvider - offline stub: deterministické "počasie" pre región (~1°) a 3-hodinové okno,
+// takže všetci hráči v okolí vidia rovnaké podmienky a výsledok sa dá zopakovať
+type LocalProvider struct {
+	Window time.Duration
+}
+
+func NewLocalProvider() *LocalProvider {
+	return &LocalProvider{Window: 3 * time.Hour}
+}
+
+// localWeatherWeights - pravdepodobnosti počasia v stube (súčet 100)
+var localWeatherWeights = []struct {
+	Weather string
+	Weight  uint64
+}{
+	{WeatherClear, 45},
+	{WeatherCloudy, 25},
+	{WeatherRain, 15},
+	{WeatherFog, 10},
+	{WeatherStorm, 5},
+}
+
+func (p *LocalProvider) Conditions(lat, lng float64, at time.Time) (Conditions, error) {
+	h := fnv.New64a()
+	h.Write([]byte(strconv.FormatInt(at.Unix()/int64(p.Window/time.Second), 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(int(math.Floor(lat)))))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.Itoa(int(math.Floor(lng)))))
+
+	roll := h.Sum64() % 100
+	for _, w := range localWeatherWeights {
+		if roll < w.Weight {
+			return Conditions{Weather: w.Weather, Source: "local"}, nil
+		}
+		roll -= w.Weight
+	}
+	return Conditions{Weather: WeatherClear, Source: "local"}, nil
+}
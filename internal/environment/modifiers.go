@@ -0,0 +1,142 @@
+package environment
+
+import "math"
+
+// Modifiers - výsledný vplyv prostredia na zónu
+type Modifiers struct {
+	ArtifactRate         float64            `json:"artifact_rate"`         // násobok šance artefaktov
+	GearRate             float64            `json:"gear_rate"`             // násobok šance gearu
+	ArtifactBoosts       map[string]float64 `json:"artifact_boosts"`       // násobok pre konkrétne artefakty
+	DangerShift          int                `json:"danger_shift"`          // o koľko stupňov sa zvýši danger level
+	DurabilityMultiplier float64            `json:"durability_multiplier"` // násobok poškodenia loadoutu
+	Effects              []string           `json:"effects"`               // aplikované pravidlá
+}
+
+// NeutralModifiers - bez vplyvu (deň, jasno)
+func NeutralModifiers() Modifiers {
+	return Modifiers{
+		ArtifactRate:         1,
+		GearRate:             1,
+		ArtifactBoosts:       map[string]float64{},
+		DurabilityMultiplier: 1,
+		Effects:              []string{},
+	}
+}
+
+// ArtifactMultiplier - celkový násobok šance pre artefakt
+func (m Modifiers) ArtifactMultiplier(artifactType string) float64 {
+	if boost, ok := m.ArtifactBoosts[artifactType]; ok {
+		return m.ArtifactRate * boost
+	}
+	return m.ArtifactRate
+}
+
+// modifier - jedno pravidlo; nulové násobky = bez zmeny
+type modifier struct {
+	ArtifactRate   float64
+	GearRate       float64
+	ArtifactBoosts map[string]float64
+	DangerShift    int
+	Durability     float64
+}
+
+func (m *Modifiers) apply(name string, mod modifier) {
+	if mod.ArtifactRate > 0 {
+		m.ArtifactRate *= mod.ArtifactRate
+	}
+	if mod.GearRate > 0 {
+		m.GearRate *= mod.GearRate
+	}
+	for artifact, boost := range mod.ArtifactBoosts {
+		if current, ok := m.ArtifactBoosts[artifact]; ok {
+			boost *= current
+		}
+		m.ArtifactBoosts[artifact] = boost
+	}
+	m.DangerShift += mod.DangerShift
+	if mod.Durability > 0 {
+		m.DurabilityMultiplier *= mod.Durability
+	}
+	m.Effects = append(m.Effects, name)
+}
+
+// phaseModifiers - vplyv dennej doby
+var phaseModifiers = map[string]modifier{
+	PhaseDawn: {Durability: 1.1},
+	PhaseDusk: {Durability: 1.1},
+	PhaseNight: {
+		GearRate: 0.8,
+		ArtifactBoosts: map[string]float64{
+			"dewdrop_pearl":   1.8,
+			"mushroom_sample": 1.3,
+			"crystal_shard":   1.5,
+			"ice_crystal":     1.5,
+			"abyss_pearl":     1.8,
+			"urban_artifact":  1.4,
+			"atomic_battery":  1.5,
+			"plutonium_core":  1.5,
+			"pure_toxin":      1.5,
+		},
+		DangerShift: 1,
+		Durability:  1.25,
+	},
+}
+
+// weatherModifiers - vplyv počasia
+var weatherModifiers = map[string]modifier{
+	WeatherRain:  {ArtifactRate: 1.1, Durability: 1.15},
+	WeatherFog:   {ArtifactRate: 1.2, GearRate: 0.9, DangerShift: 1},
+	WeatherStorm: {ArtifactRate: 1.3, GearRate: 0.8, DangerShift: 1, Durability: 1.5},
+}
+
+// effectRule - kombinácia environmental effectu biomu s fázou/počasím (prázdne = hocijaké)
+type effectRule struct {
+	Effect  string
+	Phase   string
+	Weather string
+	Mod     modifier
+}
+
+var biomeEffectRules = []effectRule{
+	{Effect: "darkness", Phase: PhaseNight, Mod: modifier{DangerShift: 1}},
+	{Effect: "wild_animals", Phase: PhaseNight, Mod: modifier{DangerShift: 1, Durability: 1.1}},
+	{Effect: "fog", Weather: WeatherFog, Mod: modifier{ArtifactRate: 1.1}},
+	{Effect: "cold_weather", Phase: PhaseNight, Mod: modifier{Durability: 1.2}},
+	{Effect: "unstable_terrain", Weather: WeatherStorm, Mod: modifier{Durability: 1.2}},
+	{Effect: "contaminated_water", Weather: WeatherRain, Mod: modifier{Durability: 1.2}},
+	{Effect: "toxic_air", Weather: WeatherRain, Mod: modifier{Durability: 1.3}},
+	{Effect: "toxic_gas", Weather: WeatherRain, Mod: modifier{Durability: 1.3}},
+	{Effect: "corrosive_damage", Weather: WeatherRain, Mod: modifier{Durability: 1.3}},
+	{Effect: "radiation_high", Weather: WeatherStorm, Mod: modifier{DangerShift: 1}},
+}
+
+// Modifiers - vplyv prostredia na zónu s danými environmental effects biomu
+func (s State) Modifiers(biomeEffects map[string]interface{}) Modifiers {
+	result := NeutralModifiers()
+
+	if mod, ok := phaseModifiers[s.Phase]; ok {
+		result.apply(s.Phase, mod)
+	}
+	if mod, ok := weatherModifiers[s.Conditions.Weather]; ok {
+		result.apply("weather:"+s.Conditions.Weather, mod)
+	}
+
+	for _, rule := range biomeEffectRules {
+		if active, _ := biomeEffects[rule.Effect].(bool); !active {
+			continue
+		}
+		if (rule.Phase != "" && rule.Phase != s.Phase) || (rule.Weather != "" && rule.Weather != s.Conditions.Weather) {
+			continue
+		}
+		result.apply("effect:"+rule.Effect, rule.Mod)
+	}
+
+	result.ArtifactRate = round3(result.ArtifactRate)
+	result.GearRate = round3(result.GearRate)
+	result.DurabilityMultiplier = round3(result.DurabilityMultiplier)
+	return result
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
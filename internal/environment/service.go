@@ -0,0 +1,51 @@
+// Package environment - modifikátory prostredia (denná doba, počasie, efekty biomu).
+//
+// Fáza dňa sa počíta z lokálneho slnečného času hráča (lat/lng + čas servera),
+// počasie dodáva pripojiteľný ConditionsProvider (predvolene offline stub).
+// Výsledné Modifiers menia šance spawnu, danger level zón a poškodenie loadoutu.
+package environment
+
+import (
+	"log"
+	"math"
+	"time"
+)
+
+// State - prostredie v danom mieste a čase
+type State struct {
+	ServerTime   time.Time  `json:"server_time"`
+	SolarTime    string     `json:"solar_time"`
+	SunElevation float64    `json:"sun_elevation"`
+	Phase        string     `json:"phase"`
+	Conditions   Conditions `json:"conditions"`
+}
+
+type Service struct {
+	provider ConditionsProvider
+}
+
+func NewService(provider ConditionsProvider) *Service {
+	if provider == nil {
+		provider = NewLocalProvider()
+	}
+	return &Service{provider: provider}
+}
+
+// At - prostredie v mieste; výpadok providera = jasno (hra nesmie stáť na počasí)
+func (s *Service) At(lat, lng float64, at time.Time) State {
+	solarHours, elevation := SolarPosition(lat, lng, at)
+
+	conditions, err := s.provider.Conditions(lat, lng, at)
+	if err != nil {
+		log.Printf("⚠️ Conditions provider failed, assuming clear weather: %v", err)
+		conditions = Conditions{Weather: WeatherClear, Source: "fallback"}
+	}
+
+	return State{
+		ServerTime:   at,
+		SolarTime:    formatSolarTime(solarHours),
+		SunElevation: math.Round(elevation*10) / 10,
+		Phase:        PhaseOf(solarHours, elevation),
+		Conditions:   conditions,
+	}
+}
@@ -0,0 +1,63 @@
+package environment
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Fázy dňa podľa výšky slnka nad obzorom
+const (
+	PhaseDay   = "day"
+	PhaseDawn  = "dawn"
+	PhaseDusk  = "dusk"
+	PhaseNight = "night"
+
+	twilightElevation = 6.0 // ± stupne okolo obzoru = súmrak
+)
+
+// SolarPosition - lokálny slnečný čas (hodiny 0-24) a výška slnka v stupňoch.
+// Počíta sa z UTC času a polohy (deklinácia + časová rovnica), bez externých dát.
+func SolarPosition(lat, lng float64, at time.Time) (float64, float64) {
+	utc := at.UTC()
+	day := float64(utc.YearDay())
+
+	// Časová rovnica v minútach
+	b := 2 * math.Pi * (day - 81) / 364
+	equationOfTime := 9.87*math.Sin(2*b) - 7.53*math.Cos(b) - 1.5*math.Sin(b)
+
+	utcMinutes := float64(utc.Hour()*60+utc.Minute()) + float64(utc.Second())/60
+	solarMinutes := math.Mod(utcMinutes+4*lng+equationOfTime, 24*60)
+	if solarMinutes < 0 {
+		solarMinutes += 24 * 60
+	}
+	solarHours := solarMinutes / 60
+
+	declination := 23.44 * math.Sin(2*math.Pi*(284+day)/365) * math.Pi / 180
+	hourAngle := (solarHours - 12) * 15 * math.Pi / 180
+	latRad := lat * math.Pi / 180
+
+	sinElevation := math.Sin(latRad)*math.Sin(declination) + math.Cos(latRad)*math.Cos(declination)*math.Cos(hourAngle)
+	elevation := math.Asin(math.Max(-1, math.Min(1, sinElevation))) * 180 / math.Pi
+
+	return solarHours, elevation
+}
+
+// PhaseOf - fáza dňa z výšky slnka; súmrak sa delí na ráno/večer podľa slnečného času
+func PhaseOf(solarHours, elevation float64) string {
+	switch {
+	case elevation >= twilightElevation:
+		return PhaseDay
+	case elevation < -twilightElevation:
+		return PhaseNight
+	case solarHours < 12:
+		return PhaseDawn
+	default:
+		return PhaseDusk
+	}
+}
+
+func formatSolarTime(solarHours float64) string {
+	minutes := int(solarHours*60) % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
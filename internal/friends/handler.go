@@ -0,0 +1,322 @@
+package friends
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"geoanomaly/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	db      *gorm.DB
+	service *Service
+}
+
+type FriendRequestBody struct {
+	UserID   string `json:"user_id,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+func NewHandler(db *gorm.DB, service *Service) *Handler {
+	return &Handler{
+		db:      db,
+		service: service,
+	}
+}
+
+// GetFriends - zoznam priateľov + čakajúce žiadosti
+func (h *Handler) GetFriends(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	friends, err := h.service.ListFriends(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friends"})
+		return
+	}
+
+	incoming, outgoing, err := h.service.PendingRequests(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch friend requests"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"friends":       friends,
+		"total_friends": len(friends),
+		"requests": gin.H{
+			"incoming": incoming,
+			"outgoing": outgoing,
+		},
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// SendFriendRequest - pošle žiadosť podľa user_id alebo username
+func (h *Handler) SendFriendRequest(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req FriendRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	targetID, ok := h.resolveTarget(c, req)
+	if !ok {
+		return
+	}
+
+	friendship, err := h.service.SendRequest(userID.(uuid.UUID), targetID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	message := "Friend request sent"
+	status := http.StatusCreated
+	if friendship.Status == StatusAccepted {
+		message = "Friend request accepted"
+		status = http.StatusOK
+	}
+
+	c.JSON(status, gin.H{
+		"message":    message,
+		"friendship": friendship,
+	})
+}
+
+// AcceptFriendRequest - adresát prijme žiadosť
+func (h *Handler) AcceptFriendRequest(c *gin.Context) {
+	h.respondToRequest(c, true)
+}
+
+// DeclineFriendRequest - adresát odmietne žiadosť
+func (h *Handler) DeclineFriendRequest(c *gin.Context) {
+	h.respondToRequest(c, false)
+}
+
+func (h *Handler) respondToRequest(c *gin.Context, accept bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	requestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+
+	friendship, err := h.service.RespondToRequest(userID.(uuid.UUID), requestID, accept)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	message := "Friend request declined"
+	if accept {
+		message = "Friend request accepted"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    message,
+		"friendship": friendship,
+	})
+}
+
+// CancelFriendRequest - odosielateľ stiahne žiadosť
+func (h *Handler) CancelFriendRequest(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	requestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request ID"})
+		return
+	}
+
+	if err := h.service.CancelRequest(userID.(uuid.UUID), requestID); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend request cancelled"})
+}
+
+// Unfriend - zruší priateľstvo (ukončí aj zdieľanie polohy)
+func (h *Handler) Unfriend(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	friendID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.service.Unfriend(userID.(uuid.UUID), friendID); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Friend removed"})
+}
+
+// GetBlocked - zoznam zablokovaných hráčov
+func (h *Handler) GetBlocked(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	blocks, err := h.service.ListBlocked(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocked users"})
+		return
+	}
+
+	blockedIDs := make([]uuid.UUID, 0, len(blocks))
+	for _, block := range blocks {
+		blockedIDs = append(blockedIDs, block.BlockedID)
+	}
+	users := map[uuid.UUID]auth.User{}
+	if len(blockedIDs) > 0 {
+		if users, err = h.service.loadUsers(blockedIDs); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocked users"})
+			return
+		}
+	}
+
+	blocked := make([]gin.H, 0, len(blocks))
+	for _, block := range blocks {
+		blocked = append(blocked, gin.H{
+			"user_id":    block.BlockedID,
+			"username":   users[block.BlockedID].Username,
+			"blocked_at": block.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"blocked": blocked,
+		"total":   len(blocks),
+	})
+}
+
+// BlockUser - zablokuje hráča
+func (h *Handler) BlockUser(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.service.Block(userID.(uuid.UUID), targetID); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User blocked"})
+}
+
+// UnblockUser - zruší blokovanie
+func (h *Handler) UnblockUser(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.service.Unblock(userID.(uuid.UUID), targetID); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unblocked"})
+}
+
+// resolveTarget - cieľ žiadosti podľa user_id alebo username
+func (h *Handler) resolveTarget(c *gin.Context, req FriendRequestBody) (uuid.UUID, bool) {
+	if req.UserID != "" {
+		targetID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return uuid.Nil, false
+		}
+		return targetID, true
+	}
+
+	if req.Username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id or username is required"})
+		return uuid.Nil, false
+	}
+
+	var user auth.User
+	if err := h.db.Select("id").First(&user, "username = ?", req.Username).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return uuid.Nil, false
+	}
+	return user.ID, true
+}
+
+// RespondError - mapovanie service chýb na HTTP odpovede (používa aj location handler)
+func RespondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, ErrRequestNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Friend request not found"})
+	case errors.Is(err, ErrShareNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "No active location share"})
+	case errors.Is(err, ErrNotFriends):
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not friends with this user"})
+	case errors.Is(err, ErrBlocked):
+		// Neprezradíme, kto koho zablokoval
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot send friend request to this user"})
+	case errors.Is(err, ErrAlreadyFriends), errors.Is(err, ErrRequestPending),
+		errors.Is(err, ErrAlreadyBlocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrSelfRequest), errors.Is(err, ErrNotBlocked):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidDuration):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":       "Invalid share duration",
+			"min_minutes": int(MinShareDuration.Minutes()),
+			"max_minutes": int(MaxShareDuration.Minutes()),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
@@ -0,0 +1,103 @@
+package friends
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Friendship statusy
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+)
+
+const (
+	// Zdieľanie polohy je vždy časovo obmedzené
+	MinShareDuration = 5 * time.Minute
+	MaxShareDuration = 24 * time.Hour
+
+	DefaultNearbyRadius = 5000.0  // metrov
+	MaxNearbyRadius     = 50000.0 // metrov
+)
+
+// Friendship - žiadosť o priateľstvo / priateľstvo medzi dvoma hráčmi (jeden riadok na dvojicu)
+type Friendship struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	RequesterID uuid.UUID  `json:"requester_id" gorm:"type:uuid;not null;index"`
+	AddresseeID uuid.UUID  `json:"addressee_id" gorm:"type:uuid;not null;index"`
+	Status      string     `json:"status" gorm:"size:20;not null;default:'pending'"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+}
+
+func (Friendship) TableName() string {
+	return "auth.friendships"
+}
+
+// OtherUser - druhá strana priateľstva z pohľadu userID
+func (f *Friendship) OtherUser(userID uuid.UUID) uuid.UUID {
+	if f.RequesterID == userID {
+		return f.AddresseeID
+	}
+	return f.RequesterID
+}
+
+// Block - hráč zablokoval iného hráča (platí obojsmerne pre viditeľnosť)
+type Block struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	BlockerID uuid.UUID `json:"blocker_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_blocks_pair"`
+	BlockedID uuid.UUID `json:"blocked_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_blocks_pair;index"`
+}
+
+func (Block) TableName() string {
+	return "auth.user_blocks"
+}
+
+// LocationShare - časovo obmedzené povolenie: Owner zdieľa polohu s Viewer
+type LocationShare struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	OwnerID   uuid.UUID  `json:"owner_id" gorm:"type:uuid;not null;index"`
+	ViewerID  uuid.UUID  `json:"viewer_id" gorm:"type:uuid;not null;index"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (LocationShare) TableName() string {
+	return "auth.location_shares"
+}
+
+// IsActive - zdieľanie ešte platí
+func (s *LocationShare) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// FriendInfo - priateľ v zozname
+type FriendInfo struct {
+	FriendshipID  uuid.UUID  `json:"friendship_id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Username      string     `json:"username"`
+	Tier          int        `json:"tier"`
+	Level         int        `json:"level"`
+	Since         *time.Time `json:"since,omitempty"`
+	SharingWithMe bool       `json:"sharing_with_me"`
+	IShareWith    bool       `json:"i_share_with"`
+}
+
+// NearbyFriend - priateľ, ktorý so mnou práve zdieľa polohu
+type NearbyFriend struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	Username       string     `json:"username"`
+	Latitude       float64    `json:"latitude"`
+	Longitude      float64    `json:"longitude"`
+	Accuracy       float64    `json:"accuracy,omitempty"`
+	DistanceMeters float64    `json:"distance_meters"`
+	ZoneID         *uuid.UUID `json:"zone_id,omitempty"`
+	ZoneName       string     `json:"zone_name,omitempty"`
+	LastSeen       time.Time  `json:"last_seen"`
+	IsOnline       bool       `json:"is_online"`
+	ShareExpiresAt time.Time  `json:"share_expires_at"`
+}
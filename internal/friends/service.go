@@ -0,0 +1,601 @@
+package friends
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/gameplay"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrSelfRequest     = errors.New("cannot befriend yourself")
+	ErrBlocked         = errors.New("user is blocked")
+	ErrAlreadyFriends  = errors.New("already friends")
+	ErrRequestPending  = errors.New("friend request already pending")
+	ErrRequestNotFound = errors.New("friend request not found")
+	ErrNotFriends      = errors.New("not friends")
+	ErrAlreadyBlocked  = errors.New("user already blocked")
+	ErrNotBlocked      = errors.New("user is not blocked")
+	ErrInvalidDuration = errors.New("invalid share duration")
+	ErrShareNotFound   = errors.New("no active location share")
+)
+
+type Service struct {
+	db *gorm.DB
+}
+
+var relationEndHook func(userID, otherID uuid.UUID, blocked bool)
+
+// SetRelationEndHook - volá sa po zrušení priateľstva alebo blokovaní (napr. odobratie zdieľaných zariadení)
+func SetRelationEndHook(fn func(userID, otherID uuid.UUID, blocked bool)) {
+	relationEndHook = fn
+}
+
+func notifyRelationEnd(userID, otherID uuid.UUID, blocked bool) {
+	if relationEndHook != nil {
+		relationEndHook(userID, otherID, blocked)
+	}
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// ==========================================
+// FRIEND REQUESTS
+// ==========================================
+
+// SendRequest - pošle žiadosť; ak už existuje opačná čakajúca žiadosť, rovno ju prijme
+func (s *Service) SendRequest(fromID, toID uuid.UUID) (*Friendship, error) {
+	if fromID == toID {
+		return nil, ErrSelfRequest
+	}
+
+	var target auth.User
+	if err := s.db.Select("id").First(&target, "id = ? AND is_active = true", toID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	blocked, err := s.IsBlocked(fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrBlocked
+	}
+
+	var friendship Friendship
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findFriendship(tx, fromID, toID)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			switch {
+			case existing.Status == StatusAccepted:
+				return ErrAlreadyFriends
+			case existing.RequesterID == fromID:
+				return ErrRequestPending
+			}
+
+			// Druhá strana už poslala žiadosť - prijmi ju
+			now := time.Now()
+			existing.Status = StatusAccepted
+			existing.AcceptedAt = &now
+			if err := tx.Save(existing).Error; err != nil {
+				return err
+			}
+			friendship = *existing
+			return nil
+		}
+
+		friendship = Friendship{
+			RequesterID: fromID,
+			AddresseeID: toID,
+			Status:      StatusPending,
+		}
+		return tx.Create(&friendship).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &friendship, nil
+}
+
+// RespondToRequest - adresát prijme alebo odmietne čakajúcu žiadosť (odmietnutá sa zmaže)
+func (s *Service) RespondToRequest(userID, requestID uuid.UUID, accept bool) (*Friendship, error) {
+	var friendship Friendship
+	err := s.db.Where("id = ? AND addressee_id = ? AND status = ?", requestID, userID, StatusPending).
+		First(&friendship).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, err
+	}
+
+	if !accept {
+		if err := s.db.Delete(&friendship).Error; err != nil {
+			return nil, err
+		}
+		return &friendship, nil
+	}
+
+	now := time.Now()
+	friendship.Status = StatusAccepted
+	friendship.AcceptedAt = &now
+	if err := s.db.Save(&friendship).Error; err != nil {
+		return nil, err
+	}
+
+	return &friendship, nil
+}
+
+// CancelRequest - odosielateľ stiahne vlastnú čakajúcu žiadosť
+func (s *Service) CancelRequest(userID, requestID uuid.UUID) error {
+	result := s.db.Where("id = ? AND requester_id = ? AND status = ?", requestID, userID, StatusPending).
+		Delete(&Friendship{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRequestNotFound
+	}
+	return nil
+}
+
+// Unfriend - zruší priateľstvo a všetky zdieľania polohy medzi dvojicou (zariadenia cez relationEndHook)
+func (s *Service) Unfriend(userID, friendID uuid.UUID) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("status = ? AND ((requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?))",
+			StatusAccepted, userID, friendID, friendID, userID).
+			Delete(&Friendship{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFriends
+		}
+		return revokeSharesBetween(tx, userID, friendID)
+	})
+	if err != nil {
+		return err
+	}
+	notifyRelationEnd(userID, friendID, false)
+	return nil
+}
+
+// ==========================================
+// BLOCKING
+// ==========================================
+
+// Block - zablokuje hráča; zruší priateľstvo, čakajúce žiadosti aj zdieľanie polohy (zariadenia cez relationEndHook)
+func (s *Service) Block(userID, targetID uuid.UUID) error {
+	if userID == targetID {
+		return ErrSelfRequest
+	}
+
+	var target auth.User
+	if err := s.db.Select("id").First(&target, "id = ?", targetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Block{}).Where("blocker_id = ? AND blocked_id = ?", userID, targetID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAlreadyBlocked
+		}
+
+		if err := tx.Create(&Block{BlockerID: userID, BlockedID: targetID}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)",
+			userID, targetID, targetID, userID).
+			Delete(&Friendship{}).Error; err != nil {
+			return err
+		}
+
+		return revokeSharesBetween(tx, userID, targetID)
+	})
+	if err != nil {
+		return err
+	}
+	notifyRelationEnd(userID, targetID, true)
+	return nil
+}
+
+// Unblock - zruší blokovanie (priateľstvo sa neobnoví)
+func (s *Service) Unblock(userID, targetID uuid.UUID) error {
+	result := s.db.Where("blocker_id = ? AND blocked_id = ?", userID, targetID).Delete(&Block{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotBlocked
+	}
+	return nil
+}
+
+// IsBlocked - true ak ktorýkoľvek z dvojice zablokoval druhého
+func (s *Service) IsBlocked(a, b uuid.UUID) (bool, error) {
+	var count int64
+	err := s.db.Model(&Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// BlockedUserIDs - všetci hráči, ktorých userID zablokoval alebo ktorí zablokovali jeho
+func (s *Service) BlockedUserIDs(userID uuid.UUID) (map[uuid.UUID]bool, error) {
+	var blocks []Block
+	if err := s.db.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make(map[uuid.UUID]bool, len(blocks))
+	for _, block := range blocks {
+		if block.BlockerID == userID {
+			ids[block.BlockedID] = true
+		} else {
+			ids[block.BlockerID] = true
+		}
+	}
+	return ids, nil
+}
+
+// ListBlocked - hráči, ktorých userID zablokoval
+func (s *Service) ListBlocked(userID uuid.UUID) ([]Block, error) {
+	var blocks []Block
+	err := s.db.Where("blocker_id = ?", userID).Order("created_at DESC").Find(&blocks).Error
+	return blocks, err
+}
+
+// ==========================================
+// LISTING
+// ==========================================
+
+// ListFriends - prijaté priateľstvá vrátane stavu zdieľania polohy
+func (s *Service) ListFriends(userID uuid.UUID) ([]FriendInfo, error) {
+	var friendships []Friendship
+	if err := s.db.Where("status = ? AND (requester_id = ? OR addressee_id = ?)", StatusAccepted, userID, userID).
+		Order("accepted_at DESC").
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+
+	friends := make([]FriendInfo, 0, len(friendships))
+	if len(friendships) == 0 {
+		return friends, nil
+	}
+
+	friendIDs := make([]uuid.UUID, 0, len(friendships))
+	for _, f := range friendships {
+		friendIDs = append(friendIDs, f.OtherUser(userID))
+	}
+
+	users, err := s.loadUsers(friendIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var shares []LocationShare
+	if err := s.db.Where("revoked_at IS NULL AND expires_at > ? AND (owner_id = ? OR viewer_id = ?)", now, userID, userID).
+		Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	sharingWithMe := make(map[uuid.UUID]bool)
+	iShareWith := make(map[uuid.UUID]bool)
+	for _, share := range shares {
+		if share.ViewerID == userID {
+			sharingWithMe[share.OwnerID] = true
+		} else {
+			iShareWith[share.ViewerID] = true
+		}
+	}
+
+	for _, f := range friendships {
+		friendID := f.OtherUser(userID)
+		user, ok := users[friendID]
+		if !ok {
+			continue
+		}
+		friends = append(friends, FriendInfo{
+			FriendshipID:  f.ID,
+			UserID:        friendID,
+			Username:      user.Username,
+			Tier:          user.Tier,
+			Level:         user.Level,
+			Since:         f.AcceptedAt,
+			SharingWithMe: sharingWithMe[friendID],
+			IShareWith:    iShareWith[friendID],
+		})
+	}
+
+	return friends, nil
+}
+
+// PendingRequests - prichádzajúce a odoslané čakajúce žiadosti
+func (s *Service) PendingRequests(userID uuid.UUID) (incoming []Friendship, outgoing []Friendship, err error) {
+	var pending []Friendship
+	if err = s.db.Where("status = ? AND (requester_id = ? OR addressee_id = ?)", StatusPending, userID, userID).
+		Order("created_at DESC").
+		Find(&pending).Error; err != nil {
+		return nil, nil, err
+	}
+
+	incoming = []Friendship{}
+	outgoing = []Friendship{}
+	for _, f := range pending {
+		if f.AddresseeID == userID {
+			incoming = append(incoming, f)
+		} else {
+			outgoing = append(outgoing, f)
+		}
+	}
+	return incoming, outgoing, nil
+}
+
+// AreFriends - prijaté priateľstvo medzi dvojicou
+func (s *Service) AreFriends(a, b uuid.UUID) (bool, error) {
+	existing, err := findFriendship(s.db, a, b)
+	if err != nil {
+		return false, err
+	}
+	return existing != nil && existing.Status == StatusAccepted, nil
+}
+
+// ==========================================
+// LOCATION SHARING
+// ==========================================
+
+// ShareLocation - owner zdieľa polohu s priateľom na danú dobu (nahradí predchádzajúce zdieľanie)
+func (s *Service) ShareLocation(ownerID, viewerID uuid.UUID, duration time.Duration) (*LocationShare, error) {
+	if duration < MinShareDuration || duration > MaxShareDuration {
+		return nil, ErrInvalidDuration
+	}
+
+	friends, err := s.AreFriends(ownerID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !friends {
+		return nil, ErrNotFriends
+	}
+
+	now := time.Now()
+	share := LocationShare{
+		OwnerID:   ownerID,
+		ViewerID:  viewerID,
+		ExpiresAt: now.Add(duration),
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&LocationShare{}).
+			Where("owner_id = ? AND viewer_id = ? AND revoked_at IS NULL AND expires_at > ?", ownerID, viewerID, now).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&share).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &share, nil
+}
+
+// StopSharing - owner predčasne ukončí zdieľanie s viewerom
+func (s *Service) StopSharing(ownerID, viewerID uuid.UUID) error {
+	now := time.Now()
+	result := s.db.Model(&LocationShare{}).
+		Where("owner_id = ? AND viewer_id = ? AND revoked_at IS NULL AND expires_at > ?", ownerID, viewerID, now).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// ActiveShares - aktívne zdieľania, ktoré userID poskytuje (outgoing) a dostáva (incoming)
+func (s *Service) ActiveShares(userID uuid.UUID) (outgoing []LocationShare, incoming []LocationShare, err error) {
+	var shares []LocationShare
+	if err = s.db.Where("revoked_at IS NULL AND expires_at > ? AND (owner_id = ? OR viewer_id = ?)", time.Now(), userID, userID).
+		Order("expires_at ASC").
+		Find(&shares).Error; err != nil {
+		return nil, nil, err
+	}
+
+	outgoing = []LocationShare{}
+	incoming = []LocationShare{}
+	for _, share := range shares {
+		if share.OwnerID == userID {
+			outgoing = append(outgoing, share)
+		} else {
+			incoming = append(incoming, share)
+		}
+	}
+	return outgoing, incoming, nil
+}
+
+// NearbyFriends - priatelia, ktorí so mnou práve zdieľajú polohu, zoradení podľa vzdialenosti
+func (s *Service) NearbyFriends(viewerID uuid.UUID, lat, lng, radius float64) ([]NearbyFriend, error) {
+	now := time.Now()
+
+	var shares []LocationShare
+	if err := s.db.Where("viewer_id = ? AND revoked_at IS NULL AND expires_at > ?", viewerID, now).
+		Find(&shares).Error; err != nil {
+		return nil, err
+	}
+
+	result := []NearbyFriend{}
+	if len(shares) == 0 {
+		return result, nil
+	}
+
+	shareExpiry := make(map[uuid.UUID]time.Time, len(shares))
+	ownerIDs := make([]uuid.UUID, 0, len(shares))
+	for _, share := range shares {
+		if _, seen := shareExpiry[share.OwnerID]; !seen {
+			ownerIDs = append(ownerIDs, share.OwnerID)
+		}
+		if share.ExpiresAt.After(shareExpiry[share.OwnerID]) {
+			shareExpiry[share.OwnerID] = share.ExpiresAt
+		}
+	}
+
+	// Zdieľanie platí len kým sú stále priatelia a nikto nikoho nezablokoval
+	var friendships []Friendship
+	if err := s.db.Where("status = ? AND ((requester_id = ? AND addressee_id IN ?) OR (addressee_id = ? AND requester_id IN ?))",
+		StatusAccepted, viewerID, ownerIDs, viewerID, ownerIDs).
+		Find(&friendships).Error; err != nil {
+		return nil, err
+	}
+	stillFriends := make(map[uuid.UUID]bool, len(friendships))
+	for _, f := range friendships {
+		stillFriends[f.OtherUser(viewerID)] = true
+	}
+
+	blocked, err := s.BlockedUserIDs(viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []auth.PlayerSession
+	if err := s.db.Where("user_id IN ?", ownerIDs).Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	users, err := s.loadUsers(ownerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	zoneIDs := []uuid.UUID{}
+	for _, session := range sessions {
+		if session.CurrentZone != nil {
+			zoneIDs = append(zoneIDs, *session.CurrentZone)
+		}
+	}
+	zoneNames := make(map[uuid.UUID]string)
+	if len(zoneIDs) > 0 {
+		var zones []gameplay.Zone
+		if err := s.db.Select("id", "name").Where("id IN ?", zoneIDs).Find(&zones).Error; err != nil {
+			return nil, err
+		}
+		for _, zone := range zones {
+			zoneNames[zone.ID] = zone.Name
+		}
+	}
+
+	for _, session := range sessions {
+		if !stillFriends[session.UserID] || blocked[session.UserID] {
+			continue
+		}
+		user, ok := users[session.UserID]
+		if !ok {
+			continue
+		}
+		if session.LastLocationLatitude == 0 && session.LastLocationLongitude == 0 {
+			continue
+		}
+
+		distance := haversine(lat, lng, session.LastLocationLatitude, session.LastLocationLongitude)
+		if distance > radius {
+			continue
+		}
+
+		friend := NearbyFriend{
+			UserID:         session.UserID,
+			Username:       user.Username,
+			Latitude:       session.LastLocationLatitude,
+			Longitude:      session.LastLocationLongitude,
+			Accuracy:       session.LastLocationAccuracy,
+			DistanceMeters: math.Round(distance),
+			ZoneID:         session.CurrentZone,
+			LastSeen:       session.LastSeen,
+			IsOnline:       session.IsOnline && now.Sub(session.LastSeen) < 5*time.Minute,
+			ShareExpiresAt: shareExpiry[session.UserID],
+		}
+		if session.CurrentZone != nil {
+			friend.ZoneName = zoneNames[*session.CurrentZone]
+		}
+		result = append(result, friend)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DistanceMeters < result[j].DistanceMeters
+	})
+
+	return result, nil
+}
+
+// ==========================================
+// HELPERS
+// ==========================================
+
+// findFriendship - riadok pre dvojicu bez ohľadu na smer (nil ak neexistuje)
+func findFriendship(tx *gorm.DB, a, b uuid.UUID) (*Friendship, error) {
+	var friendship Friendship
+	err := tx.Where("(requester_id = ? AND addressee_id = ?) OR (requester_id = ? AND addressee_id = ?)", a, b, b, a).
+		First(&friendship).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &friendship, nil
+}
+
+func revokeSharesBetween(tx *gorm.DB, a, b uuid.UUID) error {
+	return tx.Model(&LocationShare{}).
+		Where("revoked_at IS NULL AND ((owner_id = ? AND viewer_id = ?) OR (owner_id = ? AND viewer_id = ?))", a, b, b, a).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (s *Service) loadUsers(ids []uuid.UUID) (map[uuid.UUID]auth.User, error) {
+	var users []auth.User
+	if err := s.db.Select("id", "username", "tier", "level").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]auth.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	return byID, nil
+}
+
+// haversine - vzdialenosť v metroch
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 6371000.0
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
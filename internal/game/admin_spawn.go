@@ -0,0 +1,174 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"geoanomaly/internal/audit"
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/gameplay"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ============================================
+// ADMIN SPAWN ENDPOINTS
+// ============================================
+
+// SpawnArtifact - admin spawn artefaktov v konkrétnej zóne
+func (h *Handler) SpawnArtifact(c *gin.Context) {
+	h.adminSpawn(c, "artifact")
+}
+
+// SpawnGear - admin spawn gear v konkrétnej zóne
+func (h *Handler) SpawnGear(c *gin.Context) {
+	h.adminSpawn(c, "gear")
+}
+
+func (h *Handler) adminSpawn(c *gin.Context, itemType string) {
+	zoneID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zone ID format"})
+		return
+	}
+
+	var req AdminSpawnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Count == 0 {
+		req.Count = 1
+	}
+	if req.Count < 0 || req.Count > MaxAdminSpawnCount {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Count must be between 1 and %d", MaxAdminSpawnCount)})
+		return
+	}
+
+	req.Rarity = strings.ToLower(strings.TrimSpace(req.Rarity))
+	if req.Rarity != "" && !validItemRarities[req.Rarity] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid rarity: %s", req.Rarity)})
+		return
+	}
+
+	var zone gameplay.Zone
+	if err := h.db.First(&zone, "id = ? AND is_active = true", zoneID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zone not found"})
+		return
+	}
+
+	if req.Location != nil {
+		if !IsValidGPSCoordinate(req.Location.Latitude, req.Location.Longitude) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid GPS coordinates"})
+			return
+		}
+		distance := CalculateDistance(zone.Location.Latitude, zone.Location.Longitude, req.Location.Latitude, req.Location.Longitude)
+		if distance > float64(zone.RadiusMeters) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":         "Location is outside of zone",
+				"distance_m":    distance,
+				"zone_radius_m": zone.RadiusMeters,
+			})
+			return
+		}
+	}
+
+	adminID, _ := c.Get("user_id")
+	actorID, _ := adminID.(uuid.UUID)
+	actorUsername := c.GetString("username")
+
+	properties := gameplay.JSONB{}
+	for k, v := range req.Properties {
+		properties[k] = v
+	}
+	properties["spawned_by_admin"] = actorUsername
+	properties["spawned_by_admin_id"] = actorID.String()
+	if req.Reason != "" {
+		properties["admin_reason"] = req.Reason
+	}
+
+	opts := itemSpawnOptions{
+		Location:   req.Location,
+		Rarity:     req.Rarity,
+		Properties: properties,
+		Spawner:    "admin",
+		Reason:     "admin_spawn",
+		Force:      true,
+	}
+
+	var (
+		created []interface{}
+		ids     []string
+	)
+	for i := 0; i < req.Count; i++ {
+		switch itemType {
+		case "artifact":
+			artifact, err := h.createArtifactInZone(zone, req.Type, zone.Biome, zone.TierRequired, opts)
+			if err != nil {
+				log.Printf("❌ Admin artifact spawn failed (%s in %s): %v", req.Type, zone.Name, err)
+				continue
+			}
+			created = append(created, artifact)
+			ids = append(ids, artifact.ID.String())
+		case "gear":
+			gear, err := h.createGearInZone(zone, req.Type, zone.Biome, zone.TierRequired, opts)
+			if err != nil {
+				log.Printf("❌ Admin gear spawn failed (%s in %s): %v", req.Type, zone.Name, err)
+				continue
+			}
+			created = append(created, gear)
+			ids = append(ids, gear.ID.String())
+		}
+	}
+
+	if len(created) == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to spawn %s", itemType)})
+		return
+	}
+
+	action := audit.ActionSpawnArtifact
+	if itemType == "gear" {
+		action = audit.ActionSpawnGear
+	}
+	record := &audit.AdminAction{
+		ActorID:       actorID,
+		ActorUsername: actorUsername,
+		Action:        action,
+		TargetType:    audit.TargetZone,
+		TargetID:      &zone.ID,
+		Reason:        req.Reason,
+		After: auth.JSONB{
+			"item_type": itemType,
+			"type":      req.Type,
+			"item_ids":  ids,
+			"count":     len(ids),
+		},
+		Metadata: auth.JSONB{
+			"zone_name":  zone.Name,
+			"requested":  req.Count,
+			"rarity":     req.Rarity,
+			"location":   req.Location,
+			"properties": req.Properties,
+		},
+	}
+	if err := h.audit.Record(nil, record); err != nil {
+		log.Printf("⚠️ Spawned %d %s in %s but audit record failed", len(ids), itemType, zone.Name)
+	}
+
+	log.Printf("🎁 Admin %s spawned %d× %s (%s) in zone %s", actorUsername, len(ids), req.Type, itemType, zone.Name)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   fmt.Sprintf("Spawned %d %s item(s)", len(created), itemType),
+		"zone_id":   zone.ID,
+		"zone_name": zone.Name,
+		"item_type": itemType,
+		"requested": req.Count,
+		"spawned":   len(created),
+		"items":     created,
+		"audit_id":  record.ID,
+	})
+}
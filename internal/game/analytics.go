@@ -0,0 +1,44 @@
+package game
+
+import (
+	"geoanomaly/internal/analytics"
+	"geoanomaly/internal/gameplay"
+
+	"github.com/google/uuid"
+)
+
+// recordSpawn - item v zóne: zone stats + analytics event log
+func (h *Handler) recordSpawn(zone gameplay.Zone, itemType, itemKey, rarity string) {
+	h.zoneStats.RecordSpawn(zone, itemType, rarity)
+	analytics.Track(h.db, zoneEvent(analytics.EventItemSpawned, zone, nil, itemType, itemKey, rarity))
+}
+
+// recordCollect - hráč vyzbieral item: zone stats + analytics event log
+func (h *Handler) recordCollect(zone gameplay.Zone, userID uuid.UUID, tier int, itemType, itemKey, rarity string) {
+	h.zoneStats.RecordCollect(zone, userID, itemType, rarity)
+	event := zoneEvent(analytics.EventItemCollected, zone, &userID, itemType, itemKey, rarity)
+	event.Tier = &tier
+	analytics.Track(h.db, event)
+}
+
+// trackZone - udalosť viazaná na zónu bez itemu (spawn, vstup)
+func (h *Handler) trackZone(eventType string, zone gameplay.Zone, userID *uuid.UUID, tier *int) {
+	event := zoneEvent(eventType, zone, userID, "", "", "")
+	event.Tier = tier
+	analytics.Track(h.db, event)
+}
+
+func zoneEvent(eventType string, zone gameplay.Zone, userID *uuid.UUID, itemType, itemKey, rarity string) analytics.Event {
+	zoneID := zone.ID
+	zoneTier := zone.TierRequired
+	return analytics.Event{
+		Type:     eventType,
+		UserID:   userID,
+		ZoneID:   &zoneID,
+		Biome:    zone.Biome,
+		ZoneTier: &zoneTier,
+		ItemType: itemType,
+		ItemKey:  itemKey,
+		Rarity:   rarity,
+	}
+}
@@ -154,50 +154,9 @@ func GetArtifactImageFilename(artifactType string) string {
 	return "default_artifact.jpg" // fallback
 }
 
-// Get artifact rarity based on type and tier
+// GetArtifactRarity - rarity podľa typu a tieru zóny z aktívnej spawn konfigurácie
 func GetArtifactRarity(artifactType string, tier int) string {
-	// Exclusive artifacts are always legendary
-	exclusiveArtifacts := []string{
-		"plutonium_core", "reactor_fragment", "control_rod",
-		"pure_toxin", "experimental_serum", "bio_weapon", //"shadow_essence",
-	}
-	for _, exclusive := range exclusiveArtifacts {
-		if artifactType == exclusive {
-			return "legendary"
-		}
-	}
-
-	// High-tier artifacts
-	highTierArtifacts := []string{
-		"uranium_ore", "chemical_compound", "atomic_battery",
-		"nuclear_fuel", "lab_equipment", "electronic_component", //"moon_shard",
-	}
-	for _, highTier := range highTierArtifacts {
-		if artifactType == highTier {
-			if tier >= 3 {
-				return "epic"
-			}
-			return "rare"
-		}
-	}
-
-	// Medium-tier artifacts
-	mediumTierArtifacts := []string{
-		"crystal_shard", "steel_ingot", "electronics",
-		"machinery_parts", "toxic_waste", "contaminated_soil", //"owl_feather",
-		//"midnight_berry",
-	}
-	for _, mediumTier := range mediumTierArtifacts {
-		if artifactType == mediumTier {
-			if tier >= 2 {
-				return "rare"
-			}
-			return "common"
-		}
-	}
-
-	// Default to common
-	return "common"
+	return currentSpawnConfig().artifactRarity(artifactType, tier)
 }
 
 // Artifact filtering functions
@@ -230,23 +189,12 @@ func (h *Handler) getRequiredTierForRarity(rarity string) int {
 }
 
 func (h *Handler) canAccessBiome(biome string, userTier int) bool {
-	biomeRequirements := map[string]int{
-		BiomeForest:      0,
-		BiomeMountain:    1,
-		BiomeUrban:       1,
-		BiomeWater:       1,
-		BiomeIndustrial:  2,
-		BiomeRadioactive: 3,
-		BiomeChemical:    4,
-		//BiomeNight:       2, // ← pridané pre nočný biome
-	}
-
-	requiredTier, exists := biomeRequirements[biome]
+	template, exists := currentSpawnConfig().Biomes[biome]
 	if !exists {
 		return true // Unknown biome, allow access
 	}
 
-	return userTier >= requiredTier
+	return userTier >= template.MinTierRequired
 }
 
 func (h *Handler) filterArtifactsByTier(artifacts []gameplay.Artifact, userTier int) []gameplay.Artifact {
@@ -1,7 +1,12 @@
 package game
 
-// Zone templates for each biome
+// GetZoneTemplate - template biomu z aktívnej spawn konfigurácie (neznámy biome = les)
 func GetZoneTemplate(biome string) ZoneTemplate {
+	return currentSpawnConfig().template(biome)
+}
+
+// defaultZoneTemplates - seed templates pre každý biome (verzia 1 spawn konfigurácie)
+func defaultZoneTemplates() map[string]ZoneTemplate {
 	templates := map[string]ZoneTemplate{
 		BiomeForest: {
 			Names: []string{
@@ -270,8 +275,5 @@ func GetZoneTemplate(biome string) ZoneTemplate {
 		//},
 	}
 
-	if template, exists := templates[biome]; exists {
-		return template
-	}
-	return templates[BiomeForest] // Default fallback
+	return templates
 }
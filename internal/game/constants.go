@@ -9,7 +9,14 @@ const (
 	BiomeIndustrial  = "industrial"
 	BiomeRadioactive = "radioactive"
 	BiomeChemical    = "chemical"
-	//BiomeNight       = "night"
+	//BiomeNight       = "night" // noc je fáza prostredia (environment.PhaseNight), nie samostatný biome
+)
+
+// Zone type constants
+const (
+	ZoneTypeStatic  = "static"
+	ZoneTypeDynamic = "dynamic"
+	ZoneTypeEvent   = "event"
 )
 
 // Danger level constants
@@ -32,9 +39,18 @@ const (
 	ZoneMaxExpiryHours = 16
 	MinZoneDistance    = 250.0 // minimálna vzdialenosť medzi zónami v metroch
 
+	// Vstup do zóny - tolerancia GPS nepresnosti nad polomer zóny
+	EnterZoneAccuracyTolerance = 50.0
+
 	//Tier 0 zóny a ich životnosť
 	Tier0MinExpiryMinutes = 90  // 1:30 hod
 	Tier0MaxExpiryMinutes = 120 // 2 hod
+
+	// Event zóny (admin)
+	MaxEventDropQuantity = 50  // max kusov jedného záznamu v drop table
+	MaxEventDropTotal    = 200 // max kusov celkovo v drop table
+	MaxEventZoneRadius   = 5000
+	MaxAdminSpawnCount   = 50 // max kusov na jeden admin spawn request
 )
 
 // NEW: Tier-based spawning distance ranges (in meters)
@@ -0,0 +1,59 @@
+package game
+
+import (
+	"time"
+
+	"geoanomaly/internal/environment"
+)
+
+// dangerLevelOrder - poradie danger levelov pre posun modifikátorom prostredia
+var dangerLevelOrder = []string{DangerLow, DangerMedium, DangerHigh, DangerExtreme}
+
+// environmentAt - prostredie v mieste a modifikátory pre daný biome
+func (h *Handler) environmentAt(lat, lng float64, biome string, at time.Time) (environment.State, environment.Modifiers) {
+	state := h.environment.At(lat, lng, at)
+	return state, state.Modifiers(GetZoneTemplate(biome).EnvironmentalEffects)
+}
+
+// applyEnvironment - kópia templatu so šancami upravenými prostredím (zdieľané mapy configu sa nemenia)
+func applyEnvironment(template ZoneTemplate, mods environment.Modifiers) ZoneTemplate {
+	artifactRates := make(map[string]float64, len(template.ArtifactSpawnRates))
+	for artifact, rate := range template.ArtifactSpawnRates {
+		artifactRates[artifact] = rate * mods.ArtifactMultiplier(artifact)
+	}
+	gearRates := make(map[string]float64, len(template.GearSpawnRates))
+	for gear, rate := range template.GearSpawnRates {
+		gearRates[gear] = rate * mods.GearRate
+	}
+
+	template.ArtifactSpawnRates = artifactRates
+	template.GearSpawnRates = gearRates
+	return template
+}
+
+// effectiveDangerLevel - danger level zóny posunutý prostredím (max extreme, neznáme ostávajú)
+func effectiveDangerLevel(level string, shift int) string {
+	for i, l := range dangerLevelOrder {
+		if l == level {
+			return dangerLevelOrder[min(i+max(shift, 0), len(dangerLevelOrder)-1)]
+		}
+	}
+	return level
+}
+
+// dangerLevelValue - číselná hodnota danger levelu pre poškodenie loadoutu
+func dangerLevelValue(level string) int {
+	switch level {
+	case DangerLow:
+		return 1
+	case DangerMedium:
+		return 3
+	case DangerHigh:
+		return 5
+	case DangerExtreme:
+		return 8
+	case "deadly":
+		return 10
+	}
+	return 1
+}
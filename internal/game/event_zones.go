@@ -0,0 +1,516 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"geoanomaly/internal/analytics"
+	"geoanomaly/internal/gameplay"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ============================================
+// ADMIN EVENT ZONES
+// ============================================
+
+var validItemRarities = map[string]bool{
+	"common":    true,
+	"rare":      true,
+	"epic":      true,
+	"legendary": true,
+}
+
+// CreateEventZone - ručne umiestnená event zóna s plánovaným oknom a garantovaným dropom
+func (h *Handler) CreateEventZone(c *gin.Context) {
+	var req CreateEventZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !IsValidGPSCoordinate(req.Location.Latitude, req.Location.Longitude) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid GPS coordinates"})
+		return
+	}
+
+	req.Biome = strings.ToLower(strings.TrimSpace(req.Biome))
+	if !isKnownBiome(req.Biome) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown biome: %s", req.Biome)})
+		return
+	}
+
+	template := GetZoneTemplate(req.Biome)
+	if req.DangerLevel == "" {
+		req.DangerLevel = template.DangerLevel
+	}
+	if !isValidDangerLevel(req.DangerLevel) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid danger level: %s", req.DangerLevel)})
+		return
+	}
+
+	if req.RadiusMeters > MaxEventZoneRadius {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Radius must be at most %dm", MaxEventZoneRadius)})
+		return
+	}
+
+	if req.Capacity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Capacity must be 0 (unlimited) or positive"})
+		return
+	}
+
+	now := time.Now()
+	startsAt := now
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+	if err := validateEventWindow(startsAt, req.EndsAt, now); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateDropTable(req.DropTable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+
+	zone := gameplay.Zone{
+		BaseModel:   gameplay.BaseModel{ID: uuid.New()},
+		Name:        req.Name,
+		Description: req.Description,
+		Location: gameplay.Location{
+			Latitude:  req.Location.Latitude,
+			Longitude: req.Location.Longitude,
+			Timestamp: now,
+		},
+		RadiusMeters: req.RadiusMeters,
+		TierRequired: req.TierRequired,
+		IsActive:     true,
+		ZoneType:     ZoneTypeEvent,
+		Biome:        req.Biome,
+		DangerLevel:  req.DangerLevel,
+
+		// TTL fields - event končí v ends_at, bez ends_at je permanentný
+		ExpiresAt:    req.EndsAt,
+		LastActivity: now,
+		AutoCleanup:  req.EndsAt != nil,
+
+		Properties: gameplay.JSONB{
+			"event_type":            req.EventType,
+			"created_by":            c.GetString("username"),
+			"created_by_user_id":    fmt.Sprintf("%v", adminID),
+			"spawned_by":            "admin_event",
+			"starts_at":             startsAt.Unix(),
+			"capacity":              req.Capacity,
+			"drop_table":            dropTableToJSON(req.DropTable),
+			"biome":                 req.Biome,
+			"danger_level":          req.DangerLevel,
+			"environmental_effects": template.EnvironmentalEffects,
+			"zone_tier":             req.TierRequired,
+		},
+	}
+	if req.EndsAt != nil {
+		zone.Properties["ends_at"] = req.EndsAt.Unix()
+	}
+
+	if err := h.db.Create(&zone).Error; err != nil {
+		log.Printf("❌ Failed to create event zone: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create zone"})
+		return
+	}
+	h.trackZone(analytics.EventZoneSpawned, zone, nil, nil)
+	emitZoneLifecycle(ZoneLifecycleEvent{Stage: ZoneStageSpawned, Zone: zone})
+
+	artifacts, gear := h.spawnEventDrops(zone, req.DropTable)
+
+	log.Printf("🎪 Event zone created: %s (Tier: %d, Biome: %s, Starts: %s, Drops: %d artifacts + %d gear) by %s",
+		zone.Name, zone.TierRequired, zone.Biome, startsAt.Format(time.RFC3339), len(artifacts), len(gear), c.GetString("username"))
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":           "Event zone created successfully",
+		"zone":              zone,
+		"spawned_artifacts": artifacts,
+		"spawned_gear":      gear,
+		"starts_at":         startsAt.Unix(),
+		"is_live":           eventWindowOpen(zone, now),
+	})
+}
+
+// UpdateZone - úprava zóny; zmena layoutu/drop table respawne itemy
+func (h *Handler) UpdateZone(c *gin.Context) {
+	zoneID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zone ID format"})
+		return
+	}
+
+	var req UpdateZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var zone gameplay.Zone
+	if err := h.db.First(&zone, "id = ?", zoneID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zone not found"})
+		return
+	}
+	if zone.Properties == nil {
+		zone.Properties = gameplay.JSONB{}
+	}
+
+	now := time.Now()
+	layoutChanged := false
+
+	if req.Name != nil {
+		zone.Name = *req.Name
+	}
+	if req.Description != nil {
+		zone.Description = *req.Description
+	}
+	if req.EventType != nil {
+		zone.Properties["event_type"] = *req.EventType
+	}
+	if req.Biome != nil {
+		biome := strings.ToLower(strings.TrimSpace(*req.Biome))
+		if !isKnownBiome(biome) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown biome: %s", biome)})
+			return
+		}
+		layoutChanged = layoutChanged || biome != zone.Biome
+		zone.Biome = biome
+		zone.Properties["biome"] = biome
+	}
+	if req.DangerLevel != nil {
+		if !isValidDangerLevel(*req.DangerLevel) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid danger level: %s", *req.DangerLevel)})
+			return
+		}
+		zone.DangerLevel = *req.DangerLevel
+		zone.Properties["danger_level"] = *req.DangerLevel
+	}
+	if req.TierRequired != nil {
+		if *req.TierRequired < 0 || *req.TierRequired > 4 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tier must be between 0 and 4"})
+			return
+		}
+		layoutChanged = layoutChanged || *req.TierRequired != zone.TierRequired
+		zone.TierRequired = *req.TierRequired
+		zone.Properties["zone_tier"] = *req.TierRequired
+	}
+	if req.Location != nil {
+		if !IsValidGPSCoordinate(req.Location.Latitude, req.Location.Longitude) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid GPS coordinates"})
+			return
+		}
+		zone.Location.Latitude = req.Location.Latitude
+		zone.Location.Longitude = req.Location.Longitude
+		zone.Location.Timestamp = now
+		layoutChanged = true
+	}
+	if req.RadiusMeters != nil {
+		if *req.RadiusMeters < 10 || *req.RadiusMeters > MaxEventZoneRadius {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Radius must be between 10 and %dm", MaxEventZoneRadius)})
+			return
+		}
+		layoutChanged = layoutChanged || *req.RadiusMeters != zone.RadiusMeters
+		zone.RadiusMeters = *req.RadiusMeters
+	}
+	if req.Capacity != nil {
+		if *req.Capacity < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Capacity must be 0 (unlimited) or positive"})
+			return
+		}
+		zone.Properties["capacity"] = *req.Capacity
+	}
+
+	// Plánované okno (len pre event zóny má starts_at efekt na viditeľnosť)
+	if req.StartsAt != nil || req.EndsAt != nil {
+		startsAt := zone.CreatedAt
+		if ts, ok := propertyInt64(zone.Properties, "starts_at"); ok {
+			startsAt = time.Unix(ts, 0)
+		}
+		if req.StartsAt != nil {
+			startsAt = *req.StartsAt
+		}
+		endsAt := zone.ExpiresAt
+		if req.EndsAt != nil {
+			endsAt = req.EndsAt
+		}
+		if err := validateEventWindow(startsAt, endsAt, now); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		zone.Properties["starts_at"] = startsAt.Unix()
+		if endsAt != nil {
+			zone.ExpiresAt = endsAt
+			zone.AutoCleanup = true
+			zone.Properties["ends_at"] = endsAt.Unix()
+		}
+	}
+
+	dropTable := dropTableFromProperties(zone.Properties)
+	if req.DropTable != nil {
+		if err := validateDropTable(*req.DropTable); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		dropTable = *req.DropTable
+		zone.Properties["drop_table"] = dropTableToJSON(dropTable)
+		layoutChanged = true
+	}
+
+	deactivate := req.IsActive != nil && !*req.IsActive && zone.IsActive
+	if req.IsActive != nil && *req.IsActive {
+		zone.IsActive = true
+	}
+
+	zone.Properties["updated_by"] = c.GetString("username")
+	zone.Properties["updated_at"] = now.Unix()
+
+	if err := h.db.Save(&zone).Error; err != nil {
+		log.Printf("❌ Failed to update zone %s: %v", zone.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update zone"})
+		return
+	}
+
+	cleanupService := NewCleanupService(h.db)
+	itemsRemoved := 0
+	var artifacts []gameplay.Artifact
+	var gear []gameplay.Gear
+
+	if deactivate {
+		removed, _, err := cleanupService.ForceCleanupZone(zone.ID, "admin_deactivated")
+		if err != nil {
+			log.Printf("❌ Failed to deactivate zone %s: %v", zone.ID, err)
+		}
+		itemsRemoved = removed
+		zone.IsActive = false
+	} else if layoutChanged && zone.IsActive {
+		// Staré itemy by mohli ležať mimo novej zóny - zahoď a respawni
+		itemsRemoved = cleanupService.deactivateZoneItems(zone.ID)
+		if zone.ZoneType == ZoneTypeEvent {
+			artifacts, gear = h.spawnEventDrops(zone, dropTable)
+		} else {
+			h.spawnItemsInZone(globalRand{}, zone.ID, zone.TierRequired, zone.Biome, zone.Location, zone.RadiusMeters)
+		}
+	}
+
+	log.Printf("🛠️ Zone %s updated by %s (layout changed: %t, items removed: %d)",
+		zone.Name, c.GetString("username"), layoutChanged, itemsRemoved)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Zone updated successfully",
+		"zone":              zone,
+		"items_removed":     itemsRemoved,
+		"spawned_artifacts": artifacts,
+		"spawned_gear":      gear,
+		"is_live":           zone.IsActive && eventWindowOpen(zone, now),
+	})
+}
+
+// DeleteZone - deaktivuje zónu rovnako ako TTL cleanup (itemy, hráči, zóna)
+func (h *Handler) DeleteZone(c *gin.Context) {
+	zoneID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zone ID format"})
+		return
+	}
+
+	cleanupService := NewCleanupService(h.db)
+	itemsRemoved, playersAffected, err := cleanupService.ForceCleanupZone(zoneID, "admin_deleted")
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zone not found"})
+		return
+	}
+
+	log.Printf("🗑️ Zone %s deleted by %s", zoneID, c.GetString("username"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Zone deleted successfully",
+		"zone_id":          zoneID,
+		"items_removed":    itemsRemoved,
+		"players_affected": playersAffected,
+	})
+}
+
+// spawnEventDrops - garantovaný drop podľa drop table (bez náhody)
+func (h *Handler) spawnEventDrops(zone gameplay.Zone, dropTable []EventDropEntry) ([]gameplay.Artifact, []gameplay.Gear) {
+	artifacts := []gameplay.Artifact{}
+	gear := []gameplay.Gear{}
+
+	for _, entry := range dropTable {
+		quantity := entry.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+
+		opts := itemSpawnOptions{
+			Rarity:  entry.Rarity,
+			Spawner: "admin_event",
+			Reason:  "event_drop_table",
+			Force:   true,
+		}
+
+		for i := 0; i < quantity; i++ {
+			switch entry.ItemType {
+			case "artifact":
+				artifact, err := h.createArtifactInZone(zone, entry.Type, zone.Biome, zone.TierRequired, opts)
+				if err != nil {
+					log.Printf("❌ Failed to spawn event artifact %s: %v", entry.Type, err)
+					continue
+				}
+				artifacts = append(artifacts, *artifact)
+			case "gear":
+				g, err := h.createGearInZone(zone, entry.Type, zone.Biome, zone.TierRequired, opts)
+				if err != nil {
+					log.Printf("❌ Failed to spawn event gear %s: %v", entry.Type, err)
+					continue
+				}
+				gear = append(gear, *g)
+			}
+		}
+	}
+
+	return artifacts, gear
+}
+
+// ============================================
+// EVENT ZONE HELPERS
+// ============================================
+
+// eventWindowOpen - false pre event zónu, ktorej naplánovaný začiatok ešte nenastal
+func eventWindowOpen(zone gameplay.Zone, now time.Time) bool {
+	if zone.ZoneType != ZoneTypeEvent {
+		return true
+	}
+	if startsAt, ok := propertyInt64(zone.Properties, "starts_at"); ok && now.Unix() < startsAt {
+		return false
+	}
+	return true
+}
+
+// zoneCapacity - max počet hráčov v zóne z Properties (0 = bez limitu)
+func zoneCapacity(zone gameplay.Zone) int {
+	capacity, ok := propertyInt64(zone.Properties, "capacity")
+	if !ok || capacity < 0 {
+		return 0
+	}
+	return int(capacity)
+}
+
+// propertyInt64 - číselná hodnota z JSONB (po načítaní z DB je to float64)
+func propertyInt64(props gameplay.JSONB, key string) (int64, bool) {
+	if props == nil {
+		return 0, false
+	}
+	switch v := props[key].(type) {
+	case float64:
+		return int64(v), true
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func isKnownBiome(biome string) bool {
+	switch biome {
+	case BiomeForest, BiomeMountain, BiomeUrban, BiomeWater,
+		BiomeIndustrial, BiomeRadioactive, BiomeChemical:
+		return true
+	}
+	return false
+}
+
+func isValidDangerLevel(level string) bool {
+	switch level {
+	case DangerLow, DangerMedium, DangerHigh, DangerExtreme:
+		return true
+	}
+	return false
+}
+
+func validateEventWindow(startsAt time.Time, endsAt *time.Time, now time.Time) error {
+	if endsAt == nil {
+		return nil
+	}
+	if !endsAt.After(startsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+	if !endsAt.After(now) {
+		return fmt.Errorf("ends_at must be in the future")
+	}
+	return nil
+}
+
+func validateDropTable(entries []EventDropEntry) error {
+	total := 0
+	for _, entry := range entries {
+		if entry.ItemType != "artifact" && entry.ItemType != "gear" {
+			return fmt.Errorf("invalid drop item_type: %s", entry.ItemType)
+		}
+		if entry.Type == "" {
+			return fmt.Errorf("drop type is required")
+		}
+		if entry.Rarity != "" && !validItemRarities[entry.Rarity] {
+			return fmt.Errorf("invalid drop rarity: %s", entry.Rarity)
+		}
+		if entry.Quantity < 0 || entry.Quantity > MaxEventDropQuantity {
+			return fmt.Errorf("drop quantity must be between 1 and %d", MaxEventDropQuantity)
+		}
+		quantity := entry.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		total += quantity
+	}
+	if total > MaxEventDropTotal {
+		return fmt.Errorf("drop table exceeds %d items", MaxEventDropTotal)
+	}
+	return nil
+}
+
+func dropTableToJSON(entries []EventDropEntry) []interface{} {
+	result := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, map[string]interface{}{
+			"item_type": entry.ItemType,
+			"type":      entry.Type,
+			"rarity":    entry.Rarity,
+			"quantity":  entry.Quantity,
+		})
+	}
+	return result
+}
+
+func dropTableFromProperties(props gameplay.JSONB) []EventDropEntry {
+	raw, ok := props["drop_table"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+
+	var entries []EventDropEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		log.Printf("⚠️ Invalid drop_table in zone properties: %v", err)
+		return nil
+	}
+	return entries
+}
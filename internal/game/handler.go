@@ -1,21 +1,26 @@
 package game
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
 	"net/http"
 	"strconv"
 	"time"
 
+	"geoanomaly/internal/analytics"
 	"geoanomaly/internal/auth"
 	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/leaderboard"
+	"geoanomaly/internal/movement"
 	"geoanomaly/internal/xp"
+	"geoanomaly/internal/zonestats"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ============================================
@@ -41,6 +46,15 @@ func (h *Handler) ScanArea(c *gin.Context) {
 		return
 	}
 
+	// Kontrola hodnovernosti pohybu (teleport → 422, soft ban → 403)
+	verdict, ok := h.movement.Enforce(c, userID.(uuid.UUID), movement.SourceScanArea, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	})
+	if !ok {
+		return
+	}
+
 	// Get user
 	var user auth.User
 	if err := h.db.First(&user, "id = ?", userID).Error; err != nil {
@@ -59,81 +73,43 @@ func (h *Handler) ScanArea(c *gin.Context) {
 		return
 	}
 
+	analytics.Track(h.db, analytics.Event{Type: analytics.EventAreaScanned, UserID: &user.ID, Tier: &user.Tier})
+
 	// Get existing zones in area (7km visibility)
 	existingZones := h.getExistingZonesInArea(req.Latitude, req.Longitude, AreaScanRadius)
 
+	// Svet sa generuje okolo stredu bunky regiónu so seedom bunky a časového okna -
+	// hráči skenujúci rovnaké miesto v rovnakom okne dostanú rovnaký layout.
+	// Tier hráča určuje len počet a tiery zón, samotné zóny sú sloty bunky.
+	scanTime := time.Now()
+	cell := h.world.Cell(req.Latitude, req.Longitude)
+	anchorLat, anchorLng := h.world.CellCenter(cell)
+	rng := h.world.Rand(cell, scanTime, "scan")
+	slots := h.cellSlots(cell, scanTime)
+
 	// ====== GARANCIA ZÓN PRE NÍZKE TIERY ======
 	newZones := []gameplay.Zone{}
-	zonesInSpawnRadius := h.getExistingZonesInArea(req.Latitude, req.Longitude, MaxSpawnRadius)
-
-	if user.Tier == 0 {
-		// Garantuj 2x tier 0
-		tier0Count := 0
-		for _, z := range zonesInSpawnRadius {
-			if z.TierRequired == 0 && z.IsActive {
-				tier0Count++
-			}
-		}
-		if tier0Count < 2 {
-			toSpawn := 2 - tier0Count
-			log.Printf("✅ Guaranteeing %d tier 0 zone(s) for tier 0 player (currently %d in area)", toSpawn, tier0Count)
-			tier0Zones := h.spawnDynamicZones(req.Latitude, req.Longitude, 0, toSpawn, user.ID)
-			newZones = append(newZones, tier0Zones...)
-		}
-	} else if user.Tier == 1 {
-		// Garantuj aspoň 1x tier 0 a 1x tier 1 zónu
-		tier0 := false
-		tier1 := false
-		for _, z := range zonesInSpawnRadius {
-			if z.TierRequired == 0 && z.IsActive {
-				tier0 = true
-			}
-			if z.TierRequired == 1 && z.IsActive {
-				tier1 = true
-			}
-		}
-		if !tier0 {
-			log.Printf("✅ Guaranteeing 1 tier 0 zone for tier 1 player")
-			tier0Zones := h.spawnDynamicZones(req.Latitude, req.Longitude, 0, 1, user.ID)
-			newZones = append(newZones, tier0Zones...)
-		}
-		if !tier1 {
-			log.Printf("✅ Guaranteeing 1 tier 1 zone for tier 1 player")
-			tier1Zones := h.spawnDynamicZones(req.Latitude, req.Longitude, 1, 1, user.ID)
-			newZones = append(newZones, tier1Zones...)
-		}
-	} else if user.Tier == 2 {
-		// Ak nie je v okolí žiadna tier 0/1/2, spawn 2 náhodné zóny z {0,1,2}
-		tier0, tier1, tier2 := false, false, false
-		for _, z := range zonesInSpawnRadius {
-			if z.TierRequired == 0 && z.IsActive {
-				tier0 = true
-			}
-			if z.TierRequired == 1 && z.IsActive {
-				tier1 = true
-			}
-			if z.TierRequired == 2 && z.IsActive {
-				tier2 = true
-			}
-		}
-		if !(tier0 || tier1 || tier2) {
-			log.Printf("✅ Guaranteeing 2 zones (randomly picked from tier 0,1,2) for tier 2 player")
-			for i := 0; i < 2; i++ {
-				randomTier := rand.Intn(3) // 0, 1 alebo 2
-				zones := h.spawnDynamicZones(req.Latitude, req.Longitude, randomTier, 1, user.ID)
-				newZones = append(newZones, zones...)
-			}
-		}
+	zonesInSpawnRadius := h.getExistingZonesInArea(anchorLat, anchorLng, MaxSpawnRadius)
+
+	// Hráč v shadow režime (podozrivý pohyb) vidí existujúce zóny, nové sa mu nespawnujú
+	spawnAllowed := !verdict.Shadowed()
+
+	if !spawnAllowed {
+		log.Printf("🚩 Zone spawning suppressed for user %s (movement level: %s)", user.ID, verdict.Level)
+	} else if guaranteed := guaranteedZoneSpawns(rng, user.Tier, zonesInSpawnRadius); len(guaranteed) > 0 {
+		log.Printf("✅ Guaranteeing %d zone(s) for tier %d player (%d zones in area)", len(guaranteed), user.Tier, len(zonesInSpawnRadius))
+		newZones = append(newZones, h.spawnDynamicZones(slots, anchorLat, anchorLng, guaranteed, user.Tier, user.ID)...)
 	}
 
 	// Calculate how many new zones can be created (only count zones in spawn radius - 2km)
-	maxZones := h.calculateMaxZones(user.Tier)
-	currentDynamicZones := h.countDynamicZonesInArea(req.Latitude, req.Longitude, MaxSpawnRadius)
+	maxZones := currentSpawnConfig().maxZones(user.Tier)
+	currentDynamicZones := h.countDynamicZonesInArea(anchorLat, anchorLng, MaxSpawnRadius)
 	newZonesNeeded := maxZones - currentDynamicZones
 
-	if newZonesNeeded > 0 {
+	if spawnAllowed && newZonesNeeded > 0 {
 		log.Printf("🏗️ Creating %d new zones for tier %d player", newZonesNeeded, user.Tier)
-		additionalZones := h.spawnDynamicZones(req.Latitude, req.Longitude, user.Tier, newZonesNeeded, user.ID)
+		tiers := planZoneTiers(currentSpawnConfig(), rng, user.Tier, newZonesNeeded)
+		additionalZones := h.spawnDynamicZones(slots, anchorLat, anchorLng, tiers, user.Tier, user.ID)
 		newZones = append(newZones, additionalZones...)
 	}
 
@@ -151,6 +127,7 @@ func (h *Handler) ScanArea(c *gin.Context) {
 	}
 
 	response := ScanAreaResponse{
+		Environment:       h.environment.At(req.Latitude, req.Longitude, scanTime),
 		ZonesCreated:      len(newZones),
 		Zones:             zoneDetails,
 		ScanAreaCenter:    LocationPoint(req),
@@ -158,105 +135,56 @@ func (h *Handler) ScanArea(c *gin.Context) {
 		MaxZones:          maxZones,
 		CurrentZoneCount:  len(visibleZones),
 		PlayerTier:        user.Tier,
+		WorldCell:         cell.String(),
+		WorldWindow:       h.world.WindowIndex(scanTime),
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// ✅ UPDATED: spawnDynamicZones with tier-based distance spawning & minimal distance between zones
-func (h *Handler) spawnDynamicZones(lat, lng float64, playerTier int, count int, userID uuid.UUID) []gameplay.Zone {
-	var newZones []gameplay.Zone
-
-	// Získaj všetky existujúce zóny v maximálnom okruhu spawnu (2km)
-	existingZones := h.getExistingZonesInArea(lat, lng, MaxSpawnRadius)
-
-	// Špeciálne správanie pre tier 0 hráča
-	var forcedTiers []int
-	if playerTier == 0 && count > 0 {
-		forcedTiers = append(forcedTiers, 0)
-		if count > 1 {
-			forcedTiers = append(forcedTiers, 0)
-		}
+// cellSlots - sloty zón bunky v časovom okne (rovnaké pre všetkých hráčov)
+func (h *Handler) cellSlots(cell RegionCell, at time.Time) slotRand {
+	return func(zoneTier, index int) spawnRand {
+		return h.world.SlotRand(cell, at, zoneTier, index)
 	}
+}
 
-	for i := 0; i < count; i++ {
-		var zoneTier int
-		var biome string
-		var template ZoneTemplate
-
-		if i < len(forcedTiers) {
-			zoneTier = forcedTiers[i]
-			biome = h.selectBiome(zoneTier)
-			template = GetZoneTemplate(biome)
-		} else {
-			biome = h.selectBiome(playerTier)
-			template = GetZoneTemplate(biome)
-			zoneTier = h.calculateZoneTier(playerTier, template.MinTierRequired)
-		}
-
-		var zoneLat, zoneLng float64
-		valid := false
-		maxTries := 10
-
-		for try := 0; try < maxTries; try++ {
-			zoneLat, zoneLng = h.generateTierBasedPosition(lat, lng, zoneTier)
-			tooClose := false
-
-			// Kontrola vzdialenosti voči už existujúcim aj novo spawnutým zónam
-			for _, z := range append(existingZones, newZones...) {
-				distance := CalculateDistance(zoneLat, zoneLng, z.Location.Latitude, z.Location.Longitude)
-				if distance < MinZoneDistance {
-					tooClose = true
-					break
-				}
-			}
-
-			if !tooClose {
-				valid = true
-				break
-			}
-		}
+// zoneIDTaken - ID slotu už patrí zóne (aktívnej alebo upratanej v tom istom okne)
+func (h *Handler) zoneIDTaken(id uuid.UUID) bool {
+	var count int64
+	if err := h.db.Model(&gameplay.Zone{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		log.Printf("❌ Failed to check zone slot %s: %v", id, err)
+		return true
+	}
+	return count > 0
+}
 
-		if !valid {
-			log.Printf("⚠️ Could not find free position for zone after %d tries, skipping spawn.", maxTries)
-			continue
-		}
+// spawnDynamicZones - naplánuje zóny zadaných tierov zo slotov bunky a zapíše ich.
+// ID zón sú deterministické, takže súbežný scan tej istej bunky zónu nezduplikuje.
+func (h *Handler) spawnDynamicZones(slots slotRand, anchorLat, anchorLng float64, tiers []int, playerTier int, userID uuid.UUID) []gameplay.Zone {
+	var newZones []gameplay.Zone
+	cfg := currentSpawnConfig()
 
-		// Over, či pozícia je v rámci max spawn radius
-		spawnDistance := CalculateDistance(lat, lng, zoneLat, zoneLng)
-		if spawnDistance > MaxSpawnRadius {
-			log.Printf("⚠️ Zone would spawn too far (%.0fm > %.0fm), skipping", spawnDistance, MaxSpawnRadius)
-			continue
-		}
+	// Získaj všetky existujúce zóny v maximálnom okruhu spawnu (2km)
+	existingZones := h.getExistingZonesInArea(anchorLat, anchorLng, MaxSpawnRadius)
 
-		// TTL podľa tieru / Tier 0 zony načítava hodnoty s constants.go a upravuju ich životnosť
-		var randomTTL time.Duration
-		if zoneTier == 0 {
-			minTTL := time.Duration(Tier0MinExpiryMinutes) * time.Minute
-			maxTTL := time.Duration(Tier0MaxExpiryMinutes) * time.Minute
-			ttlRange := maxTTL - minTTL
-			randomTTL = minTTL + time.Duration(rand.Float64()*float64(ttlRange))
-		} else {
-			minTTL := time.Duration(ZoneMinExpiryHours) * time.Hour
-			maxTTL := time.Duration(ZoneMaxExpiryHours) * time.Hour
-			ttlRange := maxTTL - minTTL
-			randomTTL = minTTL + time.Duration(rand.Float64()*float64(ttlRange))
-		}
-		expiresAt := time.Now().Add(randomTTL)
+	for _, planned := range planDynamicZones(cfg, slots, anchorLat, anchorLng, tiers, existingZones, h.zoneIDTaken) {
+		template := cfg.template(planned.Biome)
+		expiresAt := time.Now().Add(planned.TTL)
 
 		zone := gameplay.Zone{
-			BaseModel: gameplay.BaseModel{ID: uuid.New()},
-			Name:      h.generateZoneName(biome),
+			BaseModel: gameplay.BaseModel{ID: planned.ID},
+			Name:      planned.Name,
 			Location: gameplay.Location{
-				Latitude:  zoneLat,
-				Longitude: zoneLng,
+				Latitude:  planned.Latitude,
+				Longitude: planned.Longitude,
 				Timestamp: time.Now(),
 			},
-			TierRequired: zoneTier,
-			RadiusMeters: h.calculateZoneRadius(zoneTier),
+			TierRequired: planned.Tier,
+			RadiusMeters: planned.Radius,
 			IsActive:     true,
 			ZoneType:     "dynamic",
-			Biome:        biome,
+			Biome:        planned.Biome,
 			DangerLevel:  template.DangerLevel,
 
 			// TTL fields
@@ -267,26 +195,45 @@ func (h *Handler) spawnDynamicZones(lat, lng float64, playerTier int, count int,
 			Properties: gameplay.JSONB{
 				"spawned_by":            "scan_area",
 				"created_by_user_id":    userID.String(),
-				"ttl_hours":             randomTTL.Hours(),
-				"biome":                 biome,
+				"ttl_hours":             planned.TTL.Hours(),
+				"biome":                 planned.Biome,
 				"danger_level":          template.DangerLevel,
 				"environmental_effects": template.EnvironmentalEffects,
 				"zone_template":         "biome_based",
-				"spawn_distance":        spawnDistance,
-				"zone_tier":             zoneTier,
+				"spawn_distance":        planned.SpawnDistance,
+				"zone_tier":             planned.Tier,
 				"player_tier":           playerTier,
+				"lifecycle_stage":       ZoneStageSpawned,
 			},
 		}
 
-		if err := h.db.Create(&zone).Error; err == nil {
-			h.spawnItemsInZone(zone.ID, zoneTier, zone.Biome, zone.Location, zone.RadiusMeters)
-			newZones = append(newZones, zone)
-
-			log.Printf("🏰 Zone spawned: %s (Tier: %d, Biome: %s, Distance: %.0fm, Radius: %dm, TTL: %.1fh)",
-				zone.Name, zoneTier, biome, spawnDistance, zone.RadiusMeters, randomTTL.Hours())
-		} else {
-			log.Printf("❌ Failed to create zone: %v", err)
+		result := h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&zone)
+		if result.Error != nil {
+			log.Printf("❌ Failed to create zone: %v", result.Error)
+			continue
 		}
+		if result.RowsAffected == 0 {
+			// Súbežný scan rovnakej bunky už zónu vytvoril aj s itemami
+			// (neaktívne ID vyradil zoneIDTaken ešte pri plánovaní)
+			var existing gameplay.Zone
+			if err := h.db.First(&existing, "id = ?", zone.ID).Error; err != nil {
+				log.Printf("❌ Failed to load concurrently spawned zone %s: %v", zone.ID, err)
+			} else if existing.IsActive {
+				log.Printf("🔁 Zone %s already spawned by concurrent scan", zone.ID)
+				newZones = append(newZones, existing)
+			} else {
+				log.Printf("⚠️ Zone slot %s was cleaned up concurrently, skipping", zone.ID)
+			}
+			continue
+		}
+
+		h.trackZone(analytics.EventZoneSpawned, zone, &userID, &playerTier)
+		emitZoneLifecycle(ZoneLifecycleEvent{Stage: ZoneStageSpawned, Zone: zone})
+		h.spawnItemsInZone(h.world.ZoneRand(zone.ID), zone.ID, planned.Tier, zone.Biome, zone.Location, zone.RadiusMeters)
+		newZones = append(newZones, zone)
+
+		log.Printf("🏰 Zone spawned: %s (Tier: %d, Biome: %s, Distance: %.0fm, Radius: %dm, TTL: %.1fh)",
+			zone.Name, planned.Tier, planned.Biome, planned.SpawnDistance, zone.RadiusMeters, planned.TTL.Hours())
 	}
 
 	return newZones
@@ -432,6 +379,33 @@ func (h *Handler) EnterZone(c *gin.Context) {
 		return
 	}
 
+	// Soft ban za podozrivý pohyb
+	if !h.movement.EnforceLevel(c, user.ID) {
+		return
+	}
+
+	// Hráč musí byť v zóne - poloha zo session (zapisuje ju len overený location update / scan)
+	var position auth.PlayerSession
+	if err := h.db.Select("last_location_latitude", "last_location_longitude", "last_location_accuracy").
+		Where("user_id = ?", user.ID).First(&position).Error; err != nil ||
+		(position.LastLocationLatitude == 0 && position.LastLocationLongitude == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Location unknown",
+			"message": "Update your location before entering a zone",
+		})
+		return
+	}
+	distanceFromCenter := CalculateDistance(position.LastLocationLatitude, position.LastLocationLongitude, zone.Location.Latitude, zone.Location.Longitude)
+	if distanceFromCenter > float64(zone.RadiusMeters)+math.Min(position.LastLocationAccuracy, EnterZoneAccuracyTolerance) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":         "Too far from zone",
+			"message":       "Move closer to the zone to enter it",
+			"distance_m":    math.Round(distanceFromCenter),
+			"zone_radius_m": zone.RadiusMeters,
+		})
+		return
+	}
+
 	// Tier check
 	if zone.TierRequired > user.Tier {
 		c.JSON(http.StatusForbidden, gin.H{
@@ -443,8 +417,15 @@ func (h *Handler) EnterZone(c *gin.Context) {
 		return
 	}
 
-	// Update zone activity
-	h.updateZoneActivity(zone.ID)
+	// Event zóna pred naplánovaným začiatkom
+	if !eventWindowOpen(zone, time.Now()) {
+		startsAt, _ := propertyInt64(zone.Properties, "starts_at")
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":     "Event has not started yet",
+			"starts_at": startsAt,
+		})
+		return
+	}
 
 	// Update player session
 	var session auth.PlayerSession
@@ -468,45 +449,64 @@ func (h *Handler) EnterZone(c *gin.Context) {
 	session.LastSeen = time.Now()
 	session.IsOnline = true
 
-	if err := h.db.Save(&session).Error; err != nil {
+	// Kapacita zóny (Properties.capacity, 0 = bez limitu); plná event zóna = rad
+	if err := h.occupyZone(zone, &session); err != nil {
+		var full *ZoneFullError
+		if errors.As(err, &full) {
+			response := gin.H{
+				"error":          "Zone is full",
+				"code":           "zone_full",
+				"capacity":       full.Capacity,
+				"active_players": full.ActivePlayers,
+				"waitlisted":     full.WaitlistPosition > 0,
+			}
+			if full.WaitlistPosition > 0 {
+				response["waitlist_position"] = full.WaitlistPosition
+				response["retry_within"] = int(ZoneWaitlistStaleAfter.Seconds())
+			}
+			c.JSON(http.StatusConflict, response)
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enter zone"})
 		return
 	}
 
+	// Update zone activity
+	h.updateZoneActivity(zone.ID)
+
+	// First visit counts as a zone discovery (leaderboards)
+	discovered := h.leaderboard.RecordZoneVisit(user.ID, zone.ID)
+
+	h.zoneStats.StartVisit(zone, user.ID)
+	h.trackZone(analytics.EventZoneEntered, zone, &user.ID, &user.Tier)
+
+	// Noc, počasie a efekty biomu zvyšujú nebezpečenstvo zóny
+	env, envMods := h.environmentAt(zone.Location.Latitude, zone.Location.Longitude, zone.Biome, time.Now())
+	effectiveDanger := effectiveDangerLevel(zone.DangerLevel, envMods.DangerShift)
+
 	// Apply durability damage to equipped gear
 	if h.loadoutService != nil {
-		// Convert danger level string to int
-		dangerLevel := 1 // default
-		switch zone.DangerLevel {
-		case "low":
-			dangerLevel = 1
-		case "medium":
-			dangerLevel = 3
-		case "high":
-			dangerLevel = 5
-		case "extreme":
-			dangerLevel = 8
-		case "deadly":
-			dangerLevel = 10
-		}
-
-		if err := h.loadoutService.ApplyDurabilityDamage(user.ID, dangerLevel, zone.Biome); err != nil {
+		if err := h.loadoutService.ApplyDurabilityDamage(user.ID, dangerLevelValue(effectiveDanger), zone.Biome, envMods.DurabilityMultiplier); err != nil {
 			log.Printf("Warning: Failed to apply durability damage: %v", err)
 		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":              "Successfully entered zone",
-		"zone_name":            zone.Name,
-		"biome":                zone.Biome,
-		"danger_level":         zone.DangerLevel,
-		"zone":                 zone,
-		"entered_at":           time.Now().Unix(),
-		"can_collect":          true,
-		"player_tier":          user.Tier,
-		"distance_from_center": 0,
-		"ttl_status":           zone.TTLStatus(),
-		"expires_in_seconds":   int64(zone.TimeUntilExpiry().Seconds()),
+		"message":                "Successfully entered zone",
+		"zone_name":              zone.Name,
+		"biome":                  zone.Biome,
+		"danger_level":           zone.DangerLevel,
+		"effective_danger_level": effectiveDanger,
+		"environment":            env,
+		"environment_modifiers":  envMods,
+		"zone":                   zone,
+		"entered_at":             time.Now().Unix(),
+		"can_collect":            true,
+		"player_tier":            user.Tier,
+		"distance_from_center":   math.Round(distanceFromCenter),
+		"ttl_status":             zone.TTLStatus(),
+		"expires_in_seconds":     int64(zone.TimeUntilExpiry().Seconds()),
+		"first_discovery":        discovered,
 	})
 }
 
@@ -539,9 +539,6 @@ func (h *Handler) ExitZone(c *gin.Context) {
 		}
 	}
 
-	// Calculate basic time in zone
-	timeInZone := time.Since(session.CreatedAt)
-
 	// Clear current zone
 	session.CurrentZone = nil
 	session.LastSeen = time.Now()
@@ -551,12 +548,20 @@ func (h *Handler) ExitZone(c *gin.Context) {
 		return
 	}
 
+	// Dĺžka návštevy a počet itemov zo zone stats (fallback pre návštevy spred ich zavedenia)
+	timeInZone := time.Since(session.CreatedAt)
+	itemsCollected := 0
+	if visit := h.zoneStats.EndVisit(session.UserID, zonestats.EndExit); visit != nil {
+		timeInZone = time.Duration(*visit.DurationSeconds) * time.Second
+		itemsCollected = visit.ItemsCollected
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":         "Successfully exited zone",
 		"exited_at":       time.Now().Unix(),
 		"zone_name":       zoneName,
 		"time_in_zone":    fmt.Sprintf("%.0fm", timeInZone.Minutes()),
-		"items_collected": 0, // TODO: Implement if needed
+		"items_collected": itemsCollected,
 		"xp_gained":       0, // TODO: Implement if needed
 		"total_xp_gained": 0, // TODO: Implement if needed
 	})
@@ -651,6 +656,11 @@ func (h *Handler) CollectItem(c *gin.Context) {
 		return
 	}
 
+	// Soft ban za podozrivý pohyb
+	if !h.movement.EnforceLevel(c, userID) {
+		return
+	}
+
 	// 1) Session + in-zone validácia
 	var session auth.PlayerSession
 	if err := h.db.Where("user_id = ? AND current_zone = ?", userID, zoneID).First(&session).Error; err != nil {
@@ -673,6 +683,10 @@ func (h *Handler) CollectItem(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"success": false, "code": "not_found", "error": "Artifact not found"})
 			return
 		}
+		if a.IsClaimed {
+			respondCollectError(c, ErrItemAlreadyClaimed)
+			return
+		}
 		if a.DeletedAt != nil || !a.IsActive {
 			c.JSON(http.StatusBadRequest, gin.H{"success": false, "code": "inactive", "error": "Artifact is no longer available"})
 			return
@@ -689,6 +703,10 @@ func (h *Handler) CollectItem(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"success": false, "code": "not_found", "error": "Gear not found"})
 			return
 		}
+		if g.IsClaimed {
+			respondCollectError(c, ErrItemAlreadyClaimed)
+			return
+		}
 		if g.DeletedAt != nil || !g.IsActive {
 			c.JSON(http.StatusBadRequest, gin.H{"success": false, "code": "inactive", "error": "Gear is no longer available"})
 			return
@@ -764,39 +782,45 @@ func (h *Handler) CollectItem(c *gin.Context) {
 
 	switch req.ItemType {
 	case "artifact":
+		// First-come claim + inventár + štatistiky atomicky; porazený v súboji dostane 409
 		var artifact gameplay.Artifact
-		if err := h.db.First(&artifact, "id = ? AND zone_id = ? AND is_active = true", req.ItemID, zoneID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"success": false, "code": "not_found", "error": "Artifact not found"})
-			return
-		}
-
-		// Deactivate artifact
-		artifact.IsActive = false
-		h.db.Save(&artifact)
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			if artifact, err = claimArtifact(tx, zoneID, req.ItemID); err != nil {
+				return err
+			}
 
-		// Add to inventory
-		inventory := gameplay.InventoryItem{
-			UserID:   user.ID,
-			ItemType: "artifact",
-			ItemID:   artifact.ID,
-			Quantity: 1,
-			Properties: gameplay.JSONB{
-				"name":           artifact.Name,
-				"type":           artifact.Type,
-				"rarity":         artifact.Rarity,
-				"biome":          artifact.Biome,
-				"collected_at":   time.Now().Unix(),
-				"collected_from": zoneID,
-				"zone_name":      zone.Name,
-				"zone_biome":     zone.Biome,
-				"danger_level":   zone.DangerLevel,
-			},
+			// Add to inventory
+			inventory := gameplay.InventoryItem{
+				UserID:   user.ID,
+				ItemType: "artifact",
+				ItemID:   artifact.ID,
+				Quantity: 1,
+				Properties: gameplay.JSONB{
+					"name":           artifact.Name,
+					"type":           artifact.Type,
+					"rarity":         artifact.Rarity,
+					"biome":          artifact.Biome,
+					"collected_at":   time.Now().Unix(),
+					"collected_from": zoneID,
+					"zone_name":      zone.Name,
+					"zone_biome":     zone.Biome,
+					"danger_level":   zone.DangerLevel,
+				},
+			}
+			if err := tx.Create(&inventory).Error; err != nil {
+				return err
+			}
+			return tx.Model(&user).Update("total_artifacts", gorm.Expr("total_artifacts + ?", 1)).Error
+		})
+		if err != nil {
+			respondCollectError(c, err)
+			return
 		}
-		h.db.Create(&inventory)
+		h.recordCollect(zone, user.ID, user.Tier, zonestats.ItemArtifact, artifact.Type, artifact.Rarity)
 
 		// Award XP for artifact
-		xpHandler := xp.NewHandler(h.db)
-		var err error
+		xpHandler := xp.NewHandler(h.db).WithLeaderboard(h.leaderboard)
 		xpResult, err = xpHandler.AwardArtifactXP(user.ID, artifact.Rarity, artifact.Biome, zone.TierRequired)
 		if err != nil {
 			log.Printf("❌ Failed to award XP: %v", err)
@@ -806,100 +830,34 @@ func (h *Handler) CollectItem(c *gin.Context) {
 		biome = artifact.Biome
 
 		// Update user stats
-		h.db.Model(&user).Update("total_artifacts", gorm.Expr("total_artifacts + ?", 1))
+		h.leaderboard.RecordArtifact(user.ID, artifact.Rarity, artifact.Biome)
 
 	case "gear":
-		var gear gameplay.Gear
-		if err := h.db.First(&gear, "id = ? AND zone_id = ? AND is_active = true", req.ItemID, zoneID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"success": false, "code": "not_found", "error": "Gear not found"})
+		// First-come claim + inventár + štatistiky atomicky; porazený v súboji dostane 409
+		var (
+			gear          gameplay.Gear
+			inventoryItem gameplay.InventoryItem
+		)
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			if gear, err = claimGear(tx, zoneID, req.ItemID); err != nil {
+				return err
+			}
+			inventoryItem = h.gearInventoryItem(user.ID, gear, zone, zoneID)
+			if err := tx.Create(&inventoryItem).Error; err != nil {
+				return err
+			}
+			return tx.Model(&user).Update("total_gear", gorm.Expr("total_gear + ?", 1)).Error
+		})
+		if err != nil {
+			respondCollectError(c, err)
 			return
 		}
-
-		// Deactivate gear
-		gear.IsActive = false
-		h.db.Save(&gear)
-
-		// Vytvor inventory item s properties z gear objektu alebo fallback na GearService
-		properties := gameplay.JSONB{
-			"name":           gear.Name,
-			"type":           gear.Type,
-			"level":          gear.Level,
-			"biome":          gear.Biome,
-			"equipped":       false,
-			"collected_at":   time.Now().Unix(),
-			"collected_from": zoneID,
-			"zone_name":      zone.Name,
-			"zone_biome":     gear.Biome,
-			"danger_level":   zone.DangerLevel,
-			"acquired_at":    time.Now().Format(time.RFC3339),
-		}
-
-		// Skontroluj či gear má už properties z databázy
-		if slot, exists := gear.Properties["slot"].(string); exists {
-			properties["slot"] = slot
-		} else {
-			properties["slot"] = h.gearService.getSlotForGearType(gear.Type)
-		}
-
-		if rarity, exists := gear.Properties["rarity"].(string); exists {
-			properties["rarity"] = rarity
-		} else {
-			properties["rarity"] = h.gearService.getRarityForLevel(gear.Level)
-		}
-
-		if durability, exists := gear.Properties["base_durability"].(float64); exists {
-			properties["durability"] = int(durability)
-			properties["max_durability"] = int(durability)
-		} else {
-			properties["durability"] = 100
-			properties["max_durability"] = 100
-		}
-
-		// Resistance properties
-		if zombieRes, exists := gear.Properties["zombie_resistance"].(float64); exists {
-			properties["zombie_resistance"] = int(zombieRes)
-		} else {
-			properties["zombie_resistance"] = h.gearService.calculateResistance(gear.Level, "zombie")
-		}
-
-		if banditRes, exists := gear.Properties["bandit_resistance"].(float64); exists {
-			properties["bandit_resistance"] = int(banditRes)
-		} else {
-			properties["bandit_resistance"] = h.gearService.calculateResistance(gear.Level, "bandit")
-		}
-
-		if soldierRes, exists := gear.Properties["soldier_resistance"].(float64); exists {
-			properties["soldier_resistance"] = int(soldierRes)
-		} else {
-			properties["soldier_resistance"] = h.gearService.calculateResistance(gear.Level, "soldier")
-		}
-
-		if monsterRes, exists := gear.Properties["monster_resistance"].(float64); exists {
-			properties["monster_resistance"] = int(monsterRes)
-		} else {
-			properties["monster_resistance"] = h.gearService.calculateResistance(gear.Level, "monster")
-		}
-
-		// Pridaj category_id ak existuje
-		if categoryID, exists := gear.Properties["category_id"].(string); exists {
-			properties["category_id"] = categoryID
-		}
-
-		inventoryItem := gameplay.InventoryItem{
-			UserID:     user.ID,
-			ItemType:   "gear",
-			ItemID:     uuid.New(), // Unikátne ID pre tento konkrétny predmet
-			Quantity:   1,
-			Properties: properties,
-		}
-		h.db.Create(&inventoryItem)
+		h.recordCollect(zone, user.ID, user.Tier, zonestats.ItemGear, gear.Type, h.gearRarity(gear))
 
 		collectedItem = inventoryItem
 		biome = gear.Biome
 
-		// Update user stats
-		h.db.Model(&user).Update("total_gear", gorm.Expr("total_gear + ?", 1))
-
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"success": false, "code": "bad_request", "error": "Invalid item type"})
 		return
@@ -947,6 +905,82 @@ func (h *Handler) CollectItem(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// gearInventoryItem - inventory item s properties z gear objektu alebo fallback na GearService
+func (h *Handler) gearInventoryItem(userID uuid.UUID, gear gameplay.Gear, zone gameplay.Zone, zoneID string) gameplay.InventoryItem {
+	properties := gameplay.JSONB{
+		"name":           gear.Name,
+		"type":           gear.Type,
+		"level":          gear.Level,
+		"biome":          gear.Biome,
+		"equipped":       false,
+		"collected_at":   time.Now().Unix(),
+		"collected_from": zoneID,
+		"zone_name":      zone.Name,
+		"zone_biome":     gear.Biome,
+		"danger_level":   zone.DangerLevel,
+		"acquired_at":    time.Now().Format(time.RFC3339),
+	}
+
+	// Skontroluj či gear má už properties z databázy
+	if slot, exists := gear.Properties["slot"].(string); exists {
+		properties["slot"] = slot
+	} else {
+		properties["slot"] = h.gearService.getSlotForGearType(gear.Type)
+	}
+
+	if rarity, exists := gear.Properties["rarity"].(string); exists {
+		properties["rarity"] = rarity
+	} else {
+		properties["rarity"] = h.gearService.getRarityForLevel(gear.Level)
+	}
+
+	if durability, exists := gear.Properties["base_durability"].(float64); exists {
+		properties["durability"] = int(durability)
+		properties["max_durability"] = int(durability)
+	} else {
+		properties["durability"] = 100
+		properties["max_durability"] = 100
+	}
+
+	// Resistance properties
+	if zombieRes, exists := gear.Properties["zombie_resistance"].(float64); exists {
+		properties["zombie_resistance"] = int(zombieRes)
+	} else {
+		properties["zombie_resistance"] = h.gearService.calculateResistance(gear.Level, "zombie")
+	}
+
+	if banditRes, exists := gear.Properties["bandit_resistance"].(float64); exists {
+		properties["bandit_resistance"] = int(banditRes)
+	} else {
+		properties["bandit_resistance"] = h.gearService.calculateResistance(gear.Level, "bandit")
+	}
+
+	if soldierRes, exists := gear.Properties["soldier_resistance"].(float64); exists {
+		properties["soldier_resistance"] = int(soldierRes)
+	} else {
+		properties["soldier_resistance"] = h.gearService.calculateResistance(gear.Level, "soldier")
+	}
+
+	if monsterRes, exists := gear.Properties["monster_resistance"].(float64); exists {
+		properties["monster_resistance"] = int(monsterRes)
+	} else {
+		properties["monster_resistance"] = h.gearService.calculateResistance(gear.Level, "monster")
+	}
+
+	// Pridaj category_id ak existuje
+	if categoryID, exists := gear.Properties["category_id"].(string); exists {
+		properties["category_id"] = categoryID
+	}
+
+	return gameplay.InventoryItem{
+		UserID:     userID,
+		ItemType:   "gear",
+		ItemID:     uuid.New(), // Unikátne ID pre tento konkrétny predmet
+		Quantity:   1,
+		Properties: properties,
+	}
+}
+
 // Helper function to update zone activity
 func (h *Handler) updateZoneActivity(zoneID uuid.UUID) {
 	h.db.Model(&gameplay.Zone{}).Where("id = ?", zoneID).Update("last_activity", time.Now())
@@ -971,241 +1005,28 @@ func (h *Handler) checkAndCleanupEmptyZone(zoneID uuid.UUID) {
 // ✅ HELPER FUNCTIONS - TIER-BASED SPAWNING
 // ============================================
 
-// ✅ selectBiome používa getAvailableBiomes z zones.go
-func (h *Handler) selectBiome(tier int) string {
-	availableBiomes := h.getAvailableBiomes(tier)
-	if len(availableBiomes) == 0 {
-		return BiomeForest // fallback
-	}
-	return availableBiomes[rand.Intn(len(availableBiomes))]
-}
-
-// ✅ generateZoneName používa GetZoneTemplate z biomes.go
-func (h *Handler) generateZoneName(biome string) string {
-	template := GetZoneTemplate(biome)
-	if len(template.Names) == 0 {
-		return fmt.Sprintf("Unknown %s Zone", biome)
-	}
-	return template.Names[rand.Intn(len(template.Names))]
-}
-
-// ✅ NEW: Generate position based on tier distance ranges
-func (h *Handler) generateTierBasedPosition(centerLat, centerLng float64, zoneTier int) (float64, float64) {
-	minDistance, maxDistance := h.getTierSpawnDistance(zoneTier)
-
-	// Random angle (0-360 degrees)
-	angle := rand.Float64() * 2 * math.Pi
-
-	// Random distance within tier range
-	distance := minDistance + rand.Float64()*(maxDistance-minDistance)
-
-	// Convert to GPS coordinates using Haversine
-	earthRadius := 6371000.0 // meters
-
-	latOffset := (distance * math.Cos(angle)) / earthRadius * (180 / math.Pi)
-	lngOffset := (distance * math.Sin(angle)) / earthRadius * (180 / math.Pi) / math.Cos(centerLat*math.Pi/180)
-
-	newLat := centerLat + latOffset
-	newLng := centerLng + lngOffset
-
-	log.Printf("🎯 [TIER %d] Spawning zone at distance %.0fm (range: %.0f-%.0fm) angle: %.1f°",
-		zoneTier, distance, minDistance, maxDistance, angle*180/math.Pi)
-
-	return newLat, newLng
-}
-
-// ✅ NEW: Tier-based distance calculation for zone spawning
-func (h *Handler) getTierSpawnDistance(zoneTier int) (float64, float64) {
-	switch zoneTier {
-	case 0:
-		return Tier0MinDistance, Tier0MaxDistance
-	case 1:
-		return Tier1MinDistance, Tier1MaxDistance
-	case 2:
-		return Tier2MinDistance, Tier2MaxDistance
-	case 3:
-		return Tier3MinDistance, Tier3MaxDistance
-	case 4:
-		return Tier4MinDistance, Tier4MaxDistance
-	default:
-		return Tier0MinDistance, Tier0MaxDistance
-	}
-}
-
-// ✅ ENHANCED: spawnItemsInZone s konfigurovateľnými spawn rates
-func (h *Handler) spawnItemsInZone(zoneID uuid.UUID, tier int, biome string, zoneCenter gameplay.Location, zoneRadius int) {
-	template := GetZoneTemplate(biome)
-
-	// ✅ KONFIGUROVATEĽNÉ NASTAVENIA - zmeň tieto hodnoty podľa potreby
-	const (
-		// Základné spawn rates (0.0 = 0%, 1.0 = 100%)
-		baseArtifactSpawnRate = 0.8  // 80% šanca na artifact spawn
-		baseGearSpawnRate     = 0.7  // 70% šanca na gear spawn
-		exclusiveSpawnRate    = 0.15 // 15% šanca na exclusive artifacts
-
-		// Multiplikátory podľa tier (vyšší tier = viac items)
-		tierMultiplier = 0.1 // +10% za každý tier
-
-		// Minimum garantovaných items
-		minArtifactsPerZone = 1
-		minGearPerZone      = 1
-
-		// Maximum items per zone
-		maxArtifactsPerZone = 5
-		maxGearPerZone      = 4
-	)
-
-	log.Printf("🏭 [DEBUG] Spawning items in %s zone (tier %d)", biome, tier)
-	log.Printf("🔧 [DEBUG] Template: %d artifact types, %d gear types, %d exclusive",
-		len(template.ArtifactSpawnRates), len(template.GearSpawnRates), len(template.ExclusiveArtifacts))
-
-	// Výpočet tier bonus
-	tierBonus := float64(tier) * tierMultiplier
-	adjustedArtifactRate := baseArtifactSpawnRate + tierBonus
-	adjustedGearRate := baseGearSpawnRate + tierBonus
-	adjustedExclusiveRate := exclusiveSpawnRate + tierBonus
+// spawnItemsInZone - spawn itemov podľa šancí a limitov z aktívnej spawn konfigurácie
+func (h *Handler) spawnItemsInZone(rng spawnRand, zoneID uuid.UUID, tier int, biome string, zoneCenter gameplay.Location, zoneRadius int) {
+	// Šance posunuté prostredím v mieste zóny (noc, počasie, efekty biomu)
+	_, envMods := h.environmentAt(zoneCenter.Latitude, zoneCenter.Longitude, biome, time.Now())
+	template := applyEnvironment(GetZoneTemplate(biome), envMods)
 
-	log.Printf("📊 [DEBUG] Adjusted rates: artifacts=%.2f, gear=%.2f, exclusive=%.2f",
-		adjustedArtifactRate, adjustedGearRate, adjustedExclusiveRate)
-
-	// ✅ ARTIFACT SPAWNING s debug informáciami
-	artifactsSpawned := 0
-	artifactAttempts := 0
-
-	for artifactType, templateRate := range template.ArtifactSpawnRates {
-		// Kombinuj template rate s našou adjusted rate
-		finalRate := templateRate * adjustedArtifactRate
-		roll := rand.Float64()
-
-		log.Printf("🎲 [DEBUG] %s: roll=%.3f vs rate=%.3f (template=%.2f * adjusted=%.2f)",
-			artifactType, roll, finalRate, templateRate, adjustedArtifactRate)
-
-		if roll < finalRate && artifactsSpawned < maxArtifactsPerZone {
-			if err := h.spawnSpecificArtifact(zoneID, artifactType, biome, tier); err != nil {
-				log.Printf("❌ [ERROR] Failed to spawn artifact %s: %v", artifactType, err)
-			} else {
-				artifactsSpawned++
-				log.Printf("💎 [SUCCESS] Spawned artifact: %s (roll %.3f < %.3f)",
-					GetArtifactDisplayName(artifactType), roll, finalRate)
-			}
-		} else if roll >= finalRate {
-			log.Printf("⭕ [SKIP] %s - roll failed", artifactType)
+	result := rollZoneItems(currentSpawnConfig(), template, tier, rng, func(itemType, key string) bool {
+		var err error
+		if itemType == zonestats.ItemGear {
+			err = h.spawnSpecificGear(rng, zoneID, key, biome, tier)
 		} else {
-			log.Printf("🚫 [LIMIT] %s - max artifacts reached", artifactType)
-		}
-		artifactAttempts++
-	}
-
-	// ✅ GUARANTEED MINIMUM ARTIFACTS
-	if artifactsSpawned < minArtifactsPerZone && len(template.ArtifactSpawnRates) > 0 {
-		log.Printf("🔄 [GUARANTEE] Need %d more artifacts for minimum", minArtifactsPerZone-artifactsSpawned)
-
-		// Vyber náhodné artifact types z template
-		artifactTypes := make([]string, 0, len(template.ArtifactSpawnRates))
-		for artifactType := range template.ArtifactSpawnRates {
-			artifactTypes = append(artifactTypes, artifactType)
-		}
-
-		for artifactsSpawned < minArtifactsPerZone && len(artifactTypes) > 0 {
-			randomIndex := rand.Intn(len(artifactTypes))
-			artifactType := artifactTypes[randomIndex]
-
-			if err := h.spawnSpecificArtifact(zoneID, artifactType, biome, tier); err != nil {
-				log.Printf("❌ [GUARANTEE] Failed to spawn guaranteed %s: %v", artifactType, err)
-			} else {
-				artifactsSpawned++
-				log.Printf("💎 [GUARANTEE] Spawned guaranteed: %s", GetArtifactDisplayName(artifactType))
-			}
-
-			// Odstráň z listu aby sa neopakoval
-			artifactTypes = append(artifactTypes[:randomIndex], artifactTypes[randomIndex+1:]...)
-		}
-	}
-
-	// ✅ EXCLUSIVE ARTIFACTS s debug
-	exclusiveSpawned := 0
-	for _, exclusiveType := range template.ExclusiveArtifacts {
-		roll := rand.Float64()
-		log.Printf("🌟 [DEBUG] Exclusive %s: roll=%.3f vs rate=%.3f",
-			exclusiveType, roll, adjustedExclusiveRate)
-
-		if roll < adjustedExclusiveRate && (artifactsSpawned+exclusiveSpawned) < maxArtifactsPerZone {
-			if err := h.spawnSpecificArtifact(zoneID, exclusiveType, biome, tier); err != nil {
-				log.Printf("❌ [ERROR] Failed to spawn exclusive %s: %v", exclusiveType, err)
-			} else {
-				exclusiveSpawned++
-				log.Printf("🌟 [SUCCESS] Spawned EXCLUSIVE: %s", GetArtifactDisplayName(exclusiveType))
-			}
+			err = h.spawnSpecificArtifact(rng, zoneID, key, biome, tier)
 		}
-	}
-
-	// ✅ GEAR SPAWNING s debug informáciami
-	gearSpawned := 0
-	for gearType, templateRate := range template.GearSpawnRates {
-		finalRate := templateRate * adjustedGearRate
-		roll := rand.Float64()
-
-		log.Printf("⚔️ [DEBUG] %s: roll=%.3f vs rate=%.3f", gearType, roll, finalRate)
-
-		if roll < finalRate && gearSpawned < maxGearPerZone {
-			if err := h.spawnSpecificGear(zoneID, gearType, biome, tier); err != nil {
-				log.Printf("❌ [ERROR] Failed to spawn gear %s: %v", gearType, err)
-			} else {
-				gearSpawned++
-				log.Printf("⚔️ [SUCCESS] Spawned gear: %s", GetGearDisplayName(gearType))
-			}
-		}
-	}
-
-	// ✅ GUARANTEED MINIMUM GEAR
-	if gearSpawned < minGearPerZone && len(template.GearSpawnRates) > 0 {
-		log.Printf("🔄 [GUARANTEE] Need %d more gear for minimum", minGearPerZone-gearSpawned)
-
-		gearTypes := make([]string, 0, len(template.GearSpawnRates))
-		for gearType := range template.GearSpawnRates {
-			gearTypes = append(gearTypes, gearType)
-		}
-
-		for gearSpawned < minGearPerZone && len(gearTypes) > 0 {
-			randomIndex := rand.Intn(len(gearTypes))
-			gearType := gearTypes[randomIndex]
-
-			if err := h.spawnSpecificGear(zoneID, gearType, biome, tier); err != nil {
-				log.Printf("❌ [GUARANTEE] Failed to spawn guaranteed %s: %v", gearType, err)
-			} else {
-				gearSpawned++
-				log.Printf("⚔️ [GUARANTEE] Spawned guaranteed: %s", GetGearDisplayName(gearType))
-			}
-
-			gearTypes = append(gearTypes[:randomIndex], gearTypes[randomIndex+1:]...)
+		if err != nil {
+			log.Printf("❌ [ERROR] Failed to spawn %s %s: %v", itemType, key, err)
+			return false
 		}
-	}
-
-	totalArtifacts := artifactsSpawned + exclusiveSpawned
-	log.Printf("✅ [FINAL] Zone spawning complete: %d artifacts (%d regular + %d exclusive), %d gear items",
-		totalArtifacts, artifactsSpawned, exclusiveSpawned, gearSpawned)
-	log.Printf("📊 [STATS] Success rate: artifacts=%d/%d, gear=%d/%d",
-		totalArtifacts, artifactAttempts, gearSpawned, len(template.GearSpawnRates))
-}
-
-// Generate random GPS coordinates within zone radius
-func (h *Handler) generateRandomLocationInZone(center gameplay.Location, radiusMeters int) gameplay.Location {
-	// Random angle (0-360 degrees)
-	angle := rand.Float64() * 2 * math.Pi
-
-	// Random distance (0 to radiusMeters)
-	distance := rand.Float64() * float64(radiusMeters)
-
-	// Convert to GPS coordinates
-	// 1 degree ≈ 111,000 meters at equator
-	latOffset := (distance * math.Cos(angle)) / 111000
-	lngOffset := (distance * math.Sin(angle)) / (111000 * math.Cos(center.Latitude*math.Pi/180))
+		return true
+	})
 
-	return gameplay.Location{
-		Latitude:  center.Latitude + latOffset,
-		Longitude: center.Longitude + lngOffset,
-		Timestamp: time.Now(),
-	}
+	log.Printf("✅ [FINAL] Zone spawning complete (%s, tier %d, env %v): %d artifacts (%d regular + %d exclusive), %d gear items",
+		biome, tier, envMods.Effects, result.Artifacts+result.Exclusive, result.Artifacts, result.Exclusive, result.Gear)
 }
 
 // ============================================
@@ -1236,11 +1057,25 @@ func (h *Handler) GetExpiredZones(c *gin.Context) {
 }
 
 func (h *Handler) GetZoneAnalytics(c *gin.Context) {
+	r, ok := analytics.ParseRange(c)
+	if !ok {
+		return
+	}
+
 	cleanupService := NewCleanupService(h.db)
 	stats := cleanupService.GetCleanupStats()
 
+	// Spawnuté zóny a vstupy z denných rollupov
+	activity, err := analytics.NewService(h.db).ZoneActivity(r)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load zone activity"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"zone_analytics": stats,
+		"activity":       activity,
+		"range":          r,
 		"timestamp":      time.Now().Format(time.RFC3339),
 		"status":         "success",
 	})
@@ -1317,65 +1152,82 @@ func (h *Handler) UseItem(c *gin.Context) {
 	})
 }
 
+// GetLeaderboard - globálne, biome, tier a sezónne (weekly/monthly) rebríčky
+// Query: metric=xp|artifacts|legendary|zones, scope=global|biome|tier|weekly|monthly,
+// biome, tier, period, limit, offset, view=top|me, around
 func (h *Handler) GetLeaderboard(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Get leaderboard not implemented yet",
-		"status": "planned",
-	})
-}
-
-func (h *Handler) GetGameStats(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Get game stats not implemented yet",
-		"status": "planned",
-	})
-}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
 
-func (h *Handler) GetZoneStats(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Get zone stats not implemented yet",
-		"status": "planned",
-	})
-}
+	q := leaderboard.Query{
+		Metric: leaderboard.Metric(c.DefaultQuery("metric", string(leaderboard.MetricXP))),
+		Scope:  leaderboard.Scope(c.DefaultQuery("scope", string(leaderboard.ScopeGlobal))),
+		Biome:  c.Query("biome"),
+		Period: c.Query("period"),
+	}
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	q.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-func (h *Handler) CreateEventZone(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Create event zone not implemented yet",
-		"status": "planned",
-	})
-}
+	if q.Scope == leaderboard.ScopeTier {
+		if tierStr := c.Query("tier"); tierStr != "" {
+			tier, err := strconv.Atoi(tierStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tier"})
+				return
+			}
+			q.Tier = tier
+		} else {
+			// Default to the player's own tier
+			var user auth.User
+			if err := h.db.Select("id, tier").First(&user, "id = ?", userID).Error; err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				return
+			}
+			q.Tier = user.Tier
+		}
+	}
 
-func (h *Handler) UpdateZone(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Update zone not implemented yet",
-		"status": "planned",
-	})
-}
+	if err := h.leaderboard.Normalize(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid leaderboard query",
+			"details": err.Error(),
+		})
+		return
+	}
 
-func (h *Handler) DeleteZone(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Delete zone not implemented yet",
-		"status": "planned",
-	})
-}
+	var (
+		result interface{}
+		err    error
+	)
+	if c.DefaultQuery("view", "top") == "me" {
+		around, _ := strconv.Atoi(c.DefaultQuery("around", "5"))
+		result, err = h.leaderboard.GetMyRank(q, userID.(uuid.UUID), around)
+	} else {
+		result, err = h.leaderboard.GetBoard(q)
+	}
 
-func (h *Handler) SpawnArtifact(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Spawn artifact not implemented yet",
-		"status": "planned",
-	})
-}
+	if err != nil {
+		if errors.Is(err, leaderboard.ErrRedisRequired) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("❌ Failed to load leaderboard: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load leaderboard"})
+		return
+	}
 
-func (h *Handler) SpawnGear(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Spawn gear not implemented yet",
-		"status": "planned",
+	c.JSON(http.StatusOK, gin.H{
+		"leaderboard": result,
+		"timestamp":   time.Now().Format(time.RFC3339),
 	})
 }
 
-func (h *Handler) GetItemAnalytics(c *gin.Context) {
+func (h *Handler) GetGameStats(c *gin.Context) {
 	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Get item analytics not implemented yet",
+		"error":  "Get game stats not implemented yet",
 		"status": "planned",
 	})
 }
@@ -5,16 +5,25 @@ import (
 	"log"
 	"time"
 
+	"geoanomaly/internal/deployable"
+	"geoanomaly/internal/locationhistory"
+	"geoanomaly/internal/notifications"
+	"geoanomaly/internal/realtime"
+	"geoanomaly/internal/user"
+
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Scheduler struct {
-	db             *gorm.DB
-	cleanupService *CleanupService
-	ticker         *time.Ticker
-	ctx            context.Context
-	cancel         context.CancelFunc
-	isRunning      bool
+	db              *gorm.DB
+	cleanupService  *CleanupService
+	locationHistory *locationhistory.Service
+	notifications   *notifications.Service
+	ticker          *time.Ticker
+	ctx             context.Context
+	cancel          context.CancelFunc
+	isRunning       bool
 }
 
 type SchedulerStats struct {
@@ -33,11 +42,13 @@ func NewScheduler(db *gorm.DB) *Scheduler {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Scheduler{
-		db:             db,
-		cleanupService: cleanupService,
-		ctx:            ctx,
-		cancel:         cancel,
-		isRunning:      false,
+		db:              db,
+		cleanupService:  cleanupService,
+		locationHistory: locationhistory.NewService(db),
+		notifications:   notifications.NewService(db),
+		ctx:             ctx,
+		cancel:          cancel,
+		isRunning:       false,
 	}
 }
 
@@ -102,14 +113,38 @@ func (s *Scheduler) run() {
 			result := s.cleanupService.CleanupExpiredZones()
 			s.logCleanupResult(result)
 
-			// Check for zones about to expire (30min warning)
-			s.checkExpiringZones()
+			// Zone lifecycle: aging / expiring (30min "last chance" notifikácie)
+			s.checkZoneLifecycle()
 
 			// Run battery drain for deployed scanners
 			s.drainDeployedScannerBatteries()
 
 			// Update battery charging progress and complete finished sessions
 			s.updateBatteryChargingProgress()
+
+			// Push notifikácie pre dokončený výskum
+			s.notifyFinishedResearch()
+
+			// Location history partitions + retention pruning
+			s.locationHistory.Maintain(time.Now())
+
+			// Staré notifikácie z inboxu
+			s.notifications.Prune(time.Now())
+
+			// Lift temporary bans that have expired
+			if _, err := user.LiftExpiredBans(s.db); err != nil {
+				log.Printf("❌ Failed to lift expired bans: %v", err)
+			}
+
+			// Vypršané hack tools z inventára
+			if swept, err := deployable.SweepExpiredHackTools(s.db); err != nil {
+				log.Printf("❌ Failed to sweep expired hack tools: %v", err)
+			} else if swept > 0 {
+				log.Printf("🗑️ Removed %d expired hack tools", swept)
+			}
+			if _, err := deployable.PruneHackChallenges(s.db, time.Now()); err != nil {
+				log.Printf("❌ Failed to prune minigame challenges: %v", err)
+			}
 		}
 	}
 }
@@ -130,15 +165,24 @@ func (s *Scheduler) logCleanupResult(result CleanupResult) {
 	}
 }
 
-// ✅ Check for zones about to expire
-func (s *Scheduler) checkExpiringZones() {
-	expiringZones := s.cleanupService.GetExpiringZones(30) // 30min warning
+// ✅ Posun životného cyklu zón (aging → expiring "last chance"); udalosti idú listenerom
+func (s *Scheduler) checkZoneLifecycle() {
+	for _, zone := range s.cleanupService.GetAgingZones() {
+		if advanceZoneStage(s.db, zone.ID, ZoneStageAging) {
+			emitZoneLifecycle(ZoneLifecycleEvent{Stage: ZoneStageAging, Zone: zone})
+		}
+	}
 
+	expiringZones := s.cleanupService.GetExpiringZones(int(ZoneLastChanceWarning.Minutes()))
 	if len(expiringZones) > 0 {
-		log.Printf("⚠️ WARNING: %d zones expiring in next 30 minutes:", len(expiringZones))
-		for _, zone := range expiringZones {
-			timeLeft := time.Until(*zone.ExpiresAt)
-			log.Printf("   ⏰ %s expires in %s", zone.Name, timeLeft.Round(time.Minute))
+		log.Printf("⚠️ WARNING: %d zones expiring in next %s:", len(expiringZones), ZoneLastChanceWarning)
+	}
+	for _, zone := range expiringZones {
+		log.Printf("   ⏰ %s expires in %s", zone.Name, time.Until(*zone.ExpiresAt).Round(time.Minute))
+
+		// Varovanie raz za zónu (scheduler beží každých 5 min, aj na viacerých inštanciách)
+		if advanceZoneStage(s.db, zone.ID, ZoneStageExpiring) {
+			emitZoneLifecycle(ZoneLifecycleEvent{Stage: ZoneStageExpiring, Zone: zone})
 		}
 	}
 }
@@ -327,14 +371,31 @@ func (s *Scheduler) updateBatteryChargingProgress() {
 		    updated_at = $1
 		WHERE status = 'active' 
 		AND end_time <= $1
+		RETURNING id, user_id, battery_instance_id, battery_type, slot_number
 	`
 
-	completeResult := s.db.Exec(completeQuery, now)
+	var completed []struct {
+		ID                uuid.UUID
+		UserID            uuid.UUID
+		BatteryInstanceID *uuid.UUID
+		BatteryType       string
+		SlotNumber        int
+	}
+	completeResult := s.db.Raw(completeQuery, now).Scan(&completed)
 	if completeResult.Error != nil {
 		log.Printf("❌ Error completing charging sessions: %v", completeResult.Error)
 		return
 	}
 
+	for _, session := range completed {
+		realtime.NotifyUser(session.UserID, realtime.EventChargingCompleted, map[string]interface{}{
+			"session_id":          session.ID,
+			"battery_instance_id": session.BatteryInstanceID,
+			"battery_type":        session.BatteryType,
+			"slot_number":         session.SlotNumber,
+		})
+	}
+
 	// Update battery charge to 100% for completed sessions (idempotent - safe to run multiple times)
 	batteryUpdateQuery := `
 		UPDATE gameplay.inventory_items ii
@@ -353,5 +414,37 @@ func (s *Scheduler) updateBatteryChargingProgress() {
 	}
 
 	log.Printf("✅ Battery charging progress update completed - Updated: %d, Completed: %d, Batteries charged: %d",
-		result.RowsAffected, completeResult.RowsAffected, batteryResult.RowsAffected)
+		result.RowsAffected, len(completed), batteryResult.RowsAffected)
+}
+
+// ✅ Push notifikácia, keď výskum dobehne (hráč ho potom dokončí cez /research/complete)
+func (s *Scheduler) notifyFinishedResearch() {
+	now := time.Now()
+
+	var finished []struct {
+		ID           uuid.UUID
+		UserID       uuid.UUID
+		ArtifactName string
+		ResearchType string
+		EndTime      time.Time
+	}
+	if err := s.db.Table("laboratory.research_projects").
+		Select("id, user_id, artifact_name, research_type, end_time").
+		Where("status = 'active' AND end_time <= ? AND end_time > ?", now, now.Add(-1*time.Hour)).
+		Scan(&finished).Error; err != nil {
+		log.Printf("❌ Error checking finished research: %v", err)
+		return
+	}
+
+	for _, project := range finished {
+		if !realtime.Once("research_completed:"+project.ID.String(), 2*time.Hour) {
+			continue
+		}
+		realtime.NotifyUser(project.UserID, realtime.EventResearchCompleted, map[string]interface{}{
+			"project_id":    project.ID,
+			"artifact_name": project.ArtifactName,
+			"research_type": project.ResearchType,
+			"finished_at":   project.EndTime.Unix(),
+		})
+	}
 }
@@ -0,0 +1,324 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MaxSpawnTier - najvyšší tier hráča / zóny, pre ktorý musí existovať konfigurácia
+const MaxSpawnTier = 4
+
+// SpawnConfig - verziovaná konfigurácia spawnu (biomy, rarity artefaktov, tiery, item rates).
+// Aktívna verzia sa načítava z DB a dá sa vymeniť bez reštartu servera.
+type SpawnConfig struct {
+	Biomes         map[string]ZoneTemplate `json:"biomes"`
+	Tiers          map[int]TierSpawnConfig `json:"tiers"`
+	ArtifactRarity ArtifactRarityConfig    `json:"artifact_rarity"`
+	Items          ItemSpawnConfig         `json:"items"`
+}
+
+// TierSpawnConfig - nastavenia pre jeden tier.
+// MaxZones a ZoneTierWeights sa čítajú podľa tieru hráča, vzdialenosti a polomery podľa tieru zóny.
+type TierSpawnConfig struct {
+	MinDistance float64 `json:"min_distance"`
+	MaxDistance float64 `json:"max_distance"`
+	MinRadius   float64 `json:"min_radius"`
+	MaxRadius   float64 `json:"max_radius"`
+	MaxZones    int     `json:"max_zones"`
+
+	// Pravdepodobnosť tieru novej zóny (index = tier zóny), súčet = 1.
+	// Biome zóny sa potom vyberá len z biomov dostupných pre jej tier.
+	ZoneTierWeights []float64 `json:"zone_tier_weights"`
+}
+
+// RarityRule - rarity pre skupinu artefaktov, voliteľne vyššia od určitého tieru zóny
+type RarityRule struct {
+	Artifacts      []string `json:"artifacts"`
+	Rarity         string   `json:"rarity"`
+	UpgradeAtTier  int      `json:"upgrade_at_tier,omitempty"`
+	UpgradedRarity string   `json:"upgraded_rarity,omitempty"`
+}
+
+// ArtifactRarityConfig - prvé pravidlo obsahujúce artefakt vyhráva, inak Default
+type ArtifactRarityConfig struct {
+	Rules   []RarityRule `json:"rules"`
+	Default string       `json:"default"`
+}
+
+// ItemSpawnConfig - základné šance a limity pre spawn itemov v zóne
+type ItemSpawnConfig struct {
+	BaseArtifactRate    float64 `json:"base_artifact_rate"`
+	BaseGearRate        float64 `json:"base_gear_rate"`
+	ExclusiveRate       float64 `json:"exclusive_rate"`
+	TierMultiplier      float64 `json:"tier_multiplier"` // bonus k šanci za každý tier zóny
+	MinArtifactsPerZone int     `json:"min_artifacts_per_zone"`
+	MinGearPerZone      int     `json:"min_gear_per_zone"`
+	MaxArtifactsPerZone int     `json:"max_artifacts_per_zone"`
+	MaxGearPerZone      int     `json:"max_gear_per_zone"`
+}
+
+// DefaultSpawnConfig - seed konfigurácia z pôvodných hodnôt v kóde (biomes.go, artifacts.go, constants.go)
+func DefaultSpawnConfig() *SpawnConfig {
+	return &SpawnConfig{
+		Biomes: defaultZoneTemplates(),
+		Tiers: map[int]TierSpawnConfig{
+			0: {Tier0MinDistance, Tier0MaxDistance, Tier0MinRadius, Tier0MaxRadius, 1, []float64{0.55, 0.45}},
+			1: {Tier1MinDistance, Tier1MaxDistance, Tier1MinRadius, Tier1MaxRadius, 2, []float64{0.50, 0.45, 0.05}},
+			2: {Tier2MinDistance, Tier2MaxDistance, Tier2MinRadius, Tier2MaxRadius, 3, []float64{0.30, 0.40, 0.15, 0.15}},
+			3: {Tier3MinDistance, Tier3MaxDistance, Tier3MinRadius, Tier3MaxRadius, 5, []float64{0.20, 0.20, 0.25, 0.35}},
+			4: {Tier4MinDistance, Tier4MaxDistance, Tier4MinRadius, Tier4MaxRadius, 7, []float64{0.15, 0.15, 0.20, 0.25, 0.25}},
+		},
+		ArtifactRarity: ArtifactRarityConfig{
+			Rules: []RarityRule{
+				// Exclusive artefakty sú vždy legendary
+				{
+					Artifacts: []string{"plutonium_core", "reactor_fragment", "control_rod", "pure_toxin", "experimental_serum", "bio_weapon"},
+					Rarity:    "legendary",
+				},
+				{
+					Artifacts:      []string{"uranium_ore", "chemical_compound", "atomic_battery", "nuclear_fuel", "lab_equipment", "electronic_component"},
+					Rarity:         "rare",
+					UpgradeAtTier:  3,
+					UpgradedRarity: "epic",
+				},
+				{
+					Artifacts:      []string{"crystal_shard", "steel_ingot", "electronics", "machinery_parts", "toxic_waste", "contaminated_soil"},
+					Rarity:         "common",
+					UpgradeAtTier:  2,
+					UpgradedRarity: "rare",
+				},
+			},
+			Default: "common",
+		},
+		Items: ItemSpawnConfig{
+			BaseArtifactRate:    0.8,
+			BaseGearRate:        0.7,
+			ExclusiveRate:       0.15,
+			TierMultiplier:      0.1,
+			MinArtifactsPerZone: 1,
+			MinGearPerZone:      1,
+			MaxArtifactsPerZone: 5,
+			MaxGearPerZone:      4,
+		},
+	}
+}
+
+// template - biome template, neznámy biome padá na les
+func (c *SpawnConfig) template(biome string) ZoneTemplate {
+	if template, ok := c.Biomes[biome]; ok {
+		return template
+	}
+	return c.Biomes[BiomeForest]
+}
+
+// tier - nastavenia tieru, neznámy tier padá na tier 0
+func (c *SpawnConfig) tier(tier int) TierSpawnConfig {
+	if t, ok := c.Tiers[tier]; ok {
+		return t
+	}
+	return c.Tiers[0]
+}
+
+func (c *SpawnConfig) maxZones(playerTier int) int {
+	if t, ok := c.Tiers[playerTier]; ok {
+		return t.MaxZones
+	}
+	return 1
+}
+
+// availableBiomes - biomy, ktorých MinTierRequired hráč spĺňa (zoradené kvôli stabilnému výberu)
+func (c *SpawnConfig) availableBiomes(playerTier int) []string {
+	biomes := make([]string, 0, len(c.Biomes))
+	for name, template := range c.Biomes {
+		if template.MinTierRequired <= playerTier {
+			biomes = append(biomes, name)
+		}
+	}
+	sort.Strings(biomes)
+	return biomes
+}
+
+// rollZoneTier - tier novej zóny podľa váh tieru hráča
+func (c *SpawnConfig) rollZoneTier(rng spawnRand, playerTier int) int {
+	t, ok := c.Tiers[playerTier]
+	if !ok || len(t.ZoneTierWeights) == 0 {
+		return 0
+	}
+
+	r := rng.Float64()
+	zoneTier := len(t.ZoneTierWeights) - 1
+	cumulative := 0.0
+	for tier, weight := range t.ZoneTierWeights {
+		cumulative += weight
+		if r < cumulative {
+			zoneTier = tier
+			break
+		}
+	}
+	return zoneTier
+}
+
+func (c *SpawnConfig) artifactRarity(artifactType string, tier int) string {
+	for _, rule := range c.ArtifactRarity.Rules {
+		for _, artifact := range rule.Artifacts {
+			if artifact != artifactType {
+				continue
+			}
+			if rule.UpgradedRarity != "" && tier >= rule.UpgradeAtTier {
+				return rule.UpgradedRarity
+			}
+			return rule.Rarity
+		}
+	}
+	return c.ArtifactRarity.Default
+}
+
+// SpawnConfigErrors - všetky chyby validácie naraz, aby admin nemusel opravovať po jednej
+type SpawnConfigErrors []string
+
+func (e SpawnConfigErrors) Error() string {
+	return "invalid spawn config: " + strings.Join(e, "; ")
+}
+
+var validDangerLevels = map[string]bool{
+	DangerLow:     true,
+	DangerMedium:  true,
+	DangerHigh:    true,
+	DangerExtreme: true,
+}
+
+// Validate - kontrola konzistencie pred uložením / aktiváciou
+func (c *SpawnConfig) Validate() error {
+	var errs SpawnConfigErrors
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+	rate := func(field string, value float64) {
+		if value < 0 || value > 1 || math.IsNaN(value) {
+			add("%s must be between 0 and 1", field)
+		}
+	}
+
+	// Biomy
+	if len(c.Biomes) == 0 {
+		add("at least one biome is required")
+	}
+	if _, ok := c.Biomes[BiomeForest]; !ok {
+		add("biome %q is required as fallback", BiomeForest)
+	}
+	tier0Biome := false
+	for name, template := range c.Biomes {
+		prefix := "biomes." + name
+		if template.Biome != name {
+			add("%s.biome must equal its key", prefix)
+		}
+		if len(template.Names) == 0 {
+			add("%s.names must not be empty", prefix)
+		}
+		if !validDangerLevels[template.DangerLevel] {
+			add("%s.danger_level %q is invalid", prefix, template.DangerLevel)
+		}
+		if template.MinTierRequired < 0 || template.MinTierRequired > MaxSpawnTier {
+			add("%s.min_tier_required must be between 0 and %d", prefix, MaxSpawnTier)
+		}
+		if template.MinTierRequired == 0 {
+			tier0Biome = true
+		}
+		if len(template.ArtifactSpawnRates) == 0 {
+			add("%s.artifact_spawn_rates must not be empty", prefix)
+		}
+		for artifact, value := range template.ArtifactSpawnRates {
+			rate(prefix+".artifact_spawn_rates."+artifact, value)
+		}
+		for gear, value := range template.GearSpawnRates {
+			rate(prefix+".gear_spawn_rates."+gear, value)
+		}
+	}
+	if len(c.Biomes) > 0 && !tier0Biome {
+		add("at least one biome must have min_tier_required 0")
+	}
+
+	// Tiery
+	for tier := 0; tier <= MaxSpawnTier; tier++ {
+		t, ok := c.Tiers[tier]
+		if !ok {
+			add("tiers.%d is missing", tier)
+			continue
+		}
+		prefix := fmt.Sprintf("tiers.%d", tier)
+		if t.MinDistance <= 0 || t.MaxDistance < t.MinDistance {
+			add("%s: distance range must satisfy 0 < min_distance <= max_distance", prefix)
+		}
+		if t.MaxDistance > MaxSpawnRadius {
+			add("%s.max_distance must not exceed %.0fm", prefix, MaxSpawnRadius)
+		}
+		if t.MinRadius <= 0 || t.MaxRadius < t.MinRadius {
+			add("%s: radius range must satisfy 0 < min_radius <= max_radius", prefix)
+		}
+		if t.MaxZones < 0 || t.MaxZones > 20 {
+			add("%s.max_zones must be between 0 and 20", prefix)
+		}
+		if len(t.ZoneTierWeights) == 0 || len(t.ZoneTierWeights) > MaxSpawnTier+1 {
+			add("%s.zone_tier_weights must have 1-%d entries", prefix, MaxSpawnTier+1)
+			continue
+		}
+		sum := 0.0
+		for i, weight := range t.ZoneTierWeights {
+			rate(fmt.Sprintf("%s.zone_tier_weights[%d]", prefix, i), weight)
+			sum += weight
+		}
+		if math.Abs(sum-1) > 0.001 {
+			add("%s.zone_tier_weights must sum to 1 (got %.3f)", prefix, sum)
+		}
+	}
+	for tier := range c.Tiers {
+		if tier < 0 || tier > MaxSpawnTier {
+			add("tiers.%d is out of range 0-%d", tier, MaxSpawnTier)
+		}
+	}
+
+	// Rarity artefaktov
+	if !validItemRarities[c.ArtifactRarity.Default] {
+		add("artifact_rarity.default %q is invalid", c.ArtifactRarity.Default)
+	}
+	for i, rule := range c.ArtifactRarity.Rules {
+		prefix := fmt.Sprintf("artifact_rarity.rules[%d]", i)
+		if len(rule.Artifacts) == 0 {
+			add("%s.artifacts must not be empty", prefix)
+		}
+		if !validItemRarities[rule.Rarity] {
+			add("%s.rarity %q is invalid", prefix, rule.Rarity)
+		}
+		if rule.UpgradedRarity != "" {
+			if !validItemRarities[rule.UpgradedRarity] {
+				add("%s.upgraded_rarity %q is invalid", prefix, rule.UpgradedRarity)
+			}
+			if rule.UpgradeAtTier < 0 || rule.UpgradeAtTier > MaxSpawnTier {
+				add("%s.upgrade_at_tier must be between 0 and %d", prefix, MaxSpawnTier)
+			}
+		}
+	}
+
+	// Item rates
+	rate("items.base_artifact_rate", c.Items.BaseArtifactRate)
+	rate("items.base_gear_rate", c.Items.BaseGearRate)
+	rate("items.exclusive_rate", c.Items.ExclusiveRate)
+	rate("items.tier_multiplier", c.Items.TierMultiplier)
+	if c.Items.MinArtifactsPerZone < 0 || c.Items.MaxArtifactsPerZone < c.Items.MinArtifactsPerZone {
+		add("items: artifacts per zone must satisfy 0 <= min <= max")
+	}
+	if c.Items.MinGearPerZone < 0 || c.Items.MaxGearPerZone < c.Items.MinGearPerZone {
+		add("items: gear per zone must satisfy 0 <= min <= max")
+	}
+	if c.Items.MaxArtifactsPerZone > MaxEventDropTotal || c.Items.MaxGearPerZone > MaxEventDropTotal {
+		add("items: max per zone must not exceed %d", MaxEventDropTotal)
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errs
+	}
+	return nil
+}
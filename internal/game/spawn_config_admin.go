@@ -0,0 +1,299 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"geoanomaly/internal/audit"
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/gameplay"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ============================================
+// ADMIN SPAWN CONFIG (verzie, validácia, hot reload, dry run)
+// ============================================
+
+// CreateSpawnConfigRequest - nová verzia konfigurácie
+type CreateSpawnConfigRequest struct {
+	Config   SpawnConfig `json:"config"`
+	Comment  string      `json:"comment"`
+	Activate bool        `json:"activate"`
+}
+
+// SimulateSpawnConfigRequest - dry run; bez config sa simuluje aktívna verzia
+type SimulateSpawnConfigRequest struct {
+	Config *SpawnConfig `json:"config"`
+	Scans  int          `json:"scans"`
+	Seed   *int64       `json:"seed"`
+}
+
+// GetSpawnConfig - aktívna konfigurácia (GET /admin/spawn-config)
+func (h *Handler) GetSpawnConfig(c *gin.Context) {
+	state := spawnConfigState.Load()
+	if state == nil {
+		state = &activeSpawnConfig{Config: currentSpawnConfig()}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"version":         state.Version,
+		"loaded_at":       state.LoadedAt,
+		"config":          state.Config,
+		"reload_interval": SpawnConfigReloadInterval.String(),
+		"status":          "success",
+	})
+}
+
+// ListSpawnConfigVersions - história verzií bez tela konfigurácie (GET /admin/spawn-config/versions)
+func (h *Handler) ListSpawnConfigVersions(c *gin.Context) {
+	var versions []SpawnConfigVersion
+	if err := h.db.Omit("config").Order("version DESC").Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load spawn config versions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"versions":       versions,
+		"count":          len(versions),
+		"active_version": currentSpawnConfigVersion(),
+	})
+}
+
+// GetSpawnConfigVersion - jedna verzia vrátane konfigurácie (GET /admin/spawn-config/versions/:version)
+func (h *Handler) GetSpawnConfigVersion(c *gin.Context) {
+	version, ok := spawnConfigVersionParam(c)
+	if !ok {
+		return
+	}
+
+	var record SpawnConfigVersion
+	if err := h.db.Where("version = ?", version).First(&record).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Spawn config version not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"version": record})
+}
+
+// ValidateSpawnConfig - validácia bez uloženia (POST /admin/spawn-config/validate)
+func (h *Handler) ValidateSpawnConfig(c *gin.Context) {
+	var config SpawnConfig
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := config.Validate(); err != nil {
+		c.JSON(http.StatusOK, gin.H{"valid": false, "errors": err})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"valid": true, "errors": []string{}})
+}
+
+// CreateSpawnConfigVersion - uloží novú verziu, voliteľne ju hneď aktivuje (POST /admin/spawn-config/versions)
+func (h *Handler) CreateSpawnConfigVersion(c *gin.Context) {
+	var req CreateSpawnConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := req.Config.Validate(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid spawn config", "errors": err})
+		return
+	}
+
+	adminID, _ := c.Get("user_id")
+	actorID, _ := adminID.(uuid.UUID)
+	actorUsername := c.GetString("username")
+
+	var latest int
+	h.db.Model(&SpawnConfigVersion{}).Select("COALESCE(MAX(version), 0)").Scan(&latest)
+
+	record := SpawnConfigVersion{
+		Version:       latest + 1,
+		Config:        req.Config,
+		Comment:       req.Comment,
+		CreatedBy:     &actorID,
+		CreatedByName: actorUsername,
+	}
+	if err := h.db.Create(&record).Error; err != nil {
+		log.Printf("❌ Failed to create spawn config version: %v", err)
+		c.JSON(http.StatusConflict, gin.H{"error": "Failed to create spawn config version, retry"})
+		return
+	}
+
+	h.recordSpawnConfigAction(c, audit.ActionCreateSpawnConfig, record.Version, req.Comment)
+	log.Printf("🧬 Spawn config version %d created by %s", record.Version, actorUsername)
+
+	if req.Activate {
+		activated, err := activateSpawnConfigVersion(h.db, record.Version)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Version created but activation failed", "version": record.Version})
+			return
+		}
+		record = *activated
+		h.recordSpawnConfigAction(c, audit.ActionActivateSpawnConfig, record.Version, req.Comment)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Spawn config version created",
+		"version": record,
+		"active":  record.IsActive,
+	})
+}
+
+// ActivateSpawnConfigVersion - nasadí verziu (aj rollback) bez reštartu (POST /admin/spawn-config/versions/:version/activate)
+func (h *Handler) ActivateSpawnConfigVersion(c *gin.Context) {
+	version, ok := spawnConfigVersionParam(c)
+	if !ok {
+		return
+	}
+	previous := currentSpawnConfigVersion()
+
+	record, err := activateSpawnConfigVersion(h.db, version)
+	if err != nil {
+		var validation SpawnConfigErrors
+		switch {
+		case errors.Is(err, ErrSpawnConfigNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Spawn config version not found"})
+		case errors.As(err, &validation):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid spawn config", "errors": validation})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate spawn config"})
+		}
+		return
+	}
+
+	h.recordSpawnConfigAction(c, audit.ActionActivateSpawnConfig, version, c.Query("reason"))
+	log.Printf("🧬 Spawn config version %d activated by %s (previous: %d)", version, c.GetString("username"), previous)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Spawn config activated",
+		"version":          record,
+		"previous_version": previous,
+	})
+}
+
+// DeleteSpawnConfigVersion - zmaže neaktívnu verziu (DELETE /admin/spawn-config/versions/:version)
+func (h *Handler) DeleteSpawnConfigVersion(c *gin.Context) {
+	version, ok := spawnConfigVersionParam(c)
+	if !ok {
+		return
+	}
+
+	var record SpawnConfigVersion
+	if err := h.db.Omit("config").Where("version = ?", version).First(&record).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Spawn config version not found"})
+		return
+	}
+	if record.IsActive {
+		c.JSON(http.StatusConflict, gin.H{"error": ErrSpawnConfigActive.Error()})
+		return
+	}
+
+	if err := h.db.Delete(&record).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete spawn config version"})
+		return
+	}
+
+	h.recordSpawnConfigAction(c, audit.ActionDeleteSpawnConfig, version, c.Query("reason"))
+	c.JSON(http.StatusOK, gin.H{"message": "Spawn config version deleted", "version": version})
+}
+
+// ReloadSpawnConfig - okamžitá kontrola aktívnej verzie v DB (POST /admin/spawn-config/reload)
+func (h *Handler) ReloadSpawnConfig(c *gin.Context) {
+	changed, err := reloadSpawnConfig(h.db)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload spawn config", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reloaded": changed,
+		"version":  currentSpawnConfigVersion(),
+	})
+}
+
+// SimulateSpawnConfig - dry run: očakávané dropy na 100 scanov pre každý tier (POST /admin/spawn-config/simulate)
+func (h *Handler) SimulateSpawnConfig(c *gin.Context) {
+	var req SimulateSpawnConfigRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	config := currentSpawnConfig()
+	source := "active"
+	if req.Config != nil {
+		if err := req.Config.Validate(); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid spawn config", "errors": err})
+			return
+		}
+		config = req.Config
+		source = "request"
+	}
+
+	scans := req.Scans
+	if scans == 0 {
+		scans = SimulationScansPerTier
+	}
+	if scans < 100 || scans > MaxSimulationScans {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Scans must be between 100 and " + strconv.Itoa(MaxSimulationScans)})
+		return
+	}
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	var categories []gameplay.GearCategory
+	if err := h.db.Where("is_active = ?", true).Find(&categories).Error; err != nil {
+		log.Printf("⚠️ Spawn simulation without gear categories: %v", err)
+	}
+	byID := make(map[string]gameplay.GearCategory, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	simulation := simulateSpawns(config, byID, h.gearService, scans, seed)
+
+	c.JSON(http.StatusOK, gin.H{
+		"source":         source,
+		"active_version": currentSpawnConfigVersion(),
+		"simulation":     simulation,
+	})
+}
+
+func spawnConfigVersionParam(c *gin.Context) (int, bool) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		return 0, false
+	}
+	return version, true
+}
+
+func (h *Handler) recordSpawnConfigAction(c *gin.Context, action string, version int, reason string) {
+	adminID, _ := c.Get("user_id")
+	actorID, _ := adminID.(uuid.UUID)
+
+	record := &audit.AdminAction{
+		ActorID:       actorID,
+		ActorUsername: c.GetString("username"),
+		Action:        action,
+		TargetType:    audit.TargetSpawnConfig,
+		Reason:        reason,
+		After:         auth.JSONB{"version": version},
+	}
+	if err := h.audit.Record(nil, record); err != nil {
+		log.Printf("⚠️ Spawn config %s (version %d) audit record failed", action, version)
+	}
+}
@@ -0,0 +1,193 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SpawnConfigReloadInterval - ako často inštancie kontrolujú zmenu aktívnej verzie
+const SpawnConfigReloadInterval = 30 * time.Second
+
+var (
+	ErrSpawnConfigNotFound = errors.New("spawn config version not found")
+	ErrSpawnConfigActive   = errors.New("active spawn config version cannot be deleted")
+)
+
+// SpawnConfigVersion - nemenná verzia spawn konfigurácie; zmena = nová verzia + aktivácia
+type SpawnConfigVersion struct {
+	ID            uuid.UUID   `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Version       int         `json:"version" gorm:"not null;uniqueIndex"`
+	Config        SpawnConfig `json:"config,omitempty" gorm:"type:jsonb;serializer:json;not null"`
+	Comment       string      `json:"comment,omitempty" gorm:"type:text"`
+	IsActive      bool        `json:"is_active" gorm:"not null;default:false;index:idx_spawn_config_single_active,unique,where:is_active"`
+	CreatedBy     *uuid.UUID  `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedByName string      `json:"created_by_name,omitempty" gorm:"size:50"`
+	CreatedAt     time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	ActivatedAt   *time.Time  `json:"activated_at,omitempty"`
+}
+
+func (SpawnConfigVersion) TableName() string {
+	return "gameplay.spawn_config_versions"
+}
+
+// activeSpawnConfig - práve použitá konfigurácia (atomická výmena pri hot reloade)
+type activeSpawnConfig struct {
+	Version  int          `json:"version"` // 0 = seed z kódu (DB nedostupná)
+	Config   *SpawnConfig `json:"-"`
+	LoadedAt time.Time    `json:"loaded_at"`
+}
+
+var (
+	spawnConfigState    atomic.Pointer[activeSpawnConfig]
+	defaultSpawnConfig  *SpawnConfig
+	defaultSpawnOnce    sync.Once
+	spawnConfigReloadMu sync.Mutex
+)
+
+// currentSpawnConfig - aktívna konfigurácia; pred prvým načítaním seed z kódu
+func currentSpawnConfig() *SpawnConfig {
+	if state := spawnConfigState.Load(); state != nil {
+		return state.Config
+	}
+	defaultSpawnOnce.Do(func() {
+		defaultSpawnConfig = DefaultSpawnConfig()
+	})
+	return defaultSpawnConfig
+}
+
+func currentSpawnConfigVersion() int {
+	if state := spawnConfigState.Load(); state != nil {
+		return state.Version
+	}
+	return 0
+}
+
+// LoadSpawnConfig - načíta aktívnu verziu; prázdnu tabuľku naseeduje hodnotami z kódu ako verziu 1
+func LoadSpawnConfig(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&SpawnConfigVersion{}).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		now := time.Now()
+		seed := SpawnConfigVersion{
+			Version:       1,
+			Config:        *DefaultSpawnConfig(),
+			Comment:       "Seed from built-in defaults",
+			IsActive:      true,
+			CreatedByName: "system",
+			ActivatedAt:   &now,
+		}
+		if err := db.Create(&seed).Error; err != nil {
+			return err
+		}
+		log.Printf("🌱 Spawn config seeded as version 1")
+	}
+
+	_, err := reloadSpawnConfig(db)
+	return err
+}
+
+// reloadSpawnConfig - ak sa aktívna verzia v DB zmenila, vymení ju v pamäti
+func reloadSpawnConfig(db *gorm.DB) (bool, error) {
+	spawnConfigReloadMu.Lock()
+	defer spawnConfigReloadMu.Unlock()
+
+	var active SpawnConfigVersion
+	err := db.Select("version").Where("is_active = ?", true).First(&active).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if active.Version == currentSpawnConfigVersion() {
+		return false, nil
+	}
+
+	if err := db.Where("version = ?", active.Version).First(&active).Error; err != nil {
+		return false, err
+	}
+	// Neplatná verzia sa nenasadí - ostáva predchádzajúca
+	if err := active.Config.Validate(); err != nil {
+		log.Printf("❌ Spawn config version %d is invalid, keeping version %d: %v", active.Version, currentSpawnConfigVersion(), err)
+		return false, err
+	}
+
+	config := active.Config
+	spawnConfigState.Store(&activeSpawnConfig{Version: active.Version, Config: &config, LoadedAt: time.Now()})
+	log.Printf("🔄 Spawn config version %d loaded (%d biomes)", active.Version, len(config.Biomes))
+	return true, nil
+}
+
+// activateSpawnConfigVersion - prepne aktívnu verziu (aj rollback) a hneď ju načíta
+func activateSpawnConfigVersion(db *gorm.DB, version int) (*SpawnConfigVersion, error) {
+	var target SpawnConfigVersion
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("version = ?", version).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrSpawnConfigNotFound
+			}
+			return err
+		}
+		if err := target.Config.Validate(); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&SpawnConfigVersion{}).Where("is_active = ?", true).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		target.IsActive = true
+		target.ActivatedAt = &now
+		return tx.Model(&target).Updates(map[string]interface{}{"is_active": true, "activated_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := reloadSpawnConfig(db); err != nil {
+		log.Printf("⚠️ Spawn config version %d activated but reload failed: %v", version, err)
+	}
+	return &target, nil
+}
+
+// SpawnConfigWatcher - hot reload aktívnej verzie na všetkých inštanciách
+type SpawnConfigWatcher struct {
+	db     *gorm.DB
+	stopCh chan bool
+}
+
+func NewSpawnConfigWatcher(db *gorm.DB) *SpawnConfigWatcher {
+	return &SpawnConfigWatcher{
+		db:     db,
+		stopCh: make(chan bool),
+	}
+}
+
+func (w *SpawnConfigWatcher) Start() {
+	ticker := time.NewTicker(SpawnConfigReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := reloadSpawnConfig(w.db); err != nil {
+				log.Printf("⚠️ Spawn config reload failed: %v", err)
+			}
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+func (w *SpawnConfigWatcher) Stop() {
+	close(w.stopCh)
+}
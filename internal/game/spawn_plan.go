@@ -0,0 +1,302 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/zonestats"
+
+	"github.com/google/uuid"
+)
+
+// spawnRand - zdroj náhody pre spawn rozhodnutia (*rand.Rand z WorldSeed, globálny math/rand pre admin spawny)
+type spawnRand interface {
+	Float64() float64
+	Intn(n int) int
+	Int63() int64
+}
+
+type globalRand struct{}
+
+func (globalRand) Float64() float64 { return rand.Float64() }
+func (globalRand) Intn(n int) int   { return rand.Intn(n) }
+func (globalRand) Int63() int64     { return rand.Int63() }
+
+// guaranteedZoneSpawns - garancia zón pre nízke tiery; vráti tier každej garantovanej
+// zóny (prázdny slice = nič netreba)
+func guaranteedZoneSpawns(rng spawnRand, playerTier int, zonesInSpawnRadius []gameplay.Zone) []int {
+	present := map[int]int{}
+	for _, z := range zonesInSpawnRadius {
+		if z.IsActive {
+			present[z.TierRequired]++
+		}
+	}
+
+	var spawns []int
+	switch playerTier {
+	case 0:
+		// Garantuj 2x tier 0
+		for i := present[0]; i < 2; i++ {
+			spawns = append(spawns, 0)
+		}
+	case 1:
+		// Garantuj aspoň 1x tier 0 a 1x tier 1 zónu
+		if present[0] == 0 {
+			spawns = append(spawns, 0)
+		}
+		if present[1] == 0 {
+			spawns = append(spawns, 1)
+		}
+	case 2:
+		// Ak nie je v okolí žiadna tier 0/1/2, spawn 2 náhodné zóny z {0,1,2}
+		if present[0]+present[1]+present[2] == 0 {
+			spawns = append(spawns, rng.Intn(3), rng.Intn(3))
+		}
+	}
+	return spawns
+}
+
+// planZoneTier - tier i-tej doplnkovej zóny podľa váh tieru hráča
+func planZoneTier(cfg *SpawnConfig, rng spawnRand, playerTier, index int) int {
+	// Tier 0 hráč dostane prvé dve zóny vždy ako tier 0
+	if playerTier == 0 && index < 2 {
+		return 0
+	}
+	return cfg.rollZoneTier(rng, playerTier)
+}
+
+// planZoneTiers - tiery count doplnkových zón pre hráča
+func planZoneTiers(cfg *SpawnConfig, rng spawnRand, playerTier, count int) []int {
+	tiers := make([]int, 0, count)
+	for i := 0; i < count; i++ {
+		tiers = append(tiers, planZoneTier(cfg, rng, playerTier, i))
+	}
+	return tiers
+}
+
+// slotRand - RNG index-tého slotu zón daného tieru v bunke a okne (WorldSeed.SlotRand)
+type slotRand func(zoneTier, index int) spawnRand
+
+// maxSlotSkips - koľko obsadených slotov sa preskočí, kým sa zóna vzdá
+const maxSlotSkips = 20
+
+// plannedZone - rozhodnutie o jednej dynamickej zóne pred zápisom do DB
+type plannedZone struct {
+	ID            uuid.UUID
+	Name          string
+	Tier          int
+	Biome         string
+	Latitude      float64
+	Longitude     float64
+	Radius        int
+	TTL           time.Duration
+	SpawnDistance float64
+}
+
+// planDynamicZones - rozmiestni zóny zadaných tierov okolo kotvy (stred bunky regiónu).
+// Zóna je slot bunky: ID, biome, meno, polomer aj pozícia idú z RNG slotu, nie z tieru
+// hráča, takže hráči rôznych tierov v rovnakom okne dostanú tie isté zóny. Slot, ktorého
+// ID už existuje (aj neaktívna zóna upratená v tom istom okne), sa preskočí na ďalší.
+func planDynamicZones(cfg *SpawnConfig, slots slotRand, anchorLat, anchorLng float64, tiers []int, existing []gameplay.Zone, taken func(uuid.UUID) bool) []plannedZone {
+	var planned []plannedZone
+	next := map[int]int{}
+
+	for _, zoneTier := range tiers {
+		var zone plannedZone
+		var rng spawnRand
+		free := false
+		for skip := 0; skip < maxSlotSkips && !free; skip++ {
+			rng = slots(zoneTier, next[zoneTier])
+			next[zoneTier]++
+			zone = planSlotZone(cfg, rng, zoneTier)
+			free = taken == nil || !taken(zone.ID)
+		}
+		if !free {
+			log.Printf("⚠️ No free zone slot for tier %d after %d tries, skipping spawn.", zoneTier, maxSlotSkips)
+			continue
+		}
+
+		valid := false
+		maxTries := 10
+		for try := 0; try < maxTries; try++ {
+			zone.Latitude, zone.Longitude = generateTierBasedPosition(cfg, rng, anchorLat, anchorLng, zoneTier)
+			if !tooCloseToZones(zone.Latitude, zone.Longitude, existing, planned) {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			log.Printf("⚠️ Could not find free position for zone after %d tries, skipping spawn.", maxTries)
+			continue
+		}
+
+		// Over, či pozícia je v rámci max spawn radius
+		zone.SpawnDistance = CalculateDistance(anchorLat, anchorLng, zone.Latitude, zone.Longitude)
+		if zone.SpawnDistance > MaxSpawnRadius {
+			log.Printf("⚠️ Zone would spawn too far (%.0fm > %.0fm), skipping", zone.SpawnDistance, MaxSpawnRadius)
+			continue
+		}
+
+		planned = append(planned, zone)
+	}
+
+	return planned
+}
+
+// planSlotZone - zóna slotu (všetko okrem pozície, tá závisí od okolitých zón)
+func planSlotZone(cfg *SpawnConfig, rng spawnRand, zoneTier int) plannedZone {
+	biome := selectBiome(cfg, rng, zoneTier)
+	return plannedZone{
+		ID:     zoneID(rng),
+		Name:   generateZoneName(cfg, rng, biome),
+		Tier:   zoneTier,
+		Biome:  biome,
+		Radius: calculateZoneRadius(cfg, rng, zoneTier),
+		TTL:    randomZoneTTL(rng, zoneTier),
+	}
+}
+
+// tooCloseToZones - kontrola vzdialenosti voči už existujúcim aj novo naplánovaným zónam
+func tooCloseToZones(lat, lng float64, existing []gameplay.Zone, planned []plannedZone) bool {
+	for _, z := range existing {
+		if CalculateDistance(lat, lng, z.Location.Latitude, z.Location.Longitude) < MinZoneDistance {
+			return true
+		}
+	}
+	for _, z := range planned {
+		if CalculateDistance(lat, lng, z.Latitude, z.Longitude) < MinZoneDistance {
+			return true
+		}
+	}
+	return false
+}
+
+// randomZoneTTL - TTL podľa tieru / Tier 0 zóny načítavajú hodnoty z constants.go a upravujú ich životnosť
+func randomZoneTTL(rng spawnRand, zoneTier int) time.Duration {
+	minTTL := time.Duration(ZoneMinExpiryHours) * time.Hour
+	maxTTL := time.Duration(ZoneMaxExpiryHours) * time.Hour
+	if zoneTier == 0 {
+		minTTL = time.Duration(Tier0MinExpiryMinutes) * time.Minute
+		maxTTL = time.Duration(Tier0MaxExpiryMinutes) * time.Minute
+	}
+	return minTTL + time.Duration(rng.Float64()*float64(maxTTL-minTTL))
+}
+
+// generateZoneName - náhodné meno z templatu biomu
+func generateZoneName(cfg *SpawnConfig, rng spawnRand, biome string) string {
+	template := cfg.template(biome)
+	if len(template.Names) == 0 {
+		return fmt.Sprintf("Unknown %s Zone", biome)
+	}
+	return template.Names[rng.Intn(len(template.Names))]
+}
+
+// generateTierBasedPosition - pozícia vo vzdialenostnom pásme tieru zóny
+func generateTierBasedPosition(cfg *SpawnConfig, rng spawnRand, centerLat, centerLng float64, zoneTier int) (float64, float64) {
+	tier := cfg.tier(zoneTier)
+	minDistance, maxDistance := tier.MinDistance, tier.MaxDistance
+
+	// Random angle (0-360 degrees)
+	angle := rng.Float64() * 2 * math.Pi
+
+	// Random distance within tier range
+	distance := minDistance + rng.Float64()*(maxDistance-minDistance)
+
+	// Convert to GPS coordinates using Haversine
+	earthRadius := 6371000.0 // meters
+
+	latOffset := (distance * math.Cos(angle)) / earthRadius * (180 / math.Pi)
+	lngOffset := (distance * math.Sin(angle)) / earthRadius * (180 / math.Pi) / math.Cos(centerLat*math.Pi/180)
+
+	return centerLat + latOffset, centerLng + lngOffset
+}
+
+func selectBiome(cfg *SpawnConfig, rng spawnRand, tier int) string {
+	availableBiomes := cfg.availableBiomes(tier)
+	if len(availableBiomes) == 0 {
+		return BiomeForest // fallback
+	}
+	return availableBiomes[rng.Intn(len(availableBiomes))]
+}
+
+// zoneItemRoll - výsledok rollZoneItems
+type zoneItemRoll struct {
+	Artifacts int
+	Exclusive int
+	Gear      int
+}
+
+// rollZoneItems - rozhodne, ktoré itemy sa v zóne spawnú. spawn vytvorí item a vráti,
+// či sa podaril (neúspech sa nepočíta do limitov, garancia skúsi iný typ).
+func rollZoneItems(cfg *SpawnConfig, template ZoneTemplate, tier int, rng spawnRand, spawn func(itemType, key string) bool) zoneItemRoll {
+	items := cfg.Items
+	tierBonus := float64(tier) * items.TierMultiplier
+	artifactRate := items.BaseArtifactRate + tierBonus
+	gearRate := items.BaseGearRate + tierBonus
+	exclusiveRate := items.ExclusiveRate + tierBonus
+
+	var result zoneItemRoll
+
+	artifactTypes := sortedRateKeys(template.ArtifactSpawnRates)
+	for _, artifactType := range artifactTypes {
+		if rng.Float64() < template.ArtifactSpawnRates[artifactType]*artifactRate && result.Artifacts < items.MaxArtifactsPerZone {
+			if spawn(zonestats.ItemArtifact, artifactType) {
+				result.Artifacts++
+			}
+		}
+	}
+	result.Artifacts += guaranteeItems(rng, artifactTypes, items.MinArtifactsPerZone-result.Artifacts, func(key string) bool {
+		return spawn(zonestats.ItemArtifact, key)
+	})
+
+	for _, exclusiveType := range template.ExclusiveArtifacts {
+		if rng.Float64() < exclusiveRate && result.Artifacts+result.Exclusive < items.MaxArtifactsPerZone {
+			if spawn(zonestats.ItemArtifact, exclusiveType) {
+				result.Exclusive++
+			}
+		}
+	}
+
+	gearTypes := sortedRateKeys(template.GearSpawnRates)
+	for _, gearType := range gearTypes {
+		if rng.Float64() < template.GearSpawnRates[gearType]*gearRate && result.Gear < items.MaxGearPerZone {
+			if spawn(zonestats.ItemGear, gearType) {
+				result.Gear++
+			}
+		}
+	}
+	result.Gear += guaranteeItems(rng, gearTypes, items.MinGearPerZone-result.Gear, func(key string) bool {
+		return spawn(zonestats.ItemGear, key)
+	})
+
+	return result
+}
+
+// guaranteeItems - dospawnuje chýbajúce minimum, každý typ skúsi najviac raz
+func guaranteeItems(rng spawnRand, keys []string, missing int, spawn func(key string) bool) int {
+	candidates := append([]string(nil), keys...)
+	spawned := 0
+	for spawned < missing && len(candidates) > 0 {
+		i := rng.Intn(len(candidates))
+		if spawn(candidates[i]) {
+			spawned++
+		}
+		candidates = append(candidates[:i], candidates[i+1:]...)
+	}
+	return spawned
+}
+
+func sortedRateKeys(rates map[string]float64) []string {
+	keys := make([]string, 0, len(rates))
+	for key := range rates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
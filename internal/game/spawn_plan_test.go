@@ -0,0 +1,204 @@
+package game
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"geoanomaly/internal/gameplay"
+
+	"github.com/google/uuid"
+)
+
+var testScanTime = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func planTestZones(world WorldSeed, lat, lng float64, playerTier, count int, existing []gameplay.Zone) []plannedZone {
+	cfg := DefaultSpawnConfig()
+	cell := world.Cell(lat, lng)
+	tiers := planZoneTiers(cfg, world.Rand(cell, testScanTime, "scan"), playerTier, count)
+	return planSlotZones(world, lat, lng, tiers, existing, nil)
+}
+
+func planSlotZones(world WorldSeed, lat, lng float64, tiers []int, existing []gameplay.Zone, taken func(uuid.UUID) bool) []plannedZone {
+	cell := world.Cell(lat, lng)
+	anchorLat, anchorLng := world.CellCenter(cell)
+	slots := func(zoneTier, index int) spawnRand { return world.SlotRand(cell, testScanTime, zoneTier, index) }
+	return planDynamicZones(DefaultSpawnConfig(), slots, anchorLat, anchorLng, tiers, existing, taken)
+}
+
+func TestPlanDynamicZones_SameLayoutForPlayersInCell(t *testing.T) {
+	world := NewWorldSeed("test-secret")
+
+	a := planTestZones(world, 48.14860, 17.10770, 1, 3, nil)
+	b := planTestZones(world, 48.14870, 17.10790, 1, 3, nil)
+	if len(a) == 0 {
+		t.Fatal("expected planned zones")
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("layouts differ:\n%+v\n%+v", a, b)
+	}
+}
+
+func TestPlanDynamicZones_SameZonesForDifferentTiers(t *testing.T) {
+	world := NewWorldSeed("test-secret")
+
+	// Tier 0 aj tier 3 hráč, ktorým vyjde tier 0 zóna, dostanú ten istý slot bunky
+	low := planSlotZones(world, 48.14860, 17.10770, []int{0, 0}, nil, nil)
+	high := planSlotZones(world, 48.14870, 17.10790, []int{2, 0, 0}, nil, nil)
+	if len(low) != 2 || len(high) != 3 {
+		t.Fatalf("expected 2 and 3 zones, got %d and %d", len(low), len(high))
+	}
+	if low[0].ID != high[1].ID || low[1].ID != high[2].ID {
+		t.Fatalf("tier 0 zones differ between players: %s,%s vs %s,%s", low[0].ID, low[1].ID, high[1].ID, high[2].ID)
+	}
+}
+
+func TestPlanDynamicZones_SkipsTakenSlots(t *testing.T) {
+	world := NewWorldSeed("test-secret")
+	first := planSlotZones(world, 48.1486, 17.1077, []int{1}, nil, nil)
+	if len(first) != 1 {
+		t.Fatalf("expected 1 zone, got %d", len(first))
+	}
+
+	// Zóna zo slotu bola v tomto okne upratená - riadok s jej ID ostal neaktívny
+	taken := func(id uuid.UUID) bool { return id == first[0].ID }
+	again := planSlotZones(world, 48.1486, 17.1077, []int{1, 1}, nil, taken)
+	if len(again) != 2 {
+		t.Fatalf("expected 2 zones from the next slots, got %d", len(again))
+	}
+	for _, z := range again {
+		if z.ID == first[0].ID {
+			t.Errorf("taken slot %s was planned again", z.ID)
+		}
+	}
+}
+
+func TestPlanDynamicZones_ExactLayout(t *testing.T) {
+	got := planSlotZones(NewWorldSeed("test-secret"), 48.1486, 17.1077, []int{1, 0, 2}, nil, nil)
+
+	want := []struct {
+		id     string
+		name   string
+		tier   int
+		biome  string
+		radius int
+		lat    float64
+		lng    float64
+	}{
+		{"a46c0364-70fd-5c90-8a15-71449f37f0df", "Parking Garage", 1, "urban", 212, 48.147860, 17.113388},
+		{"c1122069-f273-5e32-b0cb-29c23c8c8b21", "Beast Territory", 0, "forest", 178, 48.146449, 17.109170},
+		{"16d36562-363f-5393-be17-6561911bc00e", "Machinery Graveyard", 2, "industrial", 233, 48.152914, 17.108497},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d zones, got %d: %+v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.ID.String() != w.id || g.Name != w.name || g.Tier != w.tier || g.Biome != w.biome || g.Radius != w.radius {
+			t.Errorf("zone %d: got %s %q tier %d %s r%d, want %s %q tier %d %s r%d",
+				i, g.ID, g.Name, g.Tier, g.Biome, g.Radius, w.id, w.name, w.tier, w.biome, w.radius)
+		}
+		if math.Abs(g.Latitude-w.lat) > 1e-6 || math.Abs(g.Longitude-w.lng) > 1e-6 {
+			t.Errorf("zone %d: got position %.6f,%.6f, want %.6f,%.6f", i, g.Latitude, g.Longitude, w.lat, w.lng)
+		}
+	}
+}
+
+func TestPlanDynamicZones_Constraints(t *testing.T) {
+	world := NewWorldSeed("test-secret")
+	cfg := DefaultSpawnConfig()
+
+	for playerTier := 0; playerTier <= MaxSpawnTier; playerTier++ {
+		planned := planTestZones(world, 48.1486, 17.1077, playerTier, cfg.maxZones(playerTier), nil)
+		for i, z := range planned {
+			if z.SpawnDistance > MaxSpawnRadius {
+				t.Errorf("tier %d zone %d: spawn distance %.0fm over max", playerTier, i, z.SpawnDistance)
+			}
+			if tier := cfg.tier(z.Tier); float64(z.Radius) < tier.MinRadius || float64(z.Radius) > tier.MaxRadius {
+				t.Errorf("tier %d zone %d: radius %d outside %.0f-%.0f", playerTier, i, z.Radius, tier.MinRadius, tier.MaxRadius)
+			}
+			for _, other := range planned[:i] {
+				if d := CalculateDistance(z.Latitude, z.Longitude, other.Latitude, other.Longitude); d < MinZoneDistance {
+					t.Errorf("tier %d zone %d: %.0fm from another zone", playerTier, i, d)
+				}
+			}
+		}
+	}
+}
+
+func TestPlanDynamicZones_AvoidsExistingZones(t *testing.T) {
+	world := NewWorldSeed("test-secret")
+	free := planTestZones(world, 48.1486, 17.1077, 1, 1, nil)
+	if len(free) != 1 {
+		t.Fatalf("expected 1 zone, got %d", len(free))
+	}
+
+	// Existujúca zóna presne na naplánovanej pozícii
+	existing := []gameplay.Zone{{Location: gameplay.Location{Latitude: free[0].Latitude, Longitude: free[0].Longitude}}}
+	for _, z := range planTestZones(world, 48.1486, 17.1077, 1, 1, existing) {
+		if d := CalculateDistance(z.Latitude, z.Longitude, free[0].Latitude, free[0].Longitude); d < MinZoneDistance {
+			t.Errorf("zone planned %.0fm from existing zone", d)
+		}
+	}
+}
+
+func TestRollZoneItems_SameZoneSameItems(t *testing.T) {
+	world := NewWorldSeed("test-secret")
+	cfg := DefaultSpawnConfig()
+	zone := planTestZones(world, 48.1486, 17.1077, 1, 1, nil)[0]
+
+	roll := func() []string {
+		var spawned []string
+		rollZoneItems(cfg, cfg.template(zone.Biome), zone.Tier, world.ZoneRand(zone.ID), func(itemType, key string) bool {
+			spawned = append(spawned, itemType+":"+key)
+			return true
+		})
+		return spawned
+	}
+
+	a, b := roll(), roll()
+	if len(a) == 0 {
+		t.Fatal("expected spawned items")
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("items differ: %v vs %v", a, b)
+	}
+}
+
+func TestGuaranteedZoneSpawns(t *testing.T) {
+	zone := func(tier int) gameplay.Zone {
+		return gameplay.Zone{TierRequired: tier, IsActive: true}
+	}
+
+	tests := []struct {
+		name       string
+		playerTier int
+		zones      []gameplay.Zone
+		want       []int
+	}{
+		{"tier 0 empty area", 0, nil, []int{0, 0}},
+		{"tier 0 one zone", 0, []gameplay.Zone{zone(0)}, []int{0}},
+		{"tier 0 inactive zones ignored", 0, []gameplay.Zone{{TierRequired: 0}}, []int{0, 0}},
+		{"tier 1 empty area", 1, nil, []int{0, 1}},
+		{"tier 1 has tier 0", 1, []gameplay.Zone{zone(0)}, []int{1}},
+		{"tier 1 satisfied", 1, []gameplay.Zone{zone(0), zone(1)}, nil},
+		{"tier 2 has low tier", 2, []gameplay.Zone{zone(1)}, nil},
+		{"tier 3 never guaranteed", 3, nil, nil},
+	}
+
+	world := NewWorldSeed("test-secret")
+	for _, tt := range tests {
+		got := guaranteedZoneSpawns(world.Rand(RegionCell{}, testScanTime, "scan"), tt.playerTier, tt.zones)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Tier 2 v prázdnej oblasti - 2 zóny z {0,1,2}
+	got := guaranteedZoneSpawns(world.Rand(RegionCell{}, testScanTime, "scan"), 2, nil)
+	if len(got) != 2 || got[0] > 2 || got[1] > 2 {
+		t.Errorf("tier 2 empty area: got %v", got)
+	}
+}
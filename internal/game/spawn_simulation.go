@@ -0,0 +1,144 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+
+	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/zonestats"
+)
+
+// Dry run simulácia spawn konfigurácie
+const (
+	SimulationScansPerTier   = 2000 // výsledky sa prepočítajú na 100 scanov
+	MaxSimulationScans       = 20000
+	simulationResultPerScans = 100.0
+)
+
+// TierSpawnSimulation - očakávané hodnoty na 100 scanov hráča daného tieru
+type TierSpawnSimulation struct {
+	PlayerTier        int                `json:"player_tier"`
+	Zones             float64            `json:"zones"`
+	ZonesByTier       map[int]float64    `json:"zones_by_tier"`
+	ZonesByBiome      map[string]float64 `json:"zones_by_biome"`
+	Artifacts         float64            `json:"artifacts"`
+	ExclusiveDrops    float64            `json:"exclusive_artifacts"`
+	Gear              float64            `json:"gear"`
+	ArtifactsByRarity map[string]float64 `json:"artifacts_by_rarity"`
+	GearByRarity      map[string]float64 `json:"gear_by_rarity"`
+	ArtifactsByType   map[string]float64 `json:"artifacts_by_type"`
+	GearByType        map[string]float64 `json:"gear_by_type"`
+}
+
+// SpawnSimulation - výsledok dry runu pre všetky tiery
+type SpawnSimulation struct {
+	Seed         int64                 `json:"seed"`
+	ScansPerTier int                   `json:"scans_per_tier"`
+	PerScans     int                   `json:"per_scans"`
+	Assumptions  []string              `json:"assumptions"`
+	Tiers        []TierSpawnSimulation `json:"tiers"`
+}
+
+// simulateSpawns - prejde rovnaké rozhodovanie ako ScanArea → spawnDynamicZones → spawnItemsInZone,
+// ale so seedovaným RNG a bez zápisu do DB. gearCategories určujú, ktorý gear sa reálne vytvorí.
+func simulateSpawns(cfg *SpawnConfig, gearCategories map[string]gameplay.GearCategory, gearService *GearService, scans int, seed int64) SpawnSimulation {
+	rng := rand.New(rand.NewSource(seed))
+	scale := simulationResultPerScans / float64(scans)
+
+	result := SpawnSimulation{
+		Seed:         seed,
+		ScansPerTier: scans,
+		PerScans:     int(simulationResultPerScans),
+		Assumptions: []string{
+			"each scan happens in an empty area (no existing zones within spawn radius)",
+			"every planned zone finds a free position",
+			"gear categories missing in the database spawn via fallback",
+			"neutral environment (day, clear weather) - night and weather modifiers are not applied",
+		},
+	}
+
+	for playerTier := 0; playerTier <= MaxSpawnTier; playerTier++ {
+		sim := TierSpawnSimulation{
+			PlayerTier:        playerTier,
+			ZonesByTier:       map[int]float64{},
+			ZonesByBiome:      map[string]float64{},
+			ArtifactsByRarity: map[string]float64{},
+			GearByRarity:      map[string]float64{},
+			ArtifactsByType:   map[string]float64{},
+			GearByType:        map[string]float64{},
+		}
+
+		for scan := 0; scan < scans; scan++ {
+			// Rovnaké poradie ako ScanArea: garancia, potom doplnenie do max_zones
+			calls := guaranteedZoneSpawns(rng, playerTier, nil)
+			for extra := cfg.maxZones(playerTier) - len(calls); extra > 0; extra-- {
+				calls = append(calls, -1)
+			}
+
+			index := 0
+			for _, zoneTier := range calls {
+				if zoneTier < 0 {
+					// Doplnkové zóny - tier podľa váh tieru hráča
+					zoneTier = planZoneTier(cfg, rng, playerTier, index)
+					index++
+				}
+
+				biome := selectBiome(cfg, rng, zoneTier)
+				sim.Zones++
+				sim.ZonesByTier[zoneTier]++
+				sim.ZonesByBiome[biome]++
+
+				roll := rollZoneItems(cfg, cfg.template(biome), zoneTier, rng, func(itemType, key string) bool {
+					if itemType == zonestats.ItemArtifact {
+						sim.ArtifactsByRarity[cfg.artifactRarity(key, zoneTier)]++
+						sim.ArtifactsByType[key]++
+						return true
+					}
+
+					rarity := gearService.getRarityForLevel(zoneTier + 1)
+					if category, ok := gearCategories[key]; ok {
+						// Rovnaké kontroly ako createGearInZone
+						if category.Level > zoneTier || (category.Biome != "all" && category.Biome != biome) {
+							return false
+						}
+						rarity = category.Rarity
+					}
+					sim.GearByRarity[rarity]++
+					sim.GearByType[key]++
+					return true
+				})
+				sim.Artifacts += float64(roll.Artifacts + roll.Exclusive)
+				sim.ExclusiveDrops += float64(roll.Exclusive)
+				sim.Gear += float64(roll.Gear)
+			}
+		}
+
+		sim.Zones = round2(sim.Zones * scale)
+		sim.Artifacts = round2(sim.Artifacts * scale)
+		sim.ExclusiveDrops = round2(sim.ExclusiveDrops * scale)
+		sim.Gear = round2(sim.Gear * scale)
+		scaleIntMap(sim.ZonesByTier, scale)
+		for _, m := range []map[string]float64{sim.ZonesByBiome, sim.ArtifactsByRarity, sim.GearByRarity, sim.ArtifactsByType, sim.GearByType} {
+			scaleMap(m, scale)
+		}
+		result.Tiers = append(result.Tiers, sim)
+	}
+
+	return result
+}
+
+func scaleMap(m map[string]float64, scale float64) {
+	for k, v := range m {
+		m[k] = round2(v * scale)
+	}
+}
+
+func scaleIntMap(m map[int]float64, scale float64) {
+	for k, v := range m {
+		m[k] = round2(v * scale)
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -1,8 +1,15 @@
 package game
 
 import (
+	"geoanomaly/internal/audit"
+	"geoanomaly/internal/environment"
 	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/leaderboard"
 	"geoanomaly/internal/loadout"
+	"geoanomaly/internal/menu"
+	"geoanomaly/internal/movement"
+	"geoanomaly/internal/zonestats"
+	"geoanomaly/pkg/geoquery"
 	"time"
 
 	redis_client "github.com/redis/go-redis/v9"
@@ -15,6 +22,14 @@ type Handler struct {
 	redis          *redis_client.Client
 	loadoutService *loadout.Service
 	gearService    *GearService
+	leaderboard    *leaderboard.Service
+	audit          *audit.Service
+	geo            *geoquery.Querier
+	movement       *movement.Service
+	zoneStats      *zonestats.Service
+	world          WorldSeed
+	environment    *environment.Service
+	menu           *menu.Service
 }
 
 // Request/Response struktury
@@ -31,6 +46,9 @@ type ScanAreaResponse struct {
 	MaxZones          int               `json:"max_zones"`
 	CurrentZoneCount  int               `json:"current_zone_count"`
 	PlayerTier        int               `json:"player_tier"`
+	WorldCell         string            `json:"world_cell"`   // bunka regiónu (seed) - pre bug reporty
+	WorldWindow       int64             `json:"world_window"` // časové okno seedu
+	Environment       environment.State `json:"environment"`
 }
 
 type ZoneWithDetails struct {
@@ -87,6 +105,55 @@ type SessionStats struct {
 	DangerLevelFaced    string  `json:"danger_level_faced"`
 }
 
+// Event zone (admin) requests
+type EventDropEntry struct {
+	ItemType string `json:"item_type" binding:"required,oneof=artifact gear"`
+	Type     string `json:"type" binding:"required"`
+	Rarity   string `json:"rarity,omitempty"`   // override rarity, inak podľa typu/tieru
+	Quantity int    `json:"quantity,omitempty"` // default 1
+}
+
+type CreateEventZoneRequest struct {
+	Name         string           `json:"name" binding:"required,max=100"`
+	Description  string           `json:"description"`
+	EventType    string           `json:"event_type"`
+	Biome        string           `json:"biome" binding:"required"`
+	DangerLevel  string           `json:"danger_level"` // default podľa biome template
+	TierRequired int              `json:"tier_required" binding:"min=0,max=4"`
+	Location     LocationPoint    `json:"location" binding:"required"`
+	RadiusMeters int              `json:"radius_meters" binding:"required,min=10"`
+	StartsAt     *time.Time       `json:"starts_at,omitempty"` // nil = hneď
+	EndsAt       *time.Time       `json:"ends_at,omitempty"`   // nil = permanentná zóna
+	Capacity     int              `json:"capacity,omitempty"`  // 0 = bez limitu
+	DropTable    []EventDropEntry `json:"drop_table"`
+}
+
+type UpdateZoneRequest struct {
+	Name         *string           `json:"name,omitempty"`
+	Description  *string           `json:"description,omitempty"`
+	EventType    *string           `json:"event_type,omitempty"`
+	Biome        *string           `json:"biome,omitempty"`
+	DangerLevel  *string           `json:"danger_level,omitempty"`
+	TierRequired *int              `json:"tier_required,omitempty"`
+	Location     *LocationPoint    `json:"location,omitempty"`
+	RadiusMeters *int              `json:"radius_meters,omitempty"`
+	StartsAt     *time.Time        `json:"starts_at,omitempty"`
+	EndsAt       *time.Time        `json:"ends_at,omitempty"`
+	Capacity     *int              `json:"capacity,omitempty"`
+	DropTable    *[]EventDropEntry `json:"drop_table,omitempty"`
+	IsActive     *bool             `json:"is_active,omitempty"`
+}
+
+// Admin spawn request (artifact aj gear)
+type AdminSpawnRequest struct {
+	Type       string         `json:"type" binding:"required"`
+	Count      int            `json:"count,omitempty"`      // default 1
+	Rarity     string         `json:"rarity,omitempty"`     // override rarity
+	Location   *LocationPoint `json:"location,omitempty"`   // presná pozícia, inak náhodne v zóne
+	Properties gameplay.JSONB `json:"properties,omitempty"` // custom properties
+	Reason     string         `json:"reason,omitempty"`     // napr. "community event", "bug #123"
+}
+
 type ZoneTemplate struct {
 	Names                []string               `json:"names"`
 	Biome                string                 `json:"biome"`
@@ -114,5 +181,25 @@ func NewHandler(db *gorm.DB, redisClient *redis_client.Client) *Handler {
 		redis:          redisClient,
 		loadoutService: loadout.NewService(db),
 		gearService:    NewGearService(db),
+		leaderboard:    leaderboard.NewService(db, redisClient),
+		audit:          audit.NewService(db),
+		geo:            geoquery.New(db),
+		movement:       movement.NewService(db),
+		zoneStats:      zonestats.NewService(db),
+		world:          NewWorldSeed(""),
+		environment:    environment.NewService(environment.NewLocalProvider()),
+		menu:           menu.NewService(db),
 	}
 }
+
+// WithWorldSeed - secret pre generovanie sveta (rovnaký na všetkých inštanciách)
+func (h *Handler) WithWorldSeed(seed WorldSeed) *Handler {
+	h.world = seed
+	return h
+}
+
+// WithEnvironment - vlastný zdroj podmienok (predvolene offline stub)
+func (h *Handler) WithEnvironment(env *environment.Service) *Handler {
+	h.environment = env
+	return h
+}
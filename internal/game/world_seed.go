@@ -0,0 +1,105 @@
+package game
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// World generation - deterministický seed podľa bunky regiónu a časového okna
+const (
+	WorldCellSizeMeters = 250.0            // veľkosť bunky regiónu
+	WorldSeedWindow     = 15 * time.Minute // časové okno, v ktorom je svet v bunke rovnaký
+	metersPerDegree     = 111320.0
+)
+
+// worldZoneNamespace - namespace pre deterministické ID zón (UUID v5)
+var worldZoneNamespace = uuid.MustParse("6f1c1f0e-6a9b-4c62-9a1d-3d0f6b1e2a77")
+
+// RegionCell - bunka mriežky, v ktorej sa generuje svet
+type RegionCell struct {
+	X int64 `json:"x"`
+	Y int64 `json:"y"`
+}
+
+func (c RegionCell) String() string {
+	return fmt.Sprintf("%d:%d", c.X, c.Y)
+}
+
+// WorldSeed - zdroj deterministickej náhody pre generovanie sveta.
+// Rovnaký secret + bunka + okno + účel = rovnaká postupnosť, takže dvaja hráči
+// skenujúci to isté miesto v rovnakom čase dostanú rovnaký svet a testy vedia
+// overiť presný layout.
+type WorldSeed struct {
+	Secret   string
+	CellSize float64
+	Window   time.Duration
+}
+
+func NewWorldSeed(secret string) WorldSeed {
+	return WorldSeed{
+		Secret:   secret,
+		CellSize: WorldCellSizeMeters,
+		Window:   WorldSeedWindow,
+	}
+}
+
+// Cell - bunka, do ktorej patrí pozícia (riadky podľa lat, stĺpce škálované cos(lat) riadku)
+func (w WorldSeed) Cell(lat, lng float64) RegionCell {
+	y := int64(math.Floor(lat * metersPerDegree / w.CellSize))
+	return RegionCell{X: int64(math.Floor(lng * w.lngMetersPerDegree(y) / w.CellSize)), Y: y}
+}
+
+// CellCenter - stred bunky; zóny sa spawnujú okolo neho, nie okolo presnej polohy hráča
+func (w WorldSeed) CellCenter(cell RegionCell) (float64, float64) {
+	lat := (float64(cell.Y) + 0.5) * w.CellSize / metersPerDegree
+	lng := (float64(cell.X) + 0.5) * w.CellSize / w.lngMetersPerDegree(cell.Y)
+	return lat, lng
+}
+
+func (w WorldSeed) lngMetersPerDegree(row int64) float64 {
+	rowLat := (float64(row) + 0.5) * w.CellSize / metersPerDegree
+	return metersPerDegree * math.Max(math.Cos(rowLat*math.Pi/180), 0.01)
+}
+
+// WindowIndex - poradové číslo časového okna
+func (w WorldSeed) WindowIndex(at time.Time) int64 {
+	return at.Unix() / int64(w.Window/time.Second)
+}
+
+// Rand - RNG pre bunku, časové okno a účel (napr. "scan")
+func (w WorldSeed) Rand(cell RegionCell, at time.Time, purpose string) *rand.Rand {
+	return rand.New(rand.NewSource(w.seed(cell.String(), strconv.FormatInt(w.WindowIndex(at), 10), purpose)))
+}
+
+// SlotRand - RNG index-tého slotu zón daného tieru v bunke a okne; slot určuje celú zónu
+// (ID, biome, meno, pozíciu), takže je rovnaký pre všetkých hráčov bez ohľadu na ich tier
+func (w WorldSeed) SlotRand(cell RegionCell, at time.Time, zoneTier, index int) *rand.Rand {
+	return w.Rand(cell, at, fmt.Sprintf("zone:tier%d:%d", zoneTier, index))
+}
+
+// ZoneRand - RNG pre obsah konkrétnej zóny (itemy, pozície), odvodený od jej ID
+func (w WorldSeed) ZoneRand(zoneID uuid.UUID) *rand.Rand {
+	return rand.New(rand.NewSource(w.seed("zone", zoneID.String())))
+}
+
+// zoneID - deterministické ID zóny z RNG slotu; súbežné scany
+// rovnakej bunky vygenerujú rovnaké ID a druhý insert sa preskočí
+func zoneID(rng spawnRand) uuid.UUID {
+	return uuid.NewSHA1(worldZoneNamespace, []byte(strconv.FormatInt(rng.Int63(), 36)))
+}
+
+func (w WorldSeed) seed(parts ...string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(w.Secret))
+	for _, part := range parts {
+		h.Write([]byte{0})
+		h.Write([]byte(part))
+	}
+	return int64(h.Sum64() >> 1)
+}
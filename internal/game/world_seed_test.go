@@ -0,0 +1,92 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorldSeed_SameCellAndWindow(t *testing.T) {
+	world := NewWorldSeed("test-secret")
+	at := time.Date(2026, 10, 16, 12, 3, 0, 0, time.UTC)
+
+	// Dvaja hráči pár metrov od seba v tom istom okne
+	cellA := world.Cell(48.14860, 17.10770)
+	cellB := world.Cell(48.14861, 17.10772)
+	if cellA != cellB {
+		t.Fatalf("expected same cell, got %s and %s", cellA, cellB)
+	}
+
+	a := world.Rand(cellA, at, "scan")
+	b := world.Rand(cellB, at.Add(5*time.Minute), "scan")
+	for i := 0; i < 20; i++ {
+		if x, y := a.Int63(), b.Int63(); x != y {
+			t.Fatalf("draw %d differs: %d != %d", i, x, y)
+		}
+	}
+}
+
+func TestWorldSeed_DifferentInputs(t *testing.T) {
+	world := NewWorldSeed("test-secret")
+	at := time.Date(2026, 10, 16, 12, 3, 0, 0, time.UTC)
+	cell := world.Cell(48.1486, 17.1077)
+	base := world.Rand(cell, at, "scan").Int63()
+
+	tests := []struct {
+		name  string
+		world WorldSeed
+		cell  RegionCell
+		at    time.Time
+		use   string
+	}{
+		{"next window", world, cell, at.Add(WorldSeedWindow), "scan"},
+		{"neighbour cell", world, RegionCell{X: cell.X + 1, Y: cell.Y}, at, "scan"},
+		{"other purpose", world, cell, at, "reroll"},
+		{"other secret", NewWorldSeed("other-secret"), cell, at, "scan"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.world.Rand(tt.cell, tt.at, tt.use).Int63(); got == base {
+			t.Errorf("%s: expected different sequence, got same first draw %d", tt.name, got)
+		}
+	}
+}
+
+func TestWorldSeed_CellCenterInsideCell(t *testing.T) {
+	world := NewWorldSeed("")
+	tests := []struct {
+		name     string
+		lat, lng float64
+	}{
+		{"bratislava", 48.1486, 17.1077},
+		{"equator", 0.0001, 0.0001},
+		{"southern hemisphere", -33.8688, 151.2093},
+		{"western hemisphere", 40.7128, -74.0060},
+		{"near north pole", 89.5, 10},
+	}
+
+	for _, tt := range tests {
+		cell := world.Cell(tt.lat, tt.lng)
+		lat, lng := world.CellCenter(cell)
+		if got := world.Cell(lat, lng); got != cell {
+			t.Errorf("%s: center %.6f,%.6f maps to %s, want %s", tt.name, lat, lng, got, cell)
+		}
+		if d := CalculateDistance(tt.lat, tt.lng, lat, lng); d > world.CellSize {
+			t.Errorf("%s: center %.0fm away from position, cell size %.0fm", tt.name, d, world.CellSize)
+		}
+	}
+}
+
+func TestZoneID_Deterministic(t *testing.T) {
+	world := NewWorldSeed("test-secret")
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cell := RegionCell{X: 10, Y: 20}
+
+	a := zoneID(world.SlotRand(cell, at, 0, 0))
+	b := zoneID(world.SlotRand(cell, at, 0, 0))
+	if a != b {
+		t.Fatalf("expected same zone ID, got %s and %s", a, b)
+	}
+	if c := zoneID(world.SlotRand(cell, at.Add(WorldSeedWindow), 0, 0)); c == a {
+		t.Errorf("expected different zone ID in next window, got %s", c)
+	}
+}
@@ -0,0 +1,476 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/menu"
+	"geoanomaly/internal/movement"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ============================================
+// PLATENÉ AKCIE NAD ZÓNAMI (reroll, anchor)
+// ============================================
+
+const (
+	ZoneActionReroll = "reroll"
+	ZoneActionAnchor = "anchor"
+
+	ZoneActionDailyWindow = 24 * time.Hour
+	ZoneAnchorMaxTTL      = time.Duration(ZoneMaxExpiryHours) * time.Hour // anchor nepredĺži zónu nad max TTL od teraz
+)
+
+var (
+	ErrZoneActionNotAvailable = errors.New("zone action not available for your tier")
+	ErrZoneActionCooldown     = errors.New("zone action on cooldown")
+	ErrZoneActionDailyLimit   = errors.New("daily zone action limit reached")
+	ErrZoneAnchorLimit        = errors.New("zone cannot be anchored anymore")
+	ErrZoneAnchorMaxTTL       = errors.New("zone already has maximum lifetime")
+	ErrNothingToReroll        = errors.New("no unvisited zones of yours to reroll")
+)
+
+// ZoneActionLimits - cena a limity akcie pre jeden tier
+type ZoneActionLimits struct {
+	Cost         int           `json:"cost"`
+	CurrencyType string        `json:"currency_type"`
+	Cooldown     time.Duration `json:"-"`
+	DailyLimit   int           `json:"daily_limit"`
+	Extension    time.Duration `json:"-"`                      // anchor: o koľko sa predĺži ExpiresAt
+	MaxPerZone   int           `json:"max_per_zone,omitempty"` // anchor: max anchorov jednej zóny (všetci hráči)
+}
+
+// Reroll - len vyššie tiery; tier bez záznamu akciu nemá
+var zoneRerollLimits = map[int]ZoneActionLimits{
+	2: {Cost: 500, CurrencyType: menu.CurrencyCredits, Cooldown: 30 * time.Minute, DailyLimit: 3},
+	3: {Cost: 400, CurrencyType: menu.CurrencyCredits, Cooldown: 20 * time.Minute, DailyLimit: 5},
+	4: {Cost: 300, CurrencyType: menu.CurrencyCredits, Cooldown: 10 * time.Minute, DailyLimit: 8},
+}
+
+// Anchor - platí sa essence, predĺženie rastie s tierom
+var zoneAnchorLimits = map[int]ZoneActionLimits{
+	2: {Cost: 5, CurrencyType: menu.CurrencyEssence, Cooldown: time.Hour, DailyLimit: 2, Extension: time.Hour, MaxPerZone: 1},
+	3: {Cost: 5, CurrencyType: menu.CurrencyEssence, Cooldown: 30 * time.Minute, DailyLimit: 4, Extension: 2 * time.Hour, MaxPerZone: 2},
+	4: {Cost: 4, CurrencyType: menu.CurrencyEssence, Cooldown: 15 * time.Minute, DailyLimit: 6, Extension: 3 * time.Hour, MaxPerZone: 3},
+}
+
+func zoneActionLimits(action string, tier int) (ZoneActionLimits, bool) {
+	table := zoneRerollLimits
+	if action == ZoneActionAnchor {
+		table = zoneAnchorLimits
+	}
+	limits, ok := table[tier]
+	return limits, ok
+}
+
+// ZoneAction - záznam o zaplatenej akcii (cooldowny, denné limity, audit platby)
+type ZoneAction struct {
+	ID           uuid.UUID      `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID       uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index:idx_zone_actions_user,priority:1"`
+	Action       string         `json:"action" gorm:"size:20;not null;index:idx_zone_actions_user,priority:2"`
+	ZoneID       *uuid.UUID     `json:"zone_id,omitempty" gorm:"type:uuid;index"` // anchor
+	PlayerTier   int            `json:"player_tier" gorm:"not null"`
+	Cost         int            `json:"cost" gorm:"not null"`
+	CurrencyType string         `json:"currency_type" gorm:"size:20;not null"`
+	Details      gameplay.JSONB `json:"details,omitempty" gorm:"type:jsonb"`
+	CreatedAt    time.Time      `json:"created_at" gorm:"not null;index:idx_zone_actions_user,priority:3"`
+}
+
+func (ZoneAction) TableName() string {
+	return "gameplay.zone_actions"
+}
+
+// ZoneActionCooldownError - kedy bude akcia znova dostupná
+type ZoneActionCooldownError struct {
+	Err         error
+	AvailableAt time.Time
+}
+
+func (e *ZoneActionCooldownError) Error() string { return e.Err.Error() }
+func (e *ZoneActionCooldownError) Unwrap() error { return e.Err }
+
+// ZoneRerollRequest - poloha hráča; rerollujú sa jeho zóny v spawn okruhu bunky
+type ZoneRerollRequest struct {
+	Latitude  float64 `json:"latitude" binding:"required"`
+	Longitude float64 `json:"longitude" binding:"required"`
+}
+
+// reserveZoneAction - pod zámkom hráča over cooldown a limity a zapíš akciu ešte pred platbou,
+// aby dva súbežné requesty neprešli oba
+func (h *Handler) reserveZoneAction(userID uuid.UUID, tier int, action string, zoneID *uuid.UUID, limits ZoneActionLimits) (*ZoneAction, error) {
+	record := &ZoneAction{
+		UserID:       userID,
+		Action:       action,
+		ZoneID:       zoneID,
+		PlayerTier:   tier,
+		Cost:         limits.Cost,
+		CurrencyType: limits.CurrencyType,
+		CreatedAt:    time.Now(),
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&auth.User{}, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		var last ZoneAction
+		err := tx.Where("user_id = ? AND action = ?", userID, action).Order("created_at DESC").First(&last).Error
+		if err == nil && record.CreatedAt.Before(last.CreatedAt.Add(limits.Cooldown)) {
+			return &ZoneActionCooldownError{Err: ErrZoneActionCooldown, AvailableAt: last.CreatedAt.Add(limits.Cooldown)}
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var today int64
+		if err := tx.Model(&ZoneAction{}).Where("user_id = ? AND action = ? AND created_at > ?", userID, action, record.CreatedAt.Add(-ZoneActionDailyWindow)).
+			Count(&today).Error; err != nil {
+			return err
+		}
+		if int(today) >= limits.DailyLimit {
+			return ErrZoneActionDailyLimit
+		}
+
+		if zoneID != nil && limits.MaxPerZone > 0 {
+			var perZone int64
+			if err := tx.Model(&ZoneAction{}).Where("zone_id = ? AND action = ?", *zoneID, action).Count(&perZone).Error; err != nil {
+				return err
+			}
+			if int(perZone) >= limits.MaxPerZone {
+				return ErrZoneAnchorLimit
+			}
+		}
+
+		return tx.Create(record).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// chargeZoneAction - stiahne menu a zapíše transakciu; pri neúspechu uvoľní rezerváciu
+func (h *Handler) chargeZoneAction(record *ZoneAction, description string) error {
+	if err := h.menu.SubtractCurrency(record.UserID, record.CurrencyType, record.Cost, description); err != nil {
+		h.db.Delete(record)
+		return err
+	}
+	return nil
+}
+
+// refundZoneAction - akcia zlyhala po platbe: vráť menu a zruš záznam (nepočíta sa do limitov)
+func (h *Handler) refundZoneAction(record *ZoneAction, description string) {
+	if err := h.menu.AddCurrency(record.UserID, record.CurrencyType, record.Cost, "Refund: "+description); err != nil {
+		log.Printf("❌ Zone %s refund failed for user %s: %v", record.Action, record.UserID, err)
+	}
+	h.db.Delete(record)
+}
+
+// respondZoneActionError - spoločné mapovanie chýb limitov a platby
+func respondZoneActionError(c *gin.Context, err error) {
+	var cooldown *ZoneActionCooldownError
+	switch {
+	case errors.As(err, &cooldown):
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":        cooldown.Error(),
+			"code":         "cooldown",
+			"available_at": cooldown.AvailableAt.Unix(),
+			"retry_in_sec": int(time.Until(cooldown.AvailableAt).Seconds()),
+		})
+	case errors.Is(err, ErrZoneActionDailyLimit):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error(), "code": "daily_limit"})
+	case errors.Is(err, ErrZoneAnchorLimit), errors.Is(err, ErrZoneAnchorMaxTTL), errors.Is(err, ErrNothingToReroll):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, menu.ErrInsufficientFunds):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error(), "code": "insufficient_funds"})
+	default:
+		log.Printf("❌ Zone action failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Zone action failed"})
+	}
+}
+
+// RerollZones - zruší hráčove nenavštívené dynamické zóny v okolí a vygeneruje nové (POST /game/zones/reroll)
+func (h *Handler) RerollZones(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req ZoneRerollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !IsValidGPSCoordinate(req.Latitude, req.Longitude) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid GPS coordinates"})
+		return
+	}
+
+	verdict, ok := h.movement.Enforce(c, userID.(uuid.UUID), movement.SourceZoneReroll, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	})
+	if !ok {
+		return
+	}
+
+	var user auth.User
+	if err := h.db.First(&user, "id = ?", userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	limits, available := zoneActionLimits(ZoneActionReroll, user.Tier)
+	if !available || verdict.Shadowed() {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrZoneActionNotAvailable.Error(), "player_tier": user.Tier})
+		return
+	}
+
+	now := time.Now()
+	cell := h.world.Cell(req.Latitude, req.Longitude)
+	anchorLat, anchorLng := h.world.CellCenter(cell)
+
+	// Kandidáti sa overia pred platbou - bez nich by hráč platil za nič
+	candidates := h.rerollableZones(user.ID, anchorLat, anchorLng)
+	if len(candidates) == 0 {
+		respondZoneActionError(c, ErrNothingToReroll)
+		return
+	}
+
+	record, err := h.reserveZoneAction(user.ID, user.Tier, ZoneActionReroll, nil, limits)
+	if err != nil {
+		respondZoneActionError(c, err)
+		return
+	}
+	description := fmt.Sprintf("Zone reroll (%d zones)", len(candidates))
+	if err := h.chargeZoneAction(record, description); err != nil {
+		respondZoneActionError(c, err)
+		return
+	}
+
+	cleanupService := NewCleanupService(h.db)
+	var removed []uuid.UUID
+	for _, zone := range candidates {
+		if _, _, err := cleanupService.CleanupUnvisitedZone(zone.ID, "rerolled"); err != nil {
+			continue // medzitým expirovala alebo do nej niekto vstúpil
+		}
+		removed = append(removed, zone.ID)
+	}
+	if len(removed) == 0 {
+		h.refundZoneAction(record, description)
+		respondZoneActionError(c, ErrNothingToReroll)
+		return
+	}
+
+	// Tiery z vlastného streamu, zóny z ďalších voľných slotov bunky (zrušené ID sú obsadené)
+	rng := h.world.Rand(cell, now, fmt.Sprintf("reroll:%s", record.ID))
+	tiers := planZoneTiers(currentSpawnConfig(), rng, user.Tier, len(removed))
+	newZones := h.spawnDynamicZones(h.cellSlots(cell, now), anchorLat, anchorLng, tiers, user.Tier, user.ID)
+
+	spawned := make([]uuid.UUID, 0, len(newZones))
+	zoneDetails := make([]ZoneWithDetails, 0, len(newZones))
+	for _, zone := range newZones {
+		spawned = append(spawned, zone.ID)
+		zoneDetails = append(zoneDetails, h.buildZoneDetails(zone, req.Latitude, req.Longitude, user.Tier))
+	}
+	record.Details = gameplay.JSONB{
+		"world_cell":     cell.String(),
+		"removed_zones":  removed,
+		"spawned_zones":  spawned,
+		"removed_count":  len(removed),
+		"spawned_count":  len(spawned),
+		"cost":           limits.Cost,
+		"currency_type":  limits.CurrencyType,
+		"description":    description,
+		"next_available": now.Add(limits.Cooldown).Unix(),
+	}
+	h.db.Model(record).Update("details", record.Details)
+
+	log.Printf("🎲 User %s rerolled %d zones (%d spawned) for %d %s", user.ID, len(removed), len(spawned), limits.Cost, limits.CurrencyType)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Zones rerolled",
+		"removed_zones":  removed,
+		"zones":          zoneDetails,
+		"zones_created":  len(newZones),
+		"cost":           limits.Cost,
+		"currency_type":  limits.CurrencyType,
+		"next_available": now.Add(limits.Cooldown).Unix(),
+		"action_id":      record.ID,
+	})
+}
+
+// rerollableZones - hráčove aktívne dynamické zóny v spawn okruhu bunky, do ktorých ešte nikto nevstúpil
+func (h *Handler) rerollableZones(userID uuid.UUID, anchorLat, anchorLng float64) []gameplay.Zone {
+	var zones []gameplay.Zone
+	h.db.Where("is_active = true AND zone_type = ? AND properties->>'created_by_user_id' = ?", "dynamic", userID.String()).
+		Where("NOT EXISTS (SELECT 1 FROM gameplay.zone_visits v WHERE v.zone_id = gameplay.zones.id)").
+		Find(&zones)
+
+	var nearby []gameplay.Zone
+	for _, zone := range zones {
+		if CalculateDistance(anchorLat, anchorLng, zone.Location.Latitude, zone.Location.Longitude) <= MaxSpawnRadius {
+			nearby = append(nearby, zone)
+		}
+	}
+	return nearby
+}
+
+// AnchorZone - predĺži ExpiresAt dynamickej zóny (POST /game/zones/:id/anchor)
+func (h *Handler) AnchorZone(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	zoneID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zone ID"})
+		return
+	}
+
+	var user auth.User
+	if err := h.db.First(&user, "id = ?", userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	limits, available := zoneActionLimits(ZoneActionAnchor, user.Tier)
+	if !available {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrZoneActionNotAvailable.Error(), "player_tier": user.Tier})
+		return
+	}
+
+	var zone gameplay.Zone
+	if err := h.db.First(&zone, "id = ? AND is_active = true", zoneID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zone not found"})
+		return
+	}
+	if zone.ZoneType != "dynamic" || zone.ExpiresAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only dynamic zones can be anchored"})
+		return
+	}
+	if zone.TierRequired > user.Tier {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Zone tier too high", "required_tier": zone.TierRequired, "player_tier": user.Tier})
+		return
+	}
+
+	maxExpiry := time.Now().Add(ZoneAnchorMaxTTL)
+	if !zone.ExpiresAt.Before(maxExpiry) {
+		respondZoneActionError(c, ErrZoneAnchorMaxTTL)
+		return
+	}
+
+	record, err := h.reserveZoneAction(user.ID, user.Tier, ZoneActionAnchor, &zone.ID, limits)
+	if err != nil {
+		respondZoneActionError(c, err)
+		return
+	}
+	description := fmt.Sprintf("Zone anchor: %s (+%s)", zone.Name, limits.Extension)
+	if err := h.chargeZoneAction(record, description); err != nil {
+		respondZoneActionError(c, err)
+		return
+	}
+
+	// Predĺženie priamo v SQL - súbežný anchor iného hráča sa nestratí; lifecycle sa vráti na spawned,
+	// aby sa aging/expiring notifikácie vyhodnotili podľa nového času (dedup kľúč obsahuje expires_at)
+	result := h.db.Model(&gameplay.Zone{}).
+		Where("id = ? AND is_active = true AND expires_at IS NOT NULL", zone.ID).
+		Updates(map[string]interface{}{
+			"expires_at": gorm.Expr("LEAST(GREATEST(expires_at, NOW()) + ?::interval, ?)", fmt.Sprintf("%d seconds", int(limits.Extension.Seconds())), maxExpiry),
+			"properties": gorm.Expr("COALESCE(properties, '{}'::jsonb) || jsonb_build_object('lifecycle_stage', ?::text, 'anchor_count', COALESCE((properties->>'anchor_count')::int, 0) + 1)", ZoneStageSpawned),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		h.refundZoneAction(record, description)
+		c.JSON(http.StatusConflict, gin.H{"error": "Zone is no longer active"})
+		return
+	}
+
+	h.db.First(&zone, "id = ?", zone.ID)
+	record.Details = gameplay.JSONB{
+		"zone_name":      zone.Name,
+		"new_expires_at": zone.ExpiresAt.Unix(),
+		"extension_sec":  int(limits.Extension.Seconds()),
+		"description":    description,
+	}
+	h.db.Model(record).Update("details", record.Details)
+
+	log.Printf("⚓ User %s anchored zone %s until %s", user.ID, zone.Name, zone.ExpiresAt.Format(time.RFC3339))
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Zone anchored",
+		"zone_id":        zone.ID,
+		"expires_at":     zone.ExpiresAt.Unix(),
+		"time_to_expiry": time.Until(*zone.ExpiresAt).Round(time.Minute).String(),
+		"extension":      limits.Extension.String(),
+		"cost":           limits.Cost,
+		"currency_type":  limits.CurrencyType,
+		"next_available": record.CreatedAt.Add(limits.Cooldown).Unix(),
+		"action_id":      record.ID,
+	})
+}
+
+// GetZoneActions - ceny, limity a cooldowny rerollu/anchoru pre tier hráča (GET /game/zones/actions)
+func (h *Handler) GetZoneActions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var user auth.User
+	if err := h.db.First(&user, "id = ?", userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	now := time.Now()
+	actions := gin.H{}
+	for _, action := range []string{ZoneActionReroll, ZoneActionAnchor} {
+		limits, available := zoneActionLimits(action, user.Tier)
+		if !available {
+			actions[action] = gin.H{"available": false}
+			continue
+		}
+
+		var usedToday int64
+		h.db.Model(&ZoneAction{}).Where("user_id = ? AND action = ? AND created_at > ?", user.ID, action, now.Add(-ZoneActionDailyWindow)).Count(&usedToday)
+
+		nextAvailable := now
+		var last ZoneAction
+		if err := h.db.Where("user_id = ? AND action = ?", user.ID, action).Order("created_at DESC").First(&last).Error; err == nil {
+			if cooldownEnd := last.CreatedAt.Add(limits.Cooldown); cooldownEnd.After(now) {
+				nextAvailable = cooldownEnd
+			}
+		}
+
+		info := gin.H{
+			"available":      true,
+			"limits":         limits,
+			"cooldown":       limits.Cooldown.String(),
+			"used_today":     usedToday,
+			"remaining":      max(limits.DailyLimit-int(usedToday), 0),
+			"next_available": nextAvailable.Unix(),
+		}
+		if action == ZoneActionAnchor {
+			info["extension"] = limits.Extension.String()
+		}
+		actions[action] = info
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"player_tier": user.Tier,
+		"actions":     actions,
+	})
+}
@@ -7,13 +7,15 @@ import (
 
 	"geoanomaly/internal/auth"
 	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/zonestats"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type CleanupService struct {
-	db *gorm.DB
+	db    *gorm.DB
+	stats *zonestats.Service
 }
 
 type CleanupResult struct {
@@ -26,7 +28,7 @@ type CleanupResult struct {
 }
 
 func NewCleanupService(db *gorm.DB) *CleanupService {
-	return &CleanupService{db: db}
+	return &CleanupService{db: db, stats: zonestats.NewService(db)}
 }
 
 // ✅ Main cleanup function
@@ -57,7 +59,8 @@ func (cs *CleanupService) CleanupExpiredZones() CleanupResult {
 
 	// Process each expired zone
 	for _, zone := range expiredZones {
-		itemsRemoved, playersAffected := cs.cleanupSingleZone(zone)
+		emitZoneLifecycle(ZoneLifecycleEvent{Stage: ZoneStageExpired, Zone: zone})
+		itemsRemoved, playersAffected := cs.cleanupSingleZone(zone, "expired")
 		result.ItemsRemoved += itemsRemoved
 		result.PlayersAffected += playersAffected
 	}
@@ -72,49 +75,92 @@ func (cs *CleanupService) CleanupExpiredZones() CleanupResult {
 }
 
 // ✅ Cleanup single zone
-func (cs *CleanupService) cleanupSingleZone(zone gameplay.Zone) (int, int) {
-	log.Printf("🗑️ Cleaning zone: %s (expired %s)", zone.Name, time.Since(*zone.ExpiresAt).String())
+func (cs *CleanupService) cleanupSingleZone(zone gameplay.Zone, reason string) (int, int) {
+	if zone.ExpiresAt != nil {
+		log.Printf("🗑️ Cleaning zone: %s (expired %s)", zone.Name, time.Since(*zone.ExpiresAt).String())
+	} else {
+		log.Printf("🗑️ Cleaning zone: %s (no expiry)", zone.Name)
+	}
 
-	itemsRemoved := 0
 	playersAffected := 0
 
-	// 1. Remove/deactivate artifacts
-	var artifacts []gameplay.Artifact
-	cs.db.Where("zone_id = ? AND is_active = true", zone.ID).Find(&artifacts)
-	if len(artifacts) > 0 {
-		cs.db.Model(&gameplay.Artifact{}).Where("zone_id = ?", zone.ID).Update("is_active", false)
-		itemsRemoved += len(artifacts)
-		log.Printf("   📦 Deactivated %d artifacts", len(artifacts))
-	}
-
-	// 2. Remove/deactivate gear
-	var gear []gameplay.Gear
-	cs.db.Where("zone_id = ? AND is_active = true", zone.ID).Find(&gear)
-	if len(gear) > 0 {
-		cs.db.Model(&gameplay.Gear{}).Where("zone_id = ?", zone.ID).Update("is_active", false)
-		itemsRemoved += len(gear)
-		log.Printf("   ⚔️ Deactivated %d gear items", len(gear))
-	}
+	// 1-2. Remove/deactivate artifacts and gear
+	itemsRemoved := cs.deactivateZoneItems(zone.ID)
 
 	// 3. Remove players from zone
 	var sessions []auth.PlayerSession
 	cs.db.Where("current_zone = ?", zone.ID).Find(&sessions)
+	playersInside := make([]uuid.UUID, 0, len(sessions))
 	if len(sessions) > 0 {
 		cs.db.Model(&auth.PlayerSession{}).Where("current_zone = ?", zone.ID).Update("current_zone", nil)
 		playersAffected = len(sessions)
+		for _, session := range sessions {
+			playersInside = append(playersInside, session.UserID)
+		}
 		log.Printf("   👥 Removed %d players from zone", playersAffected)
 	}
 
 	// 4. Deactivate zone
 	zone.IsActive = false
-	zone.Properties["cleanup_reason"] = "expired"
+	if zone.Properties == nil {
+		zone.Properties = gameplay.JSONB{}
+	}
+	zone.Properties["cleanup_reason"] = reason
 	zone.Properties["cleanup_time"] = time.Now().Unix()
+	zone.Properties["lifecycle_stage"] = ZoneStageCleaned
 	cs.db.Save(&zone)
 
+	// 5. Štatistiky zóny ostávajú, len sa uzavrú
+	cs.stats.CloseZone(zone, reason)
+
+	// 6. Hráči v zóne a nedávni návštevníci sa dozvedia, že zóna zmizla
+	emitZoneLifecycle(ZoneLifecycleEvent{
+		Stage:         ZoneStageCleaned,
+		Zone:          zone,
+		Reason:        reason,
+		ItemsRemoved:  itemsRemoved,
+		PlayersInside: playersInside,
+	})
+
 	log.Printf("   ✅ Zone %s cleaned successfully", zone.Name)
 	return itemsRemoved, playersAffected
 }
 
+// ✅ Deactivate all spawned artifacts/gear in zone (zone itself stays untouched)
+func (cs *CleanupService) deactivateZoneItems(zoneID uuid.UUID) int {
+	itemsRemoved := 0
+
+	var artifacts []gameplay.Artifact
+	cs.db.Where("zone_id = ? AND is_active = true", zoneID).Find(&artifacts)
+	if len(artifacts) > 0 {
+		cs.db.Model(&gameplay.Artifact{}).Where("zone_id = ?", zoneID).Update("is_active", false)
+		itemsRemoved += len(artifacts)
+		log.Printf("   📦 Deactivated %d artifacts", len(artifacts))
+	}
+
+	var gear []gameplay.Gear
+	cs.db.Where("zone_id = ? AND is_active = true", zoneID).Find(&gear)
+	if len(gear) > 0 {
+		cs.db.Model(&gameplay.Gear{}).Where("zone_id = ?", zoneID).Update("is_active", false)
+		itemsRemoved += len(gear)
+		log.Printf("   ⚔️ Deactivated %d gear items", len(gear))
+	}
+
+	return itemsRemoved
+}
+
+// GetAgingZones - zóny pod hranicou ZoneAgingThreshold, ktoré ešte nie sú v poslednej šanci
+func (cs *CleanupService) GetAgingZones() []gameplay.Zone {
+	now := time.Now()
+
+	var agingZones []gameplay.Zone
+	cs.db.Where("is_active = true AND expires_at BETWEEN ? AND ?", now.Add(ZoneLastChanceWarning), now.Add(ZoneAgingThreshold)).
+		Where("COALESCE(properties->>'lifecycle_stage', '') NOT IN ?", []string{ZoneStageAging, ZoneStageExpiring}).
+		Find(&agingZones)
+
+	return agingZones
+}
+
 // ✅ Get zones about to expire
 func (cs *CleanupService) GetExpiringZones(warningMinutes int) []gameplay.Zone {
 	warningTime := time.Now().Add(time.Duration(warningMinutes) * time.Minute)
@@ -126,21 +172,54 @@ func (cs *CleanupService) GetExpiringZones(warningMinutes int) []gameplay.Zone {
 }
 
 // ✅ Force cleanup specific zone
-func (cs *CleanupService) ForceCleanupZone(zoneID uuid.UUID, reason string) error {
+func (cs *CleanupService) ForceCleanupZone(zoneID uuid.UUID, reason string) (int, int, error) {
 	var zone gameplay.Zone
 	if err := cs.db.First(&zone, "id = ? AND is_active = true", zoneID).Error; err != nil {
-		return fmt.Errorf("zone not found: %v", err)
+		return 0, 0, fmt.Errorf("zone not found: %v", err)
 	}
 
-	itemsRemoved, playersAffected := cs.cleanupSingleZone(zone)
+	itemsRemoved, playersAffected := cs.cleanupSingleZone(zone, reason)
 
 	// Update cleanup reason
+	if zone.Properties == nil {
+		zone.Properties = gameplay.JSONB{}
+	}
+	zone.IsActive = false
 	zone.Properties["cleanup_reason"] = reason
+	zone.Properties["cleanup_time"] = time.Now().Unix()
 	zone.Properties["force_cleanup"] = true
 	cs.db.Save(&zone)
 
 	log.Printf("🔧 Force cleanup completed for %s: %d items, %d players", zone.Name, itemsRemoved, playersAffected)
-	return nil
+	return itemsRemoved, playersAffected, nil
+}
+
+// CleanupUnvisitedZone - ako ForceCleanupZone, ale zónu zruší len ak do nej ešte nikto nevstúpil.
+// Kontrola návštev a deaktivácia sú jeden UPDATE, takže vstup po výbere kandidátov zónu zachráni.
+func (cs *CleanupService) CleanupUnvisitedZone(zoneID uuid.UUID, reason string) (int, int, error) {
+	result := cs.db.Model(&gameplay.Zone{}).
+		Where("id = ? AND is_active = true", zoneID).
+		Where("NOT EXISTS (SELECT 1 FROM gameplay.zone_visits v WHERE v.zone_id = gameplay.zones.id)").
+		Update("is_active", false)
+	if result.Error != nil {
+		return 0, 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, 0, fmt.Errorf("zone %s is no longer active or was visited", zoneID)
+	}
+
+	var zone gameplay.Zone
+	if err := cs.db.First(&zone, "id = ?", zoneID).Error; err != nil {
+		return 0, 0, fmt.Errorf("zone not found: %v", err)
+	}
+	if zone.Properties == nil {
+		zone.Properties = gameplay.JSONB{}
+	}
+	zone.Properties["force_cleanup"] = true
+
+	itemsRemoved, playersAffected := cs.cleanupSingleZone(zone, reason)
+	log.Printf("🔧 Unvisited zone cleanup completed for %s: %d items, %d players", zone.Name, itemsRemoved, playersAffected)
+	return itemsRemoved, playersAffected, nil
 }
 
 // ✅ Cleanup statistics
@@ -0,0 +1,244 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/gameplay"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ZoneWaitlistStaleAfter - čakateľ, ktorý sa dlhšie neozval (retry vstupu / poll poradia), stráca miesto v rade
+const ZoneWaitlistStaleAfter = 2 * time.Minute
+
+var ErrItemAlreadyClaimed = errors.New("item already claimed by another player")
+
+// ZoneFullError - zóna je plná; pri event zóne je hráč zaradený do radu
+type ZoneFullError struct {
+	Capacity         int
+	ActivePlayers    int64
+	WaitlistPosition int // 0 = bez radu (bežná zóna)
+}
+
+func (e *ZoneFullError) Error() string {
+	return fmt.Sprintf("zone is full (%d/%d)", e.ActivePlayers, e.Capacity)
+}
+
+// ZoneWaitlistEntry - poradie čakateľov na plnú event zónu (FIFO podľa created_at)
+type ZoneWaitlistEntry struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ZoneID     uuid.UUID `json:"zone_id" gorm:"type:uuid;not null;uniqueIndex:idx_zone_waitlist_user"`
+	UserID     uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_zone_waitlist_user"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	LastSeenAt time.Time `json:"last_seen_at" gorm:"not null"`
+}
+
+func (ZoneWaitlistEntry) TableName() string {
+	return "gameplay.zone_waitlist"
+}
+
+// ============================================
+// FIRST-COME CLAIMING
+// ============================================
+
+// claimArtifact - podmienený update is_claimed; z viacerých súbežných pokusov uspeje práve jeden
+func claimArtifact(db *gorm.DB, zoneID, artifactID string) (gameplay.Artifact, error) {
+	var artifact gameplay.Artifact
+	result := db.Model(&artifact).Clauses(clause.Returning{}).
+		Where("id = ? AND zone_id = ? AND is_active = true AND is_claimed = false AND deleted_at IS NULL", artifactID, zoneID).
+		Updates(map[string]interface{}{"is_active": false, "is_claimed": true})
+	if result.Error != nil {
+		return artifact, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return artifact, ErrItemAlreadyClaimed
+	}
+	return artifact, nil
+}
+
+// claimGear - rovnaký first-come claim pre gear
+func claimGear(db *gorm.DB, zoneID, gearID string) (gameplay.Gear, error) {
+	var gear gameplay.Gear
+	result := db.Model(&gear).Clauses(clause.Returning{}).
+		Where("id = ? AND zone_id = ? AND is_active = true AND is_claimed = false AND deleted_at IS NULL", gearID, zoneID).
+		Updates(map[string]interface{}{"is_active": false, "is_claimed": true})
+	if result.Error != nil {
+		return gear, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gear, ErrItemAlreadyClaimed
+	}
+	return gear, nil
+}
+
+// respondCollectError - 409 pre porazeného v súboji o item, inak 500
+func respondCollectError(c *gin.Context, err error) {
+	if errors.Is(err, ErrItemAlreadyClaimed) {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"code":    "already_claimed",
+			"error":   "Another player collected this item first",
+		})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"success": false, "code": "collect_failed", "error": "Failed to collect item"})
+}
+
+// ============================================
+// ZONE CAPACITY + WAITLIST
+// ============================================
+
+// occupyZone - zapíše hráča do zóny; pri kapacite pod zámkom riadku zóny, aby súbežné
+// vstupy limit neprekročili. Plná event zóna hráča zaradí do radu (záznam sa commitne).
+func (h *Handler) occupyZone(zone gameplay.Zone, session *auth.PlayerSession) error {
+	capacity := zoneCapacity(zone)
+	if capacity == 0 {
+		return h.db.Save(session).Error
+	}
+
+	var full *ZoneFullError
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			First(&gameplay.Zone{}, "id = ?", zone.ID).Error; err != nil {
+			return err
+		}
+
+		active := countPlayersInZone(tx, zone.ID, session.UserID)
+		free := int64(capacity) - active
+
+		if zone.ZoneType == ZoneTypeEvent {
+			ahead, err := waitlistAhead(tx, zone.ID, session.UserID)
+			if err != nil {
+				return err
+			}
+			if ahead >= free {
+				if err := joinWaitlist(tx, zone.ID, session.UserID); err != nil {
+					return err
+				}
+				full = &ZoneFullError{Capacity: capacity, ActivePlayers: active, WaitlistPosition: int(ahead) + 1}
+				return nil
+			}
+			// Hráč je na rade - uvoľni jeho miesto v rade
+			if err := tx.Where("zone_id = ? AND user_id = ?", zone.ID, session.UserID).Delete(&ZoneWaitlistEntry{}).Error; err != nil {
+				return err
+			}
+		} else if free <= 0 {
+			full = &ZoneFullError{Capacity: capacity, ActivePlayers: active}
+			return nil
+		}
+
+		return tx.Save(session).Error
+	})
+	if err != nil {
+		return err
+	}
+	if full != nil {
+		return full
+	}
+	return nil
+}
+
+// waitlistAhead - počet čakateľov pred hráčom (pred tým zahodí neaktívnych)
+func waitlistAhead(tx *gorm.DB, zoneID, userID uuid.UUID) (int64, error) {
+	if err := tx.Where("zone_id = ? AND last_seen_at < ?", zoneID, time.Now().Add(-ZoneWaitlistStaleAfter)).
+		Delete(&ZoneWaitlistEntry{}).Error; err != nil {
+		return 0, err
+	}
+
+	query := tx.Model(&ZoneWaitlistEntry{}).Where("zone_id = ? AND user_id <> ?", zoneID, userID)
+
+	var own ZoneWaitlistEntry
+	if err := tx.Where("zone_id = ? AND user_id = ?", zoneID, userID).First(&own).Error; err == nil {
+		query = query.Where("created_at < ?", own.CreatedAt)
+	}
+
+	var ahead int64
+	err := query.Count(&ahead).Error
+	return ahead, err
+}
+
+// joinWaitlist - zaradí hráča na koniec radu alebo obnoví jeho existujúce miesto
+func joinWaitlist(tx *gorm.DB, zoneID, userID uuid.UUID) error {
+	entry := ZoneWaitlistEntry{ZoneID: zoneID, UserID: userID, LastSeenAt: time.Now()}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "zone_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+	}).Create(&entry).Error
+}
+
+// countPlayersInZone - aktívni hráči v zóne (rovnaké okno ako buildZoneDetails)
+func countPlayersInZone(db *gorm.DB, zoneID uuid.UUID, excludeUserID uuid.UUID) int64 {
+	var count int64
+	db.Model(&auth.PlayerSession{}).
+		Where("current_zone = ? AND is_online = true AND last_seen > ? AND user_id <> ?",
+			zoneID, time.Now().Add(-5*time.Minute), excludeUserID).
+		Count(&count)
+	return count
+}
+
+// GetZoneWaitlist - poradie hráča v rade na event zónu; poll zároveň drží miesto (GET /game/zones/:id/waitlist)
+func (h *Handler) GetZoneWaitlist(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	zoneID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zone ID format"})
+		return
+	}
+
+	var zone gameplay.Zone
+	if err := h.db.First(&zone, "id = ? AND is_active = true", zoneID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Zone not found"})
+		return
+	}
+
+	var (
+		waiting bool
+		ahead   int64
+	)
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ZoneWaitlistEntry{}).Where("zone_id = ? AND user_id = ?", zoneID, userID).
+			Update("last_seen_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		waiting = true
+		n, err := waitlistAhead(tx, zoneID, userID)
+		ahead = n
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load waitlist"})
+		return
+	}
+
+	response := gin.H{
+		"zone_id":        zoneID,
+		"waiting":        waiting,
+		"capacity":       zoneCapacity(zone),
+		"active_players": countPlayersInZone(h.db, zoneID, userID),
+		"stale_after":    int(ZoneWaitlistStaleAfter.Seconds()),
+	}
+	if waiting {
+		response["position"] = ahead + 1
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// LeaveZoneWaitlist - hráč sa vzdá miesta v rade (DELETE /game/zones/:id/waitlist)
+func (h *Handler) LeaveZoneWaitlist(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	result := h.db.Where("zone_id = ? AND user_id = ?", c.Param("id"), userID).Delete(&ZoneWaitlistEntry{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave waitlist"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Left waitlist", "removed": result.RowsAffected > 0})
+}
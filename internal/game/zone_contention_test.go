@@ -0,0 +1,133 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"geoanomaly/internal/gameplay"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupClaimDB - migrovaná databáza z env (test sa preskočí bez DB_HOST)
+func setupClaimDB(t *testing.T) *gorm.DB {
+	if os.Getenv("DB_HOST") == "" {
+		t.Skip("DB_HOST not set, skipping database test")
+	}
+
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+		os.Getenv("DB_HOST"),
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_PASSWORD"),
+		os.Getenv("DB_NAME"),
+		os.Getenv("DB_PORT"),
+		os.Getenv("DB_SSLMODE"),
+		os.Getenv("DB_TIMEZONE"),
+	)
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	return db
+}
+
+func TestClaimArtifact_ConcurrentSingleWinner(t *testing.T) {
+	db := setupClaimDB(t)
+
+	zone := gameplay.Zone{
+		Name:         fmt.Sprintf("Claim Test %d", time.Now().UnixNano()),
+		Location:     gameplay.Location{Latitude: 48.1486, Longitude: 17.1077, Timestamp: time.Now()},
+		TierRequired: 0,
+		RadiusMeters: 100,
+		IsActive:     true,
+		ZoneType:     ZoneTypeStatic,
+		Biome:        BiomeForest,
+		DangerLevel:  DangerLow,
+	}
+	if err := db.Create(&zone).Error; err != nil {
+		t.Fatalf("failed to create zone: %v", err)
+	}
+	artifact := gameplay.Artifact{
+		ZoneID:   zone.ID,
+		Name:     "Contested Sample",
+		Type:     "mushroom_sample",
+		Rarity:   "common",
+		Location: zone.Location,
+		Biome:    BiomeForest,
+		IsActive: true,
+	}
+	if err := db.Create(&artifact).Error; err != nil {
+		t.Fatalf("failed to create artifact: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Unscoped().Delete(&gameplay.Artifact{}, "id = ?", artifact.ID)
+		db.Unscoped().Delete(&gameplay.Zone{}, "id = ?", zone.ID)
+	})
+
+	const players = 50
+	var (
+		wg       sync.WaitGroup
+		start    = make(chan struct{})
+		mu       sync.Mutex
+		winners  int
+		conflict int
+		failures []error
+	)
+
+	for i := 0; i < players; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			claimed, err := claimArtifact(db, zone.ID.String(), artifact.ID.String())
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				winners++
+				if claimed.ID != artifact.ID || claimed.IsActive || !claimed.IsClaimed {
+					failures = append(failures, fmt.Errorf("unexpected claimed row: %+v", claimed))
+				}
+			case errors.Is(err, ErrItemAlreadyClaimed):
+				conflict++
+			default:
+				failures = append(failures, err)
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	for _, err := range failures {
+		t.Errorf("claim failed: %v", err)
+	}
+	if winners != 1 {
+		t.Errorf("expected exactly 1 winner, got %d", winners)
+	}
+	if conflict != players-1 {
+		t.Errorf("expected %d conflicts, got %d", players-1, conflict)
+	}
+
+	var stored gameplay.Artifact
+	if err := db.First(&stored, "id = ?", artifact.ID).Error; err != nil {
+		t.Fatalf("failed to reload artifact: %v", err)
+	}
+	if stored.IsActive || !stored.IsClaimed {
+		t.Errorf("expected artifact inactive and claimed, got is_active=%t is_claimed=%t", stored.IsActive, stored.IsClaimed)
+	}
+
+	// Neskorý pokus po súboji tiež prehrá
+	if _, err := claimArtifact(db, zone.ID.String(), artifact.ID.String()); !errors.Is(err, ErrItemAlreadyClaimed) {
+		t.Errorf("expected ErrItemAlreadyClaimed for late claim, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/notifications"
+	"geoanomaly/internal/realtime"
+	"geoanomaly/internal/zonestats"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Životný cyklus zóny: spawned → aging → expiring → expired → cleaned
+const (
+	ZoneStageSpawned  = "spawned"
+	ZoneStageAging    = "aging"
+	ZoneStageExpiring = "expiring"
+	ZoneStageExpired  = "expired"
+	ZoneStageCleaned  = "cleaned"
+
+	ZoneAgingThreshold      = 6 * time.Hour    // rovnaká hranica ako Zone.TTLStatus()
+	ZoneLastChanceWarning   = 30 * time.Minute // "last chance" notifikácia pred expiráciou
+	ZoneRecentVisitorsSince = 2 * time.Hour    // kto bol v zóne nedávno, dostane notifikáciu tiež
+)
+
+// ZoneLifecycleEvent - doménová udalosť zmeny stavu zóny
+type ZoneLifecycleEvent struct {
+	Stage         string
+	Zone          gameplay.Zone
+	At            time.Time
+	Reason        string      // cleaned: expired / admin_deactivated / admin_deleted
+	ItemsRemoved  int         // cleaned: prepadnuté nevyzbierané itemy
+	PlayersInside []uuid.UUID // cleaned: hráči vyhodení zo zóny (session je už vyčistená)
+}
+
+var (
+	zoneLifecycleMu        sync.RWMutex
+	zoneLifecycleListeners []func(ZoneLifecycleEvent)
+)
+
+// OnZoneLifecycle - zaregistruje listener (volá sa v main.go pri štarte)
+func OnZoneLifecycle(fn func(ZoneLifecycleEvent)) {
+	zoneLifecycleMu.Lock()
+	defer zoneLifecycleMu.Unlock()
+	zoneLifecycleListeners = append(zoneLifecycleListeners, fn)
+}
+
+func emitZoneLifecycle(event ZoneLifecycleEvent) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	zoneLifecycleMu.RLock()
+	listeners := zoneLifecycleListeners
+	zoneLifecycleMu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// advanceZoneStage - zapíše lifecycle_stage do Properties; true len pre prvý zápis prechodu,
+// takže pri viacerých inštanciách schedulera sa udalosť emituje raz
+func advanceZoneStage(db *gorm.DB, zoneID uuid.UUID, stage string) bool {
+	result := db.Model(&gameplay.Zone{}).
+		Where("id = ? AND COALESCE(properties->>'lifecycle_stage', '') <> ?", zoneID, stage).
+		Update("properties", gorm.Expr("jsonb_set(COALESCE(properties, '{}'::jsonb), '{lifecycle_stage}', to_jsonb(?::text))", stage))
+	if result.Error != nil {
+		log.Printf("❌ Failed to advance zone %s to %s: %v", zoneID, stage, result.Error)
+		return false
+	}
+	return result.RowsAffected > 0
+}
+
+// ============================================
+// NOTIFIKÁCIE HRÁČOM
+// ============================================
+
+// ZoneLifecycleNotifier - listener, ktorý z udalostí robí notifikácie v inboxe
+type ZoneLifecycleNotifier struct {
+	db            *gorm.DB
+	notifications *notifications.Service
+}
+
+func NewZoneLifecycleNotifier(db *gorm.DB) *ZoneLifecycleNotifier {
+	return &ZoneLifecycleNotifier{db: db, notifications: notifications.NewService(db)}
+}
+
+// Handle - expiring = "last chance" pre hráčov v zóne aj nedávnych návštevníkov,
+// cleaned = zóna zmizla aj s nevyzbieranými itemami
+func (n *ZoneLifecycleNotifier) Handle(event ZoneLifecycleEvent) {
+	zone := event.Zone
+	data := map[string]interface{}{
+		"zone_id":   zone.ID,
+		"zone_name": zone.Name,
+		"biome":     zone.Biome,
+		"stage":     event.Stage,
+	}
+
+	switch event.Stage {
+	case ZoneStageExpiring:
+		var secondsLeft int
+		if zone.ExpiresAt != nil {
+			secondsLeft = int(time.Until(*zone.ExpiresAt).Seconds())
+			data["expires_at"] = zone.ExpiresAt.Unix()
+		}
+		data["seconds_left"] = secondsLeft
+		data["items_left"] = n.activeItems(zone.ID)
+
+		realtime.NotifyZone(zone.ID, nil, nil, realtime.EventZoneExpiring, data)
+
+		recipients := append(n.playersInside(zone.ID), n.recentVisitors(zone.ID, event.At)...)
+		n.notifications.Send(recipients, notifications.Notification{
+			Type:  notifications.TypeZoneExpiring,
+			Title: fmt.Sprintf("Last chance: %s is fading", zone.Name),
+			Body:  fmt.Sprintf("%s disappears in %d minutes. Collect what you can before it's gone.", zone.Name, secondsLeft/60),
+			Data:  data,
+		}, expiryDedupKey("zone_expiring", zone))
+
+	case ZoneStageAging:
+		// Len hráči práve v zóne - pri krátko žijúcich zónach by to inak bol spam
+		data["ttl_status"] = zone.TTLStatus()
+		n.notifications.Send(n.playersInside(zone.ID), notifications.Notification{
+			Type:  notifications.TypeZoneAging,
+			Title: fmt.Sprintf("%s is getting unstable", zone.Name),
+			Body:  "This zone will vanish within a few hours.",
+			Data:  data,
+		}, expiryDedupKey("zone_aging", zone))
+
+	case ZoneStageCleaned:
+		data["reason"] = event.Reason
+		data["items_removed"] = event.ItemsRemoved
+
+		recipients := append(append([]uuid.UUID{}, event.PlayersInside...), n.recentVisitors(zone.ID, event.At)...)
+		n.notifications.Send(recipients, notifications.Notification{
+			Type:  notifications.TypeZoneVanished,
+			Title: fmt.Sprintf("%s has vanished", zone.Name),
+			Body:  fmt.Sprintf("The zone is gone (%s). %d uncollected items were lost.", event.Reason, event.ItemsRemoved),
+			Data:  data,
+		}, "zone_cleaned:"+zone.ID.String())
+	}
+}
+
+// expiryDedupKey - dedup kľúč notifikácie viazaný na expires_at; po anchore (nový čas expirácie)
+// sa aging / last chance notifikácia pošle znova
+func expiryDedupKey(prefix string, zone gameplay.Zone) string {
+	key := prefix + ":" + zone.ID.String()
+	if zone.ExpiresAt != nil {
+		key += fmt.Sprintf(":%d", zone.ExpiresAt.Unix())
+	}
+	return key
+}
+
+func (n *ZoneLifecycleNotifier) playersInside(zoneID uuid.UUID) []uuid.UUID {
+	var ids []uuid.UUID
+	n.db.Model(&auth.PlayerSession{}).Where("current_zone = ?", zoneID).Pluck("user_id", &ids)
+	return ids
+}
+
+func (n *ZoneLifecycleNotifier) recentVisitors(zoneID uuid.UUID, at time.Time) []uuid.UUID {
+	var ids []uuid.UUID
+	n.db.Model(&zonestats.Visit{}).
+		Where("zone_id = ? AND (exited_at IS NULL OR exited_at > ?)", zoneID, at.Add(-ZoneRecentVisitorsSince)).
+		Distinct().Pluck("user_id", &ids)
+	return ids
+}
+
+func (n *ZoneLifecycleNotifier) activeItems(zoneID uuid.UUID) int64 {
+	var artifacts, gear int64
+	n.db.Model(&gameplay.Artifact{}).Where("zone_id = ? AND is_active = true", zoneID).Count(&artifacts)
+	n.db.Model(&gameplay.Gear{}).Where("zone_id = ? AND is_active = true", zoneID).Count(&gear)
+	return artifacts + gear
+}
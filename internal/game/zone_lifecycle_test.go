@@ -0,0 +1,26 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"geoanomaly/internal/gameplay"
+
+	"github.com/google/uuid"
+)
+
+func TestExpiryDedupKey_ChangesAfterAnchor(t *testing.T) {
+	expiresAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	zone := gameplay.Zone{BaseModel: gameplay.BaseModel{ID: uuid.New()}, ExpiresAt: &expiresAt}
+	before := expiryDedupKey("zone_expiring", zone)
+
+	if again := expiryDedupKey("zone_expiring", zone); again != before {
+		t.Fatalf("same expiry must give the same key: %s vs %s", before, again)
+	}
+
+	anchored := expiresAt.Add(2 * time.Hour)
+	zone.ExpiresAt = &anchored
+	if after := expiryDedupKey("zone_expiring", zone); after == before {
+		t.Errorf("anchored zone must get a new key, got %s twice", after)
+	}
+}
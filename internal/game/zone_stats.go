@@ -0,0 +1,102 @@
+package game
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/zonestats"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetZoneStats - štatistiky zóny (GET /game/zones/:id/stats), dostupné aj po jej cleanupe
+func (h *Handler) GetZoneStats(c *gin.Context) {
+	zoneID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zone ID format"})
+		return
+	}
+
+	report, err := h.zoneStats.ForZone(zoneID)
+	if errors.Is(err, zonestats.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No stats recorded for this zone"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load zone stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"zone_id":   zoneID,
+		"stats":     report,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// GetZoneStatsAggregate - súčty za zóny podľa biome/tier/zone_type/danger_level
+// (GET /admin/analytics/zones/stats?group_by=biome&tier=2&from=...&to=...)
+func (h *Handler) GetZoneStatsAggregate(c *gin.Context) {
+	groupBy := c.DefaultQuery("group_by", "biome")
+
+	filter := zonestats.Filter{
+		Biome:    c.Query("biome"),
+		ZoneType: c.Query("zone_type"),
+	}
+	if value := c.Query("tier"); value != "" {
+		tier, err := strconv.Atoi(value)
+		if err != nil || tier < 0 || tier > 4 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'tier', expected 0-4"})
+			return
+		}
+		filter.Tier = &tier
+	}
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' timestamp, expected RFC3339"})
+			return
+		}
+		filter.From = &parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' timestamp, expected RFC3339"})
+			return
+		}
+		filter.To = &parsed
+	}
+
+	rows, err := h.zoneStats.Aggregate(groupBy, filter)
+	if errors.Is(err, zonestats.ErrInvalidGroupBy) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid 'group_by'",
+			"allowed": []string{"biome", "tier", "zone_type", "danger_level"},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to aggregate zone stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group_by":  groupBy,
+		"groups":    rows,
+		"count":     len(rows),
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// gearRarity - rarity gearu z properties (spawn), inak podľa levelu
+func (h *Handler) gearRarity(gear gameplay.Gear) string {
+	if rarity, ok := gear.Properties["rarity"].(string); ok && rarity != "" {
+		return rarity
+	}
+	return h.gearService.getRarityForLevel(gear.Level)
+}
@@ -4,11 +4,12 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
 	"time"
 
 	"geoanomaly/internal/auth"
 	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/zonestats"
+	"geoanomaly/pkg/geoquery"
 
 	"github.com/google/uuid"
 )
@@ -17,22 +18,16 @@ import (
 func (h *Handler) getExistingZonesInArea(lat, lng, radiusMeters float64) []gameplay.Zone {
 	var zones []gameplay.Zone
 
-	if err := h.db.Where("is_active = true").Find(&zones).Error; err != nil {
+	// Spatial index (PostGIS alebo bounding box) - netreba načítať všetky zóny
+	if err := h.db.Where("is_active = true").
+		Where(h.geo.Within(geoquery.ZoneColumns, lat, lng, radiusMeters)).
+		Find(&zones).Error; err != nil {
 		log.Printf("❌ Failed to query zones: %v", err)
 		return []gameplay.Zone{}
 	}
 
-	// Manual distance filtering
-	var filteredZones []gameplay.Zone
-	for _, zone := range zones {
-		distance := CalculateDistance(lat, lng, zone.Location.Latitude, zone.Location.Longitude)
-		if distance <= radiusMeters {
-			filteredZones = append(filteredZones, zone)
-		}
-	}
-
-	log.Printf("📍 Found %d zones in area (radius: %.0fm)", len(filteredZones), radiusMeters)
-	return filteredZones
+	log.Printf("📍 Found %d zones in area (radius: %.0fm)", len(zones), radiusMeters)
+	return zones
 }
 
 // Filter zones by tier based on user tier
@@ -49,9 +44,11 @@ func (h *Handler) filterZonesByTier(zones []gameplay.Zone, userTier int) []gamep
 	default:
 		maxVisibleTier = userTier
 	}
+	now := time.Now()
 	var visibleZones []gameplay.Zone
 	for _, zone := range zones {
-		if zone.TierRequired <= maxVisibleTier {
+		// Event zóny sú viditeľné až od naplánovaného začiatku
+		if zone.TierRequired <= maxVisibleTier && eventWindowOpen(zone, now) {
 			visibleZones = append(visibleZones, zone)
 		}
 	}
@@ -70,23 +67,6 @@ func (h *Handler) countDynamicZonesInArea(lat, lng, radiusMeters float64) int {
 	return count
 }
 
-func (h *Handler) calculateMaxZones(playerTier int) int {
-	switch playerTier {
-	case 0:
-		return 1 // Free - 1 zóna len
-	case 1:
-		return 2 // Basic - 2 zóny
-	case 2:
-		return 3 // Premium - 3 zóny
-	case 3:
-		return 5 // Pro - 5 zón
-	case 4:
-		return 7 // Elite - 7 zón
-	default:
-		return 1
-	}
-}
-
 func (h *Handler) buildZoneDetails(zone gameplay.Zone, playerLat, playerLng float64, playerTier int) ZoneWithDetails {
 	distance := CalculateDistance(playerLat, playerLng, zone.Location.Latitude, zone.Location.Longitude)
 
@@ -125,40 +105,20 @@ func (h *Handler) buildZoneDetails(zone gameplay.Zone, playerLat, playerLng floa
 	return details
 }
 
-// ✅ NEW: Tier-based zone radius calculation with random ranges
-func (h *Handler) getTierZoneRadius(zoneTier int) (float64, float64) {
-	switch zoneTier {
-	case 0:
-		return Tier0MinRadius, Tier0MaxRadius
-	case 1:
-		return Tier1MinRadius, Tier1MaxRadius
-	case 2:
-		return Tier2MinRadius, Tier2MaxRadius
-	case 3:
-		return Tier3MinRadius, Tier3MaxRadius
-	case 4:
-		return Tier4MinRadius, Tier4MaxRadius
-	default:
-		return Tier0MinRadius, Tier0MaxRadius
-	}
-}
-
-// ✅ UPDATED: calculateZoneRadius now uses random ranges instead of fixed values
-func (h *Handler) calculateZoneRadius(tier int) int {
-	minRadius, maxRadius := h.getTierZoneRadius(tier)
+// calculateZoneRadius - náhodný polomer v rozsahu tieru zóny zo spawn konfigurácie
+func calculateZoneRadius(cfg *SpawnConfig, rng spawnRand, tier int) int {
+	t := cfg.tier(tier)
+	minRadius, maxRadius := t.MinRadius, t.MaxRadius
 
 	// Random radius within tier range
-	randomRadius := minRadius + rand.Float64()*(maxRadius-minRadius)
-
-	log.Printf("🏗️ [TIER %d] Zone radius: %.0fm (range: %.0f-%.0fm)",
-		tier, randomRadius, minRadius, maxRadius)
+	randomRadius := minRadius + rng.Float64()*(maxRadius-minRadius)
 
 	return int(randomRadius)
 }
 
-func (h *Handler) generateRandomPosition(centerLat, centerLng, radiusMeters float64) (float64, float64) {
-	angle := rand.Float64() * 2 * math.Pi
-	distance := rand.Float64() * radiusMeters
+func generateRandomPosition(rng spawnRand, centerLat, centerLng, radiusMeters float64) (float64, float64) {
+	angle := rng.Float64() * 2 * math.Pi
+	distance := rng.Float64() * radiusMeters
 	earthRadius := 6371000.0
 
 	latOffset := (distance * math.Cos(angle)) / earthRadius * (180 / math.Pi)
@@ -167,85 +127,6 @@ func (h *Handler) generateRandomPosition(centerLat, centerLng, radiusMeters floa
 	return centerLat + latOffset, centerLng + lngOffset
 }
 
-// ✅ SIMPLIFIED: Keep only essential functions, remove duplicates
-func (h *Handler) getAvailableBiomes(playerTier int) []string {
-	biomes := []string{BiomeForest} // Forest always available
-
-	if playerTier >= 1 {
-		biomes = append(biomes, BiomeMountain, BiomeUrban, BiomeWater)
-	}
-	if playerTier >= 2 {
-		biomes = append(biomes, BiomeIndustrial) //BiomeNight
-	}
-	if playerTier >= 3 {
-		biomes = append(biomes, BiomeRadioactive)
-	}
-	if playerTier >= 4 {
-		biomes = append(biomes, BiomeChemical)
-	}
-
-	return biomes
-}
-
-// Nahradíš pôvodnú calculateZoneTier touto verziou:
-func (h *Handler) calculateZoneTier(playerTier, biomeMinTier int) int {
-	r := rand.Float64()
-
-	switch playerTier {
-	case 0:
-		// 55% na tier 0, 45% na tier 1
-		if biomeMinTier > 1 {
-			return biomeMinTier
-		}
-		if r < 0.55 {
-			return max(biomeMinTier, 0)
-		}
-		return max(biomeMinTier, 1)
-	case 1:
-		// 50% na tier 0, 45% na tier 1, 5% na tier 2
-		if r < 0.50 {
-			return max(biomeMinTier, 0)
-		} else if r < 0.95 {
-			return max(biomeMinTier, 1)
-		}
-		return max(biomeMinTier, 2)
-	case 2:
-		// 30% na tier 0, 40% na tier 1, 15% na tier 2, 15% na tier 3
-		if r < 0.30 {
-			return max(biomeMinTier, 0)
-		} else if r < 0.70 {
-			return max(biomeMinTier, 1)
-		} else if r < 0.85 {
-			return max(biomeMinTier, 2)
-		}
-		return max(biomeMinTier, 3)
-	case 3:
-		// 20% na tier 0, 20% na tier 1, 25% na tier 2, 35% na tier 3
-		if r < 0.20 {
-			return max(biomeMinTier, 0)
-		} else if r < 0.40 {
-			return max(biomeMinTier, 1)
-		} else if r < 0.65 {
-			return max(biomeMinTier, 2)
-		}
-		return max(biomeMinTier, 3)
-	case 4:
-		// 15% na tier 0, 15% na tier 1, 20% na tier 2, 25% na tier 3, 25% na tier 4
-		if r < 0.15 {
-			return max(biomeMinTier, 0)
-		} else if r < 0.30 {
-			return max(biomeMinTier, 1)
-		} else if r < 0.50 {
-			return max(biomeMinTier, 2)
-		} else if r < 0.75 {
-			return max(biomeMinTier, 3)
-		}
-		return max(biomeMinTier, 4)
-	default:
-		return biomeMinTier
-	}
-}
-
 // Helper, pridaj hore do zones.go:
 func max(a, b int) int {
 	if a > b {
@@ -267,21 +148,76 @@ func (h *Handler) getZoneCategory(tier int) string {
 	}
 }
 
+// itemSpawnOptions - voliteľné parametre pre spawn konkrétneho itemu (event/admin spawny)
+type itemSpawnOptions struct {
+	Location   *LocationPoint // presná pozícia; nil = náhodná pozícia v zóne
+	Rarity     string         // override rarity (pri gear sa ukladá do properties)
+	Properties gameplay.JSONB // extra properties, prepíšu default hodnoty
+	Spawner    string         // default "biome_specific"
+	Reason     string         // default "zone_creation"
+	Force      bool           // gear: preskoč tier/biome kontrolu kategórie
+	Rand       spawnRand      // seedovaný RNG zóny; nil = globálny math/rand
+}
+
+func (o itemSpawnOptions) rand() spawnRand {
+	if o.Rand == nil {
+		return globalRand{}
+	}
+	return o.Rand
+}
+
+func (o itemSpawnOptions) spawner() string {
+	if o.Spawner == "" {
+		return "biome_specific"
+	}
+	return o.Spawner
+}
+
+func (o itemSpawnOptions) reason() string {
+	if o.Reason == "" {
+		return "zone_creation"
+	}
+	return o.Reason
+}
+
+func (h *Handler) spawnPosition(zone gameplay.Zone, opts itemSpawnOptions) (float64, float64) {
+	if opts.Location != nil {
+		return opts.Location.Latitude, opts.Location.Longitude
+	}
+	return generateRandomPosition(opts.rand(), zone.Location.Latitude, zone.Location.Longitude, float64(zone.RadiusMeters))
+}
+
+func mergeProperties(base, extra gameplay.JSONB) gameplay.JSONB {
+	for k, v := range extra {
+		base[k] = v
+	}
+	return base
+}
+
 // Keep existing biome-specific spawning functions
-func (h *Handler) spawnSpecificArtifact(zoneID uuid.UUID, artifactType, biome string, tier int) error {
+func (h *Handler) spawnSpecificArtifact(rng spawnRand, zoneID uuid.UUID, artifactType, biome string, tier int) error {
 	var zone gameplay.Zone
 	if err := h.db.First(&zone, "id = ?", zoneID).Error; err != nil {
 		return err
 	}
 
+	_, err := h.createArtifactInZone(zone, artifactType, biome, tier, itemSpawnOptions{Rand: rng})
+	return err
+}
+
+// createArtifactInZone - vytvorí artefakt v zóne a vráti vytvorený riadok
+func (h *Handler) createArtifactInZone(zone gameplay.Zone, artifactType, biome string, tier int, opts itemSpawnOptions) (*gameplay.Artifact, error) {
 	displayName := GetArtifactDisplayName(artifactType)
 	rarity := GetArtifactRarity(artifactType, tier)
+	if opts.Rarity != "" {
+		rarity = opts.Rarity
+	}
 
-	lat, lng := h.generateRandomPosition(zone.Location.Latitude, zone.Location.Longitude, float64(zone.RadiusMeters))
+	lat, lng := h.spawnPosition(zone, opts)
 
 	artifact := gameplay.Artifact{
 		BaseModel: gameplay.BaseModel{ID: uuid.New()},
-		ZoneID:    zoneID,
+		ZoneID:    zone.ID,
 		Name:      displayName,
 		Type:      artifactType,
 		Rarity:    rarity,
@@ -291,50 +227,67 @@ func (h *Handler) spawnSpecificArtifact(zoneID uuid.UUID, artifactType, biome st
 			Longitude: lng,
 			Timestamp: time.Now(),
 		},
-		Properties: gameplay.JSONB{
+		Properties: mergeProperties(gameplay.JSONB{
 			"spawn_time":   time.Now().Unix(),
-			"spawner":      "biome_specific",
+			"spawner":      opts.spawner(),
 			"zone_tier":    tier,
 			"biome":        biome,
-			"spawn_reason": "zone_creation",
-		},
+			"spawn_reason": opts.reason(),
+		}, opts.Properties),
 		IsActive: true,
 	}
 
-	return h.db.Create(&artifact).Error
+	if err := h.db.Create(&artifact).Error; err != nil {
+		return nil, err
+	}
+	h.recordSpawn(zone, zonestats.ItemArtifact, artifact.Type, artifact.Rarity)
+	return &artifact, nil
 }
 
-func (h *Handler) spawnSpecificGear(zoneID uuid.UUID, gearType, biome string, tier int) error {
+func (h *Handler) spawnSpecificGear(rng spawnRand, zoneID uuid.UUID, gearType, biome string, tier int) error {
 	var zone gameplay.Zone
 	if err := h.db.First(&zone, "id = ?", zoneID).Error; err != nil {
 		return err
 	}
 
+	_, err := h.createGearInZone(zone, gearType, biome, tier, itemSpawnOptions{Rand: rng})
+	return err
+}
+
+// createGearInZone - vytvorí gear v zóne a vráti vytvorený riadok
+func (h *Handler) createGearInZone(zone gameplay.Zone, gearType, biome string, tier int, opts itemSpawnOptions) (*gameplay.Gear, error) {
 	// Najdi gear kategóriu v databáze
 	var gearCategory gameplay.GearCategory
 	if err := h.db.Where("id = ? AND is_active = ?", gearType, true).First(&gearCategory).Error; err != nil {
 		// Ak sa kategória nenájde, použij fallback
 		log.Printf("⚠️ Gear category %s not found in database, using fallback", gearType)
-		return h.spawnSpecificGearFallback(zoneID, gearType, biome, tier)
+		return h.createGearFallback(zone, gearType, biome, tier, opts)
 	}
 
-	// Skontroluj tier requirements
-	if gearCategory.Level > tier {
-		log.Printf("⚠️ Gear category %s requires tier %d, but zone is tier %d", gearType, gearCategory.Level, tier)
-		return fmt.Errorf("gear level too high for zone tier")
+	if !opts.Force {
+		// Skontroluj tier requirements
+		if gearCategory.Level > tier {
+			log.Printf("⚠️ Gear category %s requires tier %d, but zone is tier %d", gearType, gearCategory.Level, tier)
+			return nil, fmt.Errorf("gear level too high for zone tier")
+		}
+
+		// Skontroluj biome requirements
+		if gearCategory.Biome != "all" && gearCategory.Biome != biome {
+			log.Printf("⚠️ Gear category %s is for biome %s, but zone is %s", gearType, gearCategory.Biome, biome)
+			return nil, fmt.Errorf("gear biome mismatch")
+		}
 	}
 
-	// Skontroluj biome requirements
-	if gearCategory.Biome != "all" && gearCategory.Biome != biome {
-		log.Printf("⚠️ Gear category %s is for biome %s, but zone is %s", gearType, gearCategory.Biome, biome)
-		return fmt.Errorf("gear biome mismatch")
+	rarity := gearCategory.Rarity
+	if opts.Rarity != "" {
+		rarity = opts.Rarity
 	}
 
-	lat, lng := h.generateRandomPosition(zone.Location.Latitude, zone.Location.Longitude, float64(zone.RadiusMeters))
+	lat, lng := h.spawnPosition(zone, opts)
 
 	gear := gameplay.Gear{
 		BaseModel: gameplay.BaseModel{ID: uuid.New()},
-		ZoneID:    zoneID,
+		ZoneID:    zone.ID,
 		Name:      gearCategory.Name,
 		Type:      gearCategory.ID, // Použijeme ID kategórie ako type
 		Level:     gearCategory.Level,
@@ -344,42 +297,52 @@ func (h *Handler) spawnSpecificGear(zoneID uuid.UUID, gearType, biome string, ti
 			Longitude: lng,
 			Timestamp: time.Now(),
 		},
-		Properties: gameplay.JSONB{
+		Properties: mergeProperties(gameplay.JSONB{
 			"spawn_time":         time.Now().Unix(),
-			"spawner":            "biome_specific",
+			"spawner":            opts.spawner(),
 			"zone_tier":          tier,
 			"biome":              biome,
-			"spawn_reason":       "zone_creation",
+			"spawn_reason":       opts.reason(),
 			"category_id":        gearCategory.ID,
 			"slot":               gearCategory.SlotID,
-			"rarity":             gearCategory.Rarity,
+			"rarity":             rarity,
 			"base_durability":    gearCategory.BaseDurability,
 			"zombie_resistance":  gearCategory.BaseZombieResistance,
 			"bandit_resistance":  gearCategory.BaseBanditResistance,
 			"soldier_resistance": gearCategory.BaseSoldierResistance,
 			"monster_resistance": gearCategory.BaseMonsterResistance,
-		},
+		}, opts.Properties),
 		IsActive: true,
 	}
 
-	return h.db.Create(&gear).Error
+	if err := h.db.Create(&gear).Error; err != nil {
+		return nil, err
+	}
+	h.recordSpawn(zone, zonestats.ItemGear, gear.Type, rarity)
+	return &gear, nil
 }
 
 // Fallback funkcia pre staré gear types
-func (h *Handler) spawnSpecificGearFallback(zoneID uuid.UUID, gearType, biome string, tier int) error {
-	var zone gameplay.Zone
-	if err := h.db.First(&zone, "id = ?", zoneID).Error; err != nil {
-		return err
-	}
-
+func (h *Handler) createGearFallback(zone gameplay.Zone, gearType, biome string, tier int, opts itemSpawnOptions) (*gameplay.Gear, error) {
 	displayName := GetGearDisplayName(gearType)
-	level := tier + rand.Intn(2) + 1
+	level := tier + opts.rand().Intn(2) + 1
 
-	lat, lng := h.generateRandomPosition(zone.Location.Latitude, zone.Location.Longitude, float64(zone.RadiusMeters))
+	lat, lng := h.spawnPosition(zone, opts)
+
+	properties := gameplay.JSONB{
+		"spawn_time":   time.Now().Unix(),
+		"spawner":      opts.spawner(),
+		"zone_tier":    tier,
+		"biome":        biome,
+		"spawn_reason": opts.reason(),
+	}
+	if opts.Rarity != "" {
+		properties["rarity"] = opts.Rarity
+	}
 
 	gear := gameplay.Gear{
 		BaseModel: gameplay.BaseModel{ID: uuid.New()},
-		ZoneID:    zoneID,
+		ZoneID:    zone.ID,
 		Name:      displayName,
 		Type:      gearType,
 		Level:     level,
@@ -389,15 +352,13 @@ func (h *Handler) spawnSpecificGearFallback(zoneID uuid.UUID, gearType, biome st
 			Longitude: lng,
 			Timestamp: time.Now(),
 		},
-		Properties: gameplay.JSONB{
-			"spawn_time":   time.Now().Unix(),
-			"spawner":      "biome_specific",
-			"zone_tier":    tier,
-			"biome":        biome,
-			"spawn_reason": "zone_creation",
-		},
-		IsActive: true,
+		Properties: mergeProperties(properties, opts.Properties),
+		IsActive:   true,
 	}
 
-	return h.db.Create(&gear).Error
+	if err := h.db.Create(&gear).Error; err != nil {
+		return nil, err
+	}
+	h.recordSpawn(zone, zonestats.ItemGear, gear.Type, h.gearRarity(gear))
+	return &gear, nil
 }
@@ -0,0 +1,40 @@
+package heatmap
+
+import "strings"
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// decodeGeohash - vráti stred geohash bunky (rovnaké kódovanie ako PostGIS ST_GeoHash)
+func decodeGeohash(hash string) (float64, float64, bool) {
+	minLat, maxLat := -90.0, 90.0
+	minLng, maxLng := -180.0, 180.0
+	evenBit := true
+
+	for _, ch := range hash {
+		idx := strings.IndexRune(geohashAlphabet, ch)
+		if idx < 0 {
+			return 0, 0, false
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := idx&(1<<uint(bit)) != 0
+			if evenBit {
+				mid := (minLng + maxLng) / 2
+				if set {
+					minLng = mid
+				} else {
+					maxLng = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if set {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			evenBit = !evenBit
+		}
+	}
+
+	return (minLat + maxLat) / 2, (minLng + maxLng) / 2, true
+}
@@ -0,0 +1,83 @@
+package heatmap
+
+import "time"
+
+// Layer identifies which data source a heatmap aggregates
+type Layer string
+
+const (
+	LayerPlayers     Layer = "players"     // location history samples
+	LayerSpawns      Layer = "spawns"      // artifacts + gear spawned in zones
+	LayerCollections Layer = "collections" // artifacts + gear picked up by players
+	LayerZones       Layer = "zones"       // zone centers
+)
+
+// AllLayers - poradie vrstiev v admin odpovedi
+var AllLayers = []Layer{LayerPlayers, LayerSpawns, LayerCollections, LayerZones}
+
+const (
+	// Geohash presnosť: 5 ≈ 4.9km, 6 ≈ 1.2km, 7 ≈ 150m, 8 ≈ 38m
+	MinPrecision       = 4
+	DefaultPrecision   = 6
+	MaxPlayerPrecision = 7 // hráči nedostanú jemnejšiu mriežku ako ~150m
+	MaxAdminPrecision  = 8
+
+	DefaultWindow = 24 * time.Hour
+	MaxWindow     = 31 * 24 * time.Hour
+
+	DefaultCellLimit = 500
+	MaxCellLimit     = 2000
+
+	// DefaultMinUsersPerCell - k-anonymita pre hráčsky heatmap (HEATMAP_MIN_USERS_PER_CELL)
+	DefaultMinUsersPerCell = 5
+)
+
+// BBox - voliteľné ohraničenie oblasti
+type BBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLng float64 `json:"min_lng"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLng float64 `json:"max_lng"`
+}
+
+// Filter - spoločné filtre pre všetky vrstvy
+type Filter struct {
+	From      time.Time
+	To        time.Time
+	Biome     string
+	Tier      *int // zone tier_required
+	Precision int
+	BBox      *BBox
+	Limit     int
+}
+
+// Cell - jedna bunka heatmapy
+type Cell struct {
+	Geohash   string  `json:"geohash"`
+	Latitude  float64 `json:"latitude"`  // stred bunky
+	Longitude float64 `json:"longitude"` // stred bunky
+	Count     int64   `json:"count"`
+	Users     int64   `json:"users,omitempty"`
+}
+
+// Map - výsledok jednej vrstvy
+type Map struct {
+	Layer       Layer     `json:"layer"`
+	Cells       []Cell    `json:"cells"`
+	TotalCount  int64     `json:"total_count"`
+	Precision   int       `json:"precision"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	MinUsers    int       `json:"min_users_per_cell,omitempty"`
+	Suppressed  int       `json:"suppressed_cells,omitempty"` // bunky skryté kvôli k-anonymite
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// ZoneSpacing - štatistika vzdialeností medzi zónami (ladenie MinZoneDistance / MaxSpawnRadius)
+type ZoneSpacing struct {
+	Zones            int     `json:"zones"`
+	MinNearestMeters float64 `json:"min_nearest_meters"`
+	AvgNearestMeters float64 `json:"avg_nearest_meters"`
+	P50NearestMeters float64 `json:"p50_nearest_meters"`
+	P90NearestMeters float64 `json:"p90_nearest_meters"`
+}
@@ -0,0 +1,341 @@
+package heatmap
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxSpacingZones - horný limit zón pre O(n²) výpočet vzdialeností
+const maxSpacingZones = 3000
+
+// Service agreguje polohy, spawny a zbery do geohash mriežky
+type Service struct {
+	db              *gorm.DB
+	minUsersPerCell int
+}
+
+func NewService(db *gorm.DB) *Service {
+	k := DefaultMinUsersPerCell
+	if value := os.Getenv("HEATMAP_MIN_USERS_PER_CELL"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			k = parsed
+		} else {
+			log.Printf("⚠️ Invalid HEATMAP_MIN_USERS_PER_CELL=%q, using %d", value, k)
+		}
+	}
+	return &Service{db: db, minUsersPerCell: k}
+}
+
+// MinUsersPerCell - k-anonymita hráčskeho heatmapu
+func (s *Service) MinUsersPerCell() int {
+	return s.minUsersPerCell
+}
+
+// ParseFilter - from/to (RFC3339), biome, tier, precision, bbox=minLat,minLng,maxLat,maxLng, limit
+func ParseFilter(c *gin.Context, maxPrecision int) (Filter, error) {
+	now := time.Now()
+	f := Filter{
+		From:      now.Add(-DefaultWindow),
+		To:        now,
+		Biome:     c.Query("biome"),
+		Precision: DefaultPrecision,
+		Limit:     DefaultCellLimit,
+	}
+
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return f, fmt.Errorf("invalid 'from' timestamp, expected RFC3339")
+		}
+		f.From = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return f, fmt.Errorf("invalid 'to' timestamp, expected RFC3339")
+		}
+		f.To = parsed
+	}
+	if !f.From.Before(f.To) {
+		return f, fmt.Errorf("'from' must be before 'to'")
+	}
+	if f.To.Sub(f.From) > MaxWindow {
+		return f, fmt.Errorf("time window too large (max %d days)", int(MaxWindow.Hours()/24))
+	}
+
+	if value := c.Query("tier"); value != "" {
+		tier, err := strconv.Atoi(value)
+		if err != nil || tier < 0 || tier > 4 {
+			return f, fmt.Errorf("invalid tier (0-4)")
+		}
+		f.Tier = &tier
+	}
+
+	if value := c.Query("precision"); value != "" {
+		precision, err := strconv.Atoi(value)
+		if err != nil || precision < MinPrecision || precision > maxPrecision {
+			return f, fmt.Errorf("precision must be between %d and %d", MinPrecision, maxPrecision)
+		}
+		f.Precision = precision
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxCellLimit {
+			return f, fmt.Errorf("limit must be between 1 and %d", MaxCellLimit)
+		}
+		f.Limit = limit
+	}
+
+	if value := c.Query("bbox"); value != "" {
+		var b BBox
+		if _, err := fmt.Sscanf(value, "%f,%f,%f,%f", &b.MinLat, &b.MinLng, &b.MaxLat, &b.MaxLng); err != nil {
+			return f, fmt.Errorf("invalid bbox, expected minLat,minLng,maxLat,maxLng")
+		}
+		if b.MinLat >= b.MaxLat || b.MinLng >= b.MaxLng ||
+			b.MinLat < -90 || b.MaxLat > 90 || b.MinLng < -180 || b.MaxLng > 180 {
+			return f, fmt.Errorf("invalid bbox bounds")
+		}
+		f.BBox = &b
+	}
+
+	return f, nil
+}
+
+type cellRow struct {
+	Cell  string
+	Count int64
+	Users int64
+}
+
+// cellSelect - geohash bunka pre stĺpce <alias>.latitude/longitude
+func cellSelect(latCol, lngCol string) string {
+	return fmt.Sprintf("ST_GeoHash(ST_SetSRID(ST_MakePoint(%s::float8, %s::float8), 4326), ?) AS cell", lngCol, latCol)
+}
+
+// applyFilter - spoločné where podmienky (z = gameplay.zones)
+func applyFilter(q *gorm.DB, f Filter, latCol, lngCol string) *gorm.DB {
+	if f.Biome != "" {
+		q = q.Where("z.biome = ?", f.Biome)
+	}
+	if f.Tier != nil {
+		q = q.Where("z.tier_required = ?", *f.Tier)
+	}
+	if f.BBox != nil {
+		q = q.Where(fmt.Sprintf("%s BETWEEN ? AND ? AND %s BETWEEN ? AND ?", latCol, lngCol),
+			f.BBox.MinLat, f.BBox.MaxLat, f.BBox.MinLng, f.BBox.MaxLng)
+	}
+	return q
+}
+
+// Players - hustota hráčov z location histórie; bunky s menej ako k hráčmi sa nevrátia
+func (s *Service) Players(f Filter, minUsers int) (*Map, error) {
+	q := s.db.Table("gameplay.location_history AS lh").
+		Select(cellSelect("lh.latitude", "lh.longitude")+", COUNT(*) AS count, COUNT(DISTINCT lh.user_id) AS users", f.Precision).
+		Where("lh.recorded_at >= ? AND lh.recorded_at < ?", f.From, f.To)
+
+	if f.Biome != "" || f.Tier != nil {
+		q = q.Joins("JOIN gameplay.zones z ON z.id = lh.zone_id")
+	}
+	q = applyFilter(q, f, "lh.latitude", "lh.longitude").Group("cell")
+	if minUsers > 1 {
+		q = q.Having("COUNT(DISTINCT lh.user_id) >= ?", minUsers)
+	}
+
+	var rows []cellRow
+	if err := q.Order("users DESC").Limit(f.Limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	m := newMap(LayerPlayers, f, rows)
+	m.MinUsers = minUsers
+	return m, nil
+}
+
+// Spawns - hustota spawnutých artefaktov a gearu
+func (s *Service) Spawns(f Filter) (*Map, error) {
+	var merged []cellRow
+	for _, table := range []string{"gameplay.artifacts", "gameplay.gear"} {
+		q := s.db.Table(table+" AS i").
+			Select(cellSelect("i.location_latitude", "i.location_longitude")+", COUNT(*) AS count", f.Precision).
+			Joins("JOIN gameplay.zones z ON z.id = i.zone_id").
+			Where("i.created_at >= ? AND i.created_at < ?", f.From, f.To)
+		q = applyFilter(q, f, "i.location_latitude", "i.location_longitude").Group("cell")
+
+		var rows []cellRow
+		if err := q.Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		merged = append(merged, rows...)
+	}
+
+	return newMap(LayerSpawns, f, mergeRows(merged)), nil
+}
+
+// Collections - kde hráči reálne zbierajú predmety (inventory_items ↔ artifacts/gear)
+func (s *Service) Collections(f Filter) (*Map, error) {
+	var merged []cellRow
+	for _, source := range []struct{ table, itemType string }{
+		{"gameplay.artifacts", "artifact"},
+		{"gameplay.gear", "gear"},
+	} {
+		q := s.db.Table("gameplay.inventory_items AS inv").
+			Select(cellSelect("i.location_latitude", "i.location_longitude")+", COUNT(*) AS count, COUNT(DISTINCT inv.user_id) AS users", f.Precision).
+			Joins(fmt.Sprintf("JOIN %s i ON i.id = inv.item_id", source.table)).
+			Joins("JOIN gameplay.zones z ON z.id = i.zone_id").
+			Where("inv.item_type = ? AND inv.acquired_at >= ? AND inv.acquired_at < ?", source.itemType, f.From, f.To)
+		q = applyFilter(q, f, "i.location_latitude", "i.location_longitude").Group("cell")
+
+		var rows []cellRow
+		if err := q.Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		merged = append(merged, rows...)
+	}
+
+	return newMap(LayerCollections, f, mergeRows(merged)), nil
+}
+
+// Zones - hustota zón vytvorených v okne
+func (s *Service) Zones(f Filter) (*Map, error) {
+	q := s.db.Table("gameplay.zones AS z").
+		Select(cellSelect("z.location_latitude", "z.location_longitude")+", COUNT(*) AS count", f.Precision).
+		Where("z.created_at >= ? AND z.created_at < ?", f.From, f.To)
+	q = applyFilter(q, f, "z.location_latitude", "z.location_longitude").Group("cell")
+
+	var rows []cellRow
+	if err := q.Order("count DESC").Limit(f.Limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return newMap(LayerZones, f, rows), nil
+}
+
+// ZoneSpacing - vzdialenosti k najbližšej aktívnej zóne v rámci filtra
+func (s *Service) ZoneSpacing(f Filter) (*ZoneSpacing, error) {
+	type point struct {
+		Lat float64
+		Lng float64
+	}
+
+	q := s.db.Table("gameplay.zones AS z").
+		Select("z.location_latitude AS lat, z.location_longitude AS lng").
+		Where("z.is_active = true")
+	q = applyFilter(q, f, "z.location_latitude", "z.location_longitude")
+
+	var points []point
+	if err := q.Limit(maxSpacingZones).Scan(&points).Error; err != nil {
+		return nil, err
+	}
+
+	spacing := &ZoneSpacing{Zones: len(points)}
+	if len(points) < 2 {
+		return spacing, nil
+	}
+
+	nearest := make([]float64, len(points))
+	for i := range points {
+		best := math.MaxFloat64
+		for j := range points {
+			if i == j {
+				continue
+			}
+			if d := haversine(points[i].Lat, points[i].Lng, points[j].Lat, points[j].Lng); d < best {
+				best = d
+			}
+		}
+		nearest[i] = best
+	}
+	sort.Float64s(nearest)
+
+	sum := 0.0
+	for _, d := range nearest {
+		sum += d
+	}
+	spacing.MinNearestMeters = math.Round(nearest[0])
+	spacing.AvgNearestMeters = math.Round(sum / float64(len(nearest)))
+	spacing.P50NearestMeters = math.Round(percentile(nearest, 0.5))
+	spacing.P90NearestMeters = math.Round(percentile(nearest, 0.9))
+	return spacing, nil
+}
+
+// mergeRows - spojí bunky z viacerých tabuliek (artifacts + gear)
+func mergeRows(rows []cellRow) []cellRow {
+	byCell := make(map[string]*cellRow)
+	var order []string
+	for _, r := range rows {
+		if existing, ok := byCell[r.Cell]; ok {
+			existing.Count += r.Count
+			existing.Users += r.Users // horný odhad - ten istý hráč môže byť v oboch zdrojoch
+			continue
+		}
+		row := r
+		byCell[r.Cell] = &row
+		order = append(order, r.Cell)
+	}
+
+	merged := make([]cellRow, 0, len(order))
+	for _, cell := range order {
+		merged = append(merged, *byCell[cell])
+	}
+	return merged
+}
+
+func newMap(layer Layer, f Filter, rows []cellRow) *Map {
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Count > rows[j].Count })
+	if len(rows) > f.Limit {
+		rows = rows[:f.Limit]
+	}
+
+	m := &Map{
+		Layer:       layer,
+		Cells:       make([]Cell, 0, len(rows)),
+		Precision:   f.Precision,
+		From:        f.From,
+		To:          f.To,
+		GeneratedAt: time.Now(),
+	}
+	for _, r := range rows {
+		lat, lng, ok := decodeGeohash(r.Cell)
+		if !ok {
+			continue
+		}
+		m.Cells = append(m.Cells, Cell{
+			Geohash:   r.Cell,
+			Latitude:  lat,
+			Longitude: lng,
+			Count:     r.Count,
+			Users:     r.Users,
+		})
+		m.TotalCount += r.Count
+	}
+	return m
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	const earthRadius = 6371000 // metrov
+
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -7,8 +7,11 @@ import (
 	"log"
 	"time"
 
+	"geoanomaly/internal/analytics"
+	"geoanomaly/internal/deployable"
 	"geoanomaly/internal/gameplay"
 	"geoanomaly/internal/menu"
+	"geoanomaly/pkg/geoquery"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -21,6 +24,7 @@ import (
 type Service struct {
 	db        *gorm.DB
 	xpHandler XPHandler
+	geo       *geoquery.Querier
 }
 
 // XPHandler interface pre dependency injection
@@ -52,6 +56,7 @@ func NewService(db *gorm.DB, xpHandler XPHandler) *Service {
 	return &Service{
 		db:        db,
 		xpHandler: xpHandler,
+		geo:       geoquery.New(db),
 	}
 }
 
@@ -468,6 +473,7 @@ func (s *Service) StartResearch(userID uuid.UUID, req *StartResearchRequest) (*R
 // CompleteResearch completes a research project and returns results
 func (s *Service) CompleteResearch(userID uuid.UUID, projectID uuid.UUID) (*ResearchResult, error) {
 	var result *ResearchResult
+	var researchType string
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Get research project
 		var project ResearchProject
@@ -543,6 +549,7 @@ func (s *Service) CompleteResearch(userID uuid.UUID, projectID uuid.UUID) (*Rese
 		// ✅ KROK 6: Log research completion
 		log.Printf("🔬 Research completed for artifact %s by user %s", project.ArtifactID, userID)
 
+		researchType = project.ResearchType
 		result = researchResult
 		return nil
 	})
@@ -550,6 +557,15 @@ func (s *Service) CompleteResearch(userID uuid.UUID, projectID uuid.UUID) (*Rese
 	if err != nil {
 		return nil, err
 	}
+
+	analytics.Track(s.db, analytics.Event{
+		Type:       analytics.EventResearchCompleted,
+		UserID:     &userID,
+		ItemType:   "research",
+		ItemKey:    researchType,
+		Rarity:     result.TrueRarity,
+		Properties: analytics.JSONB{"project_id": projectID.String()},
+	})
 	return result, nil
 }
 
@@ -622,7 +638,8 @@ func (s *Service) StartCrafting(userID uuid.UUID, req *StartCraftingRequest) (*C
 
 // CompleteCrafting completes a crafting session
 func (s *Service) CompleteCrafting(userID uuid.UUID, sessionID uuid.UUID) error {
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var recipe CraftingRecipe
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Get crafting session
 		var session CraftingSession
 		if err := tx.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
@@ -637,9 +654,6 @@ func (s *Service) CompleteCrafting(userID uuid.UUID, sessionID uuid.UUID) error
 			return fmt.Errorf("crafting is not yet complete")
 		}
 
-		// TODO: Add crafted item to user inventory
-		// This would require integration with inventory system
-
 		// Update session
 		session.Status = "completed"
 		session.Progress = 1.0
@@ -648,12 +662,17 @@ func (s *Service) CompleteCrafting(userID uuid.UUID, sessionID uuid.UUID) error
 			return fmt.Errorf("failed to update crafting session: %w", err)
 		}
 
-		// Get recipe for XP
-		var recipe CraftingRecipe
+		// Get recipe for XP and result
 		if err := tx.Where("id = ?", session.RecipeID).First(&recipe).Error; err != nil {
 			return fmt.Errorf("failed to get recipe: %w", err)
 		}
 
+		// Add crafted item to user inventory
+		// TODO: ostatné typy výsledkov (zatiaľ len hack tools)
+		if err := addCraftedItem(tx, userID, &recipe); err != nil {
+			return err
+		}
+
 		// Update XP
 		xpType := fmt.Sprintf("crafting_%s", getCraftingLevel(recipe.Level))
 		if err := s.updateLaboratoryXP(tx, userID, xpType, recipe.XPReward); err != nil {
@@ -662,6 +681,49 @@ func (s *Service) CompleteCrafting(userID uuid.UUID, sessionID uuid.UUID) error
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	analytics.Track(s.db, analytics.Event{
+		Type:       analytics.EventItemCrafted,
+		UserID:     &userID,
+		ItemType:   "recipe",
+		ItemKey:    recipe.Category,
+		Properties: analytics.JSONB{"recipe_id": recipe.ID.String(), "recipe_name": recipe.Name},
+	})
+	return nil
+}
+
+// addCraftedItem - vloží výsledok receptu do inventára (hack tool dostane uses/efekty/expiráciu podľa typu)
+func addCraftedItem(tx *gorm.DB, userID uuid.UUID, recipe *CraftingRecipe) error {
+	itemType, _ := recipe.Result["item_type"].(string)
+	if itemType != "hack_tool" {
+		return nil
+	}
+
+	properties := deployable.HackToolProperties(recipe.Result, "crafting", time.Now())
+	properties["name"] = recipe.Name
+	properties["display_name"] = recipe.Name
+	properties["recipe_id"] = recipe.ID.String()
+	if name, ok := recipe.Result["name"].(string); ok && name != "" {
+		properties["name"] = name
+		properties["display_name"] = name
+	}
+
+	item := gameplay.InventoryItem{
+		UserID:     userID,
+		ItemType:   itemType,
+		ItemID:     recipe.ID,
+		Properties: gameplay.JSONB(properties),
+		Quantity:   1,
+	}
+	if err := tx.Create(&item).Error; err != nil {
+		return fmt.Errorf("failed to add crafted item to inventory: %w", err)
+	}
+
+	log.Printf("🔧 Crafted hack tool %s (%v, %v uses) for user %s", recipe.Name, properties["tool_type"], properties["uses_left"], userID)
+	return nil
 }
 
 // =============================================
@@ -1145,8 +1207,8 @@ func (s *Service) PlaceLaboratory(userID uuid.UUID, req *PlaceLaboratoryRequest)
 		// Check for nearby laboratories (minimum 50m distance)
 		var nearbyCount int64
 		if err := tx.Model(&Laboratory{}).
-			Where("is_placed = true AND ST_DWithin(location, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)",
-				req.Longitude, req.Latitude, 50). // 50 meters minimum distance
+			Where("is_placed = true").
+			Where(s.geo.Within(geoquery.LaboratoryColumns, req.Latitude, req.Longitude, 50)). // 50 meters minimum distance
 			Count(&nearbyCount).Error; err != nil {
 			return fmt.Errorf("failed to check nearby laboratories: %w", err)
 		}
@@ -1210,8 +1272,8 @@ func (s *Service) RelocateLaboratory(userID uuid.UUID, req *RelocateLaboratoryRe
 		// Check for nearby laboratories (minimum 50m distance)
 		var nearbyCount int64
 		if err := tx.Model(&Laboratory{}).
-			Where("is_placed = true AND id != ? AND ST_DWithin(location, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)",
-				lab.ID, req.Longitude, req.Latitude, 50). // 50 meters minimum distance, exclude current lab
+			Where("is_placed = true AND id != ?", lab.ID).
+			Where(s.geo.Within(geoquery.LaboratoryColumns, req.Latitude, req.Longitude, 50)). // 50 meters minimum distance, exclude current lab
 			Count(&nearbyCount).Error; err != nil {
 			return fmt.Errorf("failed to check nearby laboratories: %w", err)
 		}
@@ -1293,7 +1355,10 @@ func (s *Service) GetNearbyLaboratories(userID uuid.UUID, req *GetNearbyLaborato
 		radiusM = 1000 // 1km default
 	}
 
-	// Query nearby laboratories using PostGIS
+	// Query nearby laboratories (PostGIS alebo bounding box fallback)
+	columns := geoquery.LaboratoryColumns.As("l")
+	distance := s.geo.Distance(columns, req.Latitude, req.Longitude)
+	within := s.geo.Within(columns, req.Latitude, req.Longitude, float64(radiusM))
 	query := `
 		SELECT
 			l.id,
@@ -1302,17 +1367,17 @@ func (s *Service) GetNearbyLaboratories(userID uuid.UUID, req *GetNearbyLaborato
 			l.level,
 			l.location_latitude,
 			l.location_longitude,
-			ST_Distance(l.location, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography) / 1000.0 as distance_km,
+			` + distance.SQL + ` / 1000.0 as distance_km,
 			l.placed_at
 		FROM laboratory.laboratories l
 		JOIN auth.users u ON l.user_id = u.id
 		WHERE l.is_placed = true
-		AND ST_DWithin(l.location, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)
+		AND ` + within.SQL + `
 		ORDER BY distance_km ASC
 		LIMIT 50
 	`
 
-	rows, err := s.db.Raw(query, req.Longitude, req.Latitude, req.Longitude, req.Latitude, radiusM).Rows()
+	rows, err := s.db.Raw(query, geoquery.Args(distance, within)...).Rows()
 	if err != nil {
 		return nil, fmt.Errorf("failed to query nearby laboratories: %w", err)
 	}
@@ -0,0 +1,107 @@
+package leaderboard
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Metric identifies what a leaderboard ranks players by
+type Metric string
+
+const (
+	MetricXP        Metric = "xp"
+	MetricArtifacts Metric = "artifacts"
+	MetricLegendary Metric = "legendary"
+	MetricZones     Metric = "zones"
+)
+
+// Scope identifies which slice of players/activity a leaderboard covers
+type Scope string
+
+const (
+	ScopeGlobal  Scope = "global"
+	ScopeBiome   Scope = "biome"
+	ScopeTier    Scope = "tier"
+	ScopeWeekly  Scope = "weekly"
+	ScopeMonthly Scope = "monthly"
+)
+
+// supportedMetrics - which metrics can be ranked in which scope.
+// Biome and seasonal boards are built from collection events, so metrics that
+// only exist as lifetime counters on auth.users are not available there.
+var supportedMetrics = map[Scope][]Metric{
+	ScopeGlobal:  {MetricXP, MetricArtifacts, MetricLegendary, MetricZones},
+	ScopeTier:    {MetricXP, MetricArtifacts, MetricLegendary, MetricZones},
+	ScopeBiome:   {MetricXP, MetricArtifacts, MetricLegendary},
+	ScopeWeekly:  {MetricXP, MetricArtifacts, MetricLegendary, MetricZones},
+	ScopeMonthly: {MetricXP, MetricArtifacts, MetricLegendary, MetricZones},
+}
+
+// Query describes a single leaderboard request
+type Query struct {
+	Metric Metric
+	Scope  Scope
+	Biome  string // required for ScopeBiome
+	Tier   int    // required for ScopeTier
+	Period string // optional for seasonal scopes, e.g. "2025-W42" or "2025-10"
+	Limit  int
+	Offset int
+}
+
+// Entry is one ranked row of a leaderboard
+type Entry struct {
+	Rank     int       `json:"rank"`
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Tier     int       `json:"tier"`
+	Level    int       `json:"level"`
+	Score    int64     `json:"score"`
+	IsMe     bool      `json:"is_me,omitempty"`
+}
+
+// Board is a page of a leaderboard
+type Board struct {
+	Metric       Metric    `json:"metric"`
+	Scope        Scope     `json:"scope"`
+	Biome        string    `json:"biome,omitempty"`
+	Tier         *int      `json:"tier,omitempty"`
+	Period       string    `json:"period,omitempty"`
+	Entries      []Entry   `json:"entries"`
+	TotalPlayers int64     `json:"total_players"`
+	Source       string    `json:"source"` // "redis" | "database"
+	GeneratedAt  time.Time `json:"generated_at"`
+}
+
+// MyRank is the "my rank and neighbours" view
+type MyRank struct {
+	Metric       Metric    `json:"metric"`
+	Scope        Scope     `json:"scope"`
+	Biome        string    `json:"biome,omitempty"`
+	Tier         *int      `json:"tier,omitempty"`
+	Period       string    `json:"period,omitempty"`
+	Rank         int       `json:"rank"` // 0 = not ranked yet
+	Score        int64     `json:"score"`
+	TotalPlayers int64     `json:"total_players"`
+	Neighbours   []Entry   `json:"neighbours"`
+	Source       string    `json:"source"`
+	GeneratedAt  time.Time `json:"generated_at"`
+}
+
+// scoreRow - raw (user, score) pair returned by database queries
+type scoreRow struct {
+	UserID uuid.UUID
+	Score  int64
+}
+
+// ZoneDiscovery - first visit of a zone by a player. The (user_id, zone_id) primary
+// key makes discovery counting idempotent and independent of Redis.
+type ZoneDiscovery struct {
+	UserID       uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	ZoneID       uuid.UUID `json:"zone_id" gorm:"type:uuid;primaryKey"`
+	DiscoveredAt time.Time `json:"discovered_at" gorm:"not null"`
+}
+
+func (ZoneDiscovery) TableName() string {
+	return "gameplay.zone_discoveries"
+}
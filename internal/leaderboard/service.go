@@ -0,0 +1,695 @@
+package leaderboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/movement"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	keyPrefix          = "leaderboard"
+	rebuildLockTTL     = 30 * time.Second
+	seasonRetention    = 30 * 24 * time.Hour // seasonal boards stay readable 30 days after the season ends
+	maxTier            = 4
+	defaultLimit       = 50
+	maxLimit           = 200
+	defaultNeighbours  = 5
+	maxNeighbours      = 25
+	weeklyPeriodFormat = "%d-W%02d"
+	monthlyPeriodFmt   = "2006-01"
+)
+
+var (
+	ErrUnsupportedBoard = errors.New("unsupported leaderboard metric/scope combination")
+	ErrRedisRequired    = errors.New("this leaderboard is only available when Redis is enabled")
+	ErrInvalidPeriod    = errors.New("invalid season period")
+)
+
+// Service spravuje leaderboardy - Redis sorted sets s fallbackom na databázu
+type Service struct {
+	db       *gorm.DB
+	redis    *redis.Client
+	movement *movement.Service
+}
+
+// NewService creates a new leaderboard service; redisClient may be nil
+func NewService(db *gorm.DB, redisClient *redis.Client) *Service {
+	return &Service{
+		db:       db,
+		redis:    redisClient,
+		movement: movement.NewService(db),
+	}
+}
+
+// ============================================
+// QUERY VALIDATION
+// ============================================
+
+// Normalize validates the query and fills in defaults (limit, current season)
+func (s *Service) Normalize(q *Query) error {
+	if q.Metric == "" {
+		q.Metric = MetricXP
+	}
+	if q.Scope == "" {
+		q.Scope = ScopeGlobal
+	}
+
+	metrics, ok := supportedMetrics[q.Scope]
+	if !ok {
+		return ErrUnsupportedBoard
+	}
+	found := false
+	for _, m := range metrics {
+		if m == q.Metric {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrUnsupportedBoard
+	}
+
+	switch q.Scope {
+	case ScopeBiome:
+		q.Biome = strings.ToLower(strings.TrimSpace(q.Biome))
+		if q.Biome == "" {
+			return fmt.Errorf("biome is required for biome leaderboards")
+		}
+	case ScopeTier:
+		if q.Tier < 0 || q.Tier > maxTier {
+			return fmt.Errorf("tier must be between 0 and %d", maxTier)
+		}
+	case ScopeWeekly, ScopeMonthly:
+		if q.Period == "" {
+			q.Period = currentPeriod(q.Scope, time.Now())
+		}
+		if _, _, err := periodBounds(q.Scope, q.Period); err != nil {
+			return err
+		}
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultLimit
+	}
+	if q.Limit > maxLimit {
+		q.Limit = maxLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	return nil
+}
+
+// ============================================
+// READ API
+// ============================================
+
+// GetBoard returns one page of a leaderboard
+func (s *Service) GetBoard(q Query) (*Board, error) {
+	if err := s.Normalize(&q); err != nil {
+		return nil, err
+	}
+
+	board := &Board{
+		Metric:      q.Metric,
+		Scope:       q.Scope,
+		Biome:       q.Biome,
+		Period:      q.Period,
+		Entries:     []Entry{},
+		GeneratedAt: time.Now(),
+	}
+	if q.Scope == ScopeTier {
+		tier := q.Tier
+		board.Tier = &tier
+	}
+
+	var rows []scoreRow
+	var startRank int
+
+	if s.redis != nil {
+		ctx := context.Background()
+		key := boardKey(q)
+		s.ensureBoard(ctx, key, q)
+
+		results, err := s.redis.ZRevRangeWithScores(ctx, key, int64(q.Offset), int64(q.Offset+q.Limit-1)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read leaderboard: %w", err)
+		}
+		board.TotalPlayers, _ = s.redis.ZCard(ctx, key).Result()
+		rows = zToRows(results)
+		startRank = q.Offset + 1
+		board.Source = "redis"
+	} else {
+		if isRedisOnly(q) {
+			return nil, ErrRedisRequired
+		}
+		sub, args := scoreQuery(q)
+
+		if err := s.db.Raw(fmt.Sprintf(
+			"SELECT s.user_id, s.score FROM (%s) s WHERE s.score > 0 ORDER BY s.score DESC, s.user_id LIMIT ? OFFSET ?", sub),
+			append(args, q.Limit, q.Offset)...).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to query leaderboard: %w", err)
+		}
+		s.db.Raw(fmt.Sprintf("SELECT COUNT(*) FROM (%s) s WHERE s.score > 0", sub), args...).Scan(&board.TotalPlayers)
+		startRank = q.Offset + 1
+		board.Source = "database"
+	}
+
+	board.Entries = s.buildEntries(rows, startRank, uuid.Nil)
+	return board, nil
+}
+
+// GetMyRank returns the player's rank with `around` neighbours above and below
+func (s *Service) GetMyRank(q Query, userID uuid.UUID, around int) (*MyRank, error) {
+	if err := s.Normalize(&q); err != nil {
+		return nil, err
+	}
+	if around <= 0 {
+		around = defaultNeighbours
+	}
+	if around > maxNeighbours {
+		around = maxNeighbours
+	}
+
+	result := &MyRank{
+		Metric:      q.Metric,
+		Scope:       q.Scope,
+		Biome:       q.Biome,
+		Period:      q.Period,
+		Neighbours:  []Entry{},
+		GeneratedAt: time.Now(),
+	}
+	if q.Scope == ScopeTier {
+		tier := q.Tier
+		result.Tier = &tier
+	}
+
+	if s.redis != nil {
+		ctx := context.Background()
+		key := boardKey(q)
+		s.ensureBoard(ctx, key, q)
+		result.Source = "redis"
+		result.TotalPlayers, _ = s.redis.ZCard(ctx, key).Result()
+
+		rank, err := s.redis.ZRevRank(ctx, key, userID.String()).Result()
+		if err == redis.Nil {
+			return result, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rank: %w", err)
+		}
+
+		start := rank - int64(around)
+		if start < 0 {
+			start = 0
+		}
+		results, err := s.redis.ZRevRangeWithScores(ctx, key, start, rank+int64(around)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read neighbours: %w", err)
+		}
+
+		result.Rank = int(rank) + 1
+		result.Neighbours = s.buildEntries(zToRows(results), int(start)+1, userID)
+		for _, e := range result.Neighbours {
+			if e.IsMe {
+				result.Score = e.Score
+			}
+		}
+		return result, nil
+	}
+
+	if isRedisOnly(q) {
+		return nil, ErrRedisRequired
+	}
+	result.Source = "database"
+
+	sub, args := scoreQuery(q)
+	s.db.Raw(fmt.Sprintf("SELECT COUNT(*) FROM (%s) s WHERE s.score > 0", sub), args...).Scan(&result.TotalPlayers)
+
+	var ranked []struct {
+		UserID uuid.UUID
+		Score  int64
+		Rank   int
+	}
+	rankedSQL := fmt.Sprintf(`
+		WITH ranked AS (
+			SELECT s.user_id, s.score, ROW_NUMBER() OVER (ORDER BY s.score DESC, s.user_id) AS rank
+			FROM (%s) s WHERE s.score > 0
+		), me AS (
+			SELECT rank FROM ranked WHERE user_id = ?
+		)
+		SELECT ranked.user_id, ranked.score, ranked.rank FROM ranked, me
+		WHERE ranked.rank BETWEEN me.rank - ? AND me.rank + ?
+		ORDER BY ranked.rank`, sub)
+	if err := s.db.Raw(rankedSQL, append(args, userID, around, around)...).Scan(&ranked).Error; err != nil {
+		return nil, fmt.Errorf("failed to query rank: %w", err)
+	}
+	if len(ranked) == 0 {
+		return result, nil
+	}
+
+	rows := make([]scoreRow, 0, len(ranked))
+	for _, r := range ranked {
+		rows = append(rows, scoreRow{UserID: r.UserID, Score: r.Score})
+		if r.UserID == userID {
+			result.Rank = r.Rank
+			result.Score = r.Score
+		}
+	}
+	result.Neighbours = s.buildEntries(rows, ranked[0].Rank, userID)
+
+	return result, nil
+}
+
+// ============================================
+// INCREMENTAL UPDATES (called from gameplay)
+// ============================================
+
+// RecordXP is called after XP has been written to auth.users.
+// biome is optional - empty for XP that doesn't come from a zone (laboratory etc.)
+func (s *Service) RecordXP(userID uuid.UUID, amount int, biome string) {
+	if s.redis == nil || amount <= 0 {
+		return
+	}
+	ctx := context.Background()
+
+	s.SyncUser(userID)
+
+	if biome != "" {
+		s.increment(ctx, Query{Metric: MetricXP, Scope: ScopeBiome, Biome: biome}, userID, amount)
+	}
+	s.incrementSeasons(ctx, MetricXP, userID, amount)
+}
+
+// RecordArtifact is called after a collected artifact was added to inventory
+func (s *Service) RecordArtifact(userID uuid.UUID, rarity, biome string) {
+	if s.redis == nil {
+		return
+	}
+	ctx := context.Background()
+	legendary := rarity == "legendary"
+
+	s.SyncUser(userID)
+
+	if biome != "" {
+		s.increment(ctx, Query{Metric: MetricArtifacts, Scope: ScopeBiome, Biome: biome}, userID, 1)
+		if legendary {
+			s.increment(ctx, Query{Metric: MetricLegendary, Scope: ScopeBiome, Biome: biome}, userID, 1)
+		}
+	}
+	s.incrementSeasons(ctx, MetricArtifacts, userID, 1)
+	if legendary {
+		s.incrementSeasons(ctx, MetricLegendary, userID, 1)
+	}
+}
+
+// RecordZoneVisit marks the zone as discovered by the player. The first visit of
+// every zone increments auth.users.zones_discovered; returns true on discovery.
+// Deduplication is a unique row in gameplay.zone_discoveries, so it works without
+// Redis and never expires.
+func (s *Service) RecordZoneVisit(userID, zoneID uuid.UUID) bool {
+	discovery := ZoneDiscovery{UserID: userID, ZoneID: zoneID, DiscoveredAt: time.Now().UTC()}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&discovery)
+	if result.Error != nil {
+		log.Printf("⚠️ [LEADERBOARD] Failed to record zone visit: %v", result.Error)
+		return false
+	}
+	if result.RowsAffected == 0 || s.discoveredBeforeTable(userID, zoneID) {
+		return false
+	}
+
+	if err := s.db.Model(&auth.User{}).Where("id = ?", userID).
+		Update("zones_discovered", gorm.Expr("zones_discovered + 1")).Error; err != nil {
+		log.Printf("❌ [LEADERBOARD] Failed to increment zones_discovered: %v", err)
+		return false
+	}
+
+	if s.redis == nil {
+		return true
+	}
+	s.SyncUser(userID)
+	s.incrementSeasons(context.Background(), MetricZones, userID, 1)
+	return true
+}
+
+// discoveredBeforeTable - discoveries counted before gameplay.zone_discoveries existed
+// were only tracked in a Redis set; don't count those zones a second time.
+func (s *Service) discoveredBeforeTable(userID, zoneID uuid.UUID) bool {
+	if s.redis == nil {
+		return false
+	}
+	setKey := fmt.Sprintf("%s:discovered:%s", keyPrefix, userID)
+	known, err := s.redis.SIsMember(context.Background(), setKey, zoneID.String()).Result()
+	return err == nil && known
+}
+
+// SyncUser refreshes the player's lifetime scores (global + tier boards) from the
+// database and moves them to the correct tier board. Call after tier changes too.
+func (s *Service) SyncUser(userID uuid.UUID) {
+	if s.redis == nil {
+		return
+	}
+	ctx := context.Background()
+
+	var user auth.User
+	if err := s.db.Select("id, tier, xp, total_artifacts, zones_discovered, is_active, is_banned").
+		First(&user, "id = ?", userID).Error; err != nil {
+		return
+	}
+
+	if !user.IsActive || user.IsBanned || s.movement.IsRestricted(userID) {
+		s.RemoveUser(userID)
+		return
+	}
+
+	var legendary int64
+	s.db.Raw(`SELECT COUNT(*) FROM gameplay.inventory_items
+		WHERE user_id = ? AND item_type = 'artifact' AND properties->>'rarity' = 'legendary'
+		AND properties->>'collected_from' IS NOT NULL`, userID).Scan(&legendary)
+
+	scores := map[Metric]int64{
+		MetricXP:        int64(user.XP),
+		MetricArtifacts: int64(user.TotalArtifacts),
+		MetricLegendary: legendary,
+		MetricZones:     int64(user.ZonesDiscovered),
+	}
+
+	member := userID.String()
+	pipe := s.redis.Pipeline()
+	for metric, score := range scores {
+		global := Query{Metric: metric, Scope: ScopeGlobal}
+		s.ensureBoard(ctx, boardKey(global), global)
+		if score > 0 {
+			pipe.ZAdd(ctx, boardKey(global), redis.Z{Score: float64(score), Member: member})
+		}
+
+		for tier := 0; tier <= maxTier; tier++ {
+			tq := Query{Metric: metric, Scope: ScopeTier, Tier: tier}
+			if tier == user.Tier && score > 0 {
+				s.ensureBoard(ctx, boardKey(tq), tq)
+				pipe.ZAdd(ctx, boardKey(tq), redis.Z{Score: float64(score), Member: member})
+			} else {
+				pipe.ZRem(ctx, boardKey(tq), member)
+			}
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("⚠️ [LEADERBOARD] Failed to sync user %s: %v", userID, err)
+	}
+}
+
+// RemoveUser removes the player from every leaderboard (bans, deactivation)
+func (s *Service) RemoveUser(userID uuid.UUID) {
+	if s.redis == nil {
+		return
+	}
+	ctx := context.Background()
+	member := userID.String()
+
+	iter := s.redis.Scan(ctx, 0, keyPrefix+":*", 500).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		if strings.HasPrefix(key, keyPrefix+":discovered:") || strings.HasPrefix(key, keyPrefix+":rebuild:") {
+			continue
+		}
+		s.redis.ZRem(ctx, key, member)
+	}
+	if err := iter.Err(); err != nil {
+		log.Printf("⚠️ [LEADERBOARD] Failed to remove user %s: %v", userID, err)
+	}
+}
+
+// ============================================
+// REDIS HELPERS
+// ============================================
+
+func (s *Service) incrementSeasons(ctx context.Context, metric Metric, userID uuid.UUID, amount int) {
+	now := time.Now()
+	for _, scope := range []Scope{ScopeWeekly, ScopeMonthly} {
+		q := Query{Metric: metric, Scope: scope, Period: currentPeriod(scope, now)}
+		s.increment(ctx, q, userID, amount)
+
+		if _, end, err := periodBounds(scope, q.Period); err == nil {
+			s.redis.ExpireAt(ctx, boardKey(q), end.Add(seasonRetention))
+		}
+	}
+}
+
+func (s *Service) increment(ctx context.Context, q Query, userID uuid.UUID, amount int) {
+	key := boardKey(q)
+	if !s.ensureBoard(ctx, key, q) {
+		// Board was just rebuilt from the database which already contains this change
+		return
+	}
+	if err := s.redis.ZIncrBy(ctx, key, float64(amount), userID.String()).Err(); err != nil {
+		log.Printf("⚠️ [LEADERBOARD] Failed to increment %s: %v", key, err)
+	}
+}
+
+// ensureBoard rebuilds a missing Redis board from the database. Returns false when
+// the board was (or is being) rebuilt, so callers must not apply their increment.
+func (s *Service) ensureBoard(ctx context.Context, key string, q Query) bool {
+	exists, err := s.redis.Exists(ctx, key).Result()
+	if err != nil || exists > 0 {
+		return true
+	}
+	if isRedisOnly(q) {
+		return true
+	}
+
+	lockKey := fmt.Sprintf("%s:rebuild:%s", keyPrefix, key)
+	locked, err := s.redis.SetNX(ctx, lockKey, "1", rebuildLockTTL).Result()
+	if err != nil || !locked {
+		return false
+	}
+	defer s.redis.Del(ctx, lockKey)
+
+	sub, args := scoreQuery(q)
+	var rows []scoreRow
+	if err := s.db.Raw(fmt.Sprintf("SELECT s.user_id, s.score FROM (%s) s WHERE s.score > 0", sub), args...).
+		Scan(&rows).Error; err != nil {
+		log.Printf("❌ [LEADERBOARD] Failed to rebuild %s: %v", key, err)
+		return false
+	}
+	if len(rows) == 0 {
+		return false
+	}
+
+	tmpKey := key + ":tmp"
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, tmpKey)
+	for i := 0; i < len(rows); i += 1000 {
+		end := i + 1000
+		if end > len(rows) {
+			end = len(rows)
+		}
+		members := make([]redis.Z, 0, end-i)
+		for _, r := range rows[i:end] {
+			members = append(members, redis.Z{Score: float64(r.Score), Member: r.UserID.String()})
+		}
+		pipe.ZAdd(ctx, tmpKey, members...)
+	}
+	pipe.Rename(ctx, tmpKey, key)
+	if _, end, err := periodBounds(q.Scope, q.Period); err == nil && (q.Scope == ScopeWeekly || q.Scope == ScopeMonthly) {
+		pipe.ExpireAt(ctx, key, end.Add(seasonRetention))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("❌ [LEADERBOARD] Failed to store rebuilt %s: %v", key, err)
+		return false
+	}
+
+	log.Printf("🏆 [LEADERBOARD] Rebuilt %s from database (%d players)", key, len(rows))
+	return false
+}
+
+func (s *Service) buildEntries(rows []scoreRow, startRank int, me uuid.UUID) []Entry {
+	entries := make([]Entry, 0, len(rows))
+	if len(rows) == 0 {
+		return entries
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.UserID)
+	}
+
+	var users []auth.User
+	s.db.Select("id, username, tier, level").Where("id IN ?", ids).Find(&users)
+	byID := make(map[uuid.UUID]auth.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	for i, r := range rows {
+		u := byID[r.UserID]
+		entries = append(entries, Entry{
+			Rank:     startRank + i,
+			UserID:   r.UserID,
+			Username: u.Username,
+			Tier:     u.Tier,
+			Level:    u.Level,
+			Score:    r.Score,
+			IsMe:     me != uuid.Nil && r.UserID == me,
+		})
+	}
+	return entries
+}
+
+func zToRows(results []redis.Z) []scoreRow {
+	rows := make([]scoreRow, 0, len(results))
+	for _, z := range results {
+		member, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+		id, err := uuid.Parse(member)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, scoreRow{UserID: id, Score: int64(z.Score)})
+	}
+	return rows
+}
+
+// ============================================
+// KEYS, PERIODS & DATABASE QUERIES
+// ============================================
+
+func boardKey(q Query) string {
+	switch q.Scope {
+	case ScopeBiome:
+		return fmt.Sprintf("%s:%s:biome:%s", keyPrefix, q.Metric, q.Biome)
+	case ScopeTier:
+		return fmt.Sprintf("%s:%s:tier:%d", keyPrefix, q.Metric, q.Tier)
+	case ScopeWeekly, ScopeMonthly:
+		return fmt.Sprintf("%s:%s:%s:%s", keyPrefix, q.Metric, q.Scope, q.Period)
+	default:
+		return fmt.Sprintf("%s:%s:global", keyPrefix, q.Metric)
+	}
+}
+
+// isRedisOnly - boards that can't be reconstructed from the database because the
+// underlying events (XP gains, zone discoveries) aren't stored with a timestamp/biome
+func isRedisOnly(q Query) bool {
+	switch q.Scope {
+	case ScopeBiome:
+		return q.Metric == MetricXP
+	case ScopeWeekly, ScopeMonthly:
+		return q.Metric == MetricXP || q.Metric == MetricZones
+	}
+	return false
+}
+
+func currentPeriod(scope Scope, now time.Time) string {
+	now = now.UTC()
+	if scope == ScopeMonthly {
+		return now.Format(monthlyPeriodFmt)
+	}
+	year, week := now.ISOWeek()
+	return fmt.Sprintf(weeklyPeriodFormat, year, week)
+}
+
+// periodBounds returns [start, end) of a season period in UTC
+func periodBounds(scope Scope, period string) (time.Time, time.Time, error) {
+	switch scope {
+	case ScopeMonthly:
+		start, err := time.Parse(monthlyPeriodFmt, period)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidPeriod
+		}
+		return start, start.AddDate(0, 1, 0), nil
+
+	case ScopeWeekly:
+		parts := strings.Split(period, "-W")
+		if len(parts) != 2 {
+			return time.Time{}, time.Time{}, ErrInvalidPeriod
+		}
+		year, err1 := strconv.Atoi(parts[0])
+		week, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil || week < 1 || week > 53 {
+			return time.Time{}, time.Time{}, ErrInvalidPeriod
+		}
+		// ISO week 1 is the week containing January 4th
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+		offset := (int(jan4.Weekday()) + 6) % 7 // days since Monday
+		start := jan4.AddDate(0, 0, -offset+(week-1)*7)
+		if y, w := start.ISOWeek(); y != year || w != week {
+			return time.Time{}, time.Time{}, ErrInvalidPeriod
+		}
+		return start, start.AddDate(0, 0, 7), nil
+	}
+
+	return time.Time{}, time.Time{}, ErrInvalidPeriod
+}
+
+// scoreQuery returns a SQL subquery producing (user_id, score) rows for a board
+func scoreQuery(q Query) (string, []interface{}) {
+	// Hráči v shadow režime / soft bane (podozrivý pohyb) sa do leaderboardov nepočítajú
+	const activeUsers = "u.is_active = true AND u.is_banned = false AND u.id NOT IN (" + movement.RestrictedUsersSQL + ")"
+
+	switch q.Scope {
+	case ScopeGlobal, ScopeTier:
+		tierFilter := ""
+		args := []interface{}{}
+		if q.Scope == ScopeTier {
+			tierFilter = " AND u.tier = ?"
+			args = append(args, q.Tier)
+		}
+
+		if q.Metric == MetricLegendary {
+			return `SELECT ii.user_id, COUNT(*) AS score FROM gameplay.inventory_items ii
+				JOIN auth.users u ON u.id = ii.user_id
+				WHERE ii.item_type = 'artifact' AND ii.properties->>'rarity' = 'legendary'
+				AND ii.properties->>'collected_from' IS NOT NULL AND ` + activeUsers + tierFilter + `
+				GROUP BY ii.user_id`, args
+		}
+
+		column := "u.xp"
+		switch q.Metric {
+		case MetricArtifacts:
+			column = "u.total_artifacts"
+		case MetricZones:
+			column = "u.zones_discovered"
+		}
+		return fmt.Sprintf("SELECT u.id AS user_id, %s AS score FROM auth.users u WHERE %s%s",
+			column, activeUsers, tierFilter), args
+
+	default:
+		// Biome / seasonal boards are counted from collected inventory items
+		filters := []string{
+			"ii.item_type = 'artifact'",
+			"ii.properties->>'collected_from' IS NOT NULL",
+			activeUsers,
+		}
+		args := []interface{}{}
+
+		if q.Metric == MetricLegendary {
+			filters = append(filters, "ii.properties->>'rarity' = 'legendary'")
+		}
+		if q.Scope == ScopeBiome {
+			filters = append(filters, "ii.properties->>'biome' = ?")
+			args = append(args, q.Biome)
+		} else if start, end, err := periodBounds(q.Scope, q.Period); err == nil {
+			filters = append(filters, "ii.acquired_at >= ? AND ii.acquired_at < ?")
+			args = append(args, start, end)
+		}
+
+		return `SELECT ii.user_id, COUNT(*) AS score FROM gameplay.inventory_items ii
+			JOIN auth.users u ON u.id = ii.user_id
+			WHERE ` + strings.Join(filters, " AND ") + `
+			GROUP BY ii.user_id`, args
+	}
+}
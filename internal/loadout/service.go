@@ -226,7 +226,8 @@ func (s *Service) GetGearCategories() ([]gameplay.GearCategory, error) {
 }
 
 // ApplyDurabilityDamage aplikuje poškodenie na gear pri návšteve zóny
-func (s *Service) ApplyDurabilityDamage(userID uuid.UUID, zoneDangerLevel int, zoneBiome string) error {
+// (environmentMultiplier - násobok z modifikátorov prostredia, 1 = bez vplyvu)
+func (s *Service) ApplyDurabilityDamage(userID uuid.UUID, zoneDangerLevel int, zoneBiome string, environmentMultiplier float64) error {
 	var loadoutItems []gameplay.LoadoutItem
 	if err := s.db.Where("user_id = ?", userID).Find(&loadoutItems).Error; err != nil {
 		return err
@@ -234,7 +235,7 @@ func (s *Service) ApplyDurabilityDamage(userID uuid.UUID, zoneDangerLevel int, z
 
 	for _, item := range loadoutItems {
 		// Vypočítaj poškodenie na základe danger level a biome
-		damage := calculateDurabilityDamage(zoneDangerLevel, zoneBiome, item)
+		damage := calculateDurabilityDamage(zoneDangerLevel, zoneBiome, item, environmentMultiplier)
 
 		if damage > 0 {
 			item.Durability = max(0, item.Durability-damage)
@@ -246,7 +247,7 @@ func (s *Service) ApplyDurabilityDamage(userID uuid.UUID, zoneDangerLevel int, z
 }
 
 // calculateDurabilityDamage vypočítá poškodenie durability
-func calculateDurabilityDamage(dangerLevel int, biome string, item gameplay.LoadoutItem) int {
+func calculateDurabilityDamage(dangerLevel int, biome string, item gameplay.LoadoutItem, environmentMultiplier float64) int {
 	baseDamage := dangerLevel * 2 // Základné poškodenie podľa danger level
 
 	// Biome modifiers
@@ -276,6 +277,11 @@ func calculateDurabilityDamage(dangerLevel int, biome string, item gameplay.Load
 		resistanceBonus = item.MonsterResistance / 10
 	}
 
+	// Noc, počasie a efekty biomu (environment.Modifiers)
+	if environmentMultiplier > 0 {
+		biomeModifier *= environmentMultiplier
+	}
+
 	finalDamage := int(float64(baseDamage)*biomeModifier) - resistanceBonus
 	return max(0, finalDamage)
 }
@@ -10,7 +10,14 @@ import (
 
 	"geoanomaly/internal/auth"
 	"geoanomaly/internal/common"
+	"geoanomaly/internal/friends"
 	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/heatmap"
+	"geoanomaly/internal/locationhistory"
+	"geoanomaly/internal/movement"
+	"geoanomaly/internal/realtime"
+	"geoanomaly/internal/zonestats"
+	"geoanomaly/pkg/geoquery"
 	"geoanomaly/pkg/redis"
 
 	"github.com/gin-gonic/gin"
@@ -20,16 +27,22 @@ import (
 )
 
 type Handler struct {
-	db    *gorm.DB
-	redis *redis_client.Client
+	db        *gorm.DB
+	redis     *redis_client.Client
+	history   *locationhistory.Service
+	heatmap   *heatmap.Service
+	friends   *friends.Service
+	geo       *geoquery.Querier
+	movement  *movement.Service
+	zoneStats *zonestats.Service
 }
 
 type UpdateLocationRequest struct {
-	Latitude  float64 `json:"latitude" binding:"required"`
-	Longitude float64 `json:"longitude" binding:"required"`
-	Accuracy  float64 `json:"accuracy,omitempty"`
-	Speed     float64 `json:"speed,omitempty"`
-	Heading   float64 `json:"heading,omitempty"`
+	Latitude  float64  `json:"latitude" binding:"required"`
+	Longitude float64  `json:"longitude" binding:"required"`
+	Accuracy  *float64 `json:"accuracy,omitempty"` // nil = klient accuracy neposlal
+	Speed     float64  `json:"speed,omitempty"`
+	Heading   float64  `json:"heading,omitempty"`
 }
 
 type PlayerInZone struct {
@@ -47,6 +60,11 @@ type PlayerInZone struct {
 	} `json:"zone_position"`
 }
 
+type ShareLocationRequest struct {
+	FriendID        string `json:"friend_id" binding:"required"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"` // default 60, max 24h
+}
+
 type NearbyPlayersResponse struct {
 	Players      []PlayerInZone              `json:"players"`
 	TotalPlayers int                         `json:"total_players"`
@@ -57,11 +75,32 @@ type NearbyPlayersResponse struct {
 
 func NewHandler(db *gorm.DB, redisClient *redis_client.Client) *Handler {
 	return &Handler{
-		db:    db,
-		redis: redisClient,
+		db:        db,
+		redis:     redisClient,
+		history:   locationhistory.NewService(db),
+		heatmap:   heatmap.NewService(db),
+		friends:   friends.NewService(db),
+		geo:       geoquery.New(db),
+		movement:  movement.NewService(db),
+		zoneStats: zonestats.NewService(db),
 	}
 }
 
+// History - zdieľaná location história (používa aj user handler)
+func (h *Handler) History() *locationhistory.Service {
+	return h.history
+}
+
+// Movement - kontrola hodnovernosti pohybu (používa aj user handler)
+func (h *Handler) Movement() *movement.Service {
+	return h.movement
+}
+
+// Friends - zdieľaný friends service (používa aj friends handler)
+func (h *Handler) Friends() *friends.Service {
+	return h.friends
+}
+
 // ✅ OPRAVENÉ: UpdateLocation - používa LocationWithAccuracy
 func (h *Handler) UpdateLocation(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -84,11 +123,27 @@ func (h *Handler) UpdateLocation(c *gin.Context) {
 		return
 	}
 
+	// Kontrola hodnovernosti pohybu - teleport sa neuloží
+	verdict := h.movement.Check(userID.(uuid.UUID), movement.SourceLocationUpdate, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Accuracy:  req.Accuracy,
+	})
+	if verdict.Rejected {
+		movement.Abort(c, verdict)
+		return
+	}
+
+	accuracy := 0.0
+	if req.Accuracy != nil {
+		accuracy = *req.Accuracy
+	}
+
 	// ✅ OPRAVENÉ: LocationWithAccuracy pre user tracking
 	location := common.LocationWithAccuracy{
 		Latitude:  req.Latitude,
 		Longitude: req.Longitude,
-		Accuracy:  req.Accuracy,
+		Accuracy:  accuracy,
 		Timestamp: time.Now(),
 	}
 
@@ -100,13 +155,17 @@ func (h *Handler) UpdateLocation(c *gin.Context) {
 
 	// Nájdi aktuálnu zónu
 	currentZone := h.findCurrentZone(req.Latitude, req.Longitude)
+	previousZone := h.sessionZone(userID.(uuid.UUID))
 
 	// Aktualizuj player session
 	h.updatePlayerSession(userID.(uuid.UUID), username.(string), currentZone, location, req.Speed, req.Heading)
 
-	// Real-time notifikácie pre ostatných hráčov v zóne
-	if currentZone != nil {
-		h.notifyPlayersInZone(*currentZone, userID.(uuid.UUID), username.(string), location)
+	// Pridaj bod do location histórie
+	h.history.Record(userID.(uuid.UUID), req.Latitude, req.Longitude, accuracy, req.Speed, req.Heading, currentZone, locationhistory.SourceLocationUpdate)
+
+	// Real-time notifikácie pre ostatných hráčov v zóne (hráča v shadow režime ostatní nevidia)
+	if !verdict.Shadowed() {
+		h.notifyPlayersInZone(previousZone, currentZone, userID.(uuid.UUID), username.(string), location)
 	}
 
 	response := gin.H{
@@ -153,7 +212,16 @@ func (h *Handler) GetNearbyPlayers(c *gin.Context) {
 
 	// Nájdi všetkých hráčov v tejto zóne
 	var playerSessions []auth.PlayerSession
-	if err := h.db.Preload("User").Where("current_zone = ? AND user_id != ? AND is_online = true", currentZone, userID).Find(&playerSessions).Error; err != nil {
+	if err := h.db.Where("current_zone = ? AND user_id != ? AND is_online = true", currentZone, userID).
+		Where("user_id NOT IN (" + movement.RestrictedUsersSQL + ")").
+		Find(&playerSessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch players"})
+		return
+	}
+
+	// Zablokovaní hráči (oboma smermi) sa navzájom nevidia
+	blocked, err := h.friends.BlockedUserIDs(userID.(uuid.UUID))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch players"})
 		return
 	}
@@ -165,6 +233,10 @@ func (h *Handler) GetNearbyPlayers(c *gin.Context) {
 	// Vytvor response
 	var players []PlayerInZone
 	for _, session := range playerSessions {
+		if blocked[session.UserID] {
+			continue
+		}
+
 		// Načítaj User samostatne
 		var user auth.User
 		if err := h.db.First(&user, "id = ?", session.UserID).Error; err != nil {
@@ -262,22 +334,19 @@ func (h *Handler) GetZoneActivity(c *gin.Context) {
 
 // ✅ OPRAVENÉ: Pomocné funkcie
 func (h *Handler) findCurrentZone(lat, lng float64) *uuid.UUID {
-	var zones []gameplay.Zone
+	var zone gameplay.Zone
 
-	// ✅ OPRAVENÉ: Simplified query bez PostGIS dependency (rovnako ako v game handler)
-	if err := h.db.Where("is_active = true").Find(&zones).Error; err != nil {
+	// Najbližšia aktívna zóna, ktorej polomer bod pokrýva (spatial index cez geoquery)
+	err := h.db.Select("id").
+		Where("is_active = true").
+		Where(h.geo.Containing(geoquery.ZoneColumns, lat, lng)).
+		Order(h.geo.OrderByDistance(geoquery.ZoneColumns, lat, lng)).
+		Take(&zone).Error
+	if err != nil {
 		return nil
 	}
 
-	// Manual distance filtering
-	for _, zone := range zones {
-		distance := calculateDistance(lat, lng, zone.Location.Latitude, zone.Location.Longitude)
-		if distance <= float64(zone.RadiusMeters) {
-			return &zone.ID
-		}
-	}
-
-	return nil
+	return &zone.ID
 }
 
 // ✅ OPRAVENÉ: updateUserLocation - log only (User table nemá location columns)
@@ -304,6 +373,12 @@ func (h *Handler) updatePlayerSession(userID uuid.UUID, username string, current
 	// Upsert player session
 	h.db.Where("user_id = ?", userID).Assign(session).FirstOrCreate(&session)
 
+	// Assign so structom ignoruje nil - odchod zo zóny treba zapísať explicitne
+	if currentZone == nil && session.CurrentZone != nil {
+		h.db.Model(&auth.PlayerSession{}).Where("user_id = ?", userID).Update("current_zone", nil)
+		h.zoneStats.EndVisit(userID, zonestats.EndLeftArea)
+	}
+
 	// Aktualizuj aj v Redis pre real-time tracking
 	h.updateRedisPlayerSession(userID, username, currentZone, location, speed, heading)
 }
@@ -341,27 +416,49 @@ func (h *Handler) updateRedisPlayerSession(userID uuid.UUID, username string, cu
 	}
 }
 
-// ✅ OPRAVENÉ: notifyPlayersInZone s LocationWithAccuracy
-func (h *Handler) notifyPlayersInZone(zoneID uuid.UUID, userID uuid.UUID, username string, location common.LocationWithAccuracy) {
-	if h.redis == nil {
-		return // Skip ak Redis nie je dostupný
+// sessionZone - zóna z poslednej uloženej polohy (pred aktualizáciou)
+func (h *Handler) sessionZone(userID uuid.UUID) *uuid.UUID {
+	var session auth.PlayerSession
+	if err := h.db.Select("current_zone").Where("user_id = ?", userID).First(&session).Error; err != nil {
+		return nil
 	}
+	return session.CurrentZone
+}
 
-	// Real-time WebSocket notifikácie (pre budúce použitie)
-	notificationKey := fmt.Sprintf("zone_notification:%s", zoneID.String())
+// notifyPlayersInZone - push (WebSocket/SSE) pri vstupe do zóny / odchode zo zóny
+func (h *Handler) notifyPlayersInZone(previousZone, currentZone *uuid.UUID, userID uuid.UUID, username string, location common.LocationWithAccuracy) {
+	if previousZone != nil && currentZone != nil && *previousZone == *currentZone {
+		return
+	}
+	if previousZone == nil && currentZone == nil {
+		return
+	}
 
-	notification := map[string]interface{}{
-		"type":      "player_moved",
-		"user_id":   userID.String(),
-		"username":  username,
-		"zone_id":   zoneID.String(),
-		"latitude":  location.Latitude,
-		"longitude": location.Longitude,
-		"timestamp": time.Now().Unix(),
+	// Zablokovaní hráči o sebe nedostávajú eventy
+	var exclude []uuid.UUID
+	if blocked, err := h.friends.BlockedUserIDs(userID); err == nil {
+		for id := range blocked {
+			exclude = append(exclude, id)
+		}
+	}
+
+	if previousZone != nil {
+		realtime.NotifyZone(*previousZone, &userID, exclude, realtime.EventPlayerLeftZone, map[string]interface{}{
+			"user_id":  userID,
+			"username": username,
+			"zone_id":  *previousZone,
+		})
 	}
 
-	// Uloži do Redis pre real-time systém
-	redis.SetWithExpiration(h.redis, notificationKey, notification, 1*time.Minute)
+	if currentZone != nil {
+		realtime.NotifyZone(*currentZone, &userID, exclude, realtime.EventPlayerEnteredZone, map[string]interface{}{
+			"user_id":   userID,
+			"username":  username,
+			"zone_id":   *currentZone,
+			"latitude":  location.Latitude,
+			"longitude": location.Longitude,
+		})
+	}
 }
 
 func isValidGPSCoordinate(lat, lng float64) bool {
@@ -390,30 +487,260 @@ func (h *Handler) GetPlayersInZone(c *gin.Context) {
 	})
 }
 
+// GetLocationHistory - stránkovaná trasa hráča (from/to v RFC3339, default posledných 24h)
 func (h *Handler) GetLocationHistory(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Location history not implemented yet",
-		"status": "planned",
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	now := time.Now()
+	to := now
+	from := now.Add(-24 * time.Hour)
+
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' timestamp, expected RFC3339"})
+			return
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' timestamp, expected RFC3339"})
+			return
+		}
+		to = parsed
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'from' must be before 'to'"})
+		return
+	}
+
+	// Parametre pre pagináciu
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	samples, total, err := h.history.Query(userID.(uuid.UUID), from, to, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch location history",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	totalPages := int64(0)
+	if total > 0 {
+		totalPages = (total + int64(limit) - 1) / int64(limit)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"history": samples,
+		"pagination": gin.H{
+			"current_page":   page,
+			"total_pages":    totalPages,
+			"total_items":    total,
+			"items_per_page": limit,
+		},
+		"range": gin.H{
+			"from": from.Format(time.RFC3339),
+			"to":   to.Format(time.RFC3339),
+		},
+		"retention_days": int(h.history.Retention().Hours() / 24),
+		"timestamp":      now.Unix(),
 	})
 }
 
+// DeleteLocationHistory - hráč si zmaže celú svoju trasu (privacy request)
+func (h *Handler) DeleteLocationHistory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	deleted, err := h.history.DeleteForUser(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete location history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Location history deleted",
+		"samples_deleted": deleted,
+		"timestamp":       time.Now().Unix(),
+	})
+}
+
+// GetLocationHeatmap - anonymizovaný heatmap hráčov (bunky s menej ako k hráčmi sú skryté)
 func (h *Handler) GetLocationHeatmap(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Location heatmap not implemented yet",
-		"status": "planned",
+	filter, err := heatmap.ParseFilter(c, heatmap.MaxPlayerPrecision)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.heatmap.Players(filter, h.heatmap.MinUsersPerCell())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to build heatmap",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"heatmap":   result,
+		"timestamp": time.Now().Unix(),
 	})
 }
 
+// ShareLocation - časovo obmedzené zdieľanie polohy s priateľom ("share for 1h with Alice")
 func (h *Handler) ShareLocation(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Share location not implemented yet",
-		"status": "planned",
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req ShareLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	friendID, err := uuid.Parse(req.FriendID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid friend ID"})
+		return
+	}
+
+	if req.DurationMinutes == 0 {
+		req.DurationMinutes = 60
+	}
+
+	share, err := h.friends.ShareLocation(userID.(uuid.UUID), friendID, time.Duration(req.DurationMinutes)*time.Minute)
+	if err != nil {
+		friends.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Location shared",
+		"share":      share,
+		"expires_in": int(time.Until(share.ExpiresAt).Seconds()),
 	})
 }
 
+// StopSharingLocation - predčasne ukončí zdieľanie s priateľom
+func (h *Handler) StopSharingLocation(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	friendID, err := uuid.Parse(c.Param("friend_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid friend ID"})
+		return
+	}
+
+	if err := h.friends.StopSharing(userID.(uuid.UUID), friendID); err != nil {
+		friends.RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Location sharing stopped"})
+}
+
+// GetLocationShares - aktívne zdieľania (moje aj tie, ktoré dostávam)
+func (h *Handler) GetLocationShares(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	outgoing, incoming, err := h.friends.ActiveShares(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch location shares"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sharing_with":   outgoing,
+		"shared_with_me": incoming,
+		"timestamp":      time.Now().Unix(),
+	})
+}
+
+// GetNearbyFriends - priatelia, ktorí so mnou práve zdieľajú polohu (vzdialenosť + zóna)
 func (h *Handler) GetNearbyFriends(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Get nearby friends not implemented yet",
-		"status": "planned",
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var lat, lng float64
+	if c.Query("lat") != "" || c.Query("lng") != "" {
+		var err error
+		lat, err = strconv.ParseFloat(c.Query("lat"), 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid latitude"})
+			return
+		}
+		lng, err = strconv.ParseFloat(c.Query("lng"), 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid longitude"})
+			return
+		}
+		if !isValidGPSCoordinate(lat, lng) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid GPS coordinates"})
+			return
+		}
+	} else {
+		// Bez súradníc použi poslednú známu polohu hráča
+		var session auth.PlayerSession
+		if err := h.db.Where("user_id = ?", userID).First(&session).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Location unknown, provide lat and lng"})
+			return
+		}
+		lat, lng = session.LastLocationLatitude, session.LastLocationLongitude
+	}
+
+	radius := friends.DefaultNearbyRadius
+	if value := c.Query("radius"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius"})
+			return
+		}
+		radius = math.Min(parsed, friends.MaxNearbyRadius)
+	}
+
+	nearby, err := h.friends.NearbyFriends(userID.(uuid.UUID), lat, lng, radius)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch nearby friends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"friends":       nearby,
+		"total_friends": len(nearby),
+		"radius_meters": radius,
+		"your_position": common.LocationWithAccuracy{Latitude: lat, Longitude: lng, Timestamp: time.Now()},
+		"timestamp":     time.Now().Unix(),
 	})
 }
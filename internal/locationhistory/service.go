@@ -0,0 +1,237 @@
+package locationhistory
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Zdroje záznamov v location histórii
+const (
+	SourceLocationUpdate = "location_update"
+	SourceUserUpdate     = "user_update"
+	SourceScanner        = "scanner"
+)
+
+const (
+	// DefaultRetentionDays - ako dlho držíme trasu, ak nie je nastavené LOCATION_HISTORY_RETENTION_DAYS
+	DefaultRetentionDays = 30
+	// Koľko mesačných partícií vytvárame dopredu
+	historyPartitionsAhead = 2
+
+	historyTable           = "gameplay.location_history"
+	historyPartitionPrefix = "location_history_"
+)
+
+// Sample - jeden bod trasy hráča (append-only, mesačne partíciovaná tabuľka)
+type Sample struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	Latitude   float64    `json:"latitude" gorm:"type:decimal(10,8);not null"`
+	Longitude  float64    `json:"longitude" gorm:"type:decimal(11,8);not null"`
+	Accuracy   float64    `json:"accuracy,omitempty"`
+	Speed      float64    `json:"speed,omitempty"`
+	Heading    float64    `json:"heading,omitempty"`
+	ZoneID     *uuid.UUID `json:"zone_id,omitempty" gorm:"type:uuid"`
+	Source     string     `json:"source" gorm:"size:20;not null"`
+	RecordedAt time.Time  `json:"recorded_at" gorm:"primaryKey;not null"`
+}
+
+func (Sample) TableName() string {
+	return historyTable
+}
+
+// Service - zápis, čítanie a retencia location histórie
+type Service struct {
+	db        *gorm.DB
+	retention time.Duration
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db:        db,
+		retention: retentionFromEnv(),
+	}
+}
+
+func retentionFromEnv() time.Duration {
+	days := DefaultRetentionDays
+	if value := os.Getenv("LOCATION_HISTORY_RETENTION_DAYS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			days = parsed
+		} else {
+			log.Printf("⚠️ Invalid LOCATION_HISTORY_RETENTION_DAYS=%q, using %d days", value, days)
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Retention - aktuálne nastavené retenčné okno
+func (s *Service) Retention() time.Duration {
+	return s.retention
+}
+
+// Record - pridá bod do trasy; chyba zápisu nesmie zhodiť update polohy
+func (s *Service) Record(userID uuid.UUID, lat, lng, accuracy, speed, heading float64, zoneID *uuid.UUID, source string) {
+	sample := Sample{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Latitude:   lat,
+		Longitude:  lng,
+		Accuracy:   accuracy,
+		Speed:      speed,
+		Heading:    heading,
+		ZoneID:     zoneID,
+		Source:     source,
+		RecordedAt: time.Now(),
+	}
+
+	if err := s.db.Create(&sample).Error; err != nil {
+		log.Printf("⚠️ Failed to record location history for user %s: %v", userID, err)
+	}
+}
+
+// Query - stránkovaná trasa hráča v časovom rozsahu (najnovšie prvé)
+func (s *Service) Query(userID uuid.UUID, from, to time.Time, page, limit int) ([]Sample, int64, error) {
+	query := s.db.Model(&Sample{}).
+		Where("user_id = ? AND recorded_at >= ? AND recorded_at < ?", userID, from, to)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	samples := []Sample{}
+	if err := query.Order("recorded_at DESC").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Find(&samples).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return samples, total, nil
+}
+
+// DeleteForUser - vymaže celú trasu hráča (privacy request)
+func (s *Service) DeleteForUser(userID uuid.UUID) (int64, error) {
+	result := s.db.Where("user_id = ?", userID).Delete(&Sample{})
+	return result.RowsAffected, result.Error
+}
+
+// EnsureSchema - vytvorí partíciovanú tabuľku a mesačné partície (idempotentné)
+func (s *Service) EnsureSchema(now time.Time) error {
+	if err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS gameplay.location_history (
+			id UUID NOT NULL DEFAULT gen_random_uuid(),
+			user_id UUID NOT NULL,
+			latitude DECIMAL(10,8) NOT NULL,
+			longitude DECIMAL(11,8) NOT NULL,
+			accuracy DOUBLE PRECISION,
+			speed DOUBLE PRECISION,
+			heading DOUBLE PRECISION,
+			zone_id UUID,
+			source VARCHAR(20) NOT NULL,
+			recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (id, recorded_at)
+		) PARTITION BY RANGE (recorded_at)
+	`).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_location_history_user_time
+		ON gameplay.location_history (user_id, recorded_at DESC)
+	`).Error; err != nil {
+		return err
+	}
+
+	month := monthStart(now)
+	for i := 0; i <= historyPartitionsAhead; i++ {
+		if err := s.ensurePartition(month.AddDate(0, i, 0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) ensurePartition(month time.Time) error {
+	name := partitionName(month)
+	return s.db.Exec(fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS gameplay.%s PARTITION OF gameplay.location_history FOR VALUES FROM ('%s') TO ('%s')`,
+		name, month.Format("2006-01-02"), month.AddDate(0, 1, 0).Format("2006-01-02"),
+	)).Error
+}
+
+// Prune - zahodí celé partície staršie ako retencia a dočistí hraničnú partíciu
+func (s *Service) Prune(now time.Time) (int64, error) {
+	cutoff := now.Add(-s.retention)
+
+	var partitions []string
+	if err := s.db.Raw(`
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		JOIN pg_namespace n ON n.oid = p.relnamespace
+		WHERE n.nspname = 'gameplay' AND p.relname = 'location_history'
+	`).Scan(&partitions).Error; err != nil {
+		return 0, err
+	}
+
+	for _, name := range partitions {
+		month, ok := parsePartitionMonth(name)
+		if !ok || month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+		if err := s.db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS gameplay.%s", name)).Error; err != nil {
+			return 0, err
+		}
+		log.Printf("🗑️ Dropped location history partition %s", name)
+	}
+
+	result := s.db.Where("recorded_at < ?", cutoff).Delete(&Sample{})
+	return result.RowsAffected, result.Error
+}
+
+// Maintain - volá scheduler: pripraví partície dopredu a aplikuje retenciu
+func (s *Service) Maintain(now time.Time) {
+	if err := s.EnsureSchema(now); err != nil {
+		log.Printf("❌ Failed to ensure location history partitions: %v", err)
+		return
+	}
+
+	pruned, err := s.Prune(now)
+	if err != nil {
+		log.Printf("❌ Failed to prune location history: %v", err)
+		return
+	}
+	if pruned > 0 {
+		log.Printf("🧹 Pruned %d location history samples older than %s", pruned, s.retention)
+	}
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func partitionName(month time.Time) string {
+	return historyPartitionPrefix + month.Format("2006_01")
+}
+
+func parsePartitionMonth(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, historyPartitionPrefix) {
+		return time.Time{}, false
+	}
+	month, err := time.Parse("2006_01", strings.TrimPrefix(name, historyPartitionPrefix))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return month, true
+}
@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
+	"geoanomaly/internal/analytics"
 	"geoanomaly/internal/common"
+	"geoanomaly/internal/deployable"
 	"geoanomaly/internal/gameplay"
 
 	"github.com/google/uuid"
@@ -33,6 +36,28 @@ type Service struct {
 	db *gorm.DB
 }
 
+var (
+	tierResetMu    sync.RWMutex
+	tierResetHooks []func(userID uuid.UUID, tier int)
+)
+
+// OnTierReset - hook po resete expirovaného tieru (napr. zmrazenie zariadení nad limit nového tieru)
+func OnTierReset(fn func(userID uuid.UUID, tier int)) {
+	tierResetMu.Lock()
+	defer tierResetMu.Unlock()
+	tierResetHooks = append(tierResetHooks, fn)
+}
+
+func notifyTierReset(userID uuid.UUID, tier int) {
+	tierResetMu.RLock()
+	hooks := tierResetHooks
+	tierResetMu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(userID, tier)
+	}
+}
+
 func NewService(db *gorm.DB) *Service {
 	return &Service{db: db}
 }
@@ -530,7 +555,7 @@ func (s *Service) SellInventoryItem(userID uuid.UUID, inventoryItemID uuid.UUID)
 	// Calculate sell price based on item type and rarity
 	sellPrice := s.calculateSellPrice(&inventoryItem)
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Add credits to user
 		if err := s.AddCurrency(userID, CurrencyCredits, sellPrice, fmt.Sprintf("Sold %s", inventoryItem.ItemType)); err != nil {
 			return err
@@ -543,6 +568,22 @@ func (s *Service) SellInventoryItem(userID uuid.UUID, inventoryItemID uuid.UUID)
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	itemKey, _ := inventoryItem.Properties["type"].(string)
+	rarity, _ := inventoryItem.Properties["rarity"].(string)
+	analytics.Track(s.db, analytics.Event{
+		Type:     analytics.EventItemSold,
+		UserID:   &userID,
+		ItemType: inventoryItem.ItemType,
+		ItemKey:  itemKey,
+		Rarity:   rarity,
+		Currency: CurrencyCredits,
+		Amount:   sellPrice,
+	})
+	return nil
 }
 
 // Helper methods
@@ -829,6 +870,7 @@ func (s *Service) CheckAndResetExpiredTier(userID uuid.UUID) error {
 			return err
 		}
 		log.Printf("✅ [TIER SERVICE] Successfully reset tier to 0 for user %s", userID.String())
+		notifyTierReset(userID, 0)
 		return nil
 	}
 
@@ -839,10 +881,10 @@ func (s *Service) CheckAndResetExpiredTier(userID uuid.UUID) error {
 // Batch check and reset all expired tiers (pre admin endpoint)
 func (s *Service) CheckAndResetAllExpiredTiers() (int, error) {
 	var count int64
+	var users []User
 
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		// Nájdeme všetkých userov s expirovaným tier
-		var users []User
 		err := tx.Where("tier > 0 AND tier_expires < ?", time.Now()).Find(&users).Error
 		if err != nil {
 			return err
@@ -864,8 +906,14 @@ func (s *Service) CheckAndResetAllExpiredTiers() (int, error) {
 
 		return nil
 	})
+	if err != nil {
+		return 0, err
+	}
 
-	return int(count), err
+	for _, user := range users {
+		notifyTierReset(user.ID, 0)
+	}
+	return int(count), nil
 }
 
 // =====================================================
@@ -1574,6 +1622,8 @@ func (s *Service) mintItemToInventory(tx *gorm.DB, userID uuid.UUID, marketItem
 		itemType = "scanner_battery"
 	case "hack_tools":
 		itemType = "hack_tool"
+	case "defense_modules":
+		itemType = "defense_module"
 	case "potions", "buffs", "consumables":
 		itemType = "consumable"
 	case "cosmetics", "skins":
@@ -1606,33 +1656,17 @@ func (s *Service) mintItemToInventory(tx *gorm.DB, userID uuid.UUID, marketItem
 		"purchased_from": "market",
 	}
 
-	// ✨ Pridaj uses_left a tool_type pre hack tools
-	if itemType == "hack_tool" {
-		// Extrahuj uses z market item properties
-		if marketItem.Properties != nil {
-			if uses, ok := marketItem.Properties["uses"]; ok {
-				properties["uses_left"] = uses
-			} else {
-				properties["uses_left"] = 1 // default 1 použitie
-			}
-
-			if toolType, ok := marketItem.Properties["tool_type"]; ok {
-				properties["tool_type"] = toolType
-			} else {
-				properties["tool_type"] = "basic_hack" // default
-			}
+	// 🛡️ Obranné moduly - module_type, level a náboje pascí
+	if itemType == "defense_module" {
+		for key, value := range deployable.DefenseModuleProperties(marketItem.Properties) {
+			properties[key] = value
+		}
+	}
 
-			// Skopíruj ostatné relevantné properties z market item
-			if successRate, ok := marketItem.Properties["success_rate"]; ok {
-				properties["success_rate"] = successRate
-			}
-			if hackTime, ok := marketItem.Properties["hack_time_seconds"]; ok {
-				properties["hack_time_seconds"] = hackTime
-			}
-		} else {
-			// Fallback ak market item nemá properties
-			properties["uses_left"] = 1
-			properties["tool_type"] = "basic_hack"
+	// ✨ Hack tools - uses_left, tool_type, efekty a expirácia podľa typu nástroja
+	if itemType == "hack_tool" {
+		for key, value := range deployable.HackToolProperties(marketItem.Properties, "market", time.Now()) {
+			properties[key] = value
 		}
 
 		log.Printf("🔧 [MARKET PURCHASE] Hack Tool Created:")
@@ -1640,6 +1674,7 @@ func (s *Service) mintItemToInventory(tx *gorm.DB, userID uuid.UUID, marketItem
 		log.Printf("  → Name: %s", marketItem.Name)
 		log.Printf("  → Tool Type: %v", properties["tool_type"])
 		log.Printf("  → Uses Left: %v", properties["uses_left"])
+		log.Printf("  → Expires At: %v", properties["expires_at"])
 		log.Printf("  → Level: %v", properties["level"])
 		log.Printf("  → Rarity: %v", properties["rarity"])
 		log.Printf("  → Market Item ID: %s", marketItem.ID)
@@ -6,6 +6,8 @@ import (
 	"math/rand"
 	"time"
 
+	"geoanomaly/internal/realtime"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -131,7 +133,8 @@ func (w *OrderWorker) processExpiredPickups() error {
 
 // transitionToReadyForPickup - prechod SCHEDULED → READY_FOR_PICKUP
 func (w *OrderWorker) transitionToReadyForPickup(order *Order) error {
-	return w.db.Transaction(func(tx *gorm.DB) error {
+	var ready *Order
+	err := w.db.Transaction(func(tx *gorm.DB) error {
 		// Znovu načítaj objednávku s lock-om
 		var lockedOrder Order
 		if err := tx.Where("id = ? AND state = ?", order.ID, OrderStateScheduled).
@@ -160,8 +163,24 @@ func (w *OrderWorker) transitionToReadyForPickup(order *Order) error {
 			return fmt.Errorf("chyba pri aktualizácii objednávky: %w", err)
 		}
 
+		ready = &lockedOrder
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// Push notifikácia až po commite (objednávka mohla byť spracovaná inou inštanciou)
+	if ready != nil {
+		realtime.NotifyUser(ready.UserID, realtime.EventOrderReady, map[string]interface{}{
+			"order_id":          ready.ID,
+			"market_item_id":    ready.MarketItemID,
+			"quantity":          ready.Quantity,
+			"pickup_expires_at": ready.PickupExpiresAt.Unix(),
+		})
+	}
+
+	return nil
 }
 
 // transitionToCancelledForfeit - prechod READY_FOR_PICKUP → CANCELLED_FORFEIT
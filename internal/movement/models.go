@@ -0,0 +1,169 @@
+package movement
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Zdroje polohy - endpointy, ktoré posielajú GPS fix
+const (
+	SourceLocationUpdate = "location_update"
+	SourceUserLocation   = "user_location"
+	SourceScanArea       = "scan_area"
+	SourceScanner        = "scanner_scan"
+	SourceDeployDevice   = "deploy_device"
+	SourceScanDevice     = "scan_device"
+	SourceHackDevice     = "hack_device"
+	SourceClaimDevice    = "claim_device"
+	SourceZoneReroll     = "zone_reroll"
+)
+
+// Typy podozrivého pohybu
+const (
+	FlagImpossibleSpeed = "impossible_speed"
+	FlagJitter          = "jitter"
+	FlagZeroAccuracy    = "zero_accuracy"
+	FlagRepeatedFix     = "repeated_coordinates"
+)
+
+// Úrovne obmedzenia
+const (
+	LevelNone = ""
+	// LevelShadow - hráč hrá ďalej, ale nie je v leaderboardoch, ostatní ho nevidia a nespawnujú sa mu nové zóny
+	LevelShadow = "shadow"
+	// LevelSoftBan - navyše sú zablokované akcie viazané na polohu (zber, scan, deploy, hack)
+	LevelSoftBan = "soft_ban"
+)
+
+const (
+	// 300 km/h - rýchlejšie sa po zemi (vlak, diaľnica) nepohybuje nikto
+	MaxPlausibleSpeedMps = 83.0
+	// Skoky pod touto vzdialenosťou sú GPS šum, rýchlosť sa nekontroluje
+	MinSpeedCheckMeters = 100.0
+	// Horná hranica accuracy, ktorou sa dá "ospravedlniť" skok (fake accuracy 50 km nič neprepáči)
+	MaxAccuracyToleranceMeters = 500.0
+	// Rýchlosť nad MaxPlausibleSpeedMps * TeleportFactor = teleport
+	TeleportFactor = 10.0
+	// Po zamietnutom skoku (reálny let, reštart GPS) sa nové miesto prijme ako referenčné,
+	// keď naň nadviaže toľkoto fixov za sebou; skok sa trestá len raz
+	RelocateConfirmFixes = 3
+
+	// Jitter - skoky tam a späť rýchlo po sebe
+	JitterMinLegMeters     = 50.0
+	JitterReversalDegrees  = 150.0
+	JitterMaxInterval      = 60 * time.Second
+	JitterPatternThreshold = 3
+
+	// Identické súradnice - reálne GPS sa vždy aspoň trochu hýbe
+	RepeatMinInterval      = 5 * time.Second
+	RepeatPatternThreshold = 10
+
+	// Skóre
+	WeightImpossibleSpeed = 4.0
+	WeightTeleport        = 8.0
+	WeightJitter          = 3.0
+	WeightZeroAccuracy    = 2.0
+	WeightRepeatedFix     = 1.0
+
+	ShadowThreshold  = 10.0
+	SoftBanThreshold = 25.0
+	ShadowDuration   = 24 * time.Hour
+	SoftBanDuration  = 12 * time.Hour
+
+	// Skóre sa polovičí každých 12 hodín bez ďalších prehreškov
+	ScoreHalfLife = 12 * time.Hour
+)
+
+// Fix - jedna poloha poslaná klientom
+type Fix struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  *float64 // nil = endpoint accuracy neposiela
+}
+
+// Verdict - výsledok kontroly jedného fixu
+type Verdict struct {
+	Rejected        bool       `json:"rejected"` // fix je fyzicky nemožný, nepoužije sa
+	Flags           []string   `json:"flags,omitempty"`
+	SpeedMps        float64    `json:"speed_mps"`
+	Score           float64    `json:"score"`
+	Level           string     `json:"level,omitempty"`
+	RestrictedUntil *time.Time `json:"restricted_until,omitempty"`
+}
+
+// Shadowed - hráč je aspoň v shadow režime
+func (v Verdict) Shadowed() bool {
+	return v.Level != LevelNone
+}
+
+// SoftBanned - akcie viazané na polohu sú zablokované
+func (v Verdict) SoftBanned() bool {
+	return v.Level == LevelSoftBan
+}
+
+// Profile - posledný overený fix a skóre podozrivosti hráča
+type Profile struct {
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+
+	LastLatitude  float64    `json:"last_latitude" gorm:"type:decimal(10,8)"`
+	LastLongitude float64    `json:"last_longitude" gorm:"type:decimal(11,8)"`
+	LastAccuracy  float64    `json:"last_accuracy"`
+	LastFixAt     *time.Time `json:"last_fix_at,omitempty"`
+	LastSource    string     `json:"last_source" gorm:"size:30"`
+
+	// Posledný úsek pohybu (pre jitter)
+	LastBearing   float64 `json:"-"`
+	LastLegMeters float64 `json:"-"`
+	JitterCount   int     `json:"-" gorm:"not null;default:0"`
+	RepeatCount   int     `json:"-" gorm:"not null;default:0"`
+
+	// Kandidát na novú referenčnú polohu po zamietnutom skoku
+	PendingLatitude  float64    `json:"-" gorm:"type:decimal(10,8)"`
+	PendingLongitude float64    `json:"-" gorm:"type:decimal(11,8)"`
+	PendingAccuracy  float64    `json:"-"`
+	PendingFixAt     *time.Time `json:"-"`
+	PendingCount     int        `json:"-" gorm:"not null;default:0"`
+
+	Score           float64    `json:"score" gorm:"not null;default:0"`
+	ScoreUpdatedAt  time.Time  `json:"score_updated_at"`
+	TotalFlags      int        `json:"total_flags" gorm:"not null;default:0"`
+	Level           string     `json:"level,omitempty" gorm:"size:20"`
+	RestrictedUntil *time.Time `json:"restricted_until,omitempty" gorm:"index"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (Profile) TableName() string {
+	return "auth.movement_profiles"
+}
+
+// ActiveLevel - úroveň obmedzenia, ak ešte platí
+func (p *Profile) ActiveLevel(now time.Time) string {
+	if p.RestrictedUntil == nil || !p.RestrictedUntil.After(now) {
+		return LevelNone
+	}
+	return p.Level
+}
+
+// Violation - záznam o podozrivom fixe (pre adminov)
+type Violation struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_movement_violations_user_time,priority:1"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index:idx_movement_violations_user_time,priority:2"`
+
+	Source         string  `json:"source" gorm:"size:30;not null"`
+	Flag           string  `json:"flag" gorm:"size:30;not null"`
+	Latitude       float64 `json:"latitude" gorm:"type:decimal(10,8)"`
+	Longitude      float64 `json:"longitude" gorm:"type:decimal(11,8)"`
+	Accuracy       float64 `json:"accuracy"`
+	DistanceMeters float64 `json:"distance_meters"`
+	SpeedMps       float64 `json:"speed_mps"`
+	ScoreAdded     float64 `json:"score_added"`
+	Rejected       bool    `json:"rejected"`
+}
+
+func (Violation) TableName() string {
+	return "auth.movement_violations"
+}
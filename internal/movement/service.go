@@ -0,0 +1,446 @@
+// Package movement - kontrola hodnovernosti pohybu (GPS spoofing / teleport).
+//
+// Každý nový fix sa porovná s posledným overeným fixom hráča (na začiatku
+// s polohou z auth.player_sessions). Podozrivé vzory pridávajú skóre, ktoré
+// časom klesá; nad prahmi sa hráč dostane do shadow režimu alebo soft banu.
+// Nemožný skok sa trestá raz - keď naň nadviaže niekoľko fixov (reálny let),
+// nové miesto sa prijme ako referenčné.
+package movement
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"geoanomaly/pkg/geoquery"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RestrictedUsersSQL - subquery hráčov s aktívnym obmedzením (leaderboardy, nearby players)
+const RestrictedUsersSQL = "SELECT user_id FROM auth.movement_profiles WHERE restricted_until > NOW()"
+
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+var restrictionHook func(userID uuid.UUID)
+
+// SetRestrictionHook - volá sa, keď hráč práve dostal obmedzenie (napr. odstránenie z leaderboardov)
+func SetRestrictionHook(fn func(userID uuid.UUID)) {
+	restrictionHook = fn
+}
+
+// Check - vyhodnotí fix, uloží ho (ak nie je zamietnutý) a vráti verdikt.
+// Pri chybe databázy fix prepustí - anti-cheat nesmie zablokovať hru.
+func (s *Service) Check(userID uuid.UUID, source string, fix Fix) Verdict {
+	now := time.Now()
+	var verdict Verdict
+	var previousLevel string
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		profile, err := s.lockProfile(tx, userID, now)
+		if err != nil {
+			return err
+		}
+
+		previousLevel = profile.ActiveLevel(now)
+		var violations []Violation
+		verdict, violations = evaluate(profile, source, fix, now)
+
+		if err := tx.Save(profile).Error; err != nil {
+			return err
+		}
+		if len(violations) > 0 {
+			if err := tx.Create(&violations).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("⚠️ Movement check failed for user %s: %v", userID, err)
+		return Verdict{}
+	}
+
+	if len(verdict.Flags) > 0 {
+		log.Printf("🚩 Movement flags for user %s (%s): %v speed=%.1fm/s score=%.1f level=%q rejected=%v",
+			userID, source, verdict.Flags, verdict.SpeedMps, verdict.Score, verdict.Level, verdict.Rejected)
+	}
+	if previousLevel == LevelNone && verdict.Level != LevelNone && restrictionHook != nil {
+		restrictionHook(userID)
+	}
+
+	return verdict
+}
+
+// Level - aktuálna úroveň obmedzenia hráča
+func (s *Service) Level(userID uuid.UUID) (string, *time.Time) {
+	var profile Profile
+	if err := s.db.Select("user_id", "level", "restricted_until").
+		Where("user_id = ?", userID).Take(&profile).Error; err != nil {
+		return LevelNone, nil
+	}
+	level := profile.ActiveLevel(time.Now())
+	if level == LevelNone {
+		return LevelNone, nil
+	}
+	return level, profile.RestrictedUntil
+}
+
+// IsRestricted - hráč je v shadow režime alebo soft bane
+func (s *Service) IsRestricted(userID uuid.UUID) bool {
+	level, _ := s.Level(userID)
+	return level != LevelNone
+}
+
+// Flagged - hráči s nenulovým skóre alebo aktívnym obmedzením, najpodozrivejší prví
+func (s *Service) Flagged(limit int) ([]Profile, error) {
+	var profiles []Profile
+	err := s.db.Where("score >= 1 OR restricted_until > ?", time.Now()).
+		Order("restricted_until DESC NULLS LAST, score DESC").
+		Limit(limit).
+		Find(&profiles).Error
+	return profiles, err
+}
+
+// Violations - posledné podozrivé fixy hráča
+func (s *Service) Violations(userID uuid.UUID, limit int) ([]Violation, error) {
+	var violations []Violation
+	err := s.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&violations).Error
+	return violations, err
+}
+
+// Profile - profil hráča (nil ak ešte žiadny fix neposlal)
+func (s *Service) Profile(userID uuid.UUID) (*Profile, error) {
+	var profile Profile
+	if err := s.db.Where("user_id = ?", userID).Take(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Clear - zruší skóre aj obmedzenie (admin); história porušení ostáva
+func (s *Service) Clear(db *gorm.DB, userID uuid.UUID) error {
+	if db == nil {
+		db = s.db
+	}
+	return db.Model(&Profile{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"score":            0,
+		"score_updated_at": time.Now(),
+		"level":            LevelNone,
+		"restricted_until": nil,
+		"jitter_count":     0,
+		"repeat_count":     0,
+		"pending_count":    0,
+		"pending_fix_at":   nil,
+	}).Error
+}
+
+// lockProfile - profil so zámkom riadku; prvý profil sa naplní z player_sessions
+func (s *Service) lockProfile(tx *gorm.DB, userID uuid.UUID, now time.Time) (*Profile, error) {
+	var profile Profile
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).Take(&profile).Error
+	if err == nil {
+		return &profile, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	profile = Profile{UserID: userID, ScoreUpdatedAt: now}
+	var session struct {
+		LastLocationLatitude  float64
+		LastLocationLongitude float64
+		LastLocationAccuracy  float64
+		LastSeen              time.Time
+	}
+	if err := tx.Table("auth.player_sessions").
+		Select("last_location_latitude, last_location_longitude, last_location_accuracy, last_seen").
+		Where("user_id = ?", userID).Take(&session).Error; err == nil &&
+		(session.LastLocationLatitude != 0 || session.LastLocationLongitude != 0) {
+		lastSeen := session.LastSeen
+		profile.LastLatitude = session.LastLocationLatitude
+		profile.LastLongitude = session.LastLocationLongitude
+		profile.LastAccuracy = session.LastLocationAccuracy
+		profile.LastFixAt = &lastSeen
+		profile.LastSource = "player_session"
+	}
+
+	// Súbežný request mohol profil vytvoriť medzitým
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&profile).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).Take(&profile).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// evaluate - porovná fix s profilom, upraví profil a vráti verdikt + záznamy porušení
+func evaluate(p *Profile, source string, fix Fix, now time.Time) (Verdict, []Violation) {
+	decayScore(p, now)
+
+	verdict := Verdict{}
+	var violations []Violation
+
+	accuracy := 0.0
+	if fix.Accuracy != nil {
+		accuracy = *fix.Accuracy
+	}
+
+	flag := func(name string, weight, distance float64) {
+		verdict.Flags = append(verdict.Flags, name)
+		p.Score += weight
+		p.TotalFlags++
+		violations = append(violations, Violation{
+			UserID:         p.UserID,
+			Source:         source,
+			Flag:           name,
+			Latitude:       fix.Latitude,
+			Longitude:      fix.Longitude,
+			Accuracy:       accuracy,
+			DistanceMeters: math.Round(distance*10) / 10,
+			SpeedMps:       math.Round(verdict.SpeedMps*10) / 10,
+			ScoreAdded:     weight,
+		})
+	}
+
+	// Skutočné GPS nikdy nevráti presnosť 0 - typický znak mock location aplikácií
+	if fix.Accuracy != nil && accuracy <= 0 {
+		flag(FlagZeroAccuracy, WeightZeroAccuracy, 0)
+	}
+
+	relocated := false
+	if p.LastFixAt != nil {
+		elapsed := now.Sub(*p.LastFixAt)
+		distance := geoquery.Haversine(p.LastLatitude, p.LastLongitude, fix.Latitude, fix.Longitude)
+
+		// Nemožná rýchlosť (po odpočítaní nepresnosti oboch fixov)
+		verdict.SpeedMps = impliedSpeed(distance, p.LastAccuracy, accuracy, elapsed)
+		if verdict.SpeedMps > MaxPlausibleSpeedMps {
+			verdict.Rejected = true
+			if continuesPending(p, fix, accuracy, now) {
+				// Hráč ostáva na novom mieste - skok už bol zaznamenaný, po pár fixoch sa prijme
+				p.PendingCount++
+				relocated = p.PendingCount >= RelocateConfirmFixes
+			} else {
+				weight := WeightImpossibleSpeed
+				if verdict.SpeedMps > MaxPlausibleSpeedMps*TeleportFactor {
+					weight = WeightTeleport
+				}
+				flag(FlagImpossibleSpeed, weight, distance)
+				p.PendingCount = 1
+			}
+
+			if relocated {
+				verdict.Rejected = false
+				p.LastLegMeters = 0
+				p.JitterCount = 0
+				p.RepeatCount = 0
+			} else {
+				pendingAt := now
+				p.PendingLatitude = fix.Latitude
+				p.PendingLongitude = fix.Longitude
+				p.PendingAccuracy = accuracy
+				p.PendingFixAt = &pendingAt
+			}
+		}
+
+		if !verdict.Rejected && !relocated {
+			// Jitter - opakované skoky tam a späť
+			heading := bearing(p.LastLatitude, p.LastLongitude, fix.Latitude, fix.Longitude)
+			fresh := elapsed <= JitterMaxInterval
+			reversal := fresh && distance >= JitterMinLegMeters && p.LastLegMeters >= JitterMinLegMeters &&
+				angleDiff(heading, p.LastBearing) >= JitterReversalDegrees
+			switch {
+			case reversal:
+				p.JitterCount++
+			case !fresh || distance >= JitterMinLegMeters:
+				p.JitterCount = 0
+			}
+			if p.JitterCount >= JitterPatternThreshold {
+				flag(FlagJitter, WeightJitter, distance)
+				p.JitterCount = 0
+			}
+			p.LastBearing = heading
+			p.LastLegMeters = distance
+
+			// Identické súradnice z location streamu (ostatné endpointy legitímne posielajú posledný fix)
+			if isStream(source) && isStream(p.LastSource) {
+				if sameCoordinate(fix.Latitude, p.LastLatitude) && sameCoordinate(fix.Longitude, p.LastLongitude) {
+					if elapsed >= RepeatMinInterval {
+						p.RepeatCount++
+					}
+				} else {
+					p.RepeatCount = 0
+				}
+				if p.RepeatCount >= RepeatPatternThreshold {
+					flag(FlagRepeatedFix, WeightRepeatedFix, 0)
+					p.RepeatCount = 0
+				}
+			}
+		}
+	}
+
+	// Zamietnutý fix sa nestane referenčným - teleport nepohne hráčom
+	if !verdict.Rejected {
+		p.PendingCount = 0
+		p.PendingFixAt = nil
+		fixTime := now
+		p.LastLatitude = fix.Latitude
+		p.LastLongitude = fix.Longitude
+		p.LastAccuracy = accuracy
+		p.LastFixAt = &fixTime
+		p.LastSource = source
+	}
+
+	if len(violations) > 0 {
+		escalate(p, now)
+		for i := range violations {
+			violations[i].Rejected = verdict.Rejected
+		}
+	}
+
+	verdict.Score = math.Round(p.Score*10) / 10
+	verdict.Level = p.ActiveLevel(now)
+	if verdict.Level != LevelNone {
+		verdict.RestrictedUntil = p.RestrictedUntil
+	}
+	return verdict, violations
+}
+
+// impliedSpeed - rýchlosť medzi dvoma fixmi po odpočítaní nepresnosti oboch (0 = skok je v rámci GPS šumu)
+func impliedSpeed(distance, accuracyA, accuracyB float64, elapsed time.Duration) float64 {
+	tolerance := math.Min(accuracyA, MaxAccuracyToleranceMeters) + math.Min(accuracyB, MaxAccuracyToleranceMeters)
+	effective := distance - tolerance
+	if distance < MinSpeedCheckMeters || effective <= 0 {
+		return 0
+	}
+	return effective / math.Max(elapsed.Seconds(), 1)
+}
+
+// continuesPending - fix hodnoverne nadväzuje na kandidáta po zamietnutom skoku
+func continuesPending(p *Profile, fix Fix, accuracy float64, now time.Time) bool {
+	if p.PendingCount == 0 || p.PendingFixAt == nil {
+		return false
+	}
+	distance := geoquery.Haversine(p.PendingLatitude, p.PendingLongitude, fix.Latitude, fix.Longitude)
+	return impliedSpeed(distance, p.PendingAccuracy, accuracy, now.Sub(*p.PendingFixAt)) <= MaxPlausibleSpeedMps
+}
+
+// escalate - nastaví obmedzenie podľa skóre (soft ban sa nikdy neprepíše na shadow)
+func escalate(p *Profile, now time.Time) {
+	var level string
+	var duration time.Duration
+	switch {
+	case p.Score >= SoftBanThreshold:
+		level, duration = LevelSoftBan, SoftBanDuration
+	case p.Score >= ShadowThreshold:
+		level, duration = LevelShadow, ShadowDuration
+	default:
+		return
+	}
+
+	current := p.ActiveLevel(now)
+	if current == LevelSoftBan && level == LevelShadow {
+		return
+	}
+
+	until := now.Add(duration)
+	if p.RestrictedUntil != nil && p.RestrictedUntil.After(until) {
+		until = *p.RestrictedUntil
+	}
+	p.Level = level
+	p.RestrictedUntil = &until
+}
+
+func decayScore(p *Profile, now time.Time) {
+	if !p.ScoreUpdatedAt.IsZero() && p.Score > 0 {
+		elapsed := now.Sub(p.ScoreUpdatedAt)
+		p.Score *= math.Pow(0.5, elapsed.Hours()/ScoreHalfLife.Hours())
+		if p.Score < 0.1 {
+			p.Score = 0
+		}
+	}
+	p.ScoreUpdatedAt = now
+}
+
+// sameCoordinate - zhoda na 8 desatinných miest (presnosť stĺpca decimal(10,8))
+func sameCoordinate(a, b float64) bool {
+	return math.Abs(a-b) < 5e-9
+}
+
+func isStream(source string) bool {
+	return source == SourceLocationUpdate || source == SourceUserLocation
+}
+
+// bearing - smer z bodu 1 do bodu 2 v stupňoch (0-360)
+func bearing(lat1, lng1, lat2, lng2 float64) float64 {
+	φ1 := lat1 * math.Pi / 180
+	φ2 := lat2 * math.Pi / 180
+	Δλ := (lng2 - lng1) * math.Pi / 180
+	y := math.Sin(Δλ) * math.Cos(φ2)
+	x := math.Cos(φ1)*math.Sin(φ2) - math.Sin(φ1)*math.Cos(φ2)*math.Cos(Δλ)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+func angleDiff(a, b float64) float64 {
+	d := math.Abs(a - b)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
+
+// ==========================================
+// GIN HELPERS
+// ==========================================
+
+// Enforce - skontroluje fix a pri zamietnutí / soft bane rovno odpovie; false = request skončil
+func (s *Service) Enforce(c *gin.Context, userID uuid.UUID, source string, fix Fix) (Verdict, bool) {
+	verdict := s.Check(userID, source, fix)
+	return verdict, !Abort(c, verdict)
+}
+
+// EnforceLevel - pre akcie bez vlastného fixu (vstup do zóny, zber) - len soft ban
+func (s *Service) EnforceLevel(c *gin.Context, userID uuid.UUID) bool {
+	level, until := s.Level(userID)
+	return !Abort(c, Verdict{Level: level, RestrictedUntil: until})
+}
+
+// Abort - odpoveď pre nemožný fix (422) alebo soft ban (403); true = request bol ukončený
+func Abort(c *gin.Context, v Verdict) bool {
+	if v.Rejected {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Location could not be verified",
+			"code":    "implausible_movement",
+			"message": "Your position changed faster than is physically possible",
+		})
+		return true
+	}
+	if v.SoftBanned() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":            "Location-based actions are temporarily restricted",
+			"code":             "movement_restricted",
+			"restricted_until": v.RestrictedUntil,
+		})
+		return true
+	}
+	return false
+}
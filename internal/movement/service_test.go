@@ -0,0 +1,150 @@
+package movement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	bratislavaLat = 48.1486
+	bratislavaLng = 17.1077
+	kosiceLat     = 48.7164
+	kosiceLng     = 21.2611
+	viennaLat     = 48.2082
+	viennaLng     = 16.3738
+)
+
+// newTestProfile - profil s overeným fixom v Bratislave
+func newTestProfile(at time.Time) *Profile {
+	return &Profile{
+		UserID:         uuid.New(),
+		LastLatitude:   bratislavaLat,
+		LastLongitude:  bratislavaLng,
+		LastAccuracy:   10,
+		LastFixAt:      &at,
+		LastSource:     SourceLocationUpdate,
+		ScoreUpdatedAt: at,
+	}
+}
+
+func fixAt(lat, lng float64) Fix {
+	accuracy := 10.0
+	return Fix{Latitude: lat, Longitude: lng, Accuracy: &accuracy}
+}
+
+func TestEvaluate_PlausibleWalkAccepted(t *testing.T) {
+	now := time.Now()
+	p := newTestProfile(now)
+
+	now = now.Add(time.Minute)
+	verdict, violations := evaluate(p, SourceLocationUpdate, fixAt(bratislavaLat+0.001, bratislavaLng), now)
+
+	if verdict.Rejected || len(verdict.Flags) > 0 || len(violations) > 0 {
+		t.Fatalf("walk should pass cleanly, got rejected=%v flags=%v", verdict.Rejected, verdict.Flags)
+	}
+	if p.LastLatitude != bratislavaLat+0.001 {
+		t.Errorf("accepted fix should become the reference, last latitude = %v", p.LastLatitude)
+	}
+}
+
+func TestEvaluate_FlightFlaggedOnceThenAccepted(t *testing.T) {
+	now := time.Now()
+	p := newTestProfile(now)
+
+	// Prvý fix po prílete - zamietnutý a zaznamenaný
+	now = now.Add(30 * time.Second)
+	verdict, violations := evaluate(p, SourceLocationUpdate, fixAt(kosiceLat, kosiceLng), now)
+	if !verdict.Rejected || len(violations) != 1 || violations[0].Flag != FlagImpossibleSpeed {
+		t.Fatalf("jump should be rejected and flagged once, got rejected=%v violations=%d", verdict.Rejected, len(violations))
+	}
+	scoreAfterJump := p.Score
+
+	// Ďalšie fixy na novom mieste - bez nového trestu, posledný sa prijme
+	for i := 2; i <= RelocateConfirmFixes; i++ {
+		now = now.Add(30 * time.Second)
+		verdict, violations = evaluate(p, SourceLocationUpdate, fixAt(kosiceLat+0.0002*float64(i), kosiceLng), now)
+		if len(violations) > 0 || len(verdict.Flags) > 0 {
+			t.Fatalf("fix %d at the new spot should not be flagged again, got %v", i, verdict.Flags)
+		}
+		if wantRejected := i < RelocateConfirmFixes; verdict.Rejected != wantRejected {
+			t.Fatalf("fix %d: rejected = %v, want %v", i, verdict.Rejected, wantRejected)
+		}
+	}
+
+	if p.LastLongitude != kosiceLng {
+		t.Errorf("new spot should become the reference, last longitude = %v", p.LastLongitude)
+	}
+	if p.PendingCount != 0 {
+		t.Errorf("pending candidate should be cleared, count = %d", p.PendingCount)
+	}
+	if p.Score > scoreAfterJump {
+		t.Errorf("score grew after the jump: %.1f → %.1f", scoreAfterJump, p.Score)
+	}
+	if verdict.Level != LevelNone {
+		t.Errorf("a single flight should not restrict the player, level = %q", verdict.Level)
+	}
+
+	// Pohyb po prílete je už bežný
+	now = now.Add(time.Minute)
+	verdict, _ = evaluate(p, SourceLocationUpdate, fixAt(kosiceLat+0.002, kosiceLng), now)
+	if verdict.Rejected || len(verdict.Flags) > 0 {
+		t.Errorf("walk after relocation should pass, got rejected=%v flags=%v", verdict.Rejected, verdict.Flags)
+	}
+}
+
+func TestEvaluate_RepeatedJumpsFlaggedEachTime(t *testing.T) {
+	now := time.Now()
+	p := newTestProfile(now)
+
+	targets := [][2]float64{{kosiceLat, kosiceLng}, {viennaLat, viennaLng}, {kosiceLat, kosiceLng}}
+	for i, target := range targets {
+		now = now.Add(30 * time.Second)
+		verdict, violations := evaluate(p, SourceLocationUpdate, fixAt(target[0], target[1]), now)
+		if !verdict.Rejected || len(violations) != 1 {
+			t.Fatalf("jump %d should be rejected and flagged, got rejected=%v violations=%d", i+1, verdict.Rejected, len(violations))
+		}
+	}
+
+	if p.LastLatitude != bratislavaLat || p.LastLongitude != bratislavaLng {
+		t.Errorf("hopping between spots must not move the reference, got %v,%v", p.LastLatitude, p.LastLongitude)
+	}
+	if p.TotalFlags != len(targets) {
+		t.Errorf("total flags = %d, want %d", p.TotalFlags, len(targets))
+	}
+}
+
+func TestEvaluate_ReturnToReferenceClearsPending(t *testing.T) {
+	now := time.Now()
+	p := newTestProfile(now)
+
+	now = now.Add(30 * time.Second)
+	if verdict, _ := evaluate(p, SourceLocationUpdate, fixAt(kosiceLat, kosiceLng), now); !verdict.Rejected {
+		t.Fatal("jump should be rejected")
+	}
+
+	now = now.Add(30 * time.Second)
+	verdict, violations := evaluate(p, SourceLocationUpdate, fixAt(bratislavaLat, bratislavaLng), now)
+	if verdict.Rejected || len(violations) > 0 {
+		t.Fatalf("fix at the reference should pass, got rejected=%v flags=%v", verdict.Rejected, verdict.Flags)
+	}
+	if p.PendingCount != 0 || p.PendingFixAt != nil {
+		t.Errorf("accepted fix should clear the pending candidate, count = %d", p.PendingCount)
+	}
+}
+
+func TestEvaluate_ZeroAccuracyFlagged(t *testing.T) {
+	now := time.Now()
+	p := newTestProfile(now)
+
+	zero := 0.0
+	verdict, violations := evaluate(p, SourceLocationUpdate, Fix{Latitude: bratislavaLat, Longitude: bratislavaLng, Accuracy: &zero}, now.Add(time.Minute))
+
+	if verdict.Rejected {
+		t.Error("zero accuracy alone should not reject the fix")
+	}
+	if len(violations) != 1 || violations[0].Flag != FlagZeroAccuracy {
+		t.Errorf("expected a single %s violation, got %v", FlagZeroAccuracy, verdict.Flags)
+	}
+}
@@ -0,0 +1,120 @@
+package notifications
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{service: NewService(db)}
+}
+
+// MarkReadRequest - prázdne ids = označ všetky
+type MarkReadRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+// notificationView - notifikácia s read stavom pre klienta
+type notificationView struct {
+	Notification
+	Read bool `json:"read"`
+}
+
+// GetNotifications - inbox hráča (GET /notifications?unread=true&limit=50&before=<unix>)
+func (h *Handler) GetNotifications(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	limit := DefaultPageSize
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > MaxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be between 1 and " + strconv.Itoa(MaxPageSize)})
+			return
+		}
+		limit = parsed
+	}
+
+	var before *time.Time
+	if raw := c.Query("before"); raw != "" {
+		unix, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid before cursor"})
+			return
+		}
+		t := time.Unix(unix, 0)
+		before = &t
+	}
+
+	items, err := h.service.List(userID, c.Query("unread") == "true", before, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load notifications"})
+		return
+	}
+
+	views := make([]notificationView, 0, len(items))
+	for _, n := range items {
+		views = append(views, notificationView{Notification: n, Read: n.IsRead()})
+	}
+
+	response := gin.H{
+		"notifications": views,
+		"count":         len(views),
+		"unread_count":  h.service.UnreadCount(userID),
+	}
+	if len(items) == limit {
+		response["next_before"] = items[len(items)-1].CreatedAt.Unix()
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// MarkNotificationRead - jedna notifikácia (POST /notifications/:id/read)
+func (h *Handler) MarkNotificationRead(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	updated, err := h.service.MarkRead(userID, []uuid.UUID{id})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"updated":      updated,
+		"unread_count": h.service.UnreadCount(userID),
+	})
+}
+
+// MarkNotificationsRead - viac naraz alebo všetky (POST /notifications/read)
+func (h *Handler) MarkNotificationsRead(c *gin.Context) {
+	userID := c.MustGet("user_id").(uuid.UUID)
+
+	var req MarkReadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	updated, err := h.service.MarkRead(userID, req.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"updated":      updated,
+		"unread_count": h.service.UnreadCount(userID),
+	})
+}
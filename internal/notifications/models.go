@@ -0,0 +1,43 @@
+package notifications
+
+import (
+	"time"
+
+	"geoanomaly/internal/gameplay"
+
+	"github.com/google/uuid"
+)
+
+// Typy notifikácií v inboxe
+const (
+	TypeZoneAging    = "zone.aging"
+	TypeZoneExpiring = "zone.expiring" // "last chance" pred zmiznutím zóny
+	TypeZoneVanished = "zone.vanished" // zóna zmizla (expirácia / admin), nevyzbierané itemy prepadli
+
+	TypeDeviceAlarm       = "device.alarm"        // pokus o hack zariadenia (pre vlastníka)
+	TypeDeviceCounterHack = "device.counter_hack" // vlastník zrušil prístup získaný hackom (pre hackera)
+	TypeDeviceShared      = "device.shared"       // priateľ so mnou zdieľa zariadenie
+	TypeDeviceNetwork     = "device.network"      // pridanie do siete zariadení / zrušenie siete
+)
+
+// Notification - záznam v inboxe hráča (read/unread stav prežije odpojenie)
+type Notification struct {
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID      `json:"user_id" gorm:"type:uuid;not null;index:idx_notifications_user_created,priority:1;uniqueIndex:idx_notifications_dedup,priority:1"`
+	Type      string         `json:"type" gorm:"size:50;not null"`
+	Title     string         `json:"title" gorm:"size:150;not null"`
+	Body      string         `json:"body" gorm:"type:text"`
+	Data      gameplay.JSONB `json:"data,omitempty" gorm:"type:jsonb;default:'{}'::jsonb"`
+	DedupKey  *string        `json:"-" gorm:"size:120;uniqueIndex:idx_notifications_dedup,priority:2"` // rovnaká udalosť = jedna notifikácia
+	ReadAt    *time.Time     `json:"read_at,omitempty"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime;index:idx_notifications_user_created,priority:2,sort:desc"`
+}
+
+func (Notification) TableName() string {
+	return "gameplay.notifications"
+}
+
+// IsRead - pre API odpoveď
+func (n Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
@@ -0,0 +1,123 @@
+// Package notifications - in-app inbox hráča uložený v DB.
+//
+// Notifikácia sa zapíše do inboxu (s deduplikáciou podľa kľúča udalosti)
+// a ak je hráč pripojený, pošle sa mu aj realtime event. Hráč, ktorý bol
+// offline, ju uvidí cez GET /notifications.
+package notifications
+
+import (
+	"log"
+	"time"
+
+	"geoanomaly/internal/realtime"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+	RetentionPeriod = 30 * 24 * time.Hour // staršie notifikácie sa mažú
+)
+
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Send - zapíše notifikáciu každému hráčovi; dedupKey zabráni duplicite pri opakovanom
+// spracovaní tej istej udalosti (napr. viac inštancií schedulera). Vráti počet nových záznamov.
+func (s *Service) Send(userIDs []uuid.UUID, template Notification, dedupKey string) int {
+	if len(userIDs) == 0 {
+		return 0
+	}
+
+	sent := 0
+	for _, userID := range uniqueIDs(userIDs) {
+		n := template
+		n.UserID = userID
+		if dedupKey != "" {
+			key := dedupKey
+			n.DedupKey = &key
+		}
+
+		result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&n)
+		if result.Error != nil {
+			log.Printf("❌ Failed to store %s notification for %s: %v", template.Type, userID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue // už doručená skôr
+		}
+
+		sent++
+		realtime.NotifyUser(n.UserID, realtime.EventNotification, map[string]interface{}{
+			"notification_id": n.ID,
+			"type":            n.Type,
+			"title":           n.Title,
+			"body":            n.Body,
+			"data":            n.Data,
+		})
+	}
+	return sent
+}
+
+// List - inbox hráča od najnovších; before = kurzor (created_at poslednej načítanej)
+func (s *Service) List(userID uuid.UUID, unreadOnly bool, before *time.Time, limit int) ([]Notification, error) {
+	query := s.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if before != nil {
+		query = query.Where("created_at < ?", *before)
+	}
+
+	var items []Notification
+	err := query.Order("created_at DESC").Limit(limit).Find(&items).Error
+	return items, err
+}
+
+func (s *Service) UnreadCount(userID uuid.UUID) int64 {
+	var count int64
+	s.db.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count)
+	return count
+}
+
+// MarkRead - označí notifikácie hráča ako prečítané (prázdne ids = všetky)
+func (s *Service) MarkRead(userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	query := s.db.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// Prune - zmaže notifikácie staršie ako RetentionPeriod
+func (s *Service) Prune(now time.Time) {
+	result := s.db.Where("created_at < ?", now.Add(-RetentionPeriod)).Delete(&Notification{})
+	if result.Error != nil {
+		log.Printf("❌ Failed to prune notifications: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("🧹 Pruned %d old notifications", result.RowsAffected)
+	}
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
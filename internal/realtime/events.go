@@ -0,0 +1,57 @@
+package realtime
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types posielané klientom
+const (
+	EventConnected         = "connected"
+	EventHeartbeat         = "heartbeat"
+	EventSessionExpired    = "session.expired" // access token expiroval - klient sa má znovu pripojiť
+	EventPlayerEnteredZone = "zone.player_entered"
+	EventPlayerLeftZone    = "zone.player_left"
+	EventZoneExpiring      = "zone.expiring"
+	EventDeviceHacked      = "device.hacked"
+	EventOrderReady        = "order.ready_for_pickup"
+	EventChargingCompleted = "laboratory.charging_completed"
+	EventResearchCompleted = "laboratory.research_completed"
+	EventNotification      = "notification.new" // nový záznam v inboxe (GET /notifications)
+)
+
+const (
+	// Jeden Redis kanál pre všetky inštancie - každá si doručí len svojim klientom
+	pubSubChannel = "geoanomaly:realtime"
+
+	clientBufferSize  = 32
+	HeartbeatInterval = 30 * time.Second
+)
+
+// Event - správa doručená klientovi cez WebSocket / SSE
+type Event struct {
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Timestamp int64                  `json:"timestamp"`
+}
+
+// NewEvent - event s vygenerovaným ID a aktuálnym časom
+func NewEvent(eventType string, data map[string]interface{}) Event {
+	return Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		Data:      data,
+		Timestamp: time.Now().Unix(),
+	}
+}
+
+// envelope - event + adresát, tak ako ide cez Redis pub/sub medzi inštanciami
+type envelope struct {
+	UserID  *uuid.UUID  `json:"user_id,omitempty"` // doruč konkrétnemu hráčovi
+	ZoneID  *uuid.UUID  `json:"zone_id,omitempty"` // doruč všetkým hráčom v zóne
+	Subject *uuid.UUID  `json:"subject,omitempty"` // hráč, ktorého sa zónový event týka (nedostane ho)
+	Exclude []uuid.UUID `json:"exclude,omitempty"` // napr. hráči, ktorí subject zablokovali
+	Event   Event       `json:"event"`
+}
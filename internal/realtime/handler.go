@@ -0,0 +1,144 @@
+package realtime
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+	"gorm.io/gorm"
+)
+
+type Handler struct {
+	db  *gorm.DB
+	hub *Hub
+}
+
+func NewHandler(db *gorm.DB, hub *Hub) *Handler {
+	return &Handler{
+		db:  db,
+		hub: hub,
+	}
+}
+
+// ServeWebSocket - GET /realtime/ws (token v Authorization hlavičke alebo ?access_token=)
+func (h *Handler) ServeWebSocket(c *gin.Context) {
+	client, deadline, ok := h.connect(c)
+	if !ok {
+		return
+	}
+	defer h.hub.Unregister(client)
+
+	server := websocket.Server{
+		// Mobilní klienti často neposielajú Origin; autentifikácia ide cez JWT
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			// Čítanie len kvôli detekcii zatvorenia spojenia (klient nič neposiela)
+			closed := make(chan struct{})
+			go func() {
+				defer close(closed)
+				var discard string
+				for {
+					if err := websocket.Message.Receive(ws, &discard); err != nil {
+						return
+					}
+				}
+			}()
+
+			h.pump(client, deadline, closed, func(event Event) error {
+				return websocket.JSON.Send(ws, event)
+			})
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// ServeSSE - GET /realtime/events (Server-Sent Events fallback pre klientov bez WebSocket)
+func (h *Handler) ServeSSE(c *gin.Context) {
+	client, deadline, ok := h.connect(c)
+	if !ok {
+		return
+	}
+	defer h.hub.Unregister(client)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	h.pump(client, deadline, c.Request.Context().Done(), func(event Event) error {
+		c.SSEvent(event.Type, event)
+		c.Writer.Flush()
+		return nil
+	})
+}
+
+// connect - registrácia klienta v hube s jeho aktuálnou zónou
+func (h *Handler) connect(c *gin.Context) (*Client, time.Time, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return nil, time.Time{}, false
+	}
+
+	// Spojenie žije len do expirácie access tokenu, potom sa klient pripojí s novým
+	deadline := time.Now().Add(15 * time.Minute)
+	if expires, ok := c.Get("token_expires"); ok {
+		deadline = expires.(time.Time)
+	}
+
+	var currentZone *uuid.UUID
+	var zoneID uuid.UUID
+	row := h.db.Table("auth.player_sessions").Select("current_zone").
+		Where("user_id = ? AND current_zone IS NOT NULL", userID).Limit(1).Row()
+	if row != nil && row.Scan(&zoneID) == nil {
+		currentZone = &zoneID
+	}
+
+	client := h.hub.Register(userID.(uuid.UUID), currentZone)
+	return client, deadline, true
+}
+
+// pump - posiela eventy, heartbeat a na konci session.expired
+func (h *Handler) pump(client *Client, deadline time.Time, done <-chan struct{}, send func(Event) error) {
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+	expiry := time.NewTimer(time.Until(deadline))
+	defer expiry.Stop()
+
+	if err := send(NewEvent(EventConnected, map[string]interface{}{
+		"user_id":    client.UserID,
+		"expires_at": deadline.Unix(),
+	})); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-client.Events():
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				if err != io.EOF {
+					log.Printf("⚠️ Realtime: send to %s failed: %v", client.UserID, err)
+				}
+				return
+			}
+		case <-heartbeat.C:
+			if err := send(NewEvent(EventHeartbeat, nil)); err != nil {
+				return
+			}
+		case <-expiry.C:
+			send(NewEvent(EventSessionExpired, nil))
+			return
+		}
+	}
+}
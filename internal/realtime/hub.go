@@ -0,0 +1,301 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Client - jedno otvorené spojenie (WebSocket alebo SSE)
+type Client struct {
+	UserID uuid.UUID
+	events chan Event
+}
+
+// Events - kanál eventov pre toto spojenie
+func (c *Client) Events() <-chan Event {
+	return c.events
+}
+
+// Hub - lokálni klienti tejto inštancie + fan-out cez Redis pub/sub
+type Hub struct {
+	redis *redis.Client
+
+	mu          sync.RWMutex
+	clients     map[uuid.UUID]map[*Client]struct{}
+	userZone    map[uuid.UUID]uuid.UUID
+	zoneMembers map[uuid.UUID]map[uuid.UUID]struct{}
+
+	onceMu sync.Mutex
+	once   map[string]time.Time // fallback pre Once bez Redis
+
+	cancel context.CancelFunc
+}
+
+func NewHub(redisClient *redis.Client) *Hub {
+	return &Hub{
+		redis:       redisClient,
+		clients:     make(map[uuid.UUID]map[*Client]struct{}),
+		userZone:    make(map[uuid.UUID]uuid.UUID),
+		zoneMembers: make(map[uuid.UUID]map[uuid.UUID]struct{}),
+		once:        make(map[string]time.Time),
+	}
+}
+
+// Start - odoberanie Redis kanála (bez Redis sa eventy doručujú len lokálne)
+func (h *Hub) Start() {
+	if h.redis == nil {
+		log.Println("⚠️  Realtime hub running without Redis - events stay on this instance")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	pubsub := h.redis.Subscribe(ctx, pubSubChannel)
+	go func() {
+		defer pubsub.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-pubsub.Channel():
+				if !ok {
+					return
+				}
+				var env envelope
+				if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+					log.Printf("⚠️ Realtime: invalid pub/sub payload: %v", err)
+					continue
+				}
+				h.dispatch(env)
+			}
+		}
+	}()
+
+	log.Printf("✅ Realtime hub subscribed to %s", pubSubChannel)
+}
+
+// Stop - ukončí odber Redis kanála
+func (h *Hub) Stop() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+}
+
+// Register - nové spojenie hráča; zoneID = zóna, v ktorej hráč práve je
+func (h *Hub) Register(userID uuid.UUID, zoneID *uuid.UUID) *Client {
+	client := &Client{
+		UserID: userID,
+		events: make(chan Event, clientBufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*Client]struct{})
+	}
+	h.clients[userID][client] = struct{}{}
+
+	if zoneID != nil {
+		h.setZoneLocked(userID, *zoneID)
+	}
+
+	return client
+}
+
+// Unregister - spojenie sa zatvorilo
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	conns := h.clients[client.UserID]
+	if _, ok := conns[client]; !ok {
+		return
+	}
+	delete(conns, client)
+	close(client.events)
+
+	if len(conns) == 0 {
+		delete(h.clients, client.UserID)
+		h.clearZoneLocked(client.UserID)
+	}
+}
+
+// ConnectedUsers - počet hráčov pripojených k tejto inštancii
+func (h *Hub) ConnectedUsers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// PublishToUser - event pre konkrétneho hráča (na ktorejkoľvek inštancii)
+func (h *Hub) PublishToUser(userID uuid.UUID, event Event) {
+	h.publish(envelope{UserID: &userID, Event: event})
+}
+
+// PublishToZone - event pre hráčov v zóne; subject (ak je) event nedostane
+func (h *Hub) PublishToZone(zoneID uuid.UUID, subject *uuid.UUID, exclude []uuid.UUID, event Event) {
+	h.publish(envelope{ZoneID: &zoneID, Subject: subject, Exclude: exclude, Event: event})
+}
+
+// Once - true len pri prvom volaní s daným kľúčom v rámci ttl (naprieč inštanciami)
+func (h *Hub) Once(key string, ttl time.Duration) bool {
+	if h.redis != nil {
+		ok, err := h.redis.SetNX(context.Background(), "realtime:once:"+key, 1, ttl).Result()
+		if err == nil {
+			return ok
+		}
+		log.Printf("⚠️ Realtime: once check failed, using local fallback: %v", err)
+	}
+
+	h.onceMu.Lock()
+	defer h.onceMu.Unlock()
+
+	now := time.Now()
+	for k, until := range h.once {
+		if now.After(until) {
+			delete(h.once, k)
+		}
+	}
+	if until, seen := h.once[key]; seen && now.Before(until) {
+		return false
+	}
+	h.once[key] = now.Add(ttl)
+	return true
+}
+
+func (h *Hub) publish(env envelope) {
+	if h.redis != nil {
+		payload, err := json.Marshal(env)
+		if err == nil {
+			err = h.redis.Publish(context.Background(), pubSubChannel, payload).Err()
+		}
+		if err == nil {
+			return
+		}
+		log.Printf("⚠️ Realtime: publish failed, delivering locally: %v", err)
+	}
+	h.dispatch(env)
+}
+
+// dispatch - doručenie eventu klientom pripojeným k tejto inštancii
+func (h *Hub) dispatch(env envelope) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if env.UserID != nil {
+		h.deliverLocked(*env.UserID, env.Event)
+	}
+
+	if env.ZoneID == nil {
+		return
+	}
+
+	// Zónové eventy zároveň udržiavajú prehľad, kto je v ktorej zóne
+	if env.Subject != nil {
+		switch env.Event.Type {
+		case EventPlayerEnteredZone:
+			if _, connected := h.clients[*env.Subject]; connected {
+				h.setZoneLocked(*env.Subject, *env.ZoneID)
+			}
+		case EventPlayerLeftZone:
+			if current, ok := h.userZone[*env.Subject]; ok && current == *env.ZoneID {
+				h.clearZoneLocked(*env.Subject)
+			}
+		}
+	}
+
+	excluded := make(map[uuid.UUID]struct{}, len(env.Exclude)+1)
+	for _, id := range env.Exclude {
+		excluded[id] = struct{}{}
+	}
+	if env.Subject != nil {
+		excluded[*env.Subject] = struct{}{}
+	}
+
+	for userID := range h.zoneMembers[*env.ZoneID] {
+		if _, skip := excluded[userID]; skip {
+			continue
+		}
+		h.deliverLocked(userID, env.Event)
+	}
+}
+
+func (h *Hub) deliverLocked(userID uuid.UUID, event Event) {
+	for client := range h.clients[userID] {
+		select {
+		case client.events <- event:
+		default:
+			// Pomalý klient - event zahodíme, nech neblokuje ostatných
+			log.Printf("⚠️ Realtime: dropping %s for user %s (buffer full)", event.Type, userID)
+		}
+	}
+}
+
+func (h *Hub) setZoneLocked(userID, zoneID uuid.UUID) {
+	h.clearZoneLocked(userID)
+	h.userZone[userID] = zoneID
+	if h.zoneMembers[zoneID] == nil {
+		h.zoneMembers[zoneID] = make(map[uuid.UUID]struct{})
+	}
+	h.zoneMembers[zoneID][userID] = struct{}{}
+}
+
+func (h *Hub) clearZoneLocked(userID uuid.UUID) {
+	zoneID, ok := h.userZone[userID]
+	if !ok {
+		return
+	}
+	delete(h.userZone, userID)
+	delete(h.zoneMembers[zoneID], userID)
+	if len(h.zoneMembers[zoneID]) == 0 {
+		delete(h.zoneMembers, zoneID)
+	}
+}
+
+// ==========================================
+// DEFAULT HUB (používajú ho ostatné balíčky)
+// ==========================================
+
+var defaultHub *Hub
+
+// SetDefault - nastaví hub pre NotifyUser / NotifyZone (volá sa v main.go)
+func SetDefault(hub *Hub) {
+	defaultHub = hub
+}
+
+// Default - aktuálny hub (nil ak realtime nie je zapnutý)
+func Default() *Hub {
+	return defaultHub
+}
+
+// NotifyUser - pošle event hráčovi; bez hubu nerobí nič
+func NotifyUser(userID uuid.UUID, eventType string, data map[string]interface{}) {
+	if defaultHub == nil {
+		return
+	}
+	defaultHub.PublishToUser(userID, NewEvent(eventType, data))
+}
+
+// NotifyZone - pošle event hráčom v zóne okrem subject a exclude
+func NotifyZone(zoneID uuid.UUID, subject *uuid.UUID, exclude []uuid.UUID, eventType string, data map[string]interface{}) {
+	if defaultHub == nil {
+		return
+	}
+	defaultHub.PublishToZone(zoneID, subject, exclude, NewEvent(eventType, data))
+}
+
+// Once - deduplikácia notifikácií (napr. varovanie o expirácii zóny raz za zónu)
+func Once(key string, ttl time.Duration) bool {
+	if defaultHub == nil {
+		return false
+	}
+	return defaultHub.Once(key, ttl)
+}
@@ -7,18 +7,28 @@ import (
 	"strings"
 	"time"
 
+	"geoanomaly/internal/locationhistory"
+	"geoanomaly/internal/movement"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Handler struct {
-	service *Service
-	db      *gorm.DB
+	service  *Service
+	db       *gorm.DB
+	history  *locationhistory.Service
+	movement *movement.Service
 }
 
 func NewHandler(service *Service, db *gorm.DB) *Handler {
-	return &Handler{service: service, db: db}
+	return &Handler{
+		service:  service,
+		db:       db,
+		history:  locationhistory.NewService(db),
+		movement: movement.NewService(db),
+	}
 }
 
 // GetScannerInstance - vráti scanner inštanciu hráča
@@ -67,6 +77,14 @@ func (h *Handler) Scan(c *gin.Context) {
 		return
 	}
 
+	// Kontrola hodnovernosti pohybu - teleportovaná poloha sa do session nezapíše
+	if _, ok := h.movement.Enforce(c, userUUID, movement.SourceScanner, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}); !ok {
+		return
+	}
+
 	// ✅ NOVÉ: Aktualizuj polohu v player_sessions
 	h.updatePlayerLocation(userUUID, req.Latitude, req.Longitude)
 
@@ -114,6 +132,8 @@ func (h *Handler) updatePlayerLocation(userID uuid.UUID, latitude, longitude flo
 	} else {
 		log.Printf("📍 Player location updated for user %s: [%.6f, %.6f]", userID, latitude, longitude)
 	}
+
+	h.history.Record(userID, latitude, longitude, 0, 0, 0, nil, locationhistory.SourceScanner)
 }
 
 // GetScannerStats - vráti stats scanner
@@ -6,9 +6,13 @@ import (
 	"strconv"
 	"time"
 
+	"geoanomaly/internal/audit"
 	"geoanomaly/internal/auth"
 	"geoanomaly/internal/common"
 	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/location"
+	"geoanomaly/internal/locationhistory"
+	"geoanomaly/internal/movement"
 	"geoanomaly/pkg/redis"
 
 	"github.com/gin-gonic/gin"
@@ -18,8 +22,10 @@ import (
 )
 
 type Handler struct {
-	db    *gorm.DB
-	redis *redis_client.Client
+	db        *gorm.DB
+	redis     *redis_client.Client
+	audit     *audit.Service
+	locations *location.Handler
 }
 
 type UpdateProfileRequest struct {
@@ -28,9 +34,9 @@ type UpdateProfileRequest struct {
 }
 
 type UpdateLocationRequest struct {
-	Latitude  float64 `json:"latitude" binding:"required,min=-90,max=90"`
-	Longitude float64 `json:"longitude" binding:"required,min=-180,max=180"`
-	Accuracy  float64 `json:"accuracy,omitempty"`
+	Latitude  float64  `json:"latitude" binding:"required,min=-90,max=90"`
+	Longitude float64  `json:"longitude" binding:"required,min=-180,max=180"`
+	Accuracy  *float64 `json:"accuracy,omitempty"`
 }
 
 // ✅ OPRAVENÉ: Pridané TierExpires pole
@@ -59,6 +65,9 @@ func NewHandler(db *gorm.DB, redisClient *redis_client.Client) *Handler {
 	return &Handler{
 		db:    db,
 		redis: redisClient,
+		audit: audit.NewService(db),
+		// História polohy je spoločná s /location endpointmi
+		locations: location.NewHandler(db, redisClient),
 	}
 }
 
@@ -278,11 +287,30 @@ func (h *Handler) UpdateLocation(c *gin.Context) {
 		return
 	}
 
+	// Kontrola hodnovernosti pohybu - teleport sa neuloží
+	verdict := h.locations.Movement().Check(userID.(uuid.UUID), movement.SourceUserLocation, movement.Fix{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Accuracy:  req.Accuracy,
+	})
+	if verdict.Rejected {
+		movement.Abort(c, verdict)
+		return
+	}
+
+	accuracy := 0.0
+	if req.Accuracy != nil {
+		accuracy = *req.Accuracy
+	}
+
+	// Pridaj bod do location histórie
+	h.locations.History().Record(userID.(uuid.UUID), req.Latitude, req.Longitude, accuracy, 0, 0, nil, locationhistory.SourceUserUpdate)
+
 	// ✅ OPRAVENÉ: Vytvor LocationWithAccuracy object
 	location := common.LocationWithAccuracy{
 		Latitude:  req.Latitude,
 		Longitude: req.Longitude,
-		Accuracy:  req.Accuracy,
+		Accuracy:  accuracy,
 		Timestamp: time.Now(),
 	}
 
@@ -399,10 +427,11 @@ func (h *Handler) GetInventoryByType(c *gin.Context) {
 }
 
 func (h *Handler) GetLocationHistory(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Location history not implemented yet",
-		"status": "planned",
-	})
+	h.locations.GetLocationHistory(c)
+}
+
+func (h *Handler) DeleteLocationHistory(c *gin.Context) {
+	h.locations.DeleteLocationHistory(c)
 }
 
 func (h *Handler) GetUserStats(c *gin.Context) {
@@ -417,7 +446,7 @@ func (h *Handler) GetUserStats(c *gin.Context) {
 func (h *Handler) GetAllUsers(c *gin.Context) {
 	// Super Admin only - get all users
 	var users []auth.User
-	if err := h.db.Select("id, username, email, tier, is_active, is_banned, created_at, updated_at, xp, level, total_artifacts, total_gear").Find(&users).Error; err != nil {
+	if err := h.db.Select("id, username, email, tier, tier_expires, is_active, is_banned, ban_reason, banned_until, created_at, updated_at, xp, level, total_artifacts, total_gear").Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to fetch users",
 			"details": err.Error(),
@@ -442,31 +471,3 @@ func (h *Handler) GetAllUsers(c *gin.Context) {
 		"admin_level":  c.GetString("admin_level"),
 	})
 }
-
-func (h *Handler) UpdateUserTier(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Update user tier not implemented yet",
-		"status": "planned",
-	})
-}
-
-func (h *Handler) BanUser(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Ban user not implemented yet",
-		"status": "planned",
-	})
-}
-
-func (h *Handler) UnbanUser(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Unban user not implemented yet",
-		"status": "planned",
-	})
-}
-
-func (h *Handler) GetPlayerAnalytics(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error":  "Player analytics not implemented yet",
-		"status": "planned",
-	})
-}
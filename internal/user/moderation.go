@@ -0,0 +1,460 @@
+package user
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"geoanomaly/internal/audit"
+	"geoanomaly/internal/auth"
+	"geoanomaly/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// Tier od ktorého má používateľ admin práva (middleware.AdminOnly)
+	adminTier = 4
+	// Tier super admina - jediný môže udeľovať/odoberať admin tiery
+	superAdminTier = 5
+	maxTier        = 5
+
+	// Moderátori môžu dávať len dočasné bany, najviac na 30 dní
+	MaxModeratorBanDuration = 30 * 24 * time.Hour
+)
+
+type BanUserRequest struct {
+	Reason        string `json:"reason" binding:"required,min=3,max=500"`
+	DurationHours int    `json:"duration_hours,omitempty" binding:"omitempty,min=1"` // 0 = permanentný ban (len admin)
+}
+
+type UnbanUserRequest struct {
+	Reason string `json:"reason,omitempty" binding:"omitempty,max=500"`
+}
+
+type UpdateTierRequest struct {
+	Tier        *int       `json:"tier" binding:"required,min=0,max=5"`
+	TierExpires *time.Time `json:"tier_expires,omitempty"`
+	Reason      string     `json:"reason,omitempty" binding:"omitempty,max=500"`
+}
+
+var (
+	banHook    func(userID uuid.UUID)
+	changeHook func(userID uuid.UUID)
+	tierHook   func(userID uuid.UUID, tier int)
+)
+
+// SetModerationHooks - onBan sa volá po bane (napr. odstránenie z leaderboardov),
+// onChange po unbane a zmene tieru (prepočet leaderboardov)
+func SetModerationHooks(onBan, onChange func(userID uuid.UUID)) {
+	banHook = onBan
+	changeHook = onChange
+}
+
+// SetTierChangeHook - volá sa po zmene tieru adminom (napr. zmrazenie zariadení nad limit nového tieru)
+func SetTierChangeHook(fn func(userID uuid.UUID, tier int)) {
+	tierHook = fn
+}
+
+// actor - kto vykonáva admin/moderátorskú akciu
+type actor struct {
+	ID       uuid.UUID
+	Username string
+	Tier     int
+}
+
+func actorFromContext(c *gin.Context) (actor, bool) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		return actor{}, false
+	}
+	id, ok := userID.(uuid.UUID)
+	if !ok {
+		return actor{}, false
+	}
+	tier, _ := c.Get("tier")
+	tierInt, _ := tier.(int)
+	return actor{ID: id, Username: c.GetString("username"), Tier: tierInt}, true
+}
+
+// moderationSnapshot - stav účtu pre before/after v audit zázname
+func moderationSnapshot(u *auth.User) auth.JSONB {
+	return auth.JSONB{
+		"tier":         u.Tier,
+		"tier_expires": u.TierExpires,
+		"is_banned":    u.IsBanned,
+		"ban_reason":   u.BanReason,
+		"banned_until": u.BannedUntil,
+	}
+}
+
+// loadTarget - načíta cieľového používateľa z :id a odmietne akciu nad sebou samým
+func (h *Handler) loadTarget(c *gin.Context, act actor) (*auth.User, bool) {
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return nil, false
+	}
+	if targetID == act.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot perform this action on your own account"})
+		return nil, false
+	}
+
+	var target auth.User
+	if err := h.db.First(&target, "id = ?", targetID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+	return &target, true
+}
+
+// BanUser - zablokuje účet (admin: dočasne aj natrvalo, moderátor: len dočasne)
+func (h *Handler) BanUser(c *gin.Context) {
+	act, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	target, ok := h.loadTarget(c, act)
+	if !ok {
+		return
+	}
+
+	// Nikto nemôže banovať rovnaký alebo vyšší tier
+	if target.Tier >= act.Tier {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":       "Cannot ban a user with equal or higher tier",
+			"target_tier": target.Tier,
+			"your_tier":   act.Tier,
+		})
+		return
+	}
+
+	duration := time.Duration(req.DurationHours) * time.Hour
+	isModerator := act.Tier < adminTier
+	if isModerator {
+		if duration == 0 {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "Moderators can only issue temporary bans",
+				"message": "Specify duration_hours",
+			})
+			return
+		}
+		if duration > MaxModeratorBanDuration {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":              "Ban duration too long",
+				"max_duration_hours": int(MaxModeratorBanDuration.Hours()),
+			})
+			return
+		}
+		// Moderátor nesmie skrátiť permanentný ban na dočasný
+		if target.IsBanActive(time.Now()) && target.BannedUntil == nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "User is permanently banned; only an admin can change it"})
+			return
+		}
+	}
+
+	now := time.Now()
+	var bannedUntil *time.Time
+	if duration > 0 {
+		until := now.Add(duration)
+		bannedUntil = &until
+	}
+
+	before := moderationSnapshot(target)
+	var record audit.AdminAction
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(target).Updates(map[string]interface{}{
+			"is_banned":    true,
+			"ban_reason":   req.Reason,
+			"banned_at":    now,
+			"banned_until": bannedUntil,
+			"banned_by":    act.ID,
+		}).Error; err != nil {
+			return err
+		}
+		target.IsBanned = true
+		target.BanReason = req.Reason
+		target.BannedAt = &now
+		target.BannedUntil = bannedUntil
+		target.BannedBy = &act.ID
+
+		record = audit.AdminAction{
+			ActorID:       act.ID,
+			ActorUsername: act.Username,
+			Action:        audit.ActionBanUser,
+			TargetType:    audit.TargetUser,
+			TargetID:      &target.ID,
+			Before:        before,
+			After:         moderationSnapshot(target),
+			Reason:        req.Reason,
+			Metadata: auth.JSONB{
+				"duration_hours": req.DurationHours,
+				"permanent":      bannedUntil == nil,
+				"actor_tier":     act.Tier,
+			},
+		}
+		return h.audit.Record(tx, &record)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ban user"})
+		return
+	}
+
+	// Okamžite odpoj všetky zariadenia a označ ban pre JWTAuth
+	sessionsRevoked := true
+	if err := auth.RevokeUserSessions(h.db, target.ID, auth.SessionRevokedBanned); err != nil {
+		log.Printf("⚠️ Failed to revoke sessions for user %s: %v", target.ID, err)
+		sessionsRevoked = false
+	}
+	if err := middleware.MarkUserBanned(target.ID, bannedUntil); err != nil {
+		log.Printf("⚠️ Failed to mark user %s as banned: %v", target.ID, err)
+	}
+	if banHook != nil {
+		banHook(target.ID)
+	}
+
+	log.Printf("🔨 User %s banned by %s (until: %v, reason: %s)", target.Username, act.Username, bannedUntil, req.Reason)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":          true,
+		"message":          "User banned",
+		"user_id":          target.ID,
+		"username":         target.Username,
+		"reason":           req.Reason,
+		"banned_until":     bannedUntil,
+		"permanent":        bannedUntil == nil,
+		"sessions_revoked": sessionsRevoked,
+		"audit_id":         record.ID,
+		"timestamp":        now.Format(time.RFC3339),
+	})
+}
+
+// UnbanUser - zruší ban (len admin)
+func (h *Handler) UnbanUser(c *gin.Context) {
+	act, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req UnbanUserRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	target, ok := h.loadTarget(c, act)
+	if !ok {
+		return
+	}
+
+	if !target.IsBanned {
+		c.JSON(http.StatusConflict, gin.H{"error": "User is not banned"})
+		return
+	}
+
+	record, err := h.liftBan(target, act.ID, act.Username, audit.ActionUnbanUser, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unban user"})
+		return
+	}
+
+	log.Printf("🔓 User %s unbanned by %s", target.Username, act.Username)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"message":   "User unbanned",
+		"user_id":   target.ID,
+		"username":  target.Username,
+		"audit_id":  record.ID,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
+
+// liftBan - zruší ban v DB, zapíše audit a odstráni ban značku
+func (h *Handler) liftBan(target *auth.User, actorID uuid.UUID, actorUsername, action, reason string) (*audit.AdminAction, error) {
+	before := moderationSnapshot(target)
+	var record audit.AdminAction
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(target).Updates(map[string]interface{}{
+			"is_banned":    false,
+			"ban_reason":   "",
+			"banned_at":    nil,
+			"banned_until": nil,
+			"banned_by":    nil,
+		}).Error; err != nil {
+			return err
+		}
+		target.IsBanned = false
+		target.BanReason = ""
+		target.BannedAt = nil
+		target.BannedUntil = nil
+		target.BannedBy = nil
+
+		record = audit.AdminAction{
+			ActorID:       actorID,
+			ActorUsername: actorUsername,
+			Action:        action,
+			TargetType:    audit.TargetUser,
+			TargetID:      &target.ID,
+			Before:        before,
+			After:         moderationSnapshot(target),
+			Reason:        reason,
+		}
+		return h.audit.Record(tx, &record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := middleware.ClearUserBan(target.ID); err != nil {
+		log.Printf("⚠️ Failed to clear ban flag for user %s: %v", target.ID, err)
+	}
+	if changeHook != nil {
+		changeHook(target.ID)
+	}
+	return &record, nil
+}
+
+// LiftExpiredBans - automaticky zruší dočasné bany, ktorým vypršal čas (volá scheduler)
+func LiftExpiredBans(db *gorm.DB) (int, error) {
+	var users []auth.User
+	if err := db.Where("is_banned = true AND banned_until IS NOT NULL AND banned_until <= ?", time.Now()).
+		Find(&users).Error; err != nil {
+		return 0, err
+	}
+
+	h := NewHandler(db, nil)
+	lifted := 0
+	for i := range users {
+		if _, err := h.liftBan(&users[i], uuid.Nil, audit.SystemActor, audit.ActionAutoUnban, "ban expired"); err != nil {
+			log.Printf("❌ Failed to lift expired ban for %s: %v", users[i].Username, err)
+			continue
+		}
+		lifted++
+	}
+
+	if lifted > 0 {
+		log.Printf("🔓 Lifted %d expired bans", lifted)
+	}
+	return lifted, nil
+}
+
+// UpdateUserTier - zmena tieru používateľa (admin tiery môže meniť len super admin)
+func (h *Handler) UpdateUserTier(c *gin.Context) {
+	act, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req UpdateTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	newTier := *req.Tier
+	if newTier < 0 || newTier > maxTier {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Tier must be between 0 and %d", maxTier)})
+		return
+	}
+	if req.TierExpires != nil && req.TierExpires.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tier_expires must be in the future"})
+		return
+	}
+
+	target, ok := h.loadTarget(c, act)
+	if !ok {
+		return
+	}
+
+	if (newTier >= adminTier || target.Tier >= adminTier) && act.Tier < superAdminTier {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":         "Super Admin access required",
+			"message":       "Only Super Admin can grant or revoke admin tiers",
+			"required_tier": superAdminTier,
+			"your_tier":     act.Tier,
+		})
+		return
+	}
+
+	before := moderationSnapshot(target)
+	var record audit.AdminAction
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(target).Updates(map[string]interface{}{
+			"tier":         newTier,
+			"tier_expires": req.TierExpires,
+		}).Error; err != nil {
+			return err
+		}
+		target.Tier = newTier
+		target.TierExpires = req.TierExpires
+
+		record = audit.AdminAction{
+			ActorID:       act.ID,
+			ActorUsername: act.Username,
+			Action:        audit.ActionUpdateTier,
+			TargetType:    audit.TargetUser,
+			TargetID:      &target.ID,
+			Before:        before,
+			After:         moderationSnapshot(target),
+			Reason:        req.Reason,
+		}
+		return h.audit.Record(tx, &record)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tier"})
+		return
+	}
+
+	// Tier je v access tokene - staré tokeny zneplatníme, klient si cez refresh vezme nový tier
+	if err := middleware.RevokeAllUserTokens(target.ID); err != nil {
+		log.Printf("⚠️ Failed to revoke access tokens for user %s after tier change: %v", target.ID, err)
+	}
+	if changeHook != nil {
+		changeHook(target.ID)
+	}
+	if tierHook != nil {
+		tierHook(target.ID, newTier)
+	}
+
+	log.Printf("🎖️ Tier of %s changed %v → %d by %s", target.Username, before["tier"], newTier, act.Username)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"message":      "User tier updated",
+		"user_id":      target.ID,
+		"username":     target.Username,
+		"old_tier":     before["tier"],
+		"new_tier":     newTier,
+		"tier_expires": req.TierExpires,
+		"audit_id":     record.ID,
+		"timestamp":    time.Now().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,201 @@
+package user
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"geoanomaly/internal/audit"
+	"geoanomaly/internal/auth"
+	"geoanomaly/internal/movement"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMovementListLimit = 50
+	maxMovementListLimit     = 200
+)
+
+type ClearMovementRequest struct {
+	Reason string `json:"reason,omitempty" binding:"omitempty,max=500"`
+}
+
+// movementSnapshot - stav anti-spoofing profilu pre before/after v audit zázname
+func movementSnapshot(p *movement.Profile) auth.JSONB {
+	if p == nil {
+		return auth.JSONB{}
+	}
+	return auth.JSONB{
+		"score":            p.Score,
+		"level":            p.Level,
+		"restricted_until": p.RestrictedUntil,
+		"total_flags":      p.TotalFlags,
+	}
+}
+
+// GetFlaggedMovement - hráči s podozrivým pohybom (GET /admin/movement/flagged)
+func (h *Handler) GetFlaggedMovement(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultMovementListLimit)))
+	if limit <= 0 || limit > maxMovementListLimit {
+		limit = defaultMovementListLimit
+	}
+
+	profiles, err := h.locations.Movement().Flagged(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load flagged players"})
+		return
+	}
+
+	userIDs := make([]interface{}, 0, len(profiles))
+	for _, p := range profiles {
+		userIDs = append(userIDs, p.UserID)
+	}
+	usernames := map[string]string{}
+	if len(userIDs) > 0 {
+		var users []auth.User
+		h.db.Select("id, username").Where("id IN ?", userIDs).Find(&users)
+		for _, u := range users {
+			usernames[u.ID.String()] = u.Username
+		}
+	}
+
+	now := time.Now()
+	players := make([]gin.H, 0, len(profiles))
+	for i := range profiles {
+		p := &profiles[i]
+		players = append(players, gin.H{
+			"user_id":          p.UserID,
+			"username":         usernames[p.UserID.String()],
+			"score":            p.Score,
+			"total_flags":      p.TotalFlags,
+			"level":            p.ActiveLevel(now),
+			"restricted_until": p.RestrictedUntil,
+			"last_fix_at":      p.LastFixAt,
+			"last_source":      p.LastSource,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"players":   players,
+		"count":     len(players),
+		"timestamp": now.Format(time.RFC3339),
+	})
+}
+
+// GetUserMovement - anti-spoofing profil a posledné porušenia hráča (GET /admin/users/:id/movement)
+func (h *Handler) GetUserMovement(c *gin.Context) {
+	act, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	target, ok := h.loadTarget(c, act)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultMovementListLimit)))
+	if limit <= 0 || limit > maxMovementListLimit {
+		limit = defaultMovementListLimit
+	}
+
+	service := h.locations.Movement()
+	profile, err := service.Profile(target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load movement profile"})
+		return
+	}
+	violations, err := service.Violations(target.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load movement violations"})
+		return
+	}
+
+	level := movement.LevelNone
+	if profile != nil {
+		level = profile.ActiveLevel(time.Now())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":    target.ID,
+		"username":   target.Username,
+		"profile":    profile,
+		"level":      level,
+		"violations": violations,
+	})
+}
+
+// ClearUserMovement - zruší skóre a shadow režim / soft ban (POST /admin/users/:id/movement/clear)
+func (h *Handler) ClearUserMovement(c *gin.Context) {
+	act, ok := actorFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var req ClearMovementRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	target, ok := h.loadTarget(c, act)
+	if !ok {
+		return
+	}
+
+	service := h.locations.Movement()
+	profile, err := service.Profile(target.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load movement profile"})
+		return
+	}
+	if profile == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User has no movement profile"})
+		return
+	}
+
+	before := movementSnapshot(profile)
+	var record audit.AdminAction
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := service.Clear(tx, target.ID); err != nil {
+			return err
+		}
+		record = audit.AdminAction{
+			ActorID:       act.ID,
+			ActorUsername: act.Username,
+			Action:        audit.ActionClearMovement,
+			TargetType:    audit.TargetUser,
+			TargetID:      &target.ID,
+			Before:        before,
+			After:         auth.JSONB{"score": 0, "level": movement.LevelNone, "restricted_until": nil},
+			Reason:        req.Reason,
+		}
+		return h.audit.Record(tx, &record)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear movement restriction"})
+		return
+	}
+
+	log.Printf("🧹 Movement restriction of %s cleared by %s", target.Username, act.Username)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"message":   "Movement restriction cleared",
+		"user_id":   target.ID,
+		"username":  target.Username,
+		"audit_id":  record.ID,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
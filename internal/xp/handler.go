@@ -3,6 +3,7 @@ package xp
 import (
 	"fmt"
 	"geoanomaly/internal/auth"
+	"geoanomaly/internal/leaderboard"
 	"log"
 	"time"
 
@@ -11,13 +12,20 @@ import (
 )
 
 type Handler struct {
-	db *gorm.DB
+	db          *gorm.DB
+	leaderboard *leaderboard.Service
 }
 
 func NewHandler(db *gorm.DB) *Handler {
 	return &Handler{db: db}
 }
 
+// WithLeaderboard - XP zisky sa budú priebežne zapisovať aj do leaderboardov
+func (h *Handler) WithLeaderboard(lb *leaderboard.Service) *Handler {
+	h.leaderboard = lb
+	return h
+}
+
 // ✅ MAIN: Award XP for artifact collection
 func (h *Handler) AwardArtifactXP(userID uuid.UUID, rarity, biome string, zoneTier int) (*XPResult, error) {
 	// Get current user
@@ -60,6 +68,10 @@ func (h *Handler) AwardArtifactXP(userID uuid.UUID, rarity, biome string, zoneTi
 		log.Printf("🎉 LEVEL UP! User %s: %d → %d (XP: %d → %d)", userID, oldLevel, newLevel, oldXP, newXP)
 	}
 
+	if h.leaderboard != nil {
+		h.leaderboard.RecordXP(userID, xpGained, biome)
+	}
+
 	result := &XPResult{
 		XPGained:     xpGained,
 		TotalXP:      newXP,
@@ -193,6 +205,10 @@ func (h *Handler) AwardLaboratoryXP(userID uuid.UUID, activity string, amount in
 			userID, oldLevel, newLevel, oldXP, newXP, activity)
 	}
 
+	if h.leaderboard != nil {
+		h.leaderboard.RecordXP(userID, amount, "")
+	}
+
 	// Create laboratory-specific breakdown
 	breakdown := XPBreakdown{
 		BaseXP:      amount,
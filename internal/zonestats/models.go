@@ -0,0 +1,139 @@
+package zonestats
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Dôvody ukončenia návštevy zóny
+const (
+	EndExit       = "exit"          // hráč zavolal /exit
+	EndLeftArea   = "left_area"     // location update mimo zóny
+	EndSwitched   = "switched_zone" // vstup do inej zóny bez exit
+	EndZoneClosed = "zone_closed"   // cleanup / admin delete
+)
+
+const (
+	// MaxSessionDuration - návšteva bez exit (zabitá appka) sa do priemeru započíta max. touto dĺžkou
+	MaxSessionDuration = 4 * time.Hour
+)
+
+// ZoneStats - súhrnné počítadlá zóny; riadok ostáva aj po cleanupe zóny (bez FK, so snapshotom atribútov)
+type ZoneStats struct {
+	ZoneID uuid.UUID `json:"zone_id" gorm:"type:uuid;primaryKey"`
+
+	// Snapshot zóny - pre porovnanie biomov a tierov aj po jej odstránení
+	Name          string    `json:"name" gorm:"size:100"`
+	Biome         string    `json:"biome" gorm:"size:50;index"`
+	DangerLevel   string    `json:"danger_level" gorm:"size:20"`
+	TierRequired  int       `json:"tier_required" gorm:"index"`
+	ZoneType      string    `json:"zone_type" gorm:"size:20"`
+	ZoneCreatedAt time.Time `json:"zone_created_at" gorm:"index"`
+
+	Visits              int64      `json:"visits" gorm:"not null;default:0"`
+	UniqueVisitors      int64      `json:"unique_visitors" gorm:"not null;default:0"`
+	CompletedSessions   int64      `json:"completed_sessions" gorm:"not null;default:0"`
+	TotalSessionSeconds int64      `json:"total_session_seconds" gorm:"not null;default:0"`
+	LastVisitAt         *time.Time `json:"last_visit_at,omitempty"`
+
+	ArtifactsSpawned   int64 `json:"artifacts_spawned" gorm:"not null;default:0"`
+	ArtifactsCollected int64 `json:"artifacts_collected" gorm:"not null;default:0"`
+	GearSpawned        int64 `json:"gear_spawned" gorm:"not null;default:0"`
+	GearCollected      int64 `json:"gear_collected" gorm:"not null;default:0"`
+
+	// Prvé vyzbieranie všetkých itemov zóny
+	DepletedAt             *time.Time `json:"depleted_at,omitempty"`
+	TimeToDepletionSeconds *int64     `json:"time_to_depletion_seconds,omitempty"`
+
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	CloseReason string     `json:"close_reason,omitempty" gorm:"size:30"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (ZoneStats) TableName() string {
+	return "gameplay.zone_stats"
+}
+
+// AverageSessionSeconds - priemerná dĺžka ukončenej návštevy
+func (s *ZoneStats) AverageSessionSeconds() float64 {
+	if s.CompletedSessions == 0 {
+		return 0
+	}
+	return float64(s.TotalSessionSeconds) / float64(s.CompletedSessions)
+}
+
+// RarityStats - spawnuté vs. vyzbierané itemy zóny podľa typu a rarity
+type RarityStats struct {
+	ZoneID    uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	ItemType  string    `json:"item_type" gorm:"size:20;primaryKey"`
+	Rarity    string    `json:"rarity" gorm:"size:20;primaryKey"`
+	Spawned   int64     `json:"spawned" gorm:"not null;default:0"`
+	Collected int64     `json:"collected" gorm:"not null;default:0"`
+}
+
+func (RarityStats) TableName() string {
+	return "gameplay.zone_rarity_stats"
+}
+
+// Visit - jedna návšteva hráča v zóne (enter → exit)
+type Visit struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ZoneID          uuid.UUID  `json:"zone_id" gorm:"type:uuid;not null;index:idx_zone_visits_zone_user,priority:1"`
+	UserID          uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_zone_visits_zone_user,priority:2;index"`
+	EnteredAt       time.Time  `json:"entered_at" gorm:"not null"`
+	ExitedAt        *time.Time `json:"exited_at,omitempty" gorm:"index"`
+	DurationSeconds *int64     `json:"duration_seconds,omitempty"`
+	ItemsCollected  int        `json:"items_collected" gorm:"not null;default:0"`
+	EndReason       string     `json:"end_reason,omitempty" gorm:"size:20"`
+}
+
+func (Visit) TableName() string {
+	return "gameplay.zone_visits"
+}
+
+// ItemCounts - spawned/collected pár pre API
+type ItemCounts struct {
+	Spawned   int64 `json:"spawned"`
+	Collected int64 `json:"collected"`
+}
+
+// ZoneReport - štatistiky jednej zóny pre API
+type ZoneReport struct {
+	ZoneStats
+	IsClosed              bool                             `json:"is_closed"`
+	AverageSessionSeconds float64                          `json:"average_session_seconds"`
+	ActiveVisitors        int64                            `json:"active_visitors"`
+	CollectionRate        float64                          `json:"collection_rate"`
+	ByRarity              map[string]map[string]ItemCounts `json:"by_rarity"`
+}
+
+// Filter - obmedzenie agregovaných štatistík
+type Filter struct {
+	Biome    string
+	Tier     *int
+	ZoneType string
+	From     *time.Time // zóny vytvorené od (vrátane)
+	To       *time.Time // zóny vytvorené pred
+}
+
+// AggregateRow - súčty za skupinu zón (biome, tier, ...)
+type AggregateRow struct {
+	Key                       string                           `json:"key"`
+	Zones                     int64                            `json:"zones"`
+	Visits                    int64                            `json:"visits"`
+	UniqueVisitors            int64                            `json:"unique_visitors"`
+	CompletedSessions         int64                            `json:"completed_sessions"`
+	TotalSessionSeconds       int64                            `json:"-"`
+	AverageSessionSeconds     float64                          `json:"average_session_seconds"`
+	ArtifactsSpawned          int64                            `json:"artifacts_spawned"`
+	ArtifactsCollected        int64                            `json:"artifacts_collected"`
+	GearSpawned               int64                            `json:"gear_spawned"`
+	GearCollected             int64                            `json:"gear_collected"`
+	CollectionRate            float64                          `json:"collection_rate"`
+	DepletedZones             int64                            `json:"depleted_zones"`
+	AvgTimeToDepletionSeconds *float64                         `json:"avg_time_to_depletion_seconds,omitempty"`
+	ByRarity                  map[string]map[string]ItemCounts `json:"by_rarity" gorm:"-"`
+}
@@ -0,0 +1,422 @@
+package zonestats
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"geoanomaly/internal/gameplay"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Typy itemov
+const (
+	ItemArtifact = "artifact"
+	ItemGear     = "gear"
+)
+
+var (
+	ErrNotFound       = errors.New("zone stats not found")
+	ErrInvalidGroupBy = errors.New("invalid group_by")
+)
+
+// Povolené stĺpce pre group_by (whitelist, ide priamo do SQL)
+var groupColumns = map[string]string{
+	"biome":        "biome",
+	"tier":         "tier_required",
+	"zone_type":    "zone_type",
+	"danger_level": "danger_level",
+}
+
+// Service - zber a čítanie štatistík zón; chyby zápisu sa len logujú, hru nesmú zhodiť
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// ensure - riadok štatistík so snapshotom zóny (ak ešte neexistuje)
+func ensure(tx *gorm.DB, zone gameplay.Zone) error {
+	stats := snapshot(zone)
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&stats).Error
+}
+
+func snapshot(zone gameplay.Zone) ZoneStats {
+	createdAt := zone.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return ZoneStats{
+		ZoneID:        zone.ID,
+		Name:          zone.Name,
+		Biome:         zone.Biome,
+		DangerLevel:   zone.DangerLevel,
+		TierRequired:  zone.TierRequired,
+		ZoneType:      zone.ZoneType,
+		ZoneCreatedAt: createdAt,
+	}
+}
+
+func spawnedColumn(itemType string) string {
+	if itemType == ItemGear {
+		return "gear_spawned"
+	}
+	return "artifacts_spawned"
+}
+
+func collectedColumn(itemType string) string {
+	if itemType == ItemGear {
+		return "gear_collected"
+	}
+	return "artifacts_collected"
+}
+
+// bumpRarity - atomický upsert počítadla podľa rarity
+func bumpRarity(tx *gorm.DB, zoneID uuid.UUID, itemType, rarity, column string) error {
+	if rarity == "" {
+		rarity = "unknown"
+	}
+	row := RarityStats{ZoneID: zoneID, ItemType: itemType, Rarity: rarity}
+	if column == "spawned" {
+		row.Spawned = 1
+	} else {
+		row.Collected = 1
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "zone_id"}, {Name: "item_type"}, {Name: "rarity"}},
+		DoUpdates: clause.Set{{
+			Column: clause.Column{Name: column},
+			Value:  gorm.Expr("gameplay.zone_rarity_stats."+column+" + ?", 1),
+		}},
+	}).Create(&row).Error
+}
+
+// RecordSpawn - nový artefakt/gear v zóne
+func (s *Service) RecordSpawn(zone gameplay.Zone, itemType, rarity string) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensure(tx, zone); err != nil {
+			return err
+		}
+		if err := tx.Model(&ZoneStats{}).Where("zone_id = ?", zone.ID).
+			Update(spawnedColumn(itemType), gorm.Expr(spawnedColumn(itemType)+" + 1")).Error; err != nil {
+			return err
+		}
+		return bumpRarity(tx, zone.ID, itemType, rarity, "spawned")
+	})
+	if err != nil {
+		log.Printf("⚠️ Zone stats: failed to record spawn in %s: %v", zone.ID, err)
+	}
+}
+
+// RecordCollect - hráč vyzbieral item; pri poslednom iteme zapíše time-to-depletion
+func (s *Service) RecordCollect(zone gameplay.Zone, userID uuid.UUID, itemType, rarity string) {
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensure(tx, zone); err != nil {
+			return err
+		}
+		if err := tx.Model(&ZoneStats{}).Where("zone_id = ?", zone.ID).
+			Update(collectedColumn(itemType), gorm.Expr(collectedColumn(itemType)+" + 1")).Error; err != nil {
+			return err
+		}
+		if err := bumpRarity(tx, zone.ID, itemType, rarity, "collected"); err != nil {
+			return err
+		}
+		if err := tx.Model(&Visit{}).
+			Where("zone_id = ? AND user_id = ? AND exited_at IS NULL", zone.ID, userID).
+			Update("items_collected", gorm.Expr("items_collected + 1")).Error; err != nil {
+			return err
+		}
+
+		var remaining int64
+		tx.Model(&gameplay.Artifact{}).Where("zone_id = ? AND is_active = true", zone.ID).Count(&remaining)
+		if remaining > 0 {
+			return nil
+		}
+		tx.Model(&gameplay.Gear{}).Where("zone_id = ? AND is_active = true", zone.ID).Count(&remaining)
+		if remaining > 0 {
+			return nil
+		}
+
+		return tx.Model(&ZoneStats{}).
+			Where("zone_id = ? AND depleted_at IS NULL", zone.ID).
+			Updates(map[string]interface{}{
+				"depleted_at":               now,
+				"time_to_depletion_seconds": gorm.Expr("GREATEST(0, EXTRACT(EPOCH FROM (? - zone_created_at)))::bigint", now),
+			}).Error
+	})
+	if err != nil {
+		log.Printf("⚠️ Zone stats: failed to record collection in %s: %v", zone.ID, err)
+	}
+}
+
+// StartVisit - vstup do zóny; rozbehnutá návšteva inej zóny sa ukončí
+func (s *Service) StartVisit(zone gameplay.Zone, userID uuid.UUID) {
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := endVisits(tx, tx.Where("user_id = ? AND exited_at IS NULL", userID), EndSwitched, now); err != nil {
+			return err
+		}
+		if err := ensure(tx, zone); err != nil {
+			return err
+		}
+
+		var seen int64
+		tx.Model(&Visit{}).Where("zone_id = ? AND user_id = ?", zone.ID, userID).Limit(1).Count(&seen)
+
+		if err := tx.Create(&Visit{ZoneID: zone.ID, UserID: userID, EnteredAt: now}).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{
+			"visits":        gorm.Expr("visits + 1"),
+			"last_visit_at": now,
+		}
+		if seen == 0 {
+			updates["unique_visitors"] = gorm.Expr("unique_visitors + 1")
+		}
+		return tx.Model(&ZoneStats{}).Where("zone_id = ?", zone.ID).Updates(updates).Error
+	})
+	if err != nil {
+		log.Printf("⚠️ Zone stats: failed to start visit of %s in %s: %v", userID, zone.ID, err)
+	}
+}
+
+// EndVisit - ukončí otvorenú návštevu hráča; vráti ju (nil ak žiadna nebola)
+func (s *Service) EndVisit(userID uuid.UUID, reason string) *Visit {
+	now := time.Now()
+
+	var open Visit
+	if err := s.db.Where("user_id = ? AND exited_at IS NULL", userID).
+		Order("entered_at DESC").First(&open).Error; err != nil {
+		return nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return endVisits(tx, tx.Where("user_id = ? AND exited_at IS NULL", userID), reason, now)
+	})
+	if err != nil {
+		log.Printf("⚠️ Zone stats: failed to end visit of %s: %v", userID, err)
+		return nil
+	}
+
+	duration := int64(sessionDuration(open.EnteredAt, now).Seconds())
+	open.ExitedAt = &now
+	open.DurationSeconds = &duration
+	open.EndReason = reason
+	return &open
+}
+
+// CloseZone - zóna bola odstránená (cleanup / admin); štatistiky ostávajú
+func (s *Service) CloseZone(zone gameplay.Zone, reason string) {
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := endVisits(tx, tx.Where("zone_id = ? AND exited_at IS NULL", zone.ID), EndZoneClosed, now); err != nil {
+			return err
+		}
+		if err := ensure(tx, zone); err != nil {
+			return err
+		}
+		return tx.Model(&ZoneStats{}).Where("zone_id = ?", zone.ID).Updates(map[string]interface{}{
+			"name":          zone.Name,
+			"biome":         zone.Biome,
+			"danger_level":  zone.DangerLevel,
+			"tier_required": zone.TierRequired,
+			"closed_at":     now,
+			"close_reason":  reason,
+		}).Error
+	})
+	if err != nil {
+		log.Printf("⚠️ Zone stats: failed to close %s: %v", zone.ID, err)
+	}
+}
+
+func sessionDuration(enteredAt, now time.Time) time.Duration {
+	duration := now.Sub(enteredAt)
+	if duration < 0 {
+		return 0
+	}
+	if duration > MaxSessionDuration {
+		return MaxSessionDuration
+	}
+	return duration
+}
+
+// endVisits - uzavrie vybrané otvorené návštevy a pripočíta ich dĺžku k zóne
+func endVisits(tx *gorm.DB, scope *gorm.DB, reason string, now time.Time) error {
+	var open []Visit
+	if err := scope.Clauses(clause.Locking{Strength: "UPDATE"}).Find(&open).Error; err != nil {
+		return err
+	}
+
+	for _, visit := range open {
+		duration := int64(sessionDuration(visit.EnteredAt, now).Seconds())
+		if err := tx.Model(&Visit{}).Where("id = ?", visit.ID).Updates(map[string]interface{}{
+			"exited_at":        now,
+			"duration_seconds": duration,
+			"end_reason":       reason,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&ZoneStats{}).Where("zone_id = ?", visit.ZoneID).Updates(map[string]interface{}{
+			"completed_sessions":    gorm.Expr("completed_sessions + 1"),
+			"total_session_seconds": gorm.Expr("total_session_seconds + ?", duration),
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForZone - štatistiky jednej zóny (aj už odstránenej)
+func (s *Service) ForZone(zoneID uuid.UUID) (*ZoneReport, error) {
+	var stats ZoneStats
+	if err := s.db.First(&stats, "zone_id = ?", zoneID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	var rarities []RarityStats
+	if err := s.db.Where("zone_id = ?", zoneID).Order("item_type, rarity").Find(&rarities).Error; err != nil {
+		return nil, err
+	}
+
+	var active int64
+	s.db.Model(&Visit{}).Where("zone_id = ? AND exited_at IS NULL", zoneID).Count(&active)
+
+	report := &ZoneReport{
+		ZoneStats:             stats,
+		IsClosed:              stats.ClosedAt != nil,
+		AverageSessionSeconds: stats.AverageSessionSeconds(),
+		ActiveVisitors:        active,
+		CollectionRate: collectionRate(stats.ArtifactsSpawned+stats.GearSpawned,
+			stats.ArtifactsCollected+stats.GearCollected),
+		ByRarity: map[string]map[string]ItemCounts{},
+	}
+	for _, r := range rarities {
+		addRarity(report.ByRarity, r.ItemType, r.Rarity, r.Spawned, r.Collected)
+	}
+	return report, nil
+}
+
+// Aggregate - súčty za zóny zoskupené podľa biome / tier / zone_type / danger_level
+func (s *Service) Aggregate(groupBy string, filter Filter) ([]AggregateRow, error) {
+	column, ok := groupColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGroupBy, groupBy)
+	}
+
+	where, args := filterSQL(filter, "")
+
+	rows := []AggregateRow{}
+	err := s.db.Raw(fmt.Sprintf(`
+		SELECT CAST(%[1]s AS TEXT) AS key,
+		       COUNT(*) AS zones,
+		       COALESCE(SUM(visits), 0) AS visits,
+		       COALESCE(SUM(unique_visitors), 0) AS unique_visitors,
+		       COALESCE(SUM(completed_sessions), 0) AS completed_sessions,
+		       COALESCE(SUM(total_session_seconds), 0) AS total_session_seconds,
+		       COALESCE(SUM(artifacts_spawned), 0) AS artifacts_spawned,
+		       COALESCE(SUM(artifacts_collected), 0) AS artifacts_collected,
+		       COALESCE(SUM(gear_spawned), 0) AS gear_spawned,
+		       COALESCE(SUM(gear_collected), 0) AS gear_collected,
+		       COUNT(depleted_at) AS depleted_zones,
+		       AVG(time_to_depletion_seconds) AS avg_time_to_depletion_seconds
+		FROM gameplay.zone_stats
+		WHERE %[2]s
+		GROUP BY %[1]s
+		ORDER BY %[1]s`, column, where), args...).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	joinWhere, joinArgs := filterSQL(filter, "zs.")
+	var rarities []struct {
+		Key       string
+		ItemType  string
+		Rarity    string
+		Spawned   int64
+		Collected int64
+	}
+	err = s.db.Raw(fmt.Sprintf(`
+		SELECT CAST(zs.%[1]s AS TEXT) AS key, r.item_type, r.rarity,
+		       SUM(r.spawned) AS spawned, SUM(r.collected) AS collected
+		FROM gameplay.zone_rarity_stats r
+		JOIN gameplay.zone_stats zs ON zs.zone_id = r.zone_id
+		WHERE %[2]s
+		GROUP BY zs.%[1]s, r.item_type, r.rarity`, column, joinWhere), joinArgs...).
+		Scan(&rarities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*AggregateRow, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		if row.CompletedSessions > 0 {
+			row.AverageSessionSeconds = float64(row.TotalSessionSeconds) / float64(row.CompletedSessions)
+		}
+		row.CollectionRate = collectionRate(row.ArtifactsSpawned+row.GearSpawned, row.ArtifactsCollected+row.GearCollected)
+		row.ByRarity = map[string]map[string]ItemCounts{}
+		byKey[row.Key] = row
+	}
+	for _, r := range rarities {
+		if row, ok := byKey[r.Key]; ok {
+			addRarity(row.ByRarity, r.ItemType, r.Rarity, r.Spawned, r.Collected)
+		}
+	}
+
+	return rows, nil
+}
+
+// filterSQL - WHERE podmienka nad gameplay.zone_stats (prefix = alias tabuľky v JOIN)
+func filterSQL(filter Filter, prefix string) (string, []interface{}) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+
+	if filter.Biome != "" {
+		conditions = append(conditions, prefix+"biome = ?")
+		args = append(args, filter.Biome)
+	}
+	if filter.Tier != nil {
+		conditions = append(conditions, prefix+"tier_required = ?")
+		args = append(args, *filter.Tier)
+	}
+	if filter.ZoneType != "" {
+		conditions = append(conditions, prefix+"zone_type = ?")
+		args = append(args, filter.ZoneType)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, prefix+"zone_created_at >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, prefix+"zone_created_at < ?")
+		args = append(args, *filter.To)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func addRarity(target map[string]map[string]ItemCounts, itemType, rarity string, spawned, collected int64) {
+	if target[itemType] == nil {
+		target[itemType] = map[string]ItemCounts{}
+	}
+	target[itemType][rarity] = ItemCounts{Spawned: spawned, Collected: collected}
+}
+
+func collectionRate(spawned, collected int64) float64 {
+	if spawned == 0 {
+		return 0
+	}
+	return float64(collected) / float64(spawned)
+}
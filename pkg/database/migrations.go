@@ -1,11 +1,22 @@
 package database
 
 import (
+	"time"
+
+	"geoanomaly/internal/analytics"
+	"geoanomaly/internal/audit"
 	"geoanomaly/internal/auth"
 	"geoanomaly/internal/deployable"
+	"geoanomaly/internal/friends"
+	"geoanomaly/internal/game"
 	"geoanomaly/internal/gameplay"
+	"geoanomaly/internal/leaderboard"
+	"geoanomaly/internal/locationhistory"
 	"geoanomaly/internal/menu"
+	"geoanomaly/internal/movement"
+	"geoanomaly/internal/notifications"
 	"geoanomaly/internal/scanner"
+	"geoanomaly/internal/zonestats"
 
 	"gorm.io/gorm"
 )
@@ -24,6 +35,33 @@ func AutoMigrate(db *gorm.DB) error {
 		&gameplay.Artifact{},
 		&gameplay.Gear{},
 		&auth.PlayerSession{},
+		&auth.RefreshSession{},
+		&audit.AdminAction{},
+		// Leaderboard - objavené zóny (dedup)
+		&leaderboard.ZoneDiscovery{},
+		// Friends models
+		&friends.Friendship{},
+		&friends.Block{},
+		&friends.LocationShare{},
+		// Movement plausibility (anti-spoofing)
+		&movement.Profile{},
+		&movement.Violation{},
+		// Zone stats (prežijú cleanup zóny)
+		&zonestats.ZoneStats{},
+		&zonestats.RarityStats{},
+		&zonestats.Visit{},
+		// Analytics event log + denné rollupy
+		&analytics.Event{},
+		&analytics.DailyItemStats{},
+		&analytics.DailyZoneStats{},
+		&analytics.DailyEconomy{},
+		&analytics.DailyActivity{},
+		&analytics.DailyFunnel{},
+		// Verziované spawn tabuľky
+		&game.SpawnConfigVersion{},
+		&game.ZoneWaitlistEntry{},
+		&game.ZoneAction{},
+		&notifications.Notification{},
 		// Menu models
 		&menu.Currency{},
 		&menu.Transaction{},
@@ -40,6 +78,11 @@ func AutoMigrate(db *gorm.DB) error {
 		// Deployable Scanner models
 		&deployable.DeployedDevice{},
 		&deployable.DeviceHack{},
+		&deployable.HackChallenge{},
+		&deployable.DefenseModule{},
+		&deployable.DeviceNetwork{},
+		&deployable.DeviceNetworkMember{},
+		&deployable.DeviceNetworkDevice{},
 		&deployable.HackTool{},
 		&deployable.DeviceAccess{},
 		&deployable.DeviceScanHistory{},
@@ -85,6 +128,15 @@ func AutoMigrate(db *gorm.DB) error {
 		return err
 	}
 
+	// Geography stĺpec + indexy pre geoquery vrstvu (PostGIS aj bounding box fallback)
+	if err := addZoneGeographyColumn(db); err != nil {
+		return err
+	}
+
+	if err := createBoundingBoxIndexes(db); err != nil {
+		return err
+	}
+
 	// ✅ PRIDANÉ: Add last_disabled_at column for deployed_devices
 	if err := addLastDisabledAtColumn(db); err != nil {
 		return err
@@ -95,6 +147,11 @@ func AutoMigrate(db *gorm.DB) error {
 		return err
 	}
 
+	// Partitioned location history (append-only trail, monthly partitions)
+	if err := locationhistory.NewService(db).EnsureSchema(time.Now()); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -461,6 +518,62 @@ func addGeographyColumn(db *gorm.DB) error {
 	return nil
 }
 
+// addZoneGeographyColumn - uložený geography bod pre zóny (ST_DWithin / KNN cez GIST index)
+func addZoneGeographyColumn(db *gorm.DB) error {
+	if err := db.Exec(`
+		ALTER TABLE zones 
+		ADD COLUMN IF NOT EXISTS location_geog GEOGRAPHY(POINT, 4326) GENERATED ALWAYS AS (
+			ST_SetSRID(ST_MakePoint(location_longitude::float8, location_latitude::float8), 4326)::geography
+		) STORED
+	`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_zones_location_geog 
+		ON zones USING GIST (location_geog)
+	`).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createBoundingBoxIndexes - btree (lat, lng) indexy pre geoquery bez PostGIS
+func createBoundingBoxIndexes(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_zones_lat_lng 
+		ON zones (location_latitude, location_longitude) 
+		WHERE is_active = true
+	`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_deployed_devices_lat_lng 
+		ON deployed_devices (latitude, longitude)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Laboratóriá majú vlastnú schému a SQL migráciu - index len ak tabuľka existuje
+	var labsExist bool
+	if err := db.Raw("SELECT to_regclass('laboratory.laboratories') IS NOT NULL").Scan(&labsExist).Error; err != nil {
+		return err
+	}
+	if labsExist {
+		if err := db.Exec(`
+			CREATE INDEX IF NOT EXISTS idx_laboratories_lat_lng 
+			ON laboratory.laboratories (location_latitude, location_longitude) 
+			WHERE is_placed = true
+		`).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // ✅ PRIDANÉ: Add last_disabled_at column for deployed_devices
 func addLastDisabledAtColumn(db *gorm.DB) error {
 	// Add last_disabled_at column if it doesn't exist
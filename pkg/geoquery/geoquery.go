@@ -0,0 +1,296 @@
+// Package geoquery - spoločná vrstva pre proximity dopyty (zóny, zariadenia, laboratóriá).
+//
+// S PostGIS ide o ST_DWithin nad geography stĺpcom s GIST indexom. Bez PostGIS
+// (alebo s GEO_QUERY_MODE=bbox) sa použije čisté SQL: bounding box nad btree
+// indexom (lat, lng) a presná haversine podmienka na zvyšných riadkoch.
+package geoquery
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Mode - spôsob vyhodnotenia proximity dopytov
+type Mode string
+
+const (
+	ModePostGIS Mode = "postgis"
+	ModeBBox    Mode = "bbox"
+)
+
+const (
+	EarthRadiusMeters = 6371000.0
+
+	// MaxZoneRadiusMeters - najväčší polomer zóny (game.MaxEventZoneRadius), horná hranica pre Containing
+	MaxZoneRadiusMeters = 5000.0
+)
+
+// Columns - stĺpce tabuľky, nad ktorou sa hľadá
+type Columns struct {
+	Lat       string // numerický stĺpec zemepisnej šírky
+	Lng       string // numerický stĺpec zemepisnej dĺžky
+	Geography string // uložený geography(POINT,4326) stĺpec s GIST indexom (voliteľné)
+	Radius    string // polomer objektu v metroch (len pre Containing)
+	MaxRadius float64
+}
+
+var (
+	ZoneColumns = Columns{
+		Lat:       "location_latitude",
+		Lng:       "location_longitude",
+		Geography: "location_geog",
+		Radius:    "radius_meters",
+		MaxRadius: MaxZoneRadiusMeters,
+	}
+	DeviceColumns = Columns{
+		Lat:       "latitude",
+		Lng:       "longitude",
+		Geography: "location",
+	}
+	LaboratoryColumns = Columns{
+		Lat:       "location_latitude",
+		Lng:       "location_longitude",
+		Geography: "location",
+	}
+)
+
+// As - stĺpce s aliasom tabuľky (napr. "dd" pre JOIN dopyty)
+func (c Columns) As(alias string) Columns {
+	prefixed := c
+	prefixed.Lat = alias + "." + c.Lat
+	prefixed.Lng = alias + "." + c.Lng
+	if c.Geography != "" {
+		prefixed.Geography = alias + "." + c.Geography
+	}
+	if c.Radius != "" {
+		prefixed.Radius = alias + "." + c.Radius
+	}
+	return prefixed
+}
+
+// Querier - generuje SQL výrazy pre zvolený Mode
+type Querier struct {
+	mode Mode
+}
+
+var (
+	detectOnce   sync.Once
+	detectedMode Mode
+)
+
+// New - Querier s automaticky zisteným režimom (raz za proces)
+func New(db *gorm.DB) *Querier {
+	detectOnce.Do(func() {
+		detectedMode = detectMode(db)
+		log.Printf("🗺️ Geo queries using %s mode", detectedMode)
+	})
+	return &Querier{mode: detectedMode}
+}
+
+// NewWithMode - Querier s pevne daným režimom (benchmarky, testy)
+func NewWithMode(mode Mode) *Querier {
+	return &Querier{mode: mode}
+}
+
+// Mode - aktuálny režim
+func (q *Querier) Mode() Mode {
+	return q.mode
+}
+
+func detectMode(db *gorm.DB) Mode {
+	switch Mode(strings.ToLower(os.Getenv("GEO_QUERY_MODE"))) {
+	case ModePostGIS:
+		return ModePostGIS
+	case ModeBBox:
+		return ModeBBox
+	}
+
+	if db == nil {
+		return ModeBBox
+	}
+
+	var installed bool
+	if err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')").Scan(&installed).Error; err != nil {
+		log.Printf("⚠️ PostGIS detection failed, falling back to bounding box queries: %v", err)
+		return ModeBBox
+	}
+	if !installed {
+		return ModeBBox
+	}
+	return ModePostGIS
+}
+
+// Within - riadky do radiusMeters od bodu (index-backed v oboch režimoch)
+func (q *Querier) Within(c Columns, lat, lng, radiusMeters float64) clause.Expr {
+	if q.mode == ModePostGIS {
+		return clause.Expr{
+			SQL:  fmt.Sprintf("ST_DWithin(%s, %s, ?)", geographyOf(c), pointSQL),
+			Vars: []interface{}{lng, lat, radiusMeters},
+		}
+	}
+
+	box := BoundingBox(lat, lng, radiusMeters)
+	boxSQL, boxVars := box.sql(c)
+	distSQL, distVars := haversineSQL(c, lat, lng)
+
+	return clause.Expr{
+		SQL:  fmt.Sprintf("(%s AND %s <= ?)", boxSQL, distSQL),
+		Vars: append(append(boxVars, distVars...), radiusMeters),
+	}
+}
+
+// Containing - objekty s vlastným polomerom (c.Radius), ktoré bod pokrývajú (napr. zóna, v ktorej hráč stojí)
+func (q *Querier) Containing(c Columns, lat, lng float64) clause.Expr {
+	within := q.Within(c, lat, lng, c.MaxRadius)
+	distance := q.Distance(c, lat, lng)
+
+	return clause.Expr{
+		SQL:  fmt.Sprintf("(%s AND %s <= %s)", within.SQL, distance.SQL, c.Radius),
+		Vars: append(append([]interface{}{}, within.Vars...), distance.Vars...),
+	}
+}
+
+// Distance - vzdialenosť v metroch od bodu (pre SELECT / ORDER BY)
+func (q *Querier) Distance(c Columns, lat, lng float64) clause.Expr {
+	if q.mode == ModePostGIS {
+		return clause.Expr{
+			SQL:  fmt.Sprintf("ST_Distance(%s, %s)", geographyOf(c), pointSQL),
+			Vars: []interface{}{lng, lat},
+		}
+	}
+
+	distSQL, distVars := haversineSQL(c, lat, lng)
+	return clause.Expr{SQL: distSQL, Vars: distVars}
+}
+
+// Nearest - výraz na zoradenie od najbližšieho (s PostGIS KNN operátor <-> nad GIST indexom)
+func (q *Querier) Nearest(c Columns, lat, lng float64) clause.Expr {
+	if q.mode == ModePostGIS {
+		return clause.Expr{
+			SQL:  fmt.Sprintf("%s <-> %s", geographyOf(c), pointSQL),
+			Vars: []interface{}{lng, lat},
+		}
+	}
+	return q.Distance(c, lat, lng)
+}
+
+// OrderByDistance - zoradenie od najbližšieho pre GORM Order()
+func (q *Querier) OrderByDistance(c Columns, lat, lng float64) clause.OrderBy {
+	return clause.OrderBy{Expression: q.Nearest(c, lat, lng)}
+}
+
+// Args - argumenty pre raw SQL; clause.Expr sa rozbalí na svoje Vars v poradí, v akom je v dopyte
+func Args(args ...interface{}) []interface{} {
+	flat := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		if expr, ok := arg.(clause.Expr); ok {
+			flat = append(flat, expr.Vars...)
+			continue
+		}
+		flat = append(flat, arg)
+	}
+	return flat
+}
+
+const pointSQL = "ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography"
+
+func geographyOf(c Columns) string {
+	if c.Geography != "" {
+		return c.Geography
+	}
+	// Bez uloženého stĺpca index nepomôže, ale dopyt funguje
+	return fmt.Sprintf("ST_SetSRID(ST_MakePoint(%s, %s), 4326)::geography", c.Lng, c.Lat)
+}
+
+// haversineSQL - presná vzdialenosť v metroch bez PostGIS
+func haversineSQL(c Columns, lat, lng float64) (string, []interface{}) {
+	sql := fmt.Sprintf(
+		"(2 * %f * ASIN(LEAST(1, SQRT(POWER(SIN(RADIANS(%s - ?) / 2), 2) + COS(RADIANS(?)) * COS(RADIANS(%s)) * POWER(SIN(RADIANS(%s - ?) / 2), 2)))))",
+		EarthRadiusMeters, c.Lat, c.Lat, c.Lng,
+	)
+	return sql, []interface{}{lat, lat, lng}
+}
+
+// BBox - obdĺžnik v stupňoch, ktorý obsahuje celý kruh
+type BBox struct {
+	MinLat float64
+	MaxLat float64
+	MinLng float64
+	MaxLng float64
+	// WrapsAntimeridian - MinLng > MaxLng, obdĺžnik prechádza cez ±180°
+	WrapsAntimeridian bool
+}
+
+// BoundingBox - obdĺžnik okolo kruhu s polomerom radiusMeters
+func BoundingBox(lat, lng, radiusMeters float64) BBox {
+	deltaLat := radiusMeters / EarthRadiusMeters * 180 / math.Pi
+
+	box := BBox{
+		MinLat: lat - deltaLat,
+		MaxLat: lat + deltaLat,
+		MinLng: -180,
+		MaxLng: 180,
+	}
+
+	// Kruh zasahuje cez pól - všetky dĺžky
+	if box.MinLat <= -90 || box.MaxLat >= 90 {
+		box.MinLat = math.Max(box.MinLat, -90)
+		box.MaxLat = math.Min(box.MaxLat, 90)
+		return box
+	}
+
+	deltaLng := math.Asin(math.Sin(radiusMeters/EarthRadiusMeters)/math.Cos(lat*math.Pi/180)) * 180 / math.Pi
+	if math.IsNaN(deltaLng) || deltaLng >= 180 {
+		return box
+	}
+
+	box.MinLng = lng - deltaLng
+	box.MaxLng = lng + deltaLng
+
+	if box.MinLng < -180 {
+		box.MinLng += 360
+		box.WrapsAntimeridian = true
+	} else if box.MaxLng > 180 {
+		box.MaxLng -= 360
+		box.WrapsAntimeridian = true
+	}
+
+	return box
+}
+
+// Contains - bod leží v obdĺžniku
+func (b BBox) Contains(lat, lng float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.WrapsAntimeridian {
+		return lng >= b.MinLng || lng <= b.MaxLng
+	}
+	return lng >= b.MinLng && lng <= b.MaxLng
+}
+
+func (b BBox) sql(c Columns) (string, []interface{}) {
+	if b.WrapsAntimeridian {
+		return fmt.Sprintf("%s BETWEEN ? AND ? AND (%s >= ? OR %s <= ?)", c.Lat, c.Lng, c.Lng),
+			[]interface{}{b.MinLat, b.MaxLat, b.MinLng, b.MaxLng}
+	}
+	return fmt.Sprintf("%s BETWEEN ? AND ? AND %s BETWEEN ? AND ?", c.Lat, c.Lng),
+		[]interface{}{b.MinLat, b.MaxLat, b.MinLng, b.MaxLng}
+}
+
+// Haversine - vzdialenosť dvoch bodov v metroch (rovnaký vzorec ako SQL fallback)
+func Haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * EarthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
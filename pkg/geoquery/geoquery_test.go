@@ -0,0 +1,315 @@
+package geoquery
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+func TestBoundingBox_ContainsCircle(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lng float64
+		radius   float64
+	}{
+		{"bratislava", 48.1486, 17.1077, 5000},
+		{"equator", 0, 0, 1000},
+		{"antimeridian east", 10, 179.99, 5000},
+		{"antimeridian west", -10, -179.99, 5000},
+		{"near north pole", 89.99, 45, 5000},
+	}
+
+	for _, tt := range tests {
+		box := BoundingBox(tt.lat, tt.lng, tt.radius)
+		// Body na kružnici (mierne vo vnútri) musia byť v obdĺžniku
+		for bearing := 0.0; bearing < 360; bearing += 15 {
+			lat, lng := destination(tt.lat, tt.lng, bearing, tt.radius*0.999)
+			if !box.Contains(lat, lng) {
+				t.Errorf("%s: point %.6f,%.6f at bearing %.0f outside box %+v", tt.name, lat, lng, bearing, box)
+			}
+		}
+	}
+}
+
+func TestBoundingBox_Antimeridian(t *testing.T) {
+	box := BoundingBox(0, 179.99, 5000)
+	if !box.WrapsAntimeridian {
+		t.Fatalf("expected box to wrap antimeridian: %+v", box)
+	}
+	if !box.Contains(0, -179.99) {
+		t.Errorf("expected point across antimeridian to be inside: %+v", box)
+	}
+	if box.Contains(0, 0) {
+		t.Errorf("point on the other side of the globe must be outside: %+v", box)
+	}
+}
+
+func TestBoundingBox_Pole(t *testing.T) {
+	box := BoundingBox(89.99, 0, 5000)
+	if box.MaxLat != 90 || box.MinLng != -180 || box.MaxLng != 180 {
+		t.Errorf("box around pole must span all longitudes: %+v", box)
+	}
+}
+
+func TestExpressions_PlaceholdersMatchVars(t *testing.T) {
+	for _, mode := range []Mode{ModePostGIS, ModeBBox} {
+		q := NewWithMode(mode)
+		exprs := map[string]clause.Expr{
+			"within":            q.Within(ZoneColumns, 48.1, 17.1, 1000),
+			"within antimerid.": q.Within(DeviceColumns, 0, 179.99, 5000),
+			"containing":        q.Containing(ZoneColumns, 48.1, 17.1),
+			"distance":          q.Distance(LaboratoryColumns.As("l"), 48.1, 17.1),
+			"nearest":           q.Nearest(DeviceColumns, 48.1, 17.1),
+		}
+		for name, expr := range exprs {
+			if got := strings.Count(expr.SQL, "?"); got != len(expr.Vars) {
+				t.Errorf("%s/%s: %d placeholders, %d vars", mode, name, got, len(expr.Vars))
+			}
+		}
+	}
+}
+
+func TestArgs_FlattensExpressions(t *testing.T) {
+	within := NewWithMode(ModePostGIS).Within(DeviceColumns, 1, 2, 3)
+	args := Args("active", within, "owner")
+	want := []interface{}{"active", 2.0, 1.0, 3.0, "owner"}
+	if fmt.Sprint(args) != fmt.Sprint(want) {
+		t.Errorf("Args() = %v, want %v", args, want)
+	}
+}
+
+func TestHaversine(t *testing.T) {
+	// Bratislava - Viedeň ~55 km
+	d := Haversine(48.1486, 17.1077, 48.2082, 16.3738)
+	if d < 54000 || d > 56000 {
+		t.Errorf("unexpected distance %.0f m", d)
+	}
+}
+
+// destination - bod vo vzdialenosti meters a smere bearing (stupne) od lat/lng
+func destination(lat, lng, bearing, meters float64) (float64, float64) {
+	φ1 := lat * math.Pi / 180
+	λ1 := lng * math.Pi / 180
+	θ := bearing * math.Pi / 180
+	δ := meters / EarthRadiusMeters
+
+	φ2 := math.Asin(math.Sin(φ1)*math.Cos(δ) + math.Cos(φ1)*math.Sin(δ)*math.Cos(θ))
+	λ2 := λ1 + math.Atan2(math.Sin(θ)*math.Sin(δ)*math.Cos(φ1), math.Cos(δ)-math.Sin(φ1)*math.Sin(φ2))
+
+	lng2 := math.Mod(λ2*180/math.Pi+540, 360) - 180
+	return φ2 * 180 / math.Pi, lng2
+}
+
+// ==========================================
+// BENCHMARKY (100k zón)
+// ==========================================
+
+const (
+	benchZoneCount = 100000
+	benchCenterLat = 48.1486
+	benchCenterLng = 17.1077
+	benchSpreadDeg = 2.0 // zóny rozhodené ±2° okolo stredu (~220 km)
+	benchRadius    = 2000.0
+)
+
+type benchZone struct {
+	Lat, Lng float64
+	Radius   float64
+}
+
+func benchZones() []benchZone {
+	rng := rand.New(rand.NewSource(42))
+	zones := make([]benchZone, benchZoneCount)
+	for i := range zones {
+		zones[i] = benchZone{
+			Lat:    benchCenterLat + (rng.Float64()*2-1)*benchSpreadDeg,
+			Lng:    benchCenterLng + (rng.Float64()*2-1)*benchSpreadDeg,
+			Radius: 100 + rng.Float64()*400,
+		}
+	}
+	return zones
+}
+
+// BenchmarkInMemoryLinearScan - pôvodný prístup: načítať všetky zóny a filtrovať v Go
+func BenchmarkInMemoryLinearScan(b *testing.B) {
+	zones := benchZones()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		found := 0
+		for _, z := range zones {
+			if Haversine(benchCenterLat, benchCenterLng, z.Lat, z.Lng) <= benchRadius {
+				found++
+			}
+		}
+		_ = found
+	}
+}
+
+// BenchmarkInMemoryBoundingBox - rovnaký filter s bounding box predfiltrom
+func BenchmarkInMemoryBoundingBox(b *testing.B) {
+	zones := benchZones()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		box := BoundingBox(benchCenterLat, benchCenterLng, benchRadius)
+		found := 0
+		for _, z := range zones {
+			if box.Contains(z.Lat, z.Lng) && Haversine(benchCenterLat, benchCenterLng, z.Lat, z.Lng) <= benchRadius {
+				found++
+			}
+		}
+		_ = found
+	}
+}
+
+// setupBenchDB - dočasná tabuľka so 100k zónami (benchmark sa preskočí bez DB_HOST)
+func setupBenchDB(b *testing.B) (*gorm.DB, bool) {
+	if os.Getenv("DB_HOST") == "" {
+		b.Skip("DB_HOST not set, skipping database benchmark")
+	}
+
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+		os.Getenv("DB_HOST"),
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_PASSWORD"),
+		os.Getenv("DB_NAME"),
+		os.Getenv("DB_PORT"),
+		os.Getenv("DB_SSLMODE"),
+		os.Getenv("DB_TIMEZONE"),
+	)
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		b.Fatalf("failed to connect to benchmark database: %v", err)
+	}
+
+	// Dočasná tabuľka žije len na jednom spojení
+	sqlDB, err := db.DB()
+	if err != nil {
+		b.Fatalf("failed to get generic DB: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	var hasPostGIS bool
+	db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')").Scan(&hasPostGIS)
+
+	statements := []string{
+		`CREATE TEMP TABLE bench_zones (
+			id SERIAL PRIMARY KEY,
+			location_latitude DECIMAL(10,8) NOT NULL,
+			location_longitude DECIMAL(11,8) NOT NULL,
+			radius_meters INTEGER NOT NULL,
+			is_active BOOLEAN NOT NULL DEFAULT true
+		)`,
+		fmt.Sprintf(`INSERT INTO bench_zones (location_latitude, location_longitude, radius_meters)
+			SELECT %f + (random() * 2 - 1) * %f, %f + (random() * 2 - 1) * %f, 100 + floor(random() * 400)
+			FROM generate_series(1, %d)`,
+			benchCenterLat, benchSpreadDeg, benchCenterLng, benchSpreadDeg, benchZoneCount),
+		`CREATE INDEX ON bench_zones (location_latitude, location_longitude) WHERE is_active = true`,
+	}
+	if hasPostGIS {
+		statements = append(statements,
+			`ALTER TABLE bench_zones ADD COLUMN location_geog GEOGRAPHY(POINT, 4326) GENERATED ALWAYS AS (
+				ST_SetSRID(ST_MakePoint(location_longitude::float8, location_latitude::float8), 4326)::geography
+			) STORED`,
+			`CREATE INDEX ON bench_zones USING GIST (location_geog)`,
+		)
+	}
+	statements = append(statements, `ANALYZE bench_zones`)
+
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			b.Fatalf("failed to seed benchmark table: %v", err)
+		}
+	}
+
+	b.Cleanup(func() {
+		db.Exec("DROP TABLE IF EXISTS bench_zones")
+		sqlDB.Close()
+	})
+
+	return db, hasPostGIS
+}
+
+func benchmarkWithin(b *testing.B, db *gorm.DB, q *Querier) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var ids []int
+		if err := db.Table("bench_zones").Select("id").
+			Where("is_active = true").
+			Where(q.Within(ZoneColumns, benchCenterLat, benchCenterLng, benchRadius)).
+			Order(q.OrderByDistance(ZoneColumns, benchCenterLat, benchCenterLng)).
+			Find(&ids).Error; err != nil {
+			b.Fatalf("query failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkWithin100k(b *testing.B) {
+	db, hasPostGIS := setupBenchDB(b)
+
+	b.Run("bbox", func(b *testing.B) {
+		benchmarkWithin(b, db, NewWithMode(ModeBBox))
+	})
+
+	b.Run("postgis", func(b *testing.B) {
+		if !hasPostGIS {
+			b.Skip("PostGIS not installed")
+		}
+		benchmarkWithin(b, db, NewWithMode(ModePostGIS))
+	})
+
+	// Pôvodný prístup: všetky aktívne zóny do aplikácie + filter v Go
+	b.Run("full_scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var zones []benchZone
+			if err := db.Table("bench_zones").
+				Select("location_latitude AS lat, location_longitude AS lng, radius_meters AS radius").
+				Where("is_active = true").
+				Find(&zones).Error; err != nil {
+				b.Fatalf("query failed: %v", err)
+			}
+			found := 0
+			for _, z := range zones {
+				if Haversine(benchCenterLat, benchCenterLng, z.Lat, z.Lng) <= benchRadius {
+					found++
+				}
+			}
+			_ = found
+		}
+	})
+}
+
+func BenchmarkContaining100k(b *testing.B) {
+	db, hasPostGIS := setupBenchDB(b)
+
+	modes := []Mode{ModeBBox}
+	if hasPostGIS {
+		modes = append(modes, ModePostGIS)
+	}
+
+	for _, mode := range modes {
+		q := NewWithMode(mode)
+		b.Run(string(mode), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var ids []int
+				if err := db.Table("bench_zones").Select("id").
+					Where("is_active = true").
+					Where(q.Containing(ZoneColumns, benchCenterLat, benchCenterLng)).
+					Order(q.OrderByDistance(ZoneColumns, benchCenterLat, benchCenterLng)).
+					Limit(1).
+					Find(&ids).Error; err != nil {
+					b.Fatalf("query failed: %v", err)
+				}
+			}
+		})
+	}
+}
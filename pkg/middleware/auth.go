@@ -11,16 +11,34 @@ import (
 	"github.com/google/uuid"
 )
 
+// AccessTokenTTL - platnosť access tokenu; dlhodobé prihlásenie rieši refresh token
+const AccessTokenTTL = 15 * time.Minute
+
 type JWTClaims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
-	Tier     int       `json:"tier"`
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+	Tier       int       `json:"tier"`
+	SessionID  string    `json:"sid,omitempty"`    // refresh session (zariadenie), ku ktorej token patrí
+	IssuedAtMs int64     `json:"iat_ms,omitempty"` // iat v milisekundách (iat má len sekundy) - pre hromadné odvolanie
 	jwt.RegisteredClaims
 }
 
 func JWTAuth() gin.HandlerFunc {
+	return jwtAuth(false)
+}
+
+// JWTAuthStream - JWTAuth pre WebSocket/SSE: prehliadače tam nevedia poslať hlavičku,
+// preto akceptuje aj ?access_token= (len pre realtime endpointy)
+func JWTAuthStream() gin.HandlerFunc {
+	return jwtAuth(true)
+}
+
+func jwtAuth(allowQueryToken bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && allowQueryToken && c.Query("access_token") != "" {
+			authHeader = "Bearer " + c.Query("access_token")
+		}
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			c.Abort()
@@ -47,10 +65,35 @@ func JWTAuth() gin.HandlerFunc {
 		}
 
 		if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+			// Zabanovaný účet
+			if isUserBanned(claims.UserID) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "Account is banned",
+					"code":  "ACCOUNT_BANNED",
+				})
+				c.Abort()
+				return
+			}
+
+			// Odvolaný token (logout, ban, zmena hesla)
+			if isTokenRevoked(claims) {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Token has been revoked",
+					"code":  "TOKEN_REVOKED",
+				})
+				c.Abort()
+				return
+			}
+
 			// Set user context
 			c.Set("user_id", claims.UserID)
 			c.Set("username", claims.Username)
 			c.Set("tier", claims.Tier)
+			c.Set("token_id", claims.ID)
+			c.Set("session_id", claims.SessionID)
+			if claims.ExpiresAt != nil {
+				c.Set("token_expires", claims.ExpiresAt.Time)
+			}
 			c.Next()
 		} else {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
@@ -60,18 +103,22 @@ func JWTAuth() gin.HandlerFunc {
 	}
 }
 
-func GenerateJWT(userID uuid.UUID, username string, tier int) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+func GenerateJWT(userID uuid.UUID, username string, tier int, sessionID string) (string, error) {
+	now := time.Now()
+	expirationTime := now.Add(AccessTokenTTL)
 
 	claims := &JWTClaims{
-		UserID:   userID,
-		Username: username,
-		Tier:     tier,
+		UserID:     userID,
+		Username:   username,
+		Tier:       tier,
+		SessionID:  sessionID,
+		IssuedAtMs: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "geoanomaly",
+			ID:        uuid.New().String(), // jti - potrebné pre odvolanie tokenu
 		},
 	}
 
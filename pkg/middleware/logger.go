@@ -2,10 +2,14 @@ package middleware
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/gin-gonic/gin"
 )
 
+// accessTokenParam - JWT z ?access_token= (realtime endpointy) sa nesmie dostať do logov
+var accessTokenParam = regexp.MustCompile(`([?&]access_token=)[^&]*`)
+
 func Logger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		return fmt.Sprintf("[GeoAnomaly] %v | %3d | %13v | %15s | %-7s %#v\n%s",
@@ -14,7 +18,7 @@ func Logger() gin.HandlerFunc {
 			param.Latency,
 			param.ClientIP,
 			param.Method,
-			param.Path,
+			accessTokenParam.ReplaceAllString(param.Path, "${1}[REDACTED]"),
 			param.ErrorMessage,
 		)
 	})
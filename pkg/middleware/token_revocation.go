@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis kľúče pre odvolané tokeny
+//   - revoked:jti:<jti>         -> jednotlivý token (logout), TTL = zostávajúca platnosť tokenu
+//   - revoked:session:<sid>     -> všetky access tokeny jednej refresh session (zariadenia)
+//   - revoked:user:<user_id>    -> unix čas v ms; všetky tokeny vydané v tej milisekunde alebo skôr sú neplatné
+const (
+	revokedTokenKeyPrefix   = "revoked:jti:"
+	revokedSessionKeyPrefix = "revoked:session:"
+	revokedUserKeyPrefix    = "revoked:user:"
+	bannedUserKeyPrefix     = "banned:user:"
+)
+
+// revokeAllTTL - ako dlho držíme hromadné odvolanie; pokrýva aj staršie 24h tokeny
+const revokeAllTTL = 24 * time.Hour
+
+// secondsCutoffLimit - staršie záznamy revoked:user ukladali sekundy; menšie číslo nie je ms timestamp
+const secondsCutoffLimit = 100_000_000_000
+
+var revocationClient *redis.Client
+
+// SetRevocationStore - nastaví Redis klienta pre denylist tokenov (volá sa pri štarte servera)
+func SetRevocationStore(client *redis.Client) {
+	revocationClient = client
+	if client == nil {
+		log.Printf("⚠️ [AUTH] Redis not available - token revocation disabled")
+		return
+	}
+	log.Printf("🔐 [AUTH] Token revocation store enabled")
+}
+
+// RevokeToken - odvolá jeden konkrétny token (napr. pri logout)
+func RevokeToken(tokenID string, expiresAt time.Time) error {
+	if revocationClient == nil {
+		return fmt.Errorf("token revocation store not configured")
+	}
+	if tokenID == "" {
+		return fmt.Errorf("token has no jti")
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil // token už aj tak expiroval
+	}
+
+	return revocationClient.Set(context.Background(), revokedTokenKeyPrefix+tokenID, "revoked", ttl).Err()
+}
+
+// RevokeSession - odvolá access tokeny vydané pre danú refresh session
+func RevokeSession(sessionID string) error {
+	if revocationClient == nil {
+		return fmt.Errorf("token revocation store not configured")
+	}
+	if sessionID == "" {
+		return nil
+	}
+
+	return revocationClient.Set(context.Background(), revokedSessionKeyPrefix+sessionID, "revoked", AccessTokenTTL).Err()
+}
+
+// RevokeAllUserTokens - odvolá všetky doteraz vydané tokeny používateľa (ban, zmena hesla)
+func RevokeAllUserTokens(userID uuid.UUID) error {
+	if revocationClient == nil {
+		return fmt.Errorf("token revocation store not configured")
+	}
+
+	key := revokedUserKeyPrefix + userID.String()
+	return revocationClient.Set(context.Background(), key, time.Now().UnixMilli(), revokeAllTTL).Err()
+}
+
+// MarkUserBanned - JWTAuth bude používateľa odmietať; pri dočasnom bane kľúč sám expiruje
+func MarkUserBanned(userID uuid.UUID, until *time.Time) error {
+	if revocationClient == nil {
+		return fmt.Errorf("token revocation store not configured")
+	}
+
+	var ttl time.Duration // 0 = permanentný ban
+	if until != nil {
+		ttl = time.Until(*until)
+		if ttl <= 0 {
+			return ClearUserBan(userID)
+		}
+	}
+
+	return revocationClient.Set(context.Background(), bannedUserKeyPrefix+userID.String(), "banned", ttl).Err()
+}
+
+// ClearUserBan - zruší ban značku (unban)
+func ClearUserBan(userID uuid.UUID) error {
+	if revocationClient == nil {
+		return fmt.Errorf("token revocation store not configured")
+	}
+
+	return revocationClient.Del(context.Background(), bannedUserKeyPrefix+userID.String()).Err()
+}
+
+// isUserBanned - rýchla kontrola banu bez DB
+func isUserBanned(userID uuid.UUID) bool {
+	if revocationClient == nil {
+		return false
+	}
+
+	exists, err := revocationClient.Exists(context.Background(), bannedUserKeyPrefix+userID.String()).Result()
+	if err != nil {
+		log.Printf("⚠️ [AUTH] Failed to check ban status: %v", err)
+		return false
+	}
+	return exists > 0
+}
+
+// isTokenRevoked - skontroluje jti denylist aj hromadné odvolanie pre používateľa
+func isTokenRevoked(claims *JWTClaims) bool {
+	if revocationClient == nil {
+		return false
+	}
+
+	ctx := context.Background()
+
+	var keys []string
+	if claims.ID != "" {
+		keys = append(keys, revokedTokenKeyPrefix+claims.ID)
+	}
+	if claims.SessionID != "" {
+		keys = append(keys, revokedSessionKeyPrefix+claims.SessionID)
+	}
+	if len(keys) > 0 {
+		exists, err := revocationClient.Exists(ctx, keys...).Result()
+		if err != nil {
+			log.Printf("⚠️ [AUTH] Failed to check token denylist: %v", err)
+		} else if exists > 0 {
+			return true
+		}
+	}
+
+	revokedAt, err := revocationClient.Get(ctx, revokedUserKeyPrefix+claims.UserID.String()).Result()
+	if err == redis.Nil {
+		return false
+	}
+	if err != nil {
+		log.Printf("⚠️ [AUTH] Failed to check user revocation: %v", err)
+		return false
+	}
+
+	cutoff, err := strconv.ParseInt(revokedAt, 10, 64)
+	if err != nil {
+		return false
+	}
+	return issuedBeforeCutoff(claims, cutoff)
+}
+
+// issuedBeforeCutoff - token vydaný najneskôr v čase hromadného odvolania (ms). Porovnanie
+// v sekundách by odmietlo aj token z refreshu hneď po odvolaní v tej istej sekunde.
+func issuedBeforeCutoff(claims *JWTClaims, cutoffMs int64) bool {
+	if cutoffMs < secondsCutoffLimit {
+		cutoffMs *= 1000 // záznam z doby pred ms presnosťou
+	}
+
+	switch {
+	case claims.IssuedAtMs > 0:
+		return claims.IssuedAtMs <= cutoffMs
+	case claims.IssuedAt != nil:
+		// starší token bez iat_ms - presnosť len na sekundy
+		return claims.IssuedAt.Time.UnixMilli() <= cutoffMs
+	default:
+		// Token bez iat nevieme zaradiť - radšej ho odmietneme
+		return true
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestIssuedBeforeCutoff(t *testing.T) {
+	revokedAt := time.Date(2026, 10, 16, 12, 0, 0, 400_000_000, time.UTC)
+	cutoff := revokedAt.UnixMilli()
+
+	claims := func(issued time.Time, withMs bool) *JWTClaims {
+		c := &JWTClaims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(issued)}}
+		if withMs {
+			c.IssuedAtMs = issued.UnixMilli()
+		}
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		claims *JWTClaims
+		cutoff int64
+		want   bool
+	}{
+		{"issued before revoke", claims(revokedAt.Add(-time.Minute), true), cutoff, true},
+		{"issued at revoke", claims(revokedAt, true), cutoff, true},
+		{"refreshed in the same second", claims(revokedAt.Add(300*time.Millisecond), true), cutoff, false},
+		{"issued after revoke", claims(revokedAt.Add(time.Minute), true), cutoff, false},
+		{"legacy token without iat_ms", claims(revokedAt.Add(-time.Second), false), cutoff, true},
+		{"legacy cutoff in seconds", claims(revokedAt.Add(-time.Minute), true), revokedAt.Unix(), true},
+		{"legacy cutoff in seconds, newer token", claims(revokedAt.Add(time.Minute), true), revokedAt.Unix(), false},
+		{"token without iat", &JWTClaims{}, cutoff, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := issuedBeforeCutoff(tt.claims, tt.cutoff); got != tt.want {
+				t.Errorf("issuedBeforeCutoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}